@@ -227,3 +227,14 @@ func (p *DefaultChannelClient) addUser(u channelClientUser) {
 	defer p.usersMutex.Unlock()
 	p.users = append(p.users, u)
 }
+
+func (p *DefaultChannelClient) removeUser(u channelClientUser) {
+	p.usersMutex.Lock()
+	defer p.usersMutex.Unlock()
+	for i, user := range p.users {
+		if user == u {
+			p.users = append(p.users[:i], p.users[i+1:]...)
+			return
+		}
+	}
+}
@@ -4,47 +4,167 @@
 package amqp
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
+	AMQP "github.com/streadway/amqp"
 )
 
 // DownlinkHandler is called for downlink messages
 type DownlinkHandler func(subscriber Subscriber, appID string, devID string, req types.DownlinkMessage)
 
-// PublishDownlink publishes a downlink message to the AMQP broker
+// PublishDownlink publishes a downlink message to the AMQP broker in the encoding of the application
 func (c *DefaultPublisher) PublishDownlink(dataDown types.DownlinkMessage) error {
 	key := DeviceKey{dataDown.AppID, dataDown.DevID, DeviceDownlink, ""}
-	msg, err := json.Marshal(dataDown)
+	encoding := c.encoding(dataDown.AppID)
+	msg, err := encoding.Marshal(dataDown)
 	if err != nil {
 		return fmt.Errorf("Unable to marshal the message payload: %s", err)
 	}
-	return c.publish(key.String(), msg, time.Now())
+	return c.publish(key.String(), encoding, msg, time.Now())
 }
 
+// DownlinkAckHandler is called for downlink messages. The message is acknowledged if the handler returns nil,
+// otherwise it is dead-lettered.
+type DownlinkAckHandler func(subscriber Subscriber, appID string, devID string, req types.DownlinkMessage) error
+
 // SubscribeDeviceDownlink subscribes to all downlink messages for the given application and device
 func (s *DefaultSubscriber) SubscribeDeviceDownlink(appID, devID string, handler DownlinkHandler) error {
+	return s.SubscribeDeviceDownlinkAck(appID, devID, func(subscriber Subscriber, appID string, devID string, req types.DownlinkMessage) error {
+		handler(subscriber, appID, devID, req)
+		return nil
+	})
+}
+
+// SubscribeDeviceDownlinkAck subscribes to all downlink messages for the given application and device. Messages
+// are acknowledged after the handler returned without error. Messages that can not be unmarshaled or that the
+// handler returns an error for are dead-lettered.
+func (s *DefaultSubscriber) SubscribeDeviceDownlinkAck(appID, devID string, handler DownlinkAckHandler) error {
 	key := DeviceKey{appID, devID, DeviceDownlink, ""}
 	messages, err := s.subscribe(key.String())
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		for delivery := range messages {
-			dataDown := &types.DownlinkMessage{}
-			err := json.Unmarshal(delivery.Body, dataDown)
-			if err != nil {
-				s.ctx.Warnf("Could not unmarshal downlink %v (%s)", delivery, err)
-				continue
+	go s.handleDownlinkAck(messages, handler)
+	return nil
+}
+
+func (s *DefaultSubscriber) handleDownlinkAck(messages <-chan AMQP.Delivery, handler DownlinkAckHandler) {
+	for delivery := range messages {
+		dataDown := &types.DownlinkMessage{}
+		err := unmarshalDelivery(delivery, dataDown)
+		if err != nil {
+			s.reject(delivery, fmt.Errorf("Could not unmarshal downlink (%s)", err))
+			continue
+		}
+		// The routing key determines the device, the AppID and DevID in the message are only used if the
+		// routing key does not contain them
+		if key, err := ParseDeviceKey(delivery.RoutingKey); err == nil {
+			if key.AppID != "" {
+				dataDown.AppID = key.AppID
+			}
+			if key.DevID != "" {
+				dataDown.DevID = key.DevID
 			}
-			handler(s, dataDown.AppID, dataDown.DevID, *dataDown)
-			delivery.Ack(false)
 		}
-	}()
+		if err := handler(s, dataDown.AppID, dataDown.DevID, *dataDown); err != nil {
+			s.reject(delivery, err)
+			continue
+		}
+		if err := delivery.Ack(false); err != nil {
+			s.ctx.Warnf("Could not acknowledge message (%s)", err)
+		}
+	}
+}
+
+// SubscribeAppDownlinkAck subscribes to all downlink messages for the given application
+func (s *DefaultSubscriber) SubscribeAppDownlinkAck(appID string, handler DownlinkAckHandler) error {
+	return s.SubscribeDeviceDownlinkAck(appID, "", handler)
+}
 
+// SubscribeDownlinkAck subscribes to all downlink messages that the current user has access to
+func (s *DefaultSubscriber) SubscribeDownlinkAck(handler DownlinkAckHandler) error {
+	return s.SubscribeDeviceDownlinkAck("", "", handler)
+}
+
+// AppDownlinkQueue returns the name of the downlink queue of an application. Application IDs can not contain dots,
+// so the queue name can not collide with other queues of the subscriber, such as its dead-letter queue.
+func (s *DefaultSubscriber) AppDownlinkQueue(appID string) string {
+	return fmt.Sprintf("%s.app.%s", s.name, appID)
+}
+
+type appDownlinkConsumer struct {
+	subscriber *DefaultSubscriber
+	queue      string
+	handler    DownlinkAckHandler
+}
+
+func (c *appDownlinkConsumer) use(channel *AMQP.Channel) error {
+	// Without global QoS, the prefetch count applies to each consumer, so that every application
+	// gets its own share of unacknowledged messages
+	err := channel.Qos(PrefetchCount, PrefetchSize, false)
+	if err != nil {
+		return fmt.Errorf("Failed to set channel QoS (%s)", err)
+	}
+	deliveries, err := channel.Consume(c.queue, c.queue, false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	go c.subscriber.handleDownlinkAck(deliveries, c.handler)
+	return nil
+}
+
+func (c *appDownlinkConsumer) close() {}
+
+// ConsumeAppDownlink declares a durable downlink queue for the application, binds it to the downlink messages of
+// the application and consumes it. Each application is consumed by its own consumer, so that applications with
+// many downlinks do not delay the downlinks of other applications.
+func (s *DefaultSubscriber) ConsumeAppDownlink(appID string, handler DownlinkAckHandler) error {
+	queue := s.AppDownlinkQueue(appID)
+	s.appConsumersMutex.Lock()
+	defer s.appConsumersMutex.Unlock()
+	if _, ok := s.appConsumers[appID]; ok {
+		return nil
+	}
+	if _, err := s.channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("Failed to declare queue '%s' (%s)", queue, err)
+	}
+	if err := s.QueueBind(queue, DeviceKey{appID, "", DeviceDownlink, ""}.String()); err != nil {
+		return err
+	}
+	c := &appDownlinkConsumer{
+		subscriber: s,
+		queue:      queue,
+		handler:    handler,
+	}
+	if err := c.use(s.channel); err != nil {
+		return err
+	}
+	s.addUser(c)
+	if s.appConsumers == nil {
+		s.appConsumers = make(map[string]*appDownlinkConsumer)
+	}
+	s.appConsumers[appID] = c
+	return nil
+}
+
+// StopAppDownlink stops consuming the downlink queue of the application and deletes the queue
+func (s *DefaultSubscriber) StopAppDownlink(appID string) error {
+	s.appConsumersMutex.Lock()
+	defer s.appConsumersMutex.Unlock()
+	queue := s.AppDownlinkQueue(appID)
+	if c, ok := s.appConsumers[appID]; ok {
+		s.removeUser(c)
+		delete(s.appConsumers, appID)
+		if err := s.channel.Cancel(queue, false); err != nil {
+			return fmt.Errorf("Failed to cancel consumer of queue '%s' (%s)", queue, err)
+		}
+	}
+	if _, err := s.channel.QueueDelete(queue, false, false, false); err != nil {
+		return fmt.Errorf("Failed to delete queue '%s' (%s)", queue, err)
+	}
 	return nil
 }
 
@@ -4,11 +4,14 @@
 package amqp
 
 import (
+	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
 	. "github.com/smartystreets/assertions"
+	AMQP "github.com/streadway/amqp"
 )
 
 func TestPublishDownlink(t *testing.T) {
@@ -67,3 +70,120 @@ func TestSubscribeDownlink(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestSubscribeDownlinkAck(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "TestSubscribeDownlinkAck"), "guest", "guest", host)
+	err := c.Connect()
+	a.So(err, ShouldBeNil)
+	defer c.Disconnect()
+
+	ch, err := c.(*DefaultClient).GetChannel()
+	a.So(err, ShouldBeNil)
+	defer ch.Close()
+	err = ch.ExchangeDeclare("ttn-test.dead-letter", "topic", false, true, false, false, nil)
+	a.So(err, ShouldBeNil)
+	deadLetterQueue, err := ch.QueueDeclare("", false, true, true, false, nil)
+	a.So(err, ShouldBeNil)
+	err = ch.QueueBind(deadLetterQueue.Name, "#", "ttn-test.dead-letter", false, nil)
+	a.So(err, ShouldBeNil)
+	deadLetters, err := ch.Consume(deadLetterQueue.Name, "", true, true, false, false, nil)
+	a.So(err, ShouldBeNil)
+
+	p := c.NewPublisher("amq.topic")
+	err = p.Open()
+	a.So(err, ShouldBeNil)
+	defer p.Close()
+
+	s := c.NewSubscriber("amq.topic", "", false, true).WithDeadLetterExchange("ttn-test.dead-letter")
+	err = s.Open()
+	a.So(err, ShouldBeNil)
+	defer s.Close()
+
+	err = s.SubscribeAppDownlinkAck("ack-app", func(_ Subscriber, appID, devID string, req types.DownlinkMessage) error {
+		if devID == "fail" {
+			return errors.New("could not enqueue")
+		}
+		return nil
+	})
+	a.So(err, ShouldBeNil)
+
+	err = p.PublishDownlink(types.DownlinkMessage{AppID: "ack-app", DevID: "test", PayloadRaw: []byte{0x01}})
+	a.So(err, ShouldBeNil)
+	err = p.PublishDownlink(types.DownlinkMessage{AppID: "ack-app", DevID: "fail", PayloadRaw: []byte{0x01}})
+	a.So(err, ShouldBeNil)
+	err = ch.Publish("amq.topic", "ack-app.devices.invalid.down", false, false, AMQP.Publishing{Body: []byte("invalid")})
+	a.So(err, ShouldBeNil)
+
+	errs := make(map[string]interface{})
+	for i := 0; i < 2; i++ {
+		select {
+		case delivery := <-deadLetters:
+			errs[delivery.RoutingKey] = delivery.Headers[ErrorHeader]
+		case <-time.After(time.Second):
+			t.Fatal("Did not receive dead-lettered message")
+		}
+	}
+	a.So(errs["ack-app.devices.fail.down"], ShouldEqual, "could not enqueue")
+	a.So(errs["ack-app.devices.invalid.down"], ShouldStartWith, "Could not unmarshal downlink")
+}
+
+func TestConsumeAppDownlink(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "TestConsumeAppDownlink"), "guest", "guest", host)
+	err := c.Connect()
+	a.So(err, ShouldBeNil)
+	defer c.Disconnect()
+
+	p := c.NewPublisher("amq.topic")
+	err = p.Open()
+	a.So(err, ShouldBeNil)
+	defer p.Close()
+
+	s := c.NewSubscriber("amq.topic", "ttn-test-downlink", true, false)
+	err = s.Open()
+	a.So(err, ShouldBeNil)
+	defer s.Close()
+
+	a.So(s.AppDownlinkQueue("app-1"), ShouldEqual, "ttn-test-downlink.app.app-1")
+
+	// The queue of an application does not collide with the dead-letter queue
+	a.So(s.AppDownlinkQueue("dead-letter"), ShouldNotEqual, "ttn-test-downlink.dead-letter")
+
+	received := make(chan types.DownlinkMessage, 10)
+	handler := func(_ Subscriber, appID, devID string, req types.DownlinkMessage) error {
+		received <- req
+		return nil
+	}
+	a.So(s.ConsumeAppDownlink("app-1", handler), ShouldBeNil)
+	a.So(s.ConsumeAppDownlink("app-2", handler), ShouldBeNil)
+	defer s.StopAppDownlink("app-2")
+
+	// The AppID and DevID are taken from the routing key
+	err = p.PublishDownlink(types.DownlinkMessage{AppID: "app-1", DevID: "dev", PayloadRaw: []byte{0x01}})
+	a.So(err, ShouldBeNil)
+	err = p.PublishDownlink(types.DownlinkMessage{AppID: "app-2", DevID: "dev", PayloadRaw: []byte{0x02}})
+	a.So(err, ShouldBeNil)
+
+	payloads := make(map[string][]byte)
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-received:
+			payloads[msg.AppID] = msg.PayloadRaw
+		case <-time.After(time.Second):
+			t.Fatal("Did not receive downlink")
+		}
+	}
+	a.So(payloads["app-1"], ShouldResemble, []byte{0x01})
+	a.So(payloads["app-2"], ShouldResemble, []byte{0x02})
+
+	// After stopping, downlinks of the application are no longer consumed
+	a.So(s.StopAppDownlink("app-1"), ShouldBeNil)
+	err = p.PublishDownlink(types.DownlinkMessage{AppID: "app-1", DevID: "dev", PayloadRaw: []byte{0x01}})
+	a.So(err, ShouldBeNil)
+	select {
+	case <-received:
+		t.Fatal("Received downlink after stopping")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
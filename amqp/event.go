@@ -4,41 +4,44 @@
 package amqp
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
 )
 
-// AppEventHandler is called for events
+// AppEventHandler is called for events. The payload is in the encoding of the application.
 type AppEventHandler func(sub Subscriber, appID string, eventType types.EventType, payload []byte)
 
-// DeviceEventHandler is called for events
+// DeviceEventHandler is called for events. The payload is in the encoding of the application.
 type DeviceEventHandler func(sub Subscriber, appID string, devID string, eventType types.EventType, payload []byte)
 
 // PublishAppEvent publishes an event to the topic for application events of the given type
-// it will marshal the payload to json
+// it will marshal the payload in the encoding of the application
 func (c *DefaultPublisher) PublishAppEvent(appID string, eventType types.EventType, payload interface{}) error {
 	key := ApplicationKey{appID, AppEvents, string(eventType)}
-	msg, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("Unable to marshal the message payload: %s", err)
-	}
-	c.publish(key.String(), msg, time.Now())
-	return nil
+	return c.publishEvent(key.String(), types.DeviceEvent{AppID: appID, Event: eventType, Data: payload})
 }
 
 // PublishDeviceEvent publishes an event to the topic for device events of the given type
-// it will marshal the payload to json
+// it will marshal the payload in the encoding of the application
 func (c *DefaultPublisher) PublishDeviceEvent(appID string, devID string, eventType types.EventType, payload interface{}) error {
 	key := DeviceKey{appID, devID, DeviceEvents, string(eventType)}
-	msg, err := json.Marshal(payload)
+	return c.publishEvent(key.String(), types.DeviceEvent{AppID: appID, DevID: devID, Event: eventType, Data: payload})
+}
+
+// publishEvent publishes the event data, or the complete DeviceEvent message if the application uses protobuf
+func (c *DefaultPublisher) publishEvent(key string, event types.DeviceEvent) error {
+	encoding := c.encoding(event.AppID)
+	var payload interface{} = event.Data
+	if encoding == types.ProtobufEncoding {
+		payload = event
+	}
+	msg, err := encoding.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("Unable to marshal the message payload: %s", err)
 	}
-	c.publish(key.String(), msg, time.Now())
-	return nil
+	return c.publish(key, encoding, msg, time.Now())
 }
 
 // SubscribeAppEvents subscribes to events of the given type for the given application. In order to subscribe to
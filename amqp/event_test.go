@@ -7,6 +7,7 @@ import (
 	"github.com/TheThingsNetwork/ttn/core/types"
 	. "github.com/TheThingsNetwork/ttn/utils/testing"
 	. "github.com/smartystreets/assertions"
+	AMQP "github.com/streadway/amqp"
 	"testing"
 	"time"
 )
@@ -75,3 +76,39 @@ func TestPublishSubscribeDeviceEvents(t *testing.T) {
 	err = wg.WaitFor(time.Millisecond * 200)
 	a.So(err, ShouldBeNil)
 }
+
+func TestPublishEventNack(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "TestPublishEventNack"), "guest", "guest", host)
+	err := c.Connect()
+	a.So(err, ShouldBeNil)
+	defer c.Disconnect()
+
+	// The AMQP server nacks messages that are routed to a full queue that rejects publishes
+	ch, err := c.(*DefaultClient).GetChannel()
+	a.So(err, ShouldBeNil)
+	defer ch.Close()
+	full, err := ch.QueueDeclare("", false, true, true, false, AMQP.Table{
+		"x-max-length": int32(0),
+		"x-overflow":   "reject-publish",
+	})
+	a.So(err, ShouldBeNil)
+	err = ch.QueueBind(full.Name, "nack-app.#", "amq.topic", false, nil)
+	a.So(err, ShouldBeNil)
+
+	defer func(retries int) { PublishRetries = retries }(PublishRetries)
+	PublishRetries = 0
+
+	p := c.NewPublisher("amq.topic")
+	err = p.Open()
+	a.So(err, ShouldBeNil)
+	defer p.Close()
+
+	err = p.PublishDeviceEvent("nack-app", "dev-id", "some-event", "payload")
+	a.So(err, ShouldNotBeNil)
+	err = p.PublishAppEvent("nack-app", "some-event", "payload")
+	a.So(err, ShouldNotBeNil)
+
+	err = p.PublishAppEvent("other-app", "some-event", "payload")
+	a.So(err, ShouldBeNil)
+}
@@ -4,6 +4,9 @@
 package amqp
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
@@ -18,11 +21,95 @@ type Publisher interface {
 	PublishDownlink(dataDown types.DownlinkMessage) error
 	PublishDeviceEvent(appID string, devID string, eventType types.EventType, payload interface{}) error
 	PublishAppEvent(appID string, eventType types.EventType, payload interface{}) error
+	WithEncoding(encoding func(appID string) types.Encoding) Publisher
 }
 
-// DefaultPublisher represents the default AMQP publisher
+var (
+	// PublishConfirmTimeout is the time to wait for the AMQP server to confirm a published message
+	PublishConfirmTimeout = 5 * time.Second
+	// PublishRetries says how many times a message that is not confirmed should be published again
+	PublishRetries = 3
+	// PublishRetryDelay says how long the publisher should wait between retries
+	PublishRetryDelay = 100 * time.Millisecond
+)
+
+// DefaultPublisher represents the default AMQP publisher. It publishes in confirm mode, so that a message is
+// only considered published after the AMQP server acknowledged it.
 type DefaultPublisher struct {
 	DefaultChannelClient
+
+	confirmMutex sync.Mutex
+	confirms     *confirmTracker
+
+	encodingFunc func(appID string) types.Encoding
+}
+
+// amqpPublisher publishes messages on an AMQP channel
+type amqpPublisher interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg AMQP.Publishing) error
+}
+
+// confirmTracker keeps track of the outstanding confirmations of the messages that are published on a channel in
+// confirm mode, so that messages can be published while others wait for their confirmation
+type confirmTracker struct {
+	mu          sync.Mutex
+	channel     amqpPublisher
+	deliveryTag uint64
+	pending     map[uint64]chan bool
+	closed      bool
+}
+
+// newConfirmTracker returns a confirmTracker for the channel, that receives the confirmations from confirms
+func newConfirmTracker(channel amqpPublisher, confirms <-chan AMQP.Confirmation) *confirmTracker {
+	t := &confirmTracker{
+		channel: channel,
+		pending: make(map[uint64]chan bool),
+	}
+	go t.run(confirms)
+	return t
+}
+
+// run delivers the confirmations to the messages that wait for them, until the channel is closed
+func (t *confirmTracker) run(confirms <-chan AMQP.Confirmation) {
+	for confirm := range confirms {
+		t.mu.Lock()
+		if ack, ok := t.pending[confirm.DeliveryTag]; ok {
+			delete(t.pending, confirm.DeliveryTag)
+			ack <- confirm.Ack
+		}
+		t.mu.Unlock()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for deliveryTag, ack := range t.pending {
+		delete(t.pending, deliveryTag)
+		close(ack)
+	}
+}
+
+// publish publishes the message and returns its delivery tag and the channel on which its confirmation is delivered.
+// The confirmation channel is closed if the AMQP channel is closed before the message was confirmed.
+func (t *confirmTracker) publish(exchange, key string, msg AMQP.Publishing) (uint64, <-chan bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return 0, nil, errors.New("Channel closed")
+	}
+	if err := t.channel.Publish(exchange, key, false, false, msg); err != nil {
+		return 0, nil, err
+	}
+	t.deliveryTag++
+	ack := make(chan bool, 1)
+	t.pending[t.deliveryTag] = ack
+	return t.deliveryTag, ack, nil
+}
+
+// forget stops waiting for the confirmation of the message with the delivery tag
+func (t *confirmTracker) forget(deliveryTag uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pending, deliveryTag)
 }
 
 // NewPublisher returns a new topic publisher on the specified exchange
@@ -37,11 +124,92 @@ func (c *DefaultClient) NewPublisher(exchange string) Publisher {
 	}
 }
 
-func (p *DefaultPublisher) publish(key string, msg []byte, timestamp time.Time) error {
-	return p.channel.Publish(p.exchange, key, false, false, AMQP.Publishing{
-		ContentType:  "application/json",
-		DeliveryMode: AMQP.Persistent,
-		Timestamp:    timestamp,
-		Body:         msg,
-	})
+// WithEncoding sets the function that returns the encoding of the messages that are published for an application.
+// Messages are published as JSON if it is not set or returns an empty encoding. The encoding is indicated by the
+// content type of the message.
+func (p *DefaultPublisher) WithEncoding(encoding func(appID string) types.Encoding) Publisher {
+	p.encodingFunc = encoding
+	return p
+}
+
+// encoding returns the encoding of the messages that are published for the application
+func (p *DefaultPublisher) encoding(appID string) types.Encoding {
+	if p.encodingFunc != nil {
+		if encoding := p.encodingFunc(appID); encoding != "" {
+			return encoding
+		}
+	}
+	return types.JSONEncoding
+}
+
+// Open opens a new channel and puts it in confirm mode
+func (p *DefaultPublisher) Open() error {
+	if err := p.DefaultChannelClient.Open(); err != nil {
+		return err
+	}
+	if err := p.use(p.channel); err != nil {
+		return err
+	}
+	p.addUser(p)
+	return nil
+}
+
+// use puts the channel in confirm mode. It is called again when the channel is reopened after a reconnect.
+func (p *DefaultPublisher) use(channel *AMQP.Channel) error {
+	p.confirmMutex.Lock()
+	defer p.confirmMutex.Unlock()
+	if err := channel.Confirm(false); err != nil {
+		return fmt.Errorf("Could not put channel in confirm mode (%s)", err)
+	}
+	p.confirms = newConfirmTracker(channel, channel.NotifyPublish(make(chan AMQP.Confirmation, 16)))
+	return nil
+}
+
+func (p *DefaultPublisher) close() {}
+
+func (p *DefaultPublisher) publish(key string, encoding types.Encoding, msg []byte, timestamp time.Time) (err error) {
+	for retries := 0; retries <= PublishRetries; retries++ {
+		if retries > 0 {
+			p.ctx.Warnf("Could not publish message with routing key %s (%s), retrying...", key, err)
+			<-time.After(PublishRetryDelay)
+		}
+		err = p.publishConfirmed(key, AMQP.Publishing{
+			ContentType:  encoding.ContentType(),
+			DeliveryMode: AMQP.Persistent,
+			Timestamp:    timestamp,
+			Body:         msg,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// publishConfirmed publishes the message and waits until the AMQP server confirms it. Other messages can be
+// published while it waits.
+func (p *DefaultPublisher) publishConfirmed(key string, msg AMQP.Publishing) error {
+	p.confirmMutex.Lock()
+	confirms := p.confirms
+	p.confirmMutex.Unlock()
+	if confirms == nil {
+		return errors.New("Publisher is not open")
+	}
+	deliveryTag, ack, err := confirms.publish(p.exchange, key, msg)
+	if err != nil {
+		return err
+	}
+	select {
+	case acked, ok := <-ack:
+		if !ok {
+			return errors.New("Channel closed before the message was confirmed")
+		}
+		if !acked {
+			return errors.New("Message was not acknowledged by the AMQP server")
+		}
+		return nil
+	case <-time.After(PublishConfirmTimeout):
+		confirms.forget(deliveryTag)
+		return errors.New("Timeout waiting for the AMQP server to confirm the message")
+	}
 }
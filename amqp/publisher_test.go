@@ -5,8 +5,10 @@ package amqp
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/assertions"
+	AMQP "github.com/streadway/amqp"
 )
 
 func TestOpenPublisher(t *testing.T) {
@@ -21,3 +23,54 @@ func TestOpenPublisher(t *testing.T) {
 	a.So(err, ShouldBeNil)
 	defer p.Close()
 }
+
+type testChannel struct {
+	published []string
+}
+
+func (c *testChannel) Publish(exchange, key string, mandatory, immediate bool, msg AMQP.Publishing) error {
+	c.published = append(c.published, key)
+	return nil
+}
+
+func TestConfirmTracker(t *testing.T) {
+	a := New(t)
+
+	channel := new(testChannel)
+	confirms := make(chan AMQP.Confirmation)
+	tracker := newConfirmTracker(channel, confirms)
+
+	tag1, ack1, err := tracker.publish("exchange", "key1", AMQP.Publishing{})
+	a.So(err, ShouldBeNil)
+	tag2, ack2, err := tracker.publish("exchange", "key2", AMQP.Publishing{})
+	a.So(err, ShouldBeNil)
+	tag3, ack3, err := tracker.publish("exchange", "key3", AMQP.Publishing{})
+	a.So(err, ShouldBeNil)
+	a.So(channel.published, ShouldResemble, []string{"key1", "key2", "key3"})
+	a.So([]uint64{tag1, tag2, tag3}, ShouldResemble, []uint64{1, 2, 3})
+
+	// Confirmations are delivered to the message with the delivery tag
+	confirms <- AMQP.Confirmation{DeliveryTag: tag2, Ack: true}
+	a.So(<-ack2, ShouldBeTrue)
+	confirms <- AMQP.Confirmation{DeliveryTag: tag1, Ack: false}
+	a.So(<-ack1, ShouldBeFalse)
+
+	// Messages that are no longer waited for are forgotten
+	tracker.forget(tag3)
+	confirms <- AMQP.Confirmation{DeliveryTag: tag3, Ack: true}
+	select {
+	case <-ack3:
+		t.Error("Expected confirmation of forgotten message to be dropped")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Messages that wait when the channel is closed are not confirmed
+	_, ack4, err := tracker.publish("exchange", "key4", AMQP.Publishing{})
+	a.So(err, ShouldBeNil)
+	close(confirms)
+	_, ok := <-ack4
+	a.So(ok, ShouldBeFalse)
+
+	_, _, err = tracker.publish("exchange", "key5", AMQP.Publishing{})
+	a.So(err, ShouldNotBeNil)
+}
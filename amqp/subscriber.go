@@ -5,6 +5,7 @@ package amqp
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
 	AMQP "github.com/streadway/amqp"
@@ -33,6 +34,14 @@ type Subscriber interface {
 	SubscribeDeviceDownlink(appID, devID string, handler DownlinkHandler) error
 	SubscribeAppDownlink(appID string, handler DownlinkHandler) error
 	SubscribeDownlink(handler DownlinkHandler) error
+	SubscribeDeviceDownlinkAck(appID, devID string, handler DownlinkAckHandler) error
+	SubscribeAppDownlinkAck(appID string, handler DownlinkAckHandler) error
+	SubscribeDownlinkAck(handler DownlinkAckHandler) error
+	WithDeadLetterExchange(exchange string) Subscriber
+
+	AppDownlinkQueue(appID string) string
+	ConsumeAppDownlink(appID string, handler DownlinkAckHandler) error
+	StopAppDownlink(appID string) error
 
 	SubscribeDeviceEvents(appID string, devID string, eventType types.EventType, handler DeviceEventHandler) error
 	SubscribeAppEvents(appID string, eventType types.EventType, handler AppEventHandler) error
@@ -42,9 +51,13 @@ type Subscriber interface {
 type DefaultSubscriber struct {
 	DefaultChannelClient
 
-	name       string
-	durable    bool
-	autoDelete bool
+	name               string
+	durable            bool
+	autoDelete         bool
+	deadLetterExchange string
+
+	appConsumersMutex sync.Mutex
+	appConsumers      map[string]*appDownlinkConsumer
 }
 
 // NewSubscriber returns a new topic subscriber on the specified exchange
@@ -62,6 +75,56 @@ func (c *DefaultClient) NewSubscriber(exchange, name string, durable, autoDelete
 	}
 }
 
+// WithDeadLetterExchange sets the exchange to which messages are published that could not be handled
+func (s *DefaultSubscriber) WithDeadLetterExchange(exchange string) Subscriber {
+	s.deadLetterExchange = exchange
+	return s
+}
+
+// ErrorHeader is the header that contains the reason why a message was dead-lettered
+const ErrorHeader = "x-error"
+
+// reject dead-letters a message that could not be handled. The message is published to the dead-letter
+// exchange with the same routing key and an ErrorHeader, and is then removed from the queue.
+func (s *DefaultSubscriber) reject(delivery AMQP.Delivery, reason error) {
+	ctx := s.ctx.WithField("RoutingKey", delivery.RoutingKey).WithError(reason)
+	if s.deadLetterExchange == "" {
+		ctx.Warn("Could not handle message, discarding")
+		delivery.Nack(false, false)
+		return
+	}
+	headers := AMQP.Table{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+	headers[ErrorHeader] = reason.Error()
+	err := s.channel.Publish(s.deadLetterExchange, delivery.RoutingKey, false, false, AMQP.Publishing{
+		Headers:      headers,
+		ContentType:  delivery.ContentType,
+		DeliveryMode: AMQP.Persistent,
+		Timestamp:    delivery.Timestamp,
+		Body:         delivery.Body,
+	})
+	if err != nil {
+		ctx.Warnf("Could not dead-letter message, discarding (%s)", err)
+		delivery.Nack(false, false)
+		return
+	}
+	ctx.Warn("Could not handle message, dead-lettered")
+	if err := delivery.Ack(false); err != nil {
+		s.ctx.Warnf("Could not acknowledge message (%s)", err)
+	}
+}
+
+// unmarshalDelivery decodes the body of a delivery in the encoding that is indicated by its content type
+func unmarshalDelivery(delivery AMQP.Delivery, v interface{}) error {
+	encoding, err := types.EncodingForContentType(delivery.ContentType)
+	if err != nil {
+		return err
+	}
+	return encoding.Unmarshal(delivery.Body, v)
+}
+
 // QueueDeclare declares the queue on the AMQP broker
 func (s *DefaultSubscriber) QueueDeclare() (string, error) {
 	queue, err := s.channel.QueueDeclare(s.name, s.durable, s.autoDelete, false, false, nil)
@@ -4,7 +4,6 @@
 package amqp
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -16,20 +15,21 @@ import (
 // UplinkHandler is called for uplink messages
 type UplinkHandler func(subscriber Subscriber, appID string, devID string, req types.UplinkMessage)
 
-// PublishUplink publishes an uplink message to the AMQP broker
+// PublishUplink publishes an uplink message to the AMQP broker in the encoding of the application
 func (c *DefaultPublisher) PublishUplink(dataUp types.UplinkMessage) error {
 	key := DeviceKey{dataUp.AppID, dataUp.DevID, DeviceUplink, ""}
-	msg, err := json.Marshal(dataUp)
+	encoding := c.encoding(dataUp.AppID)
+	msg, err := encoding.Marshal(dataUp)
 	if err != nil {
 		return fmt.Errorf("Unable to marshal the message payload: %s", err)
 	}
-	return c.publish(key.String(), msg, time.Time(dataUp.Metadata.Time))
+	return c.publish(key.String(), encoding, msg, time.Time(dataUp.Metadata.Time))
 }
 
 func (s *DefaultSubscriber) handleUplink(messages <-chan AMQP.Delivery, handler UplinkHandler) {
 	for delivery := range messages {
 		dataUp := &types.UplinkMessage{}
-		if err := json.Unmarshal(delivery.Body, dataUp); err != nil {
+		if err := unmarshalDelivery(delivery, dataUp); err != nil {
 			s.ctx.Warnf("Could not unmarshal uplink (%s)", err)
 			continue
 		}
@@ -67,3 +67,47 @@ func TestSubscribeUplink(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestSubscribeEncodedUplink(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "TestSubscribeEncodedUplink"), "guest", "guest", host)
+	err := c.Connect()
+	a.So(err, ShouldBeNil)
+	defer c.Disconnect()
+
+	encodings := map[string]types.Encoding{
+		"app-cbor":     types.CBOREncoding,
+		"app-protobuf": types.ProtobufEncoding,
+	}
+	p := c.NewPublisher("amq.topic").WithEncoding(func(appID string) types.Encoding { return encodings[appID] })
+	err = p.Open()
+	a.So(err, ShouldBeNil)
+	defer p.Close()
+
+	s := c.NewSubscriber("amq.topic", "", false, true)
+	err = s.Open()
+	a.So(err, ShouldBeNil)
+	defer s.Close()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	err = s.SubscribeUplink(func(_ Subscriber, appID, devID string, req types.UplinkMessage) {
+		a.So(devID, ShouldEqual, "test")
+		a.So(req.PayloadRaw, ShouldResemble, []byte{0x01, 0x08})
+		a.So(req.PayloadFields, ShouldResemble, map[string]interface{}{"on": true})
+		wg.Done()
+	})
+	a.So(err, ShouldBeNil)
+
+	for _, appID := range []string{"app-cbor", "app-protobuf"} {
+		err = p.PublishUplink(types.UplinkMessage{
+			AppID:         appID,
+			DevID:         "test",
+			PayloadRaw:    []byte{0x01, 0x08},
+			PayloadFields: map[string]interface{}{"on": true},
+		})
+		a.So(err, ShouldBeNil)
+	}
+
+	wg.Wait()
+}
@@ -10,12 +10,14 @@ import (
 	"github.com/juju/ratelimit"
 )
 
-// Registry for rate limiting
+// Registry for rate limiting. Entities with a full bucket are removed from the registry once per rate limiting period,
+// so that the registry does not keep every entity that it has seen.
 type Registry struct {
 	rate     int
 	per      time.Duration
 	mu       sync.RWMutex
 	entities map[string]*ratelimit.Bucket
+	swept    time.Time
 }
 
 // NewRegistry returns a new Registry for rate limiting
@@ -24,23 +26,41 @@ func NewRegistry(rate int, per time.Duration) *Registry {
 		rate:     rate,
 		per:      per,
 		entities: make(map[string]*ratelimit.Bucket),
+		swept:    time.Now(),
 	}
 }
 
 func (r *Registry) getOrCreate(id string, createFunc func() *ratelimit.Bucket) *ratelimit.Bucket {
 	r.mu.RLock()
 	limiter, ok := r.entities[id]
+	sweep := time.Since(r.swept) >= r.per
 	r.mu.RUnlock()
-	if ok {
+	if ok && !sweep {
 		return limiter
 	}
-	limiter = createFunc()
 	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.swept) >= r.per {
+		r.sweep()
+	}
+	if limiter, ok := r.entities[id]; ok {
+		return limiter
+	}
+	limiter = createFunc()
 	r.entities[id] = limiter
-	r.mu.Unlock()
 	return limiter
 }
 
+// sweep removes the entities with a full bucket, as a new bucket is the same. The caller must hold the write lock.
+func (r *Registry) sweep() {
+	for id, limiter := range r.entities {
+		if limiter.Available() >= limiter.Capacity() {
+			delete(r.entities, id)
+		}
+	}
+	r.swept = time.Now()
+}
+
 func (r *Registry) newFunc() *ratelimit.Bucket {
 	return ratelimit.NewBucketWithQuantum(r.per, int64(r.rate), int64(r.rate))
 }
@@ -55,6 +75,20 @@ func (r *Registry) Wait(id string) time.Duration {
 	return r.getOrCreate(id, r.newFunc).Take(1)
 }
 
+// Reached returns true if the ratelimit for the given entity has been reached, without taking from it. Entities
+// that are not in the registry have not reached their ratelimit.
+func (r *Registry) Reached(id string) bool {
+	r.mu.RLock()
+	limiter, ok := r.entities[id]
+	r.mu.RUnlock()
+	return ok && limiter.Available() <= 0
+}
+
+// Take takes from the ratelimit of the given entity, if it has not been reached
+func (r *Registry) Take(id string) {
+	r.getOrCreate(id, r.newFunc).TakeAvailable(1)
+}
+
 // WaitMaxDuration returns the time to wait until available, but with a max
 func (r *Registry) WaitMaxDuration(id string, max time.Duration) (time.Duration, bool) {
 	return r.getOrCreate(id, r.newFunc).TakeMaxDuration(1, max)
@@ -0,0 +1,45 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestRegistry(t *testing.T) {
+	a := New(t)
+
+	r := NewRegistry(2, 50*time.Millisecond)
+	a.So(r.Limit("a"), ShouldBeFalse)
+	a.So(r.Limit("a"), ShouldBeFalse)
+	a.So(r.Limit("a"), ShouldBeTrue)
+	a.So(r.Limit("b"), ShouldBeFalse)
+
+	// Entities are removed once their bucket is full again
+	time.Sleep(150 * time.Millisecond)
+	a.So(r.Limit("c"), ShouldBeFalse)
+	a.So(r.entities, ShouldHaveLength, 1)
+	a.So(r.entities, ShouldContainKey, "c")
+	a.So(r.Limit("c"), ShouldBeFalse)
+	a.So(r.Limit("c"), ShouldBeTrue)
+}
+
+func TestRegistryReachedTake(t *testing.T) {
+	a := New(t)
+	r := NewRegistry(2, time.Hour)
+
+	a.So(r.Reached("a"), ShouldBeFalse)
+	a.So(r.entities, ShouldBeEmpty)
+
+	r.Take("a")
+	a.So(r.Reached("a"), ShouldBeFalse)
+	r.Take("a")
+	a.So(r.Reached("a"), ShouldBeTrue)
+	r.Take("a")
+	a.So(r.Reached("a"), ShouldBeTrue)
+	a.So(r.Reached("b"), ShouldBeFalse)
+}
@@ -57,6 +57,11 @@ var brokerCmd = &cobra.Command{
 			time.Duration(viper.GetInt("broker.deduplication-delay")) * time.Millisecond,
 		)
 		broker.SetNetworkServer(viper.GetString("broker.networkserver-address"), nsCert, viper.GetString("broker.networkserver-token"))
+		broker.SetActivationRateLimits(
+			viper.GetInt("broker.activation-limit-dev-eui"),
+			viper.GetInt("broker.activation-limit-app-eui"),
+			time.Duration(viper.GetInt("broker.activation-backoff"))*time.Second,
+		)
 		err = broker.Init(component)
 		if err != nil {
 			ctx.WithError(err).Fatal("Could not initialize broker")
@@ -98,6 +103,13 @@ func init() {
 	brokerCmd.Flags().Int("deduplication-delay", 200, "Deduplication delay (in ms)")
 	viper.BindPFlag("broker.deduplication-delay", brokerCmd.Flags().Lookup("deduplication-delay"))
 
+	brokerCmd.Flags().Int("activation-limit-dev-eui", 20, "Maximum number of activations per DevEUI in the activation backoff (0 disables the limit)")
+	viper.BindPFlag("broker.activation-limit-dev-eui", brokerCmd.Flags().Lookup("activation-limit-dev-eui"))
+	brokerCmd.Flags().Int("activation-limit-app-eui", 0, "Maximum number of activations per AppEUI in the activation backoff (0 disables the limit)")
+	viper.BindPFlag("broker.activation-limit-app-eui", brokerCmd.Flags().Lookup("activation-limit-app-eui"))
+	brokerCmd.Flags().Int("activation-backoff", 60, "Time in which the activation limits apply (in s)")
+	viper.BindPFlag("broker.activation-backoff", brokerCmd.Flags().Lookup("activation-backoff"))
+
 	brokerCmd.Flags().String("server-address", "0.0.0.0", "The IP address to listen for communication")
 	brokerCmd.Flags().String("server-address-announce", "localhost", "The public IP address to announce")
 	brokerCmd.Flags().Int("server-port", 1902, "The port for communication")
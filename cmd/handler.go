@@ -11,6 +11,7 @@ import (
 	"os/signal"
 	goruntime "runtime"
 	"syscall"
+	"time"
 
 	pb "github.com/TheThingsNetwork/api/handler"
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
@@ -19,6 +20,7 @@ import (
 	"github.com/TheThingsNetwork/ttn/core/handler"
 	"github.com/TheThingsNetwork/ttn/core/proxy"
 	"github.com/TheThingsNetwork/ttn/core/proxy/jsonpb"
+	"github.com/TheThingsNetwork/ttn/mqtt"
 	"github.com/TheThingsNetwork/ttn/utils/parse"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/spf13/cobra"
@@ -41,6 +43,8 @@ var handlerCmd = &cobra.Command{
 			"Database":      fmt.Sprintf("%s/%d", viper.GetString("handler.redis-address"), viper.GetInt("handler.redis-db")),
 			"TTN Broker ID": viper.GetString("handler.broker-id"),
 			"MQTT":          viper.GetString("handler.mqtt-address"),
+			"MQTT Broker":   viper.GetString("handler.mqtt-broker-address"),
+			"MQTT Auth":     viper.GetString("handler.mqtt-auth-address"),
 			"AMQP":          viper.GetString("handler.amqp-address"),
 		}).Info("Initializing Handler")
 	},
@@ -80,7 +84,26 @@ var handlerCmd = &cobra.Command{
 				viper.GetString("handler.mqtt-username"),
 				viper.GetString("handler.mqtt-password"),
 				viper.GetString("handler.mqtt-address"),
-			).WithMQTTFields(viper.GetBool("handler.mqtt-fields"))
+			).WithMQTTFields(viper.GetBool("handler.mqtt-fields")).WithMQTTTopics(
+				viper.GetBool("handler.mqtt-v2-topics"),
+				viper.GetBool("handler.mqtt-v3-topics"),
+			)
+
+			mqttOptions := mqtt.ClientOptions{
+				QoS:               make(map[mqtt.MessageClass]byte),
+				PersistentSession: viper.GetBool("handler.mqtt-persistent-session"),
+				PublishBufferSize: viper.GetInt("handler.mqtt-publish-buffer"),
+			}
+			for class, key := range map[mqtt.MessageClass]string{
+				mqtt.UplinkMessages:   "handler.mqtt-uplink-qos",
+				mqtt.DownlinkMessages: "handler.mqtt-downlink-qos",
+				mqtt.EventMessages:    "handler.mqtt-events-qos",
+			} {
+				if qos := viper.GetInt(key); qos > 0 {
+					mqttOptions.QoS[class] = byte(qos)
+				}
+			}
+			handler = handler.WithMQTTClientOptions(mqttOptions)
 
 			mqttPort, err := parse.Port(viper.GetString("handler.mqtt-address"))
 			if err != nil {
@@ -91,7 +114,23 @@ var handlerCmd = &cobra.Command{
 			} else {
 				component.Identity.MqttAddress = fmt.Sprintf("%s:%d", viper.GetString("handler.server-address-announce"), mqttPort)
 			}
-		} else {
+		}
+		if viper.GetString("handler.mqtt-broker-address") != "" {
+			handler = handler.WithMQTTBroker(viper.GetString("handler.mqtt-broker-address"))
+
+			if viper.GetString("handler.mqtt-address") == "" {
+				mqttPort, err := parse.Port(viper.GetString("handler.mqtt-broker-address"))
+				if err != nil {
+					ctx.WithError(err).Error("Could not announce the handler")
+				}
+				if announceAddr := viper.GetString("handler.mqtt-address-announce"); announceAddr != "" {
+					component.Identity.MqttAddress = fmt.Sprintf("%s:%d", announceAddr, mqttPort)
+				} else {
+					component.Identity.MqttAddress = fmt.Sprintf("%s:%d", viper.GetString("handler.server-address-announce"), mqttPort)
+				}
+			}
+		}
+		if viper.GetString("handler.mqtt-address") == "" && viper.GetString("handler.mqtt-broker-address") == "" {
 			ctx.Warn("MQTT is not enabled in your configuration")
 		}
 		if viper.GetString("handler.amqp-address") != "" {
@@ -100,7 +139,7 @@ var handlerCmd = &cobra.Command{
 				viper.GetString("handler.amqp-password"),
 				viper.GetString("handler.amqp-address"),
 				viper.GetString("handler.amqp-exchange"),
-			)
+			).WithAMQPApplicationQueues(viper.GetBool("handler.amqp-app-queues"))
 
 			amqpPort, err := parse.Port(viper.GetString("handler.amqp-address"))
 			if err != nil {
@@ -121,6 +160,12 @@ var handlerCmd = &cobra.Command{
 			ctx.Debug("No extra device attribute set in your configuration")
 		}
 
+		handler = handler.WithActivationRateLimits(
+			viper.GetInt("handler.activation-limit-dev-eui"),
+			viper.GetInt("handler.activation-limit-app-eui"),
+			time.Duration(viper.GetInt("handler.activation-backoff"))*time.Second,
+		)
+
 		err = handler.Init(component)
 		if err != nil {
 			ctx.WithError(err).Fatal("Could not initialize handler")
@@ -169,6 +214,15 @@ var handlerCmd = &cobra.Command{
 			}()
 		}
 
+		if mqttAuthAddress := viper.GetString("handler.mqtt-auth-address"); mqttAuthAddress != "" {
+			go func() {
+				err := http.ListenAndServe(mqttAuthAddress, handler.MQTTAuthHandler())
+				if err != nil {
+					ctx.WithError(err).Fatal("Error in MQTT auth backend")
+				}
+			}()
+		}
+
 		sigChan := make(chan os.Signal)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		ctx.WithField("signal", <-sigChan).Info("signal received")
@@ -194,22 +248,42 @@ func init() {
 	handlerCmd.Flags().String("mqtt-username", "", "MQTT username")
 	handlerCmd.Flags().String("mqtt-password", "", "MQTT password")
 	handlerCmd.Flags().Bool("mqtt-fields", true, "Enable MQTT Fields")
+	handlerCmd.Flags().Bool("mqtt-v2-topics", true, "Publish and subscribe on MQTT topics in the v2 layout (<app>/devices/<dev>/up)")
+	handlerCmd.Flags().Bool("mqtt-v3-topics", false, "Publish and subscribe on MQTT topics in the v3 layout (v3/<app>/devices/<dev>/up)")
+	handlerCmd.Flags().Int("mqtt-uplink-qos", 0, "MQTT QoS for uplink messages")
+	handlerCmd.Flags().Int("mqtt-downlink-qos", 0, "MQTT QoS for downlink messages (1 if empty while persistent session is enabled)")
+	handlerCmd.Flags().Int("mqtt-events-qos", 0, "MQTT QoS for events")
+	handlerCmd.Flags().Bool("mqtt-persistent-session", false, "Subscribe to MQTT downlink messages in a persistent session")
+	handlerCmd.Flags().Int("mqtt-publish-buffer", 1000, "Number of MQTT messages to buffer while disconnected")
+	handlerCmd.Flags().String("mqtt-broker-address", "", "Host and port for the embedded MQTT broker. Leave empty to disable the embedded MQTT broker")
+	handlerCmd.Flags().String("mqtt-auth-address", "", "Host and port for the HTTP auth backend of an external MQTT broker. Leave empty to disable the auth backend")
 	viper.BindPFlag("handler.mqtt-address", handlerCmd.Flags().Lookup("mqtt-address"))
 	viper.BindPFlag("handler.mqtt-address-announce", handlerCmd.Flags().Lookup("mqtt-address-announce"))
 	viper.BindPFlag("handler.mqtt-username", handlerCmd.Flags().Lookup("mqtt-username"))
 	viper.BindPFlag("handler.mqtt-password", handlerCmd.Flags().Lookup("mqtt-password"))
 	viper.BindPFlag("handler.mqtt-fields", handlerCmd.Flags().Lookup("mqtt-fields"))
+	viper.BindPFlag("handler.mqtt-v2-topics", handlerCmd.Flags().Lookup("mqtt-v2-topics"))
+	viper.BindPFlag("handler.mqtt-v3-topics", handlerCmd.Flags().Lookup("mqtt-v3-topics"))
+	viper.BindPFlag("handler.mqtt-uplink-qos", handlerCmd.Flags().Lookup("mqtt-uplink-qos"))
+	viper.BindPFlag("handler.mqtt-downlink-qos", handlerCmd.Flags().Lookup("mqtt-downlink-qos"))
+	viper.BindPFlag("handler.mqtt-events-qos", handlerCmd.Flags().Lookup("mqtt-events-qos"))
+	viper.BindPFlag("handler.mqtt-persistent-session", handlerCmd.Flags().Lookup("mqtt-persistent-session"))
+	viper.BindPFlag("handler.mqtt-publish-buffer", handlerCmd.Flags().Lookup("mqtt-publish-buffer"))
+	viper.BindPFlag("handler.mqtt-broker-address", handlerCmd.Flags().Lookup("mqtt-broker-address"))
+	viper.BindPFlag("handler.mqtt-auth-address", handlerCmd.Flags().Lookup("mqtt-auth-address"))
 
 	handlerCmd.Flags().String("amqp-address", "", "AMQP host and port. Leave empty to disable AMQP")
 	handlerCmd.Flags().String("amqp-address-announce", "", "AMQP address to announce (takes value of server-address-announce if empty while enabled)")
 	handlerCmd.Flags().String("amqp-username", "guest", "AMQP username")
 	handlerCmd.Flags().String("amqp-password", "guest", "AMQP password")
 	handlerCmd.Flags().String("amqp-exchange", "ttn.handler", "AMQP exchange")
+	handlerCmd.Flags().Bool("amqp-app-queues", false, "Consume AMQP downlink messages from a queue per application")
 	viper.BindPFlag("handler.amqp-address", handlerCmd.Flags().Lookup("amqp-address"))
 	viper.BindPFlag("handler.amqp-address-announce", handlerCmd.Flags().Lookup("amqp-address-announce"))
 	viper.BindPFlag("handler.amqp-username", handlerCmd.Flags().Lookup("amqp-username"))
 	viper.BindPFlag("handler.amqp-password", handlerCmd.Flags().Lookup("amqp-password"))
 	viper.BindPFlag("handler.amqp-exchange", handlerCmd.Flags().Lookup("amqp-exchange"))
+	viper.BindPFlag("handler.amqp-app-queues", handlerCmd.Flags().Lookup("amqp-app-queues"))
 
 	handlerCmd.Flags().String("server-address", "0.0.0.0", "The IP address to listen for communication")
 	handlerCmd.Flags().String("server-address-announce", "localhost", "The public IP address to announce")
@@ -225,4 +299,11 @@ func init() {
 
 	handlerCmd.Flags().StringSlice("extra-device-attributes", nil, "Extra device attributes to be whitelisted")
 	viper.BindPFlag("handler.extra-device-attributes", handlerCmd.Flags().Lookup("extra-device-attributes"))
+
+	handlerCmd.Flags().Int("activation-limit-dev-eui", 10, "Maximum number of activations per DevEUI in the activation backoff (0 disables the limit)")
+	viper.BindPFlag("handler.activation-limit-dev-eui", handlerCmd.Flags().Lookup("activation-limit-dev-eui"))
+	handlerCmd.Flags().Int("activation-limit-app-eui", 0, "Maximum number of activations per AppEUI in the activation backoff (0 disables the limit)")
+	viper.BindPFlag("handler.activation-limit-app-eui", handlerCmd.Flags().Lookup("activation-limit-app-eui"))
+	handlerCmd.Flags().Int("activation-backoff", 60, "Time in which the activation limits apply (in s)")
+	viper.BindPFlag("handler.activation-backoff", handlerCmd.Flags().Lookup("activation-backoff"))
 }
@@ -0,0 +1,65 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+// Package activationlimit limits the number of activations per DevEUI and per AppEUI. The broker and the handler both
+// apply these limits to the activations that they handle. Only accepted activations are counted, so that forged
+// join-requests can not use up the limits of a device or an application.
+package activationlimit
+
+import (
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/api/ratelimit"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+)
+
+// ErrThrottled is returned for activations beyond the rate limits
+var ErrThrottled = errors.New("Activation rate limit reached")
+
+// Limits are the activation rate limits per DevEUI and per AppEUI
+type Limits struct {
+	devEUI *ratelimit.Registry
+	appEUI *ratelimit.Registry
+}
+
+// NewLimits returns Limits of devEUIRate activations per DevEUI and appEUIRate activations per AppEUI in the backoff
+// time. A rate of zero disables that limit. NewLimits returns nil if no limit is enabled.
+func NewLimits(devEUIRate, appEUIRate int, backoff time.Duration) *Limits {
+	if backoff <= 0 || (devEUIRate <= 0 && appEUIRate <= 0) {
+		return nil
+	}
+	l := new(Limits)
+	if devEUIRate > 0 {
+		l.devEUI = ratelimit.NewRegistry(devEUIRate, backoff)
+	}
+	if appEUIRate > 0 {
+		l.appEUI = ratelimit.NewRegistry(appEUIRate, backoff)
+	}
+	return l
+}
+
+// Throttled returns true if the rate limit of the DevEUI or the AppEUI is reached. Throttled does not count the
+// activation; accepted activations are counted with Count. Throttled of nil Limits always returns false.
+func (l *Limits) Throttled(appEUI types.AppEUI, devEUI types.DevEUI) bool {
+	if l == nil {
+		return false
+	}
+	if l.devEUI != nil && l.devEUI.Reached(devEUI.String()) {
+		return true
+	}
+	return l.appEUI != nil && l.appEUI.Reached(appEUI.String())
+}
+
+// Count counts an accepted activation of the DevEUI and the AppEUI. Count of nil Limits does nothing.
+func (l *Limits) Count(appEUI types.AppEUI, devEUI types.DevEUI) {
+	if l == nil {
+		return
+	}
+	if l.devEUI != nil {
+		l.devEUI.Take(devEUI.String())
+	}
+	if l.appEUI != nil {
+		l.appEUI.Take(appEUI.String())
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package activationlimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestThrottled(t *testing.T) {
+	a := New(t)
+
+	appEUI, otherAppEUI := types.AppEUI{1, 2, 3, 4, 5, 6, 7, 8}, types.AppEUI{2, 2, 3, 4, 5, 6, 7, 8}
+	devEUI, otherDevEUI := types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8}, types.DevEUI{2, 2, 3, 4, 5, 6, 7, 8}
+
+	var l *Limits
+	a.So(l.Throttled(appEUI, devEUI), ShouldBeFalse)
+
+	l.Count(appEUI, devEUI)
+
+	l = NewLimits(2, 3, time.Hour)
+
+	// Activations are only throttled after they were counted
+	a.So(l.Throttled(appEUI, devEUI), ShouldBeFalse)
+	a.So(l.Throttled(appEUI, devEUI), ShouldBeFalse)
+	a.So(l.Throttled(appEUI, devEUI), ShouldBeFalse)
+
+	l.Count(appEUI, devEUI)
+	a.So(l.Throttled(appEUI, devEUI), ShouldBeFalse)
+	l.Count(appEUI, devEUI)
+	a.So(l.Throttled(appEUI, devEUI), ShouldBeTrue)
+
+	a.So(l.Throttled(appEUI, otherDevEUI), ShouldBeFalse)
+	l.Count(appEUI, otherDevEUI)
+	a.So(l.Throttled(appEUI, otherDevEUI), ShouldBeTrue)
+
+	a.So(l.Throttled(otherAppEUI, types.DevEUI{3, 2, 3, 4, 5, 6, 7, 8}), ShouldBeFalse)
+
+	// A backoff of zero disables the limits
+	a.So(NewLimits(2, 3, 0), ShouldBeNil)
+	a.So(NewLimits(0, 0, time.Hour), ShouldBeNil)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/TheThingsNetwork/api/logfields"
 	"github.com/TheThingsNetwork/api/trace"
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/activationlimit"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
 	"github.com/brocaar/lorawan"
 )
@@ -102,6 +103,14 @@ func (b *broker) HandleActivation(activation *pb.DeviceActivationRequest) (res *
 		"DevID": deduplicatedActivationRequest.DevID,
 	})
 
+	// Rate limits are checked after the NetworkServer found the device. Only activations that the Handler accepted
+	// are counted, as the Broker can not check the MIC. Throttled activations do not reach the Handler, so the
+	// application does not get a throttled event for them.
+	if b.activationLimits.Throttled(deduplicatedActivationRequest.AppEUI, deduplicatedActivationRequest.DevEUI) {
+		throttledActivationsCounter.Inc()
+		return nil, activationlimit.ErrThrottled
+	}
+
 	// Find Handler (based on AppEUI)
 	var announcements []*pb_discovery.Announcement
 	announcements, err = b.Discovery.GetAllHandlersForAppID(deduplicatedActivationRequest.AppID)
@@ -206,6 +215,8 @@ func (b *broker) HandleActivation(activation *pb.DeviceActivationRequest) (res *
 		return nil, errors.Wrap(errors.FromGRPCError(err), "Handler refused activation")
 	}
 
+	b.activationLimits.Count(deduplicatedActivationRequest.AppEUI, deduplicatedActivationRequest.DevEUI)
+
 	handlerResponse.Trace = handlerResponse.Trace.WithEvent(trace.ReceiveEvent)
 
 	handlerResponse, err = b.ns.Activate(b.Component.GetContext(b.nsToken), handlerResponse)
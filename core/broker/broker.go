@@ -13,6 +13,7 @@ import (
 	"github.com/TheThingsNetwork/api/networkserver"
 	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
 	"github.com/TheThingsNetwork/ttn/api"
+	"github.com/TheThingsNetwork/ttn/core/activationlimit"
 	"github.com/TheThingsNetwork/ttn/core/component"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
@@ -24,6 +25,7 @@ type Broker interface {
 	component.ManagementInterface
 
 	SetNetworkServer(addr, cert, token string)
+	SetActivationRateLimits(devEUIRate, appEUIRate int, backoff time.Duration)
 
 	HandleUplink(uplink *pb.UplinkMessage) error
 	HandleDownlink(downlink *pb.DownlinkMessage) error
@@ -50,6 +52,13 @@ func (b *broker) SetNetworkServer(addr, cert, token string) {
 	b.nsToken = token
 }
 
+// SetActivationRateLimits limits the number of activations per DevEUI and per AppEUI in the backoff time. Activations
+// beyond the limit are dropped without reaching the Handler, so they do not result in throttled events for the
+// application. A rate of zero disables the limit.
+func (b *broker) SetActivationRateLimits(devEUIRate, appEUIRate int, backoff time.Duration) {
+	b.activationLimits = activationlimit.NewLimits(devEUIRate, appEUIRate, backoff)
+}
+
 type broker struct {
 	*component.Component
 	routers                map[string]*router
@@ -63,6 +72,10 @@ type broker struct {
 	ns                     networkserver.NetworkServerClient
 	uplinkDeduplicator     Deduplicator
 	activationDeduplicator Deduplicator
+	activationLimits       *activationlimit.Limits
+	downlinkOptions        downlinkOptionStore
+	uplinkRepetitions      uplinkRepetitionStore
+	uplinkQueues           uplinkQueues
 	status                 *status
 	// monitorStream          monitorclient.Stream
 }
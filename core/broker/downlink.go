@@ -42,6 +42,25 @@ func (b *broker) HandleDownlink(downlink *pb.DownlinkMessage) error {
 
 	downlink.Trace = downlink.Trace.WithEvent(trace.ReceiveEvent)
 
+	// The handler requests another option of the uplink to satisfy the scheduling hints of the downlink
+	if requested := downlink.DownlinkOption; requested != nil {
+		if options := b.downlinkOptions.Get(requested.Identifier); options != nil {
+			var option *pb.DownlinkOption
+			option, err = selectRequestedDownlink(options, requested)
+			if err != nil {
+				return err
+			}
+			if option.Identifier != requested.Identifier || option.GatewayConfiguration.Timestamp != requested.GatewayConfiguration.Timestamp {
+				if lorawan := option.ProtocolConfiguration.GetLoRaWAN(); lorawan != nil {
+					lorawan.FCnt = requested.ProtocolConfiguration.GetLoRaWAN().GetFCnt()
+				}
+				ctx = ctx.WithField("GatewayID", option.GatewayID)
+				downlink.DownlinkOption = option
+				downlink.Trace = downlink.Trace.WithEvent("select downlink option", "gateway", option.GatewayID)
+			}
+		}
+	}
+
 	downlink, err = b.ns.Downlink(b.Component.GetContext(b.nsToken), downlink)
 	if err != nil {
 		return errors.Wrap(errors.FromGRPCError(err), "NetworkServer did not handle downlink")
@@ -0,0 +1,81 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package broker
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/TheThingsNetwork/api/broker"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"github.com/bluele/gcache"
+)
+
+// DownlinkOptionsCacheSize is the number of uplinks for which the downlink options are kept
+var DownlinkOptionsCacheSize = 100000
+
+// DownlinkOptionsTimeout is how long the downlink options of an uplink are kept for selecting the downlink option that
+// the handler requests
+var DownlinkOptionsTimeout = 10 * time.Second
+
+// downlinkOptionStore keeps the downlink options of uplinks by the identifier of the selected option. The zero value
+// is ready to use.
+type downlinkOptionStore struct {
+	once  sync.Once
+	cache gcache.Cache
+}
+
+func (s *downlinkOptionStore) init() {
+	s.once.Do(func() {
+		s.cache = gcache.New(DownlinkOptionsCacheSize).Expiration(DownlinkOptionsTimeout).LRU().Build()
+	})
+}
+
+func (s *downlinkOptionStore) Set(identifier string, options []*pb.DownlinkOption) {
+	s.init()
+	s.cache.Set(identifier, options)
+}
+
+func (s *downlinkOptionStore) Get(identifier string) []*pb.DownlinkOption {
+	s.init()
+	if options, err := s.cache.Get(identifier); err == nil {
+		return options.([]*pb.DownlinkOption)
+	}
+	return nil
+}
+
+func selectBestDownlink(options []*pb.DownlinkOption) *pb.DownlinkOption {
+	sort.Sort(ByScore(options))
+	return options[0]
+}
+
+// selectRequestedDownlink selects the downlink option on the gateway and at the timestamp of the requested option. If
+// the requested timestamp is after all options of the gateway, the last option of the gateway is moved to that
+// timestamp. The moved option has no schedule identifier, so that the router reserves a new transmission slot.
+func selectRequestedDownlink(options []*pb.DownlinkOption, requested *pb.DownlinkOption) (*pb.DownlinkOption, error) {
+	var last *pb.DownlinkOption
+	for _, option := range options {
+		if option.GatewayID != requested.GatewayID {
+			continue
+		}
+		if option.GatewayConfiguration.Timestamp == requested.GatewayConfiguration.Timestamp {
+			return option, nil
+		}
+		if last == nil || option.GatewayConfiguration.Timestamp > last.GatewayConfiguration.Timestamp {
+			last = option
+		}
+	}
+	if last == nil {
+		return nil, errors.NewErrNotFound("downlink option on gateway " + requested.GatewayID)
+	}
+	if requested.GatewayConfiguration.Timestamp-last.GatewayConfiguration.Timestamp > 1<<31 {
+		return nil, errors.NewErrNotFound("downlink option at the requested timestamp")
+	}
+	option := *last
+	option.Identifier = last.Identifier[:strings.Index(last.Identifier, ":")+1]
+	option.GatewayConfiguration.Timestamp = requested.GatewayConfiguration.Timestamp
+	return &option, nil
+}
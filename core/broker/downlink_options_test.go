@@ -0,0 +1,79 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package broker
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/TheThingsNetwork/api/broker"
+	pb_gateway "github.com/TheThingsNetwork/api/gateway"
+	pb_protocol "github.com/TheThingsNetwork/api/protocol"
+	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
+	. "github.com/smartystreets/assertions"
+)
+
+func buildTestDownlinkOption(identifier, gatewayID string, score uint32, timestamp uint32, dataRate string) *pb.DownlinkOption {
+	return &pb.DownlinkOption{
+		Identifier: identifier,
+		GatewayID:  gatewayID,
+		Score:      score,
+		ProtocolConfiguration: pb_protocol.TxConfiguration{Protocol: &pb_protocol.TxConfiguration_LoRaWAN{LoRaWAN: &pb_lorawan.TxConfiguration{
+			Modulation: pb_lorawan.Modulation_LORA,
+			DataRate:   dataRate,
+		}}},
+		GatewayConfiguration: pb_gateway.TxConfiguration{Timestamp: timestamp},
+	}
+}
+
+func TestSelectRequestedDownlink(t *testing.T) {
+	a := New(t)
+
+	options := []*pb.DownlinkOption{
+		buildTestDownlinkOption("r:1", "gtw-1", 10, 1001000, "SF7BW125"),
+		buildTestDownlinkOption("r:2", "gtw-1", 20, 2001000, "SF9BW125"),
+		buildTestDownlinkOption("r:3", "gtw-2", 30, 1002000, "SF7BW125"),
+	}
+
+	a.So(selectBestDownlink(options).Identifier, ShouldEqual, "r:1")
+
+	option, err := selectRequestedDownlink(options, buildTestDownlinkOption("r:1", "gtw-1", 0, 2001000, ""))
+	a.So(err, ShouldBeNil)
+	a.So(option.Identifier, ShouldEqual, "r:2")
+
+	option, err = selectRequestedDownlink(options, buildTestDownlinkOption("r:1", "gtw-2", 0, 1002000, ""))
+	a.So(err, ShouldBeNil)
+	a.So(option.Identifier, ShouldEqual, "r:3")
+
+	// Later than the receive windows
+	option, err = selectRequestedDownlink(options, buildTestDownlinkOption("r:1", "gtw-1", 0, 60001000, ""))
+	a.So(err, ShouldBeNil)
+	a.So(option.Identifier, ShouldEqual, "r:")
+	a.So(option.GatewayConfiguration.Timestamp, ShouldEqual, 60001000)
+	a.So(option.ProtocolConfiguration.GetLoRaWAN().DataRate, ShouldEqual, "SF9BW125")
+	a.So(options[1].GatewayConfiguration.Timestamp, ShouldEqual, 2001000)
+
+	_, err = selectRequestedDownlink(options, buildTestDownlinkOption("r:1", "gtw-1", 0, 1500000, ""))
+	a.So(err, ShouldNotBeNil)
+
+	_, err = selectRequestedDownlink(options, buildTestDownlinkOption("r:1", "gtw-3", 0, 1001000, ""))
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestDownlinkOptionStore(t *testing.T) {
+	a := New(t)
+
+	defer func(timeout time.Duration) { DownlinkOptionsTimeout = timeout }(DownlinkOptionsTimeout)
+	DownlinkOptionsTimeout = 50 * time.Millisecond
+
+	var store downlinkOptionStore
+	a.So(store.Get("r:1"), ShouldBeNil)
+
+	options := []*pb.DownlinkOption{buildTestDownlinkOption("r:1", "gtw-1", 10, 1001000, "SF7BW125")}
+	store.Set("r:1", options)
+	a.So(store.Get("r:1"), ShouldResemble, options)
+
+	time.Sleep(100 * time.Millisecond)
+	a.So(store.Get("r:1"), ShouldBeNil)
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	pb "github.com/TheThingsNetwork/api/broker"
+	pb_gateway "github.com/TheThingsNetwork/api/gateway"
 	"github.com/TheThingsNetwork/api/monitor/monitorclient"
 	"github.com/TheThingsNetwork/ttn/core/component"
 	"github.com/TheThingsNetwork/ttn/core/types"
@@ -61,4 +62,45 @@ func TestDownlink(t *testing.T) {
 	})
 	a.So(err, ShouldBeNil)
 	a.So(len(dlch), ShouldEqual, 1)
+	<-dlch
+
+	// The handler requests another option of the uplink
+	b.downlinkOptions.Set("routerID:scheduleID", []*pb.DownlinkOption{
+		{Identifier: "routerID:scheduleID", GatewayID: "gtw-1", Score: 10, GatewayConfiguration: pb_gateway.TxConfiguration{Timestamp: 1001000}},
+		{Identifier: "routerID:rx2ScheduleID", GatewayID: "gtw-1", Score: 20, GatewayConfiguration: pb_gateway.TxConfiguration{Timestamp: 2001000}},
+		{Identifier: "routerID:otherScheduleID", GatewayID: "gtw-2", Score: 30, GatewayConfiguration: pb_gateway.TxConfiguration{Timestamp: 1002000}},
+	})
+
+	request := func(gatewayID string, timestamp uint32) *pb.DownlinkMessage {
+		return &pb.DownlinkMessage{
+			DevEUI: devEUI,
+			AppEUI: appEUI,
+			DownlinkOption: &pb.DownlinkOption{
+				Identifier:           "routerID:scheduleID",
+				GatewayID:            gatewayID,
+				GatewayConfiguration: pb_gateway.TxConfiguration{Timestamp: timestamp},
+			},
+		}
+	}
+
+	err = b.HandleDownlink(request("gtw-2", 1002000))
+	a.So(err, ShouldBeNil)
+	a.So(len(dlch), ShouldEqual, 1)
+	a.So((<-dlch).DownlinkOption.Identifier, ShouldEqual, "routerID:otherScheduleID")
+
+	err = b.HandleDownlink(request("gtw-1", 2001000))
+	a.So(err, ShouldBeNil)
+	a.So((<-dlch).DownlinkOption.Identifier, ShouldEqual, "routerID:rx2ScheduleID")
+
+	err = b.HandleDownlink(request("gtw-1", 5001000))
+	a.So(err, ShouldBeNil)
+	option := (<-dlch).DownlinkOption
+	a.So(option.Identifier, ShouldEqual, "routerID:")
+	a.So(option.GatewayConfiguration.Timestamp, ShouldEqual, 5001000)
+
+	err = b.HandleDownlink(request("gtw-2", 1000000))
+	a.So(err, ShouldNotBeNil)
+
+	err = b.HandleDownlink(request("gtw-3", 1002000))
+	a.So(err, ShouldNotBeNil)
 }
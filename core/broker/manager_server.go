@@ -15,13 +15,19 @@ import (
 	"github.com/TheThingsNetwork/go-account-lib/rights"
 	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
 	"github.com/TheThingsNetwork/ttn/api/ratelimit"
+	ttntypes "github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"github.com/TheThingsNetwork/ttn/utils/security"
 	"github.com/gogo/protobuf/types"
 	"golang.org/x/net/context" // See https://github.com/grpc/grpc-go/issues/711"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
+// errSuspensionNotFromHandler is returned if the suspension of a device is not set by the Handler of the application
+var errSuspensionNotFromHandler = errors.NewErrPermissionDenied("Suspension can only be set by the Handler of the application")
+
 type brokerManager struct {
 	broker         *broker
 	deviceManager  pb_lorawan.DeviceManagerClient
@@ -47,10 +53,15 @@ func (b *brokerManager) GetDevice(ctx context.Context, in *lorawan.DeviceIdentif
 		return nil, err
 	}
 	token, _ := ttnctx.TokenFromIncomingContext(ctx)
-	res, err := b.deviceManager.GetDevice(ttnctx.OutgoingContextWithToken(ctx, token), in)
+	var header metadata.MD
+	res, err := b.deviceManager.GetDevice(ttnctx.OutgoingContextWithToken(ctx, token), in, grpc.Header(&header))
 	if err != nil {
 		return nil, errors.Wrap(errors.FromGRPCError(err), "NetworkServer did not return device")
 	}
+	// Forward the frame history that the NetworkServer returns in the response header
+	if frames := header.Get(ttntypes.FrameHistoryKey); len(frames) > 0 {
+		grpc.SetHeader(ctx, metadata.Pairs(ttntypes.FrameHistoryKey, frames[0]))
+	}
 	return res, nil
 }
 
@@ -59,13 +70,46 @@ func (b *brokerManager) SetDevice(ctx context.Context, in *lorawan.Device) (*typ
 		return nil, err
 	}
 	token, _ := ttnctx.TokenFromIncomingContext(ctx)
-	res, err := b.deviceManager.SetDevice(ttnctx.OutgoingContextWithToken(ctx, token), in)
+	outCtx := ttnctx.OutgoingContextWithToken(ctx, token)
+	// Forward the suspension of the device that the Handler sends in the request metadata
+	if suspension := ttnctx.MetadataFromIncomingContext(ctx).Get(ttntypes.SuspensionKey); len(suspension) > 0 {
+		if err := b.validateSuspensionToken(ctx, in.AppID); err != nil {
+			return nil, err
+		}
+		outCtx = metadata.AppendToOutgoingContext(outCtx, ttntypes.SuspensionKey, suspension[0])
+	}
+	res, err := b.deviceManager.SetDevice(outCtx, in)
 	if err != nil {
 		return nil, errors.Wrap(errors.FromGRPCError(err), "NetworkServer did not set device")
 	}
 	return res, nil
 }
 
+// validateSuspensionToken checks that the suspension in the request metadata is set by the Handler of the application,
+// so that users can not resume the devices that are suspended in the Handler by setting them in the Broker
+func (b *brokerManager) validateSuspensionToken(ctx context.Context, appID string) error {
+	announcements, err := b.broker.Discovery.GetAllHandlersForAppID(appID)
+	if err != nil {
+		return err
+	}
+	if len(announcements) != 1 {
+		return errSuspensionNotFromHandler
+	}
+	handler := announcements[0]
+	if handler.PublicKey == "" {
+		return nil
+	}
+	token := ttnctx.MetadataFromIncomingContext(ctx).Get(ttntypes.SuspensionTokenKey)
+	if len(token) == 0 {
+		return errSuspensionNotFromHandler
+	}
+	tokenClaims, err := security.ValidateJWT(token[0], []byte(handler.PublicKey))
+	if err != nil || tokenClaims.Issuer != handler.ID {
+		return errSuspensionNotFromHandler
+	}
+	return nil
+}
+
 func (b *brokerManager) DeleteDevice(ctx context.Context, in *lorawan.DeviceIdentifier) (*types.Empty, error) {
 	if _, err := b.validateClient(ctx); err != nil {
 		return nil, err
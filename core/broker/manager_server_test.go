@@ -0,0 +1,53 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package broker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	pb_discovery "github.com/TheThingsNetwork/api/discovery"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/security"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValidateSuspensionToken(t *testing.T) {
+	a := New(t)
+
+	b := getTestBroker(t)
+	m := &brokerManager{broker: b.broker}
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	publicKey, _ := security.PublicPEM(key)
+	b.discovery.EXPECT().GetAllHandlersForAppID("appid").AnyTimes().Return([]*pb_discovery.Announcement{
+		&pb_discovery.Announcement{
+			ID:        "handlerID",
+			PublicKey: string(publicKey),
+		},
+	}, nil)
+
+	ctxWithToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.SuspensionTokenKey, token))
+	}
+
+	// Without token
+	a.So(m.validateSuspensionToken(context.Background(), "appid"), ShouldEqual, errSuspensionNotFromHandler)
+
+	// With token of the Handler
+	token, _ := security.BuildJWT("handlerID", 20*time.Second, key)
+	a.So(m.validateSuspensionToken(ctxWithToken(token), "appid"), ShouldBeNil)
+
+	// With token of another component
+	token, _ = security.BuildJWT("otherID", 20*time.Second, key)
+	a.So(m.validateSuspensionToken(ctxWithToken(token), "appid"), ShouldEqual, errSuspensionNotFromHandler)
+	token, _ = security.BuildJWT("handlerID", 20*time.Second, otherKey)
+	a.So(m.validateSuspensionToken(ctxWithToken(token), "appid"), ShouldEqual, errSuspensionNotFromHandler)
+}
@@ -27,6 +27,24 @@ var micChecksHistogram = prometheus.NewHistogram(
 	},
 )
 
+var uplinkRepetitionsCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "ttn",
+		Subsystem: "broker",
+		Name:      "uplink_repetitions_total",
+		Help:      "Total number of recognised repetitions of unconfirmed uplinks.",
+	},
+)
+
+var throttledActivationsCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "ttn",
+		Subsystem: "broker",
+		Name:      "throttled_activations_total",
+		Help:      "Total number of activations that were dropped because of the activation rate limits.",
+	},
+)
+
 var connectedRouters = prometheus.NewGauge(
 	prometheus.GaugeOpts{
 		Namespace: "ttn",
@@ -54,6 +72,8 @@ func initMetrics() {
 	initialized = true
 	prometheus.MustRegister(duplicatesHistogram)
 	prometheus.MustRegister(micChecksHistogram)
+	prometheus.MustRegister(uplinkRepetitionsCounter)
+	prometheus.MustRegister(throttledActivationsCounter)
 	prometheus.MustRegister(connectedRouters)
 	prometheus.MustRegister(connectedHandlers)
 }
@@ -17,6 +17,7 @@ import (
 	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
 	"github.com/TheThingsNetwork/api/trace"
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/repetition"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
 	"github.com/TheThingsNetwork/ttn/utils/fcnt"
@@ -86,12 +87,19 @@ func (b *broker) HandleUplink(uplink *pb.UplinkMessage) (err error) {
 		return errors.NewErrInvalidArgument("Uplink", "does not contain a MAC payload")
 	}
 
-	// Request devices from NS
 	devAddr := types.DevAddr(macPayload.FHDR.DevAddr)
 	ctx = ctx.WithFields(ttnlog.Fields{
 		"DevAddr": devAddr,
 		"FCnt":    macPayload.FHDR.FCnt,
 	})
+
+	// Uplinks with the same DevAddr find their device one by one and in order. The uplinks of a session are then
+	// processed one by one and in order, so that the FCnt check of an uplink sees the FCnt of the previous uplink of
+	// the session, and the handler receives them in order.
+	devAddrTurn := b.uplinkQueues.Acquire(devAddr.String())
+	defer devAddrTurn.Release()
+
+	// Request devices from NS
 	var getDevicesResp *networkserver.DevicesResponse
 	getDevicesResp, err = b.ns.GetDevices(b.Component.GetContext(b.nsToken), &networkserver.DevicesRequest{
 		DevAddr: devAddr,
@@ -173,6 +181,23 @@ func (b *broker) HandleUplink(uplink *pb.UplinkMessage) (err error) {
 		ctx = ctx.WithField("RealFCnt", macPayload.FHDR.FCnt)
 	}
 
+	sessionTurn := b.uplinkQueues.Queue(fmt.Sprintf("%s:%s:%s", devAddr, device.AppEUI, device.DevEUI))
+	defer sessionTurn.Release()
+	devAddrTurn.Release()
+	sessionTurn.Wait()
+
+	// The NS may not have the FCnt of the previous uplink of the session yet when the devices were requested
+	if fCnt, ok := sessionTurn.LastFCnt(); ok && fCnt > device.FCntUp {
+		device.FCntUp = fCnt
+	}
+
+	// Repetitions of unconfirmed uplinks (NbTrans) that arrive after the deduplication window only go to the NS
+	if phyPayload.MHDR.MType == lorawan.UnconfirmedDataUp && macPayload.FHDR.FCnt == device.FCntUp &&
+		b.uplinkRepetitions.IsRepetition(device.AppEUI, device.DevEUI, macPayload.FHDR.FCnt, phyPayload.MIC) {
+		ctx = ctx.WithField("Repetition", true)
+		return b.handleUplinkRepetition(deduplicatedUplink, duplicates, macPayload.FHDR.FCnt)
+	}
+
 	switch {
 	case macPayload.FHDR.FCnt > device.FCntUp && macPayload.FHDR.FCnt-device.FCntUp <= maxFCntGap:
 		// FCnt higher than latest and within max FCnt gap (normal case)
@@ -206,20 +231,33 @@ func (b *broker) HandleUplink(uplink *pb.UplinkMessage) (err error) {
 
 	// Select best DownlinkOption
 	if len(downlinkOptions) > 0 {
+		downlinkOption := selectBestDownlink(downlinkOptions)
+		b.downlinkOptions.Set(downlinkOption.Identifier, downlinkOptions)
 		deduplicatedUplink.ResponseTemplate = &pb.DownlinkMessage{
 			DevEUI:         device.DevEUI,
 			AppEUI:         device.AppEUI,
 			AppID:          device.AppID,
 			DevID:          device.DevID,
-			DownlinkOption: selectBestDownlink(downlinkOptions),
+			DownlinkOption: downlinkOption,
 		}
 	}
 
 	// Pass Uplink through NS
-	deduplicatedUplink, err = b.ns.Uplink(b.Component.GetContext(b.nsToken), deduplicatedUplink)
+	var nsUplink *pb.DeduplicatedUplinkMessage
+	nsUplink, err = b.ns.Uplink(b.Component.GetContext(b.nsToken), deduplicatedUplink)
+	if types.IsDeviceSuspended(err) {
+		// The NetworkServer drops and counts the uplinks of suspended devices, which is not an error of the Broker
+		deduplicatedUplink.Trace = deduplicatedUplink.Trace.WithEvent(trace.DropEvent, "reason", "device suspended")
+		ctx.Debug("Dropped uplink of suspended device")
+		return nil
+	}
 	if err != nil {
 		return errors.Wrap(errors.FromGRPCError(err), "NetworkServer did not handle uplink")
 	}
+	deduplicatedUplink = nsUplink
+
+	b.uplinkRepetitions.Set(device.AppEUI, device.DevEUI, macPayload.FHDR.FCnt, phyPayload.MIC)
+	sessionTurn.SetFCnt(macPayload.FHDR.FCnt)
 
 	var announcements []*pb_discovery.Announcement
 	announcements, err = b.Discovery.GetAllHandlersForAppID(device.AppID)
@@ -243,7 +281,31 @@ func (b *broker) HandleUplink(uplink *pb.UplinkMessage) (err error) {
 		"handler", announcements[0].ID,
 	)
 
-	handler <- deduplicatedUplink
+	select {
+	case handler <- deduplicatedUplink:
+	case <-time.After(UplinkForwardTimeout):
+		return errors.NewErrUnavailable(fmt.Sprintf("Handler %s did not accept uplink", announcements[0].ID))
+	}
+
+	return nil
+}
+
+// handleUplinkRepetition passes the gateway metadata of a repetition of an unconfirmed uplink to the NS for ADR. The
+// repetition is not forwarded to the handler, as it already received the uplink.
+func (b *broker) handleUplinkRepetition(deduplicatedUplink *pb.DeduplicatedUplinkMessage, duplicates []*pb.UplinkMessage, fCnt uint32) error {
+	deduplicatedUplink.ProtocolMetadata.GetLoRaWAN().FCnt = fCnt
+	for _, duplicate := range duplicates {
+		deduplicatedUplink.GatewayMetadata = append(deduplicatedUplink.GatewayMetadata, &duplicate.GatewayMetadata)
+	}
+	deduplicatedUplink.Trace = repetition.AddToTrace(deduplicatedUplink.Trace)
+
+	if _, err := b.ns.Uplink(b.Component.GetContext(b.nsToken), deduplicatedUplink); types.IsDeviceSuspended(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(errors.FromGRPCError(err), "NetworkServer did not handle uplink repetition")
+	}
+
+	uplinkRepetitionsCounter.Inc()
 
 	return nil
 }
@@ -261,11 +323,6 @@ func (b *broker) deduplicateUplink(duplicate *pb.UplinkMessage) (uplinks []*pb.U
 	return
 }
 
-func selectBestDownlink(options []*pb.DownlinkOption) *pb.DownlinkOption {
-	sort.Sort(ByScore(options))
-	return options[0]
-}
-
 // ByFCntUp implements sort.Interface for []*pb_lorawan.Device based on FCnt
 type ByFCntUp []*pb_lorawan.Device
 
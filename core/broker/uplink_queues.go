@@ -0,0 +1,99 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package broker
+
+import (
+	"sync"
+	"time"
+)
+
+// UplinkForwardTimeout is how long the broker waits for a handler to accept an uplink
+var UplinkForwardTimeout = 5 * time.Second
+
+// uplinkQueues lets uplinks with the same key take their turn one by one, in the order in which they were queued.
+// The queue of a key is created for the first uplink with that key, and removed when its last uplink is released. The
+// zero value is ready to use.
+type uplinkQueues struct {
+	sync.Mutex
+	queues map[string]*uplinkQueue
+}
+
+type uplinkQueue struct {
+	uplinks int             // The number of queued uplinks, including the uplink that has its turn
+	turns   []chan struct{} // The turns of the waiting uplinks
+	fCnt    uint32          // The FCnt of the last uplink that had its turn
+	hasFCnt bool
+}
+
+// uplinkTurn is the turn of a queued uplink
+type uplinkTurn struct {
+	queues  *uplinkQueues
+	key     string
+	queue   *uplinkQueue
+	turn    chan struct{}
+	release sync.Once
+}
+
+// Queue adds an uplink to the queue of the key. The uplink must Wait for its turn, and Release it when it is
+// processed, so that the next uplink gets its turn.
+func (q *uplinkQueues) Queue(key string) *uplinkTurn {
+	q.Lock()
+	defer q.Unlock()
+	if q.queues == nil {
+		q.queues = make(map[string]*uplinkQueue)
+	}
+	queue, ok := q.queues[key]
+	if !ok {
+		queue = new(uplinkQueue)
+		q.queues[key] = queue
+	}
+	queue.uplinks++
+	turn := &uplinkTurn{queues: q, key: key, queue: queue}
+	if queue.uplinks > 1 {
+		turn.turn = make(chan struct{})
+		queue.turns = append(queue.turns, turn.turn)
+	}
+	return turn
+}
+
+// Acquire queues an uplink and waits for its turn
+func (q *uplinkQueues) Acquire(key string) *uplinkTurn {
+	turn := q.Queue(key)
+	turn.Wait()
+	return turn
+}
+
+// Wait blocks until it is the turn of the uplink
+func (t *uplinkTurn) Wait() {
+	if t.turn != nil {
+		<-t.turn
+	}
+}
+
+// LastFCnt returns the FCnt of the previous uplink of the queue, if that uplink set it
+func (t *uplinkTurn) LastFCnt() (fCnt uint32, ok bool) {
+	return t.queue.fCnt, t.queue.hasFCnt
+}
+
+// SetFCnt sets the FCnt that the next uplink of the queue gets from LastFCnt
+func (t *uplinkTurn) SetFCnt(fCnt uint32) {
+	t.queue.fCnt, t.queue.hasFCnt = fCnt, true
+}
+
+// Release gives the next uplink of the queue its turn. It can be called more than once.
+func (t *uplinkTurn) Release() {
+	t.release.Do(func() {
+		q := t.queues
+		q.Lock()
+		defer q.Unlock()
+		t.queue.uplinks--
+		if t.queue.uplinks == 0 {
+			delete(q.queues, t.key)
+			return
+		}
+		next := t.queue.turns[0]
+		t.queue.turns = t.queue.turns[1:]
+		close(next)
+	})
+}
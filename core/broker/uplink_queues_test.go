@@ -0,0 +1,73 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package broker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestUplinkQueues(t *testing.T) {
+	a := New(t)
+
+	var q uplinkQueues
+
+	// Uplinks with the same key get their turn in order
+	turn := q.Acquire("key")
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer q.Acquire("key").Release()
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i)
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	a.So(order, ShouldBeEmpty)
+	mu.Unlock()
+	turn.Release()
+	turn.Release() // Releasing twice does not give a turn to another uplink
+	wg.Wait()
+	a.So(order, ShouldResemble, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	// The queue is removed when its last uplink is released
+	a.So(q.queues, ShouldBeEmpty)
+
+	// The next uplink of a queue gets the FCnt of the previous uplink
+	turn = q.Acquire("key")
+	_, ok := turn.LastFCnt()
+	a.So(ok, ShouldBeFalse)
+	turn.SetFCnt(42)
+	next := q.Queue("key")
+	turn.Release()
+	next.Wait()
+	fCnt, ok := next.LastFCnt()
+	a.So(ok, ShouldBeTrue)
+	a.So(fCnt, ShouldEqual, 42)
+
+	// Uplinks with other keys do not wait
+	acquired := make(chan struct{})
+	go func() {
+		q.Acquire("other-key").Release()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Uplink with other key did not get its turn")
+	}
+	next.Release()
+	a.So(q.queues, ShouldBeEmpty)
+}
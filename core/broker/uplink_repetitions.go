@@ -0,0 +1,63 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package broker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/brocaar/lorawan"
+)
+
+// UplinkRepetitionWindow is how long after an unconfirmed uplink the broker recognises retransmissions of that uplink
+// (because of NbTrans) as repetitions
+var UplinkRepetitionWindow = time.Minute
+
+type uplinkRepetition struct {
+	fCnt    uint32
+	mic     lorawan.MIC
+	expires time.Time
+}
+
+// uplinkRepetitionStore keeps the FCnt and MIC of the last uplink of devices. The zero value is ready to use.
+type uplinkRepetitionStore struct {
+	sync.Mutex
+	items   map[string]*uplinkRepetition
+	cleaned time.Time
+}
+
+func uplinkRepetitionKey(appEUI types.AppEUI, devEUI types.DevEUI) string {
+	return appEUI.String() + ":" + devEUI.String()
+}
+
+func (s *uplinkRepetitionStore) Set(appEUI types.AppEUI, devEUI types.DevEUI, fCnt uint32, mic lorawan.MIC) {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	if s.items == nil {
+		s.items = make(map[string]*uplinkRepetition)
+	}
+	if now.Sub(s.cleaned) > UplinkRepetitionWindow {
+		for key, item := range s.items {
+			if now.After(item.expires) {
+				delete(s.items, key)
+			}
+		}
+		s.cleaned = now
+	}
+	s.items[uplinkRepetitionKey(appEUI, devEUI)] = &uplinkRepetition{
+		fCnt:    fCnt,
+		mic:     mic,
+		expires: now.Add(UplinkRepetitionWindow),
+	}
+}
+
+// IsRepetition returns true if the device recently sent an uplink with the same FCnt and MIC
+func (s *uplinkRepetitionStore) IsRepetition(appEUI types.AppEUI, devEUI types.DevEUI, fCnt uint32, mic lorawan.MIC) bool {
+	s.Lock()
+	defer s.Unlock()
+	item, ok := s.items[uplinkRepetitionKey(appEUI, devEUI)]
+	return ok && time.Now().Before(item.expires) && item.fCnt == fCnt && item.mic == mic
+}
@@ -0,0 +1,40 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package broker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/brocaar/lorawan"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestUplinkRepetitionStore(t *testing.T) {
+	a := New(t)
+
+	appEUI := types.AppEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	devEUI := types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	otherDevEUI := types.DevEUI{1, 2, 3, 4, 5, 6, 7, 9}
+	mic := lorawan.MIC{1, 2, 3, 4}
+
+	var s uplinkRepetitionStore
+	a.So(s.IsRepetition(appEUI, devEUI, 1, mic), ShouldBeFalse)
+
+	s.Set(appEUI, devEUI, 1, mic)
+	a.So(s.IsRepetition(appEUI, devEUI, 1, mic), ShouldBeTrue)
+	a.So(s.IsRepetition(appEUI, devEUI, 2, mic), ShouldBeFalse)
+	a.So(s.IsRepetition(appEUI, devEUI, 1, lorawan.MIC{4, 3, 2, 1}), ShouldBeFalse)
+	a.So(s.IsRepetition(appEUI, otherDevEUI, 1, mic), ShouldBeFalse)
+
+	defer func(window time.Duration) {
+		UplinkRepetitionWindow = window
+	}(UplinkRepetitionWindow)
+	UplinkRepetitionWindow = 10 * time.Millisecond
+
+	s.Set(appEUI, devEUI, 2, mic)
+	time.Sleep(20 * time.Millisecond)
+	a.So(s.IsRepetition(appEUI, devEUI, 2, mic), ShouldBeFalse)
+}
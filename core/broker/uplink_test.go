@@ -148,6 +148,198 @@ func TestHandleUplink(t *testing.T) {
 		ProtocolMetadata: protocol.RxMetadata{Protocol: &protocol.RxMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.Metadata{}}},
 	})
 	a.So(err, ShouldBeNil)
+	a.So(b.handlers["handlerID"].uplink, ShouldHaveLength, 2)
+
+	// Repetition after the deduplication window (the NS now has the FCnt of the uplink)
+	b.uplinkDeduplicator = NewDeduplicator(10 * time.Millisecond)
+	nsResponse.Results[0].FCntUp = 1
+	b.ns.EXPECT().GetDevices(gomock.Any(), gomock.Any()).Return(nsResponse, nil)
+	b.ns.EXPECT().Uplink(gomock.Any(), gomock.Any()).Return(&pb.DeduplicatedUplinkMessage{}, nil)
+	err = b.HandleUplink(&pb.UplinkMessage{
+		Payload:          bytes,
+		GatewayMetadata:  gateway.RxMetadata{SNR: 3.4, GatewayID: gtwID},
+		ProtocolMetadata: protocol.RxMetadata{Protocol: &protocol.RxMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.Metadata{}}},
+	})
+	a.So(err, ShouldBeNil)
+	a.So(b.handlers["handlerID"].uplink, ShouldHaveLength, 2)
+
+	// Same FCnt outside the repetition window
+	b.uplinkDeduplicator = NewDeduplicator(10 * time.Millisecond)
+	b.uplinkRepetitions = uplinkRepetitionStore{}
+	b.ns.EXPECT().GetDevices(gomock.Any(), gomock.Any()).Return(nsResponse, nil)
+	err = b.HandleUplink(&pb.UplinkMessage{
+		Payload:          bytes,
+		GatewayMetadata:  gateway.RxMetadata{SNR: 3.4, GatewayID: gtwID},
+		ProtocolMetadata: protocol.RxMetadata{Protocol: &protocol.RxMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.Metadata{}}},
+	})
+	a.So(err, ShouldHaveSameTypeAs, &errors.ErrInvalidArgument{})
+
+	// Uplinks of suspended devices are dropped by the NS without an error
+	b.uplinkDeduplicator = NewDeduplicator(10 * time.Millisecond)
+	b.uplinkRepetitions = uplinkRepetitionStore{}
+	nsResponse.Results[0].FCntUp = 0
+	b.ns.EXPECT().GetDevices(gomock.Any(), gomock.Any()).Return(nsResponse, nil)
+	b.ns.EXPECT().Uplink(gomock.Any(), gomock.Any()).Return(nil, errors.BuildGRPCError(types.ErrDeviceSuspended))
+	err = b.HandleUplink(&pb.UplinkMessage{
+		Payload:          bytes,
+		GatewayMetadata:  gateway.RxMetadata{SNR: 3.4, GatewayID: gtwID},
+		ProtocolMetadata: protocol.RxMetadata{Protocol: &protocol.RxMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.Metadata{}}},
+	})
+	a.So(err, ShouldBeNil)
+	a.So(b.handlers["handlerID"].uplink, ShouldHaveLength, 2)
+}
+
+func TestHandleUplinkInOrder(t *testing.T) {
+	a := New(t)
+
+	b := getTestBroker(t)
+	b.handlers["handlerID"] = &handler{uplink: make(chan *pb.DeduplicatedUplinkMessage, 20)}
+
+	devEUI := types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	appEUI := types.AppEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	nwkSKey := types.NwkSKey{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+
+	// The NS keeps the FCntUp of the device
+	var (
+		mu     sync.Mutex
+		fCntUp uint32 = 10
+		nsFCnt []uint32
+	)
+	b.ns.EXPECT().GetDevices(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ interface{}, _ *pb_networkserver.DevicesRequest, _ ...interface{}) (*pb_networkserver.DevicesResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return &pb_networkserver.DevicesResponse{
+				Results: []*pb_lorawan.Device{
+					{DevEUI: devEUI, AppEUI: appEUI, AppID: "appid-1", NwkSKey: &nwkSKey, FCntUp: fCntUp},
+				},
+			}, nil
+		},
+	)
+	b.ns.EXPECT().Uplink(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ interface{}, in *pb.DeduplicatedUplinkMessage, _ ...interface{}) (*pb.DeduplicatedUplinkMessage, error) {
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			fCntUp = in.ProtocolMetadata.GetLoRaWAN().FCnt
+			nsFCnt = append(nsFCnt, fCntUp)
+			return in, nil
+		},
+	)
+	b.discovery.EXPECT().GetAllHandlersForAppID("appid-1").AnyTimes().Return([]*pb_discovery.Announcement{
+		&pb_discovery.Announcement{
+			ID: "handlerID",
+		},
+	}, nil)
+
+	// Uplinks arrive faster than the NS handles them
+	var wg sync.WaitGroup
+	for fCnt := uint32(11); fCnt <= 20; fCnt++ {
+		phy := lorawan.PHYPayload{
+			MHDR: lorawan.MHDR{
+				MType: lorawan.UnconfirmedDataUp,
+				Major: lorawan.LoRaWANR1,
+			},
+			MACPayload: &lorawan.MACPayload{
+				FHDR: lorawan.FHDR{
+					DevAddr: lorawan.DevAddr([4]byte{1, 2, 3, 4}),
+					FCnt:    fCnt,
+				},
+			},
+		}
+		phy.SetMIC(lorawan.AES128Key(nwkSKey))
+		bytes, _ := phy.MarshalBinary()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := b.HandleUplink(&pb.UplinkMessage{
+				Payload:          bytes,
+				GatewayMetadata:  gateway.RxMetadata{SNR: 1.2, GatewayID: "eui-0102030405060708"},
+				ProtocolMetadata: protocol.RxMetadata{Protocol: &protocol.RxMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.Metadata{}}},
+			})
+			a.So(err, ShouldBeNil)
+		}()
+		time.Sleep(2 * time.Millisecond)
+	}
+	wg.Wait()
+
+	expected := []uint32{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	a.So(nsFCnt, ShouldResemble, expected)
+	a.So(b.handlers["handlerID"].uplink, ShouldHaveLength, len(expected))
+	for _, fCnt := range expected {
+		uplink := <-b.handlers["handlerID"].uplink
+		a.So(uplink.ProtocolMetadata.GetLoRaWAN().FCnt, ShouldEqual, fCnt)
+	}
+}
+
+func TestHandleUplinkSlowHandler(t *testing.T) {
+	a := New(t)
+
+	defer func(timeout time.Duration) { UplinkForwardTimeout = timeout }(UplinkForwardTimeout)
+	UplinkForwardTimeout = 500 * time.Millisecond
+
+	b := getTestBroker(t)
+	b.handlers["slowHandlerID"] = &handler{uplink: make(chan *pb.DeduplicatedUplinkMessage)}
+	b.handlers["handlerID"] = &handler{uplink: make(chan *pb.DeduplicatedUplinkMessage, 1)}
+
+	// Two devices with the same DevAddr, of which the first one has a handler that does not accept uplinks
+	slowNwkSKey := types.NwkSKey{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+	nwkSKey := types.NwkSKey{8, 7, 6, 5, 4, 3, 2, 1, 8, 7, 6, 5, 4, 3, 2, 1}
+	b.ns.EXPECT().GetDevices(gomock.Any(), gomock.Any()).AnyTimes().Return(&pb_networkserver.DevicesResponse{
+		Results: []*pb_lorawan.Device{
+			{DevEUI: types.DevEUI{1}, AppEUI: types.AppEUI{1}, AppID: "slow-app", NwkSKey: &slowNwkSKey},
+			{DevEUI: types.DevEUI{2}, AppEUI: types.AppEUI{2}, AppID: "app", NwkSKey: &nwkSKey},
+		},
+	}, nil)
+	b.ns.EXPECT().Uplink(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ interface{}, in *pb.DeduplicatedUplinkMessage, _ ...interface{}) (*pb.DeduplicatedUplinkMessage, error) {
+			return in, nil
+		},
+	)
+	b.discovery.EXPECT().GetAllHandlersForAppID("slow-app").AnyTimes().Return([]*pb_discovery.Announcement{
+		&pb_discovery.Announcement{ID: "slowHandlerID"},
+	}, nil)
+	b.discovery.EXPECT().GetAllHandlersForAppID("app").AnyTimes().Return([]*pb_discovery.Announcement{
+		&pb_discovery.Announcement{ID: "handlerID"},
+	}, nil)
+
+	uplink := func(key types.NwkSKey) *pb.UplinkMessage {
+		phy := lorawan.PHYPayload{
+			MHDR: lorawan.MHDR{
+				MType: lorawan.UnconfirmedDataUp,
+				Major: lorawan.LoRaWANR1,
+			},
+			MACPayload: &lorawan.MACPayload{
+				FHDR: lorawan.FHDR{
+					DevAddr: lorawan.DevAddr([4]byte{1, 2, 3, 4}),
+					FCnt:    1,
+				},
+			},
+		}
+		phy.SetMIC(lorawan.AES128Key(key))
+		bytes, _ := phy.MarshalBinary()
+		return &pb.UplinkMessage{
+			Payload:          bytes,
+			GatewayMetadata:  gateway.RxMetadata{SNR: 1.2, GatewayID: "eui-0102030405060708"},
+			ProtocolMetadata: protocol.RxMetadata{Protocol: &protocol.RxMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.Metadata{}}},
+		}
+	}
+
+	slowErr := make(chan error)
+	go func() {
+		slowErr <- b.HandleUplink(uplink(slowNwkSKey))
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	a.So(b.HandleUplink(uplink(nwkSKey)), ShouldBeNil)
+	a.So(time.Since(start), ShouldBeLessThan, 200*time.Millisecond)
+	a.So(b.handlers["handlerID"].uplink, ShouldHaveLength, 1)
+
+	// The uplink of the slow handler is dropped after the timeout
+	a.So(<-slowErr, ShouldHaveSameTypeAs, &errors.ErrUnavailable{})
+	a.So(b.uplinkQueues.queues, ShouldBeEmpty)
 }
 
 func TestDeduplicateUplink(t *testing.T) {
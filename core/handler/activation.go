@@ -16,6 +16,7 @@ import (
 	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
 	"github.com/TheThingsNetwork/go-utils/random"
+	"github.com/TheThingsNetwork/ttn/core/activationlimit"
 	"github.com/TheThingsNetwork/ttn/core/handler/device"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
@@ -30,7 +31,7 @@ func (h *handler) getActivationMetadata(ctx ttnlog.Interface, activation *pb_bro
 		ServerTime:       activation.ServerTime,
 	}
 	appUp := &types.UplinkMessage{}
-	err := h.ConvertMetadata(ctx, ttnUp, appUp, device)
+	err := h.ConvertMetadata(ctx, ttnUp, appUp, device, nil)
 	if err != nil {
 		return types.Metadata{}, err
 	}
@@ -79,11 +80,15 @@ func (h *handler) HandleActivation(activation *pb_broker.DeduplicatedDeviceActiv
 	h.RegisterReceived(activation)
 	defer func() {
 		if err != nil {
+			event := types.ActivationErrorEvent
+			if err == activationlimit.ErrThrottled {
+				event = types.ActivationThrottledEvent
+			}
 			select {
 			case h.qEvent <- &types.DeviceEvent{
 				AppID: appID,
 				DevID: devID,
-				Event: types.ActivationErrorEvent,
+				Event: event,
 				Data: types.ActivationEventData{
 					AppEUI:         activation.AppEUI,
 					DevEUI:         activation.DevEUI,
@@ -91,7 +96,7 @@ func (h *handler) HandleActivation(activation *pb_broker.DeduplicatedDeviceActiv
 				},
 			}:
 			case <-time.After(eventPublishTimeout):
-				ctx.Warnf("Could not emit %q event", types.ActivationErrorEvent)
+				ctx.Warnf("Could not emit %q event", event)
 			}
 			activation.Trace = activation.Trace.WithEvent(trace.DropEvent, "reason", err)
 			ctx.WithError(err).Warn("Could not handle activation")
@@ -111,16 +116,38 @@ func (h *handler) HandleActivation(activation *pb_broker.DeduplicatedDeviceActiv
 		return nil, errors.NewErrInvalidArgument("Activation", "No gateways available for downlink")
 	}
 
+	unlock := h.deviceLocks.Lock(appID, devID)
+	defer func() { unlock() }()
+
 	// Find Device
 	dev, err := h.devices.Get(appID, devID)
 	if err != nil {
 		return nil, err
 	}
+	if dev.Suspension.Suspended {
+		return nil, types.ErrDeviceSuspended
+	}
+
+	// Devices that register on join must be in the claimable devices of the application, and use their AppKey from
+	// the manifest
+	if dev.DevEUI.IsEmpty() {
+		claimable, err := h.getClaimableDevice(appID, activation.DevEUI)
+		if err != nil {
+			return nil, err
+		}
+		dev.AppKey = claimable.AppKey
+	}
 
 	if dev.AppKey.IsEmpty() {
 		return nil, errors.NewErrNotFound(fmt.Sprintf("AppKey for device %s", devID))
 	}
 
+	// Rate limits are checked before the MIC check, so that devices in a join loop do not cost MIC checks and
+	// join-accepts. Activations are only counted once the MIC and DevNonce are valid.
+	if h.activationLimits.Throttled(activation.AppEUI, activation.DevEUI) {
+		return nil, activationlimit.ErrThrottled
+	}
+
 	// Check for LoRaWAN
 	metadata := activation.ActivationMetadata.GetLoRaWAN()
 	if metadata == nil {
@@ -154,10 +181,19 @@ func (h *handler) HandleActivation(activation *pb_broker.DeduplicatedDeviceActiv
 
 	if dev.DevEUI.IsEmpty() {
 		activation.Trace = activation.Trace.WithEvent("registering on join")
+
+		// The lock of the template device is not held while the device is registered, and the activation continues
+		// with the lock of the registered device
+		unlock()
 		dev, err = h.registerDeviceOnJoin(dev, activation)
 		if err != nil {
 			return nil, err
 		}
+		unlock = h.deviceLocks.Lock(dev.AppID, dev.DevID)
+		dev, err = h.devices.Get(dev.AppID, dev.DevID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Validate DevNonce
@@ -173,6 +209,8 @@ func (h *handler) HandleActivation(activation *pb_broker.DeduplicatedDeviceActiv
 		return nil, err
 	}
 
+	h.activationLimits.Count(activation.AppEUI, activation.DevEUI)
+
 	ctx.Debug("Accepting Join Request")
 	activation.Trace = activation.Trace.WithEvent(trace.AcceptEvent)
 
@@ -272,7 +310,10 @@ func (h *handler) HandleActivation(activation *pb_broker.DeduplicatedDeviceActiv
 	return res, nil
 }
 
-func (h *handler) registerDeviceOnJoin(base *device.Device, activation *pb_broker.DeduplicatedDeviceActivationRequest) (*device.Device, error) {
+// registerDeviceOnJoin registers the device of the activation from the template device base. It is called without the
+// lock of the template device, because it makes requests to the account server and the Broker. Claiming the device
+// prevents that it is registered twice.
+func (h *handler) registerDeviceOnJoin(base *device.Device, activation *pb_broker.DeduplicatedDeviceActivationRequest) (registered *device.Device, err error) {
 	ctx := h.Ctx.WithFields(logfields.ForMessage(activation))
 
 	clone := base.Clone()
@@ -294,6 +335,22 @@ func (h *handler) registerDeviceOnJoin(base *device.Device, activation *pb_broke
 		return nil, err
 	}
 
+	// The device is no longer claimable once it is registered
+	claimed, err := h.claim(activation.DevEUI, func(claimable *device.ClaimableDevice) error {
+		if claimable.AppID != base.AppID {
+			return errors.NewErrNotFound(fmt.Sprintf("Claimable device %s", activation.DevEUI))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			h.unclaim(claimed)
+		}
+	}()
+
 	lorawanPb := clone.ToLoRaWANPb()
 	lorawanPb.AppKey = nil
 	lorawanPb.AppSKey = nil
@@ -302,7 +359,9 @@ func (h *handler) registerDeviceOnJoin(base *device.Device, activation *pb_broke
 		return nil, errors.Wrap(errors.FromGRPCError(err), "Broker did not set device")
 	}
 
+	unlock := h.deviceLocks.Lock(clone.AppID, clone.DevID)
 	err = h.devices.Set(clone)
+	unlock()
 	if err != nil {
 		return nil, err
 	}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -79,6 +80,7 @@ func TestHandleActivation(t *testing.T) {
 		Component:    &component.Component{Ctx: GetLogger(t, "TestHandleActivation")},
 		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-activation"),
 		devices:      device.NewRedisDeviceStore(GetRedisClient(), "handler-test-activation"),
+		claims:       device.NewRedisClaimStore(GetRedisClient(), "handler-test-activation"),
 		qEvent:       make(chan *types.DeviceEvent, 10),
 	}
 	var eventsReceived uint
@@ -213,6 +215,18 @@ func TestHandleActivation(t *testing.T) {
 		req.DevID = "default"
 	}
 
+	// Device is not claimable
+	_, err = h.HandleActivation(req)
+	a.So(err, ShouldNotBeNil)
+
+	h.claims.Set(&device.ClaimableDevice{
+		AppID:     appID,
+		DevEUI:    otherDevEUI,
+		AppKey:    appKey,
+		ClaimCode: "claim-code",
+	})
+	defer func() { h.claims.Delete(otherDevEUI) }()
+
 	// No access key set
 	_, err = h.HandleActivation(req)
 	a.So(err, ShouldNotBeNil)
@@ -229,7 +243,7 @@ func TestHandleActivation(t *testing.T) {
 	a.So(err, ShouldNotBeNil)
 
 	time.Sleep(200 * time.Millisecond)
-	a.So(eventsReceived, ShouldEqual, 10) // 10 activation error events. One for each HandleActivation
+	a.So(eventsReceived, ShouldEqual, 11) // 11 activation error events. One for each HandleActivation
 
 	for _, env := range strings.Split("ACCOUNT_SERVER_PROTO ACCOUNT_SERVER_USERNAME ACCOUNT_SERVER_PASSWORD ACCOUNT_SERVER_URL APP_ID APP_TOKEN", " ") {
 		if os.Getenv(env) == "" {
@@ -255,7 +269,91 @@ func TestHandleActivation(t *testing.T) {
 	a.So(err, ShouldBeNil)
 	a.So(res.ActivationMetadata.GetLoRaWAN().DevEUI, ShouldResemble, otherDevEUI)
 
+	// The registered device is no longer claimable
+	_, err = h.claims.Get(otherDevEUI)
+	a.So(err, ShouldNotBeNil)
+
 	// TODO: Check response
 	// TODO: Check DB contents
 
 }
+
+func TestHandleActivationConcurrent(t *testing.T) {
+	a := New(t)
+
+	h := &handler{
+		Component:    &component.Component{Ctx: GetLogger(t, "TestHandleActivationConcurrent")},
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-activation-concurrent"),
+		devices:      device.NewRedisDeviceStore(GetRedisClient(), "handler-test-activation-concurrent"),
+		qEvent:       make(chan *types.DeviceEvent, 100),
+	}
+	h.InitStatus()
+
+	devAddr := types.DevAddr{1, 2, 3, 4}
+	appEUI, devEUI := types.AppEUI{1, 2, 3, 4, 5, 6, 7, 8}, types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	appID, devID := "appid", "devid"
+	appKey := types.AppKey{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+
+	h.applications.Set(&application.Application{AppID: appID})
+	defer func() { h.applications.Delete(appID) }()
+
+	h.devices.Set(&device.Device{
+		AppID:  appID,
+		DevID:  devID,
+		AppEUI: appEUI,
+		DevEUI: devEUI,
+		AppKey: appKey,
+	})
+	defer func() { h.devices.Delete(appID, devID) }()
+
+	joinRequest := func(devNonce types.DevNonce) *pb_broker.DeduplicatedDeviceActivationRequest {
+		req := &pb_broker.DeduplicatedDeviceActivationRequest{
+			AppID:  appID,
+			DevID:  devID,
+			AppEUI: appEUI,
+			DevEUI: devEUI,
+			ActivationMetadata: &pb_protocol.ActivationMetadata{Protocol: &pb_protocol.ActivationMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.ActivationMetadata{
+				AppEUI:  appEUI,
+				DevEUI:  devEUI,
+				DevAddr: &devAddr,
+			}}},
+		}
+		req.ResponseTemplate = new(pb_broker.DeviceActivationResponse)
+		req.ResponseTemplate.Message = new(pb_protocol.Message)
+		accept := req.ResponseTemplate.Message.InitLoRaWAN()
+		accept.MType = pb_lorawan.MType_JOIN_ACCEPT
+		accept.Payload = &pb_lorawan.Message_JoinAcceptPayload{JoinAcceptPayload: &pb_lorawan.JoinAcceptPayload{}}
+		req.ResponseTemplate.Payload = accept.PHYPayloadBytes()
+		req.ResponseTemplate.DownlinkOption = new(pb_broker.DownlinkOption)
+		req.Message = new(pb_protocol.Message)
+		msg := req.Message.InitLoRaWAN()
+		msg.MType = pb_lorawan.MType_JOIN_REQUEST
+		msg.Payload = &pb_lorawan.Message_JoinRequestPayload{JoinRequestPayload: &pb_lorawan.JoinRequestPayload{
+			AppEUI:   appEUI,
+			DevEUI:   devEUI,
+			DevNonce: devNonce,
+		}}
+		phy := msg.PHYPayload()
+		phy.SetMIC(lorawan.AES128Key(appKey))
+		req.Payload, _ = phy.MarshalBinary()
+		return req
+	}
+
+	// Concurrent joins must not overwrite each other's DevNonces
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		req := joinRequest(types.DevNonce{0, byte(i)})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := h.HandleActivation(req)
+			a.So(err, ShouldBeNil)
+		}()
+	}
+	wg.Wait()
+
+	dev, err := h.devices.Get(appID, devID)
+	a.So(err, ShouldBeNil)
+	a.So(dev.UsedDevNonces, ShouldHaveLength, 10)
+	a.So(dev.UsedAppNonces, ShouldHaveLength, 10)
+}
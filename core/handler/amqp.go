@@ -8,12 +8,16 @@ import (
 
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
 	"github.com/TheThingsNetwork/ttn/amqp"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/types"
 )
 
 // AMQPBufferSize indicates the size for uplink channel buffers
 var AMQPBufferSize = 10
 
+// AMQPPublishConcurrency indicates how many messages of each type can wait for their publish confirmation at once
+var AMQPPublishConcurrency = 50
+
 func (h *handler) assertAMQPExchange() error {
 	ch, err := h.amqpClient.(*amqp.DefaultClient).GetChannel()
 	if err != nil {
@@ -36,6 +40,28 @@ func (h *handler) assertAMQPExchange() error {
 	return nil
 }
 
+// declareAMQPDeadLetter declares the exchange for downlink messages that could not be handled. If the downlink
+// queue is durable, a durable queue is bound to the dead-letter exchange, so that the messages can be inspected.
+func (h *handler) declareAMQPDeadLetter(exchange, downlinkQueue string) error {
+	ch, err := h.amqpClient.(*amqp.DefaultClient).GetChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	if err := ch.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		h.Ctx.Errorf("Could not create AMQP Exchange %s.", exchange)
+		return err
+	}
+	if downlinkQueue == "" {
+		return nil
+	}
+	queue, err := ch.QueueDeclare(downlinkQueue+AMQPDeadLetterSuffix, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	return ch.QueueBind(queue.Name, "#", exchange, false, nil)
+}
+
 func (h *handler) HandleAMQP(username, password, host, exchange, downlinkQueue string) error {
 	h.amqpClient = amqp.NewClient(h.Ctx, username, password, host)
 
@@ -66,15 +92,32 @@ func (h *handler) HandleAMQP(username, password, host, exchange, downlinkQueue s
 			subscriber.Close()
 		}
 	}()
-	err = subscriber.SubscribeDownlink(func(_ amqp.Subscriber, _, _ string, req types.DownlinkMessage) {
-		h.EnqueueDownlink(&req)
-	})
-	if err != nil {
+	deadLetterExchange := h.amqpExchange + AMQPDeadLetterSuffix
+	if err = h.declareAMQPDeadLetter(deadLetterExchange, downlinkQueue); err != nil {
 		return err
 	}
+	subscriber.WithDeadLetterExchange(deadLetterExchange)
+	if h.amqpAppQueuesEnabled {
+		var apps []*application.Application
+		apps, err = h.applications.List(nil)
+		if err != nil {
+			return err
+		}
+		h.amqpSubscriber = subscriber
+		for _, app := range apps {
+			if err = h.consumeAMQPAppDownlink(app.AppID); err != nil {
+				return err
+			}
+		}
+	} else {
+		err = subscriber.SubscribeDownlinkAck(h.handleAMQPDownlink)
+		if err != nil {
+			return err
+		}
+	}
 
 	ctx := h.Ctx.WithField("Protocol", "AMQP")
-	publisher := h.amqpClient.NewPublisher(h.amqpExchange)
+	publisher := h.amqpClient.NewPublisher(h.amqpExchange).WithEncoding(h.encoding)
 	err = publisher.Open()
 	if err != nil {
 		ctx.WithError(err).Error("Could not open publisher channel")
@@ -90,22 +133,34 @@ func (h *handler) HandleAMQP(username, password, host, exchange, downlinkQueue s
 		}()
 	}()
 
+	// Messages are published concurrently, so that they do not wait for the confirmations of the previous messages
 	go func() {
 		defer pubWait.Done()
+		var publishing sync.WaitGroup
+		defer publishing.Wait()
+		concurrency := make(chan struct{}, AMQPPublishConcurrency)
 		for up := range h.amqpUp {
 			ctx := ctx.WithFields(ttnlog.Fields{
 				"DevID": up.DevID,
 				"AppID": up.AppID,
 			})
 			ctx.Debug("Publish Uplink")
-			if err := publisher.PublishUplink(*up); err != nil {
-				ctx.WithError(err).Warn("Could not publish Uplink")
-			}
+			concurrency <- struct{}{}
+			publishing.Add(1)
+			go func(up *types.UplinkMessage) {
+				defer func() { <-concurrency; publishing.Done() }()
+				if err := publisher.PublishUplink(*up); err != nil {
+					ctx.WithError(err).Warn("Could not publish Uplink")
+				}
+			}(up)
 		}
 	}()
 
 	go func() {
 		defer pubWait.Done()
+		var publishing sync.WaitGroup
+		defer publishing.Wait()
+		concurrency := make(chan struct{}, AMQPPublishConcurrency)
 		for event := range h.amqpEvent {
 			ctx := ctx.WithFields(ttnlog.Fields{
 				"DevID": event.DevID,
@@ -113,17 +168,44 @@ func (h *handler) HandleAMQP(username, password, host, exchange, downlinkQueue s
 				"Event": event.Event,
 			})
 			ctx.Debug("Publish Event")
-			if event.DevID == "" {
-				if err := publisher.PublishAppEvent(event.AppID, event.Event, event.Data); err != nil {
-					ctx.WithError(err).Warn("Could not publish App Event")
+			concurrency <- struct{}{}
+			publishing.Add(1)
+			go func(event *types.DeviceEvent) {
+				defer func() { <-concurrency; publishing.Done() }()
+				if event.DevID == "" {
+					if err := publisher.PublishAppEvent(event.AppID, event.Event, event.Data); err != nil {
+						ctx.WithError(err).Warn("Could not publish App Event")
+					}
+				} else {
+					if err := publisher.PublishDeviceEvent(event.AppID, event.DevID, event.Event, event.Data); err != nil {
+						ctx.WithError(err).Warn("Could not publish Device Event")
+					}
 				}
-			} else {
-				if err := publisher.PublishDeviceEvent(event.AppID, event.DevID, event.Event, event.Data); err != nil {
-					ctx.WithError(err).Warn("Could not publish Device Event")
-				}
-			}
+			}(event)
 		}
 	}()
 
 	return nil
 }
+
+func (h *handler) handleAMQPDownlink(_ amqp.Subscriber, _, _ string, req types.DownlinkMessage) error {
+	return h.EnqueueDownlink(&req)
+}
+
+// consumeAMQPAppDownlink starts consuming the AMQP downlink queue of the application, if per-application
+// queues are enabled
+func (h *handler) consumeAMQPAppDownlink(appID string) error {
+	if h.amqpSubscriber == nil {
+		return nil
+	}
+	return h.amqpSubscriber.ConsumeAppDownlink(appID, h.handleAMQPDownlink)
+}
+
+// deleteAMQPAppDownlink stops consuming and deletes the AMQP downlink queue of the application, if
+// per-application queues are enabled
+func (h *handler) deleteAMQPAppDownlink(appID string) error {
+	if h.amqpSubscriber == nil {
+		return nil
+	}
+	return h.amqpSubscriber.StopAppDownlink(appID)
+}
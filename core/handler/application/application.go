@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/fatih/structs"
 )
 
@@ -22,6 +23,34 @@ const (
 	PayloadFormatCayenneLPP PayloadFormat = "cayennelpp"
 )
 
+// LocationFields maps payload fields to the location of devices. Fields are names of payload fields, where nested
+// fields are separated by dots. The GPS field is an object with latitude, longitude and altitude, such as gps_1 of
+// CayenneLPP, and is used instead of the Latitude, Longitude and Altitude fields.
+type LocationFields struct {
+	GPS         string  `json:"gps,omitempty"`
+	Latitude    string  `json:"latitude,omitempty"`
+	Longitude   string  `json:"longitude,omitempty"`
+	Altitude    string  `json:"altitude,omitempty"`
+	Accuracy    string  `json:"accuracy,omitempty"`     // Accuracy in meters
+	MinDistance float64 `json:"min_distance,omitempty"` // Minimum distance in meters before the location is updated
+	MaxAccuracy float64 `json:"max_accuracy,omitempty"` // Locations that are less accurate (in meters) are ignored
+}
+
+// Coordinate is a point on the earth
+type Coordinate struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geofence is a named area that devices can enter and exit. It is either a polygon or a circle.
+type Geofence struct {
+	Name       string       `json:"name"`
+	Polygon    []Coordinate `json:"polygon,omitempty"`    // Vertices of a polygon
+	Center     *Coordinate  `json:"center,omitempty"`     // Center of a circle
+	Radius     float64      `json:"radius,omitempty"`     // Radius in meters of a circle
+	Hysteresis float64      `json:"hysteresis,omitempty"` // Distance in meters that devices have to leave the geofence before they exit
+}
+
 // Application contains the state of an application
 type Application struct {
 	old *Application
@@ -46,6 +75,29 @@ type Application struct {
 	// set to PayloadFormatCustom
 	CustomEncoder string `redis:"custom_encoder"`
 
+	// UplinkFieldsSchema is a JSON Schema that the payload fields of uplink messages
+	// should match
+	UplinkFieldsSchema string `redis:"uplink_fields_schema"`
+	// DownlinkFieldsSchema is a JSON Schema that the payload fields of downlink messages
+	// should match before they are encoded
+	DownlinkFieldsSchema string `redis:"downlink_fields_schema"`
+	// ForwardRawOnInvalidFields forwards uplink messages with payload fields that do not
+	// match the UplinkFieldsSchema without the fields, instead of dropping them
+	ForwardRawOnInvalidFields bool `redis:"forward_raw_on_invalid_fields"`
+
+	// LocationFields updates the location of devices from the payload fields of uplink
+	// messages
+	LocationFields *LocationFields `redis:"location_fields"`
+
+	// Geofences are evaluated against the location of devices
+	Geofences []Geofence `redis:"geofences"`
+
+	// Encoding is the encoding of the MQTT and AMQP messages of the application. An
+	// empty encoding is JSON.
+	Encoding types.Encoding `redis:"encoding"`
+	// LoRaWANMetadata adds the LoRaWAN frame header to uplink messages
+	LoRaWANMetadata bool `redis:"lorawan_metadata"`
+
 	RegisterOnJoinAccessKey string `redis:"register_on_join_access_key"`
 
 	CreatedAt time.Time `redis:"created_at"`
@@ -0,0 +1,126 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"golang.org/x/net/context"
+)
+
+var errInvalidClaimCode = errors.NewErrPermissionDenied("Invalid claim code")
+
+// claimableDevicesFromIncomingContext returns the claimable devices in the manifest in the request metadata
+func claimableDevicesFromIncomingContext(ctx context.Context, appID string) ([]*device.ClaimableDevice, error) {
+	values := ttnctx.MetadataFromIncomingContext(ctx).Get(ClaimableDevicesKey)
+	if len(values) == 0 || values[0] == "" {
+		return nil, nil
+	}
+	var manifest []*device.ClaimableDevice
+	if err := json.Unmarshal([]byte(values[0]), &manifest); err != nil {
+		return nil, errors.NewErrInvalidArgument("Claimable Devices", err.Error())
+	}
+	devEUIs := make(map[types.DevEUI]bool, len(manifest))
+	for _, claimable := range manifest {
+		switch {
+		case claimable.DevEUI.IsEmpty():
+			return nil, errors.NewErrInvalidArgument("Claimable Devices", "dev_eui required")
+		case devEUIs[claimable.DevEUI]:
+			return nil, errors.NewErrInvalidArgument("Claimable Devices", fmt.Sprintf("duplicate dev_eui %s", claimable.DevEUI))
+		case claimable.AppKey.IsEmpty():
+			return nil, errors.NewErrInvalidArgument("Claimable Devices", fmt.Sprintf("%s: app_key required", claimable.DevEUI))
+		case claimable.ClaimCode == "":
+			return nil, errors.NewErrInvalidArgument("Claimable Devices", fmt.Sprintf("%s: claim_code required", claimable.DevEUI))
+		}
+		devEUIs[claimable.DevEUI] = true
+		claimable.AppID = appID
+	}
+	return manifest, nil
+}
+
+// claimCodeFromIncomingContext returns the claim code in the request metadata
+func claimCodeFromIncomingContext(ctx context.Context) string {
+	if values := ttnctx.MetadataFromIncomingContext(ctx).Get(ClaimCodeKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// importClaimableDevices adds the claimable devices to the claimable devices of their application
+func (h *handler) importClaimableDevices(manifest []*device.ClaimableDevice) error {
+	h.claimLock.Lock()
+	defer h.claimLock.Unlock()
+	for _, claimable := range manifest {
+		existing, err := h.claims.Get(claimable.DevEUI)
+		if err != nil && errors.GetErrType(err) != errors.NotFound {
+			return err
+		}
+		if existing != nil && existing.AppID != claimable.AppID {
+			return errors.NewErrAlreadyExists(fmt.Sprintf("Claimable device %s", claimable.DevEUI))
+		}
+	}
+	for _, claimable := range manifest {
+		if err := h.claims.Set(claimable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getClaimableDevice returns the claimable device with the DevEUI in the claimable devices of the application
+func (h *handler) getClaimableDevice(appID string, devEUI types.DevEUI) (*device.ClaimableDevice, error) {
+	claimable, err := h.claims.Get(devEUI)
+	if err != nil && errors.GetErrType(err) != errors.NotFound {
+		return nil, err
+	}
+	if claimable == nil || claimable.AppID != appID {
+		return nil, errors.NewErrNotFound(fmt.Sprintf("Claimable device %s", devEUI))
+	}
+	return claimable, nil
+}
+
+// claim removes the device with the DevEUI from the claimable devices if check accepts it, and returns it. Devices
+// that are claimed but can not be registered should be returned with unclaim.
+func (h *handler) claim(devEUI types.DevEUI, check func(*device.ClaimableDevice) error) (*device.ClaimableDevice, error) {
+	h.claimLock.Lock()
+	defer h.claimLock.Unlock()
+	claimable, err := h.claims.Get(devEUI)
+	if errors.GetErrType(err) == errors.NotFound {
+		return nil, errors.NewErrNotFound(fmt.Sprintf("Claimable device %s", devEUI))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := check(claimable); err != nil {
+		return nil, err
+	}
+	if err := h.claims.Delete(devEUI); err != nil {
+		return nil, err
+	}
+	return claimable, nil
+}
+
+// withClaimCode returns a check for claim that accepts devices with the claim code
+func withClaimCode(claimCode string) func(*device.ClaimableDevice) error {
+	return func(claimable *device.ClaimableDevice) error {
+		if !claimable.ValidClaimCode(claimCode) {
+			return errInvalidClaimCode
+		}
+		return nil
+	}
+}
+
+// unclaim returns a claimed device to the claimable devices
+func (h *handler) unclaim(claimable *device.ClaimableDevice) {
+	h.claimLock.Lock()
+	defer h.claimLock.Unlock()
+	if err := h.claims.Set(claimable); err != nil {
+		h.Ctx.WithError(err).WithField("DevEUI", claimable.DevEUI).Warn("Could not return claimed device to claimable devices")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/component"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestClaimableDevicesFromIncomingContext(t *testing.T) {
+	a := New(t)
+
+	manifest, err := claimableDevicesFromIncomingContext(context.Background(), "app")
+	a.So(err, ShouldBeNil)
+	a.So(manifest, ShouldBeEmpty)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ClaimableDevicesKey, `[{"dev_eui":"0102030405060708","app_key":"01020304050607080102030405060708","claim_code":"C1A1M"}]`))
+	manifest, err = claimableDevicesFromIncomingContext(ctx, "app")
+	a.So(err, ShouldBeNil)
+	a.So(manifest, ShouldHaveLength, 1)
+	a.So(manifest[0].AppID, ShouldEqual, "app")
+	a.So(manifest[0].DevEUI, ShouldEqual, types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8})
+	a.So(manifest[0].AppKey, ShouldEqual, types.AppKey{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8})
+	a.So(manifest[0].ClaimCode, ShouldEqual, "C1A1M")
+
+	for _, invalid := range []string{
+		`{`,
+		`[{"app_key":"01020304050607080102030405060708","claim_code":"C1A1M"}]`,
+		`[{"dev_eui":"0102030405060708","claim_code":"C1A1M"}]`,
+		`[{"dev_eui":"0102030405060708","app_key":"01020304050607080102030405060708"}]`,
+		`[{"dev_eui":"0102030405060708","app_key":"01020304050607080102030405060708","claim_code":"C1A1M"},{"dev_eui":"0102030405060708","app_key":"01020304050607080102030405060708","claim_code":"C1A1M"}]`,
+	} {
+		ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(ClaimableDevicesKey, invalid))
+		_, err = claimableDevicesFromIncomingContext(ctx, "app")
+		a.So(err, ShouldNotBeNil)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	a := New(t)
+
+	h := &handler{
+		Component: &component.Component{Ctx: GetLogger(t, "TestClaim")},
+		claims:    device.NewRedisClaimStore(GetRedisClient(), "handler-test-claim"),
+	}
+
+	devEUI := types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	claimable := &device.ClaimableDevice{
+		AppID:     "app",
+		DevEUI:    devEUI,
+		AppKey:    types.AppKey{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8},
+		ClaimCode: "C1A1M",
+	}
+
+	a.So(h.importClaimableDevices([]*device.ClaimableDevice{claimable}), ShouldBeNil)
+	defer func() {
+		h.claims.Delete(devEUI)
+	}()
+
+	// Import again by the same application
+	a.So(h.importClaimableDevices([]*device.ClaimableDevice{claimable}), ShouldBeNil)
+
+	// Import by another application
+	other := *claimable
+	other.AppID = "other-app"
+	a.So(h.importClaimableDevices([]*device.ClaimableDevice{&other}), ShouldNotBeNil)
+
+	_, err := h.getClaimableDevice("app", devEUI)
+	a.So(err, ShouldBeNil)
+	_, err = h.getClaimableDevice("other-app", devEUI)
+	a.So(err, ShouldNotBeNil)
+
+	// Invalid claim code
+	_, err = h.claim(devEUI, withClaimCode("invalid"))
+	a.So(err, ShouldEqual, errInvalidClaimCode)
+
+	// Valid claim code
+	claimed, err := h.claim(devEUI, withClaimCode("C1A1M"))
+	a.So(err, ShouldBeNil)
+	a.So(claimed.AppKey, ShouldEqual, claimable.AppKey)
+
+	// Already claimed
+	_, err = h.claim(devEUI, withClaimCode("C1A1M"))
+	a.So(err, ShouldNotBeNil)
+	_, err = h.getClaimableDevice("app", devEUI)
+	a.So(err, ShouldNotBeNil)
+
+	// Unclaim
+	h.unclaim(claimed)
+	_, err = h.getClaimableDevice("app", devEUI)
+	a.So(err, ShouldBeNil)
+}
@@ -32,10 +32,8 @@ type PayloadEncoder interface {
 }
 
 // ConvertFieldsUp converts the payload to fields using the application's payload formatter
-func (h *handler) ConvertFieldsUp(ctx ttnlog.Interface, _ *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, dev *device.Device) error {
-	// Find Application
-	app, err := h.applications.Get(appUp.AppID)
-	if err != nil {
+func (h *handler) ConvertFieldsUp(ctx ttnlog.Interface, _ *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, dev *device.Device, app *application.Application) error {
+	if app == nil {
 		return nil // Do not process if application not found
 	}
 
@@ -46,6 +44,8 @@ func (h *handler) ConvertFieldsUp(ctx ttnlog.Interface, _ *pb_broker.Deduplicate
 			Decoder:   app.CustomDecoder,
 			Converter: app.CustomConverter,
 			Validator: app.CustomValidator,
+			Context:   uplinkPayloadContext(appUp, dev),
+			State:     dev.State,
 			Logger:    functions.Ignore,
 		}
 	case application.PayloadFormatCayenneLPP:
@@ -97,6 +97,50 @@ func (h *handler) ConvertFieldsUp(ctx ttnlog.Interface, _ *pb_broker.Deduplicate
 		return nil
 	}
 
+	if app.UplinkFieldsSchema != "" {
+		if err := h.validateFields(app.AppID, "up", app.UplinkFieldsSchema, fields); err != nil {
+			if !app.ForwardRawOnInvalidFields {
+				return err
+			}
+
+			// Emit the error
+			select {
+			case h.qEvent <- &types.DeviceEvent{
+				AppID: appUp.AppID,
+				DevID: appUp.DevID,
+				Event: types.UplinkErrorEvent,
+				Data:  errorEventData(err),
+			}:
+			case <-time.After(eventPublishTimeout):
+				ctx.Warnf("Could not emit %q event", types.UplinkErrorEvent)
+			}
+
+			// Forward the message without payload fields
+			return nil
+		}
+	}
+
+	if custom, ok := decoder.(*CustomUplinkFunctions); ok && (len(custom.State) > 0 || len(dev.State) > 0) {
+		if state, _ := json.Marshal(custom.State); len(state) > device.MaxStateSize {
+			// Emit the error
+			select {
+			case h.qEvent <- &types.DeviceEvent{
+				AppID: appUp.AppID,
+				DevID: appUp.DevID,
+				Event: types.UplinkErrorEvent,
+				Data:  types.ErrorEventData{Error: fmt.Sprintf("Decoder state exceeds maximum size (%d)", device.MaxStateSize)},
+			}:
+			case <-time.After(eventPublishTimeout):
+				ctx.Warnf("Could not emit %q event", types.UplinkErrorEvent)
+			}
+
+			// Do not set fields or state if processing failed, but allow the handler to continue processing
+			// without payload formatting
+			return nil
+		}
+		dev.State = custom.State
+	}
+
 	appUp.PayloadFields = fields
 	appUp.Attributes = dev.Attributes
 
@@ -104,7 +148,7 @@ func (h *handler) ConvertFieldsUp(ctx ttnlog.Interface, _ *pb_broker.Deduplicate
 }
 
 // ConvertFieldsDown converts the fields into a payload
-func (h *handler) ConvertFieldsDown(ctx ttnlog.Interface, appDown *types.DownlinkMessage, ttnDown *pb_broker.DownlinkMessage, _ *device.Device) error {
+func (h *handler) ConvertFieldsDown(ctx ttnlog.Interface, appDown *types.DownlinkMessage, ttnDown *pb_broker.DownlinkMessage, dev *device.Device) error {
 	if appDown.PayloadFields == nil || len(appDown.PayloadFields) == 0 {
 		return nil
 	}
@@ -118,11 +162,18 @@ func (h *handler) ConvertFieldsDown(ctx ttnlog.Interface, appDown *types.Downlin
 		return nil
 	}
 
+	if app.DownlinkFieldsSchema != "" {
+		if err := h.validateFields(app.AppID, "down", app.DownlinkFieldsSchema, appDown.PayloadFields); err != nil {
+			return err
+		}
+	}
+
 	var encoder PayloadEncoder
 	switch app.PayloadFormat {
 	case application.PayloadFormatCustom:
 		encoder = &CustomDownlinkFunctions{
 			Encoder: app.CustomEncoder,
+			Context: downlinkPayloadContext(ttnDown, dev),
 			Logger:  functions.Ignore,
 		}
 	case application.PayloadFormatCayenneLPP:
@@ -140,3 +191,38 @@ func (h *handler) ConvertFieldsDown(ctx ttnlog.Interface, appDown *types.Downlin
 
 	return nil
 }
+
+// uplinkPayloadContext returns the context for the uplink payload functions
+func uplinkPayloadContext(appUp *types.UplinkMessage, dev *device.Device) *PayloadContext {
+	ctx := &PayloadContext{
+		DevID:          appUp.DevID,
+		HardwareSerial: appUp.HardwareSerial,
+		FCnt:           appUp.FCnt,
+		Metadata: &PayloadContextMetadata{
+			Time:         appUp.Metadata.Time,
+			Frequency:    appUp.Metadata.Frequency,
+			DataRate:     appUp.Metadata.DataRate,
+			GatewayCount: len(appUp.Metadata.Gateways),
+		},
+	}
+	if dev != nil {
+		ctx.Attributes = dev.Attributes
+	}
+	return ctx
+}
+
+// downlinkPayloadContext returns the context for the downlink payload function
+func downlinkPayloadContext(ttnDown *pb_broker.DownlinkMessage, dev *device.Device) *PayloadContext {
+	ctx := new(PayloadContext)
+	if dev != nil {
+		ctx.DevID = dev.DevID
+		ctx.HardwareSerial = dev.DevEUI.String()
+		ctx.Attributes = dev.Attributes
+	}
+	if ttnDown != nil && ttnDown.DownlinkOption != nil {
+		if lorawan := ttnDown.DownlinkOption.ProtocolConfiguration.GetLoRaWAN(); lorawan != nil {
+			ctx.FCnt = lorawan.FCnt
+		}
+	}
+	return ctx
+}
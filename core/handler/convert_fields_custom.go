@@ -4,15 +4,58 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
 
 	pb_handler "github.com/TheThingsNetwork/api/handler"
 	"github.com/TheThingsNetwork/ttn/core/handler/functions"
+	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
 )
 
+// PayloadContext is passed as read-only third argument to the payload functions, so that one function can serve
+// devices that need different constants
+type PayloadContext struct {
+	DevID          string                  `json:"dev_id,omitempty"`
+	HardwareSerial string                  `json:"hardware_serial,omitempty"`
+	Attributes     map[string]string       `json:"attributes,omitempty"`
+	FCnt           uint32                  `json:"counter"`
+	Metadata       *PayloadContextMetadata `json:"metadata,omitempty"`
+}
+
+// PayloadContextMetadata contains the metadata of a received uplink message
+type PayloadContextMetadata struct {
+	Time         types.JSONTime `json:"time,omitempty"`
+	Frequency    float32        `json:"frequency,omitempty"`
+	DataRate     string         `json:"data_rate,omitempty"`
+	GatewayCount int            `json:"gateway_count"`
+}
+
+// contextArgument is the JavaScript expression for the context argument. The context is passed as JSON and frozen,
+// so that changes made by one function do not leak into the next
+const contextArgument = `(function freeze(obj) {
+	Object.getOwnPropertyNames(obj).forEach(function (name) {
+		if (typeof obj[name] === 'object' && obj[name] !== null) {
+			freeze(obj[name]);
+		}
+	});
+	return Object.freeze(obj);
+})(JSON.parse(context))`
+
+// contextEnv returns the context as JSON for the environment of the payload functions
+func contextEnv(ctx *PayloadContext) (string, error) {
+	if ctx == nil {
+		ctx = new(PayloadContext)
+	}
+	data, err := json.Marshal(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // CustomUplinkFunctions decodes, converts and validates payload using JavaScript functions
 type CustomUplinkFunctions struct {
 	// Decoder is a JavaScript function that accepts the payload as byte array and
@@ -25,6 +68,16 @@ type CustomUplinkFunctions struct {
 	// Converter and returns a boolean value indicating the validity of the data
 	Validator string
 
+	// Context is passed as third argument to the functions. Functions that
+	// accept only the payload and the port ignore it
+	Context *PayloadContext
+
+	// State is passed as fourth argument to the Decoder, which can update it in
+	// place. It is only updated by Decode if decoding, converting and validating
+	// succeed
+	State        map[string]interface{}
+	decodedState map[string]interface{}
+
 	// Logger is the logger that will be used to store logs
 	Logger functions.Logger
 }
@@ -38,24 +91,46 @@ func (f *CustomUplinkFunctions) decode(payload []byte, port uint8) (map[string]i
 		return nil, nil
 	}
 
+	context, err := contextEnv(f.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	state := []byte("{}")
+	if len(f.State) > 0 {
+		state, err = json.Marshal(f.State)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	env := map[string]interface{}{
 		"payload": payload,
 		"port":    port,
+		"context": context,
+		"state":   string(state),
 	}
 	code := fmt.Sprintf(`
 		%s;
-		Decoder(payload.slice(0), port);
-	`, f.Decoder)
+		(function (state) {
+			return { fields: Decoder(payload.slice(0), port, %s, state), state: state };
+		})(JSON.parse(state));
+	`, f.Decoder, contextArgument)
 
 	value, err := functions.RunCode("Decoder", code, env, timeOut, f.Logger)
 	if err != nil {
 		return nil, err
 	}
 
-	m, ok := value.(map[string]interface{})
+	result, _ := value.(map[string]interface{})
+	m, ok := result["fields"].(map[string]interface{})
 	if !ok {
 		return nil, errors.NewErrInvalidArgument("Decoder", "does not return an object")
 	}
+	f.decodedState, ok = result["state"].(map[string]interface{})
+	if !ok {
+		return nil, errors.NewErrInvalidArgument("Decoder", "state is not an object")
+	}
 	return m, nil
 }
 
@@ -67,15 +142,20 @@ func (f *CustomUplinkFunctions) convert(fields map[string]interface{}, port uint
 		return fields, nil
 	}
 
-	env := map[string]interface{}{
-		"fields": fields,
-		"port":   port,
+	context, err := contextEnv(f.Context)
+	if err != nil {
+		return nil, err
 	}
 
+	env := map[string]interface{}{
+		"fields":  fields,
+		"port":    port,
+		"context": context,
+	}
 	code := fmt.Sprintf(`
 		%s;
-		Converter(fields, port)
-	`, f.Converter)
+		Converter(fields, port, %s)
+	`, f.Converter, contextArgument)
 
 	value, err := functions.RunCode("Converter", code, env, timeOut, f.Logger)
 	if err != nil {
@@ -97,14 +177,20 @@ func (f *CustomUplinkFunctions) validate(fields map[string]interface{}, port uin
 		return true, nil
 	}
 
+	context, err := contextEnv(f.Context)
+	if err != nil {
+		return false, err
+	}
+
 	env := map[string]interface{}{
-		"fields": fields,
-		"port":   port,
+		"fields":  fields,
+		"port":    port,
+		"context": context,
 	}
 	code := fmt.Sprintf(`
 		%s;
-		Validator(fields, port)
-	`, f.Validator)
+		Validator(fields, port, %s)
+	`, f.Validator, contextArgument)
 
 	value, err := functions.RunCode("Validator", code, env, timeOut, f.Logger)
 	if err != nil {
@@ -131,6 +217,9 @@ func (f *CustomUplinkFunctions) Decode(payload []byte, port uint8) (map[string]i
 	}
 
 	valid, err := f.validate(converted, port)
+	if err == nil && valid && f.decodedState != nil {
+		f.State = f.decodedState
+	}
 	return converted, valid, err
 }
 
@@ -145,6 +234,9 @@ type CustomDownlinkFunctions struct {
 	// returns an array of bytes
 	Encoder string
 
+	// Context is passed as third argument to the function
+	Context *PayloadContext
+
 	// Logger is the logger that will be used to store logs
 	Logger functions.Logger
 }
@@ -156,14 +248,20 @@ func (f *CustomDownlinkFunctions) encode(payload map[string]interface{}, port ui
 		return nil, errors.NewErrInvalidArgument("Downlink Payload", "fields supplied, but no Encoder function set")
 	}
 
+	context, err := contextEnv(f.Context)
+	if err != nil {
+		return nil, err
+	}
+
 	env := map[string]interface{}{
 		"payload": payload,
 		"port":    port,
+		"context": context,
 	}
 	code := fmt.Sprintf(`
 		%s;
-		Encoder(payload, port)
-	`, f.Encoder)
+		Encoder(payload, port, %s)
+	`, f.Encoder, contextArgument)
 
 	value, err := functions.RunCode("Encoder", code, env, timeOut, f.Logger)
 	if err != nil {
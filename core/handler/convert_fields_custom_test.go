@@ -105,6 +105,122 @@ func TestCustomDecodeUplink(t *testing.T) {
 	a.So(data["humidity"], ShouldEqual, 110)
 }
 
+func TestCustomDecodeContext(t *testing.T) {
+	a := New(t)
+
+	context := &PayloadContext{
+		DevID:          "dev",
+		HardwareSerial: "0102030405060708",
+		Attributes:     map[string]string{"offset": "5"},
+		FCnt:           42,
+		Metadata: &PayloadContextMetadata{
+			Frequency:    868.1,
+			DataRate:     "SF7BW125",
+			GatewayCount: 2,
+		},
+	}
+
+	functions := &CustomUplinkFunctions{
+		Decoder: `function Decoder (payload, port, context) {
+	return {
+		temperature: payload[0] - parseInt(context.attributes.offset),
+		dev_id: context.dev_id,
+		hardware_serial: context.hardware_serial,
+		counter: context.counter,
+		data_rate: context.metadata.data_rate,
+		gateway_count: context.metadata.gateway_count
+	}
+}`,
+		Converter: `function Converter (data, port, context) {
+	context.dev_id = "other";
+	context.attributes.offset = "0";
+	data.converted_dev_id = context.dev_id;
+	data.converted_offset = context.attributes.offset;
+	return data;
+}`,
+		Validator: `function Validator (data, port, context) {
+	return context.counter === 42;
+}`,
+		Context: context,
+	}
+
+	data, valid, err := functions.Decode([]byte{25}, 1)
+	a.So(err, ShouldBeNil)
+	a.So(valid, ShouldBeTrue)
+	a.So(data["temperature"], ShouldEqual, 20)
+	a.So(data["dev_id"], ShouldEqual, "dev")
+	a.So(data["hardware_serial"], ShouldEqual, "0102030405060708")
+	a.So(data["counter"], ShouldEqual, 42)
+	a.So(data["data_rate"], ShouldEqual, "SF7BW125")
+	a.So(data["gateway_count"], ShouldEqual, 2)
+
+	// The context is read-only
+	a.So(data["converted_dev_id"], ShouldEqual, "dev")
+	a.So(data["converted_offset"], ShouldEqual, "5")
+	a.So(context.DevID, ShouldEqual, "dev")
+
+	// Without context
+	functions = &CustomUplinkFunctions{
+		Decoder: `function Decoder (payload, port, context) {
+	return { dev_id: context.dev_id, counter: context.counter }
+}`,
+	}
+	data, _, err = functions.Decode([]byte{25}, 1)
+	a.So(err, ShouldBeNil)
+	a.So(data["dev_id"], ShouldBeNil)
+	a.So(data["counter"], ShouldEqual, 0)
+}
+
+func TestCustomDecodeState(t *testing.T) {
+	a := New(t)
+
+	functions := &CustomUplinkFunctions{
+		Decoder: `function Decoder (payload, port, context, state) {
+	var value = (state.last || 0) + payload[0];
+	state.last = value;
+	return { value: value };
+}`,
+		Validator: `function Validator (data) {
+	return data.value < 100;
+}`,
+	}
+
+	data, valid, err := functions.Decode([]byte{10}, 1)
+	a.So(err, ShouldBeNil)
+	a.So(valid, ShouldBeTrue)
+	a.So(data["value"], ShouldEqual, 10)
+	a.So(functions.State["last"], ShouldEqual, 10)
+
+	data, valid, err = functions.Decode([]byte{5}, 1)
+	a.So(err, ShouldBeNil)
+	a.So(valid, ShouldBeTrue)
+	a.So(data["value"], ShouldEqual, 15)
+	a.So(functions.State["last"], ShouldEqual, 15)
+
+	// Invalid data does not update the state
+	_, valid, err = functions.Decode([]byte{100}, 1)
+	a.So(err, ShouldBeNil)
+	a.So(valid, ShouldBeFalse)
+	a.So(functions.State["last"], ShouldEqual, 15)
+
+	// Errors do not update the state
+	functions.Converter = `function Converter (data) { throw new Error("expected"); }`
+	_, _, err = functions.Decode([]byte{1}, 1)
+	a.So(err, ShouldNotBeNil)
+	a.So(functions.State["last"], ShouldEqual, 15)
+
+	// Replacing the state is not supported
+	functions = &CustomUplinkFunctions{
+		Decoder: `function Decoder (payload, port, context, state) {
+	state = { last: payload[0] };
+	return {};
+}`,
+	}
+	_, _, err = functions.Decode([]byte{1}, 1)
+	a.So(err, ShouldBeNil)
+	a.So(functions.State, ShouldBeEmpty)
+}
+
 func TestCustomDecodeInvalidUplinkFunction(t *testing.T) {
 	a := New(t)
 
@@ -239,6 +355,25 @@ func TestCustomEncode(t *testing.T) {
 	a.So(err, ShouldBeNil)
 }
 
+func TestCustomEncodeContext(t *testing.T) {
+	a := New(t)
+
+	functions := &CustomDownlinkFunctions{
+		Encoder: `function Encoder (payload, port, context) {
+	return [ payload.value * parseInt(context.attributes.scale), context.counter, context.dev_id.length ]
+}`,
+		Context: &PayloadContext{
+			DevID:      "dev",
+			Attributes: map[string]string{"scale": "2"},
+			FCnt:       7,
+		},
+	}
+
+	m, _, err := functions.Encode(map[string]interface{}{"value": 11}, 1)
+	a.So(err, ShouldBeNil)
+	a.So(m, ShouldResemble, []byte{22, 7, 3})
+}
+
 func TestCustomProcessDownlinkInvalidFunction(t *testing.T) {
 	a := New(t)
 
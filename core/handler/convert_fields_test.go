@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	pb_broker "github.com/TheThingsNetwork/api/broker"
+	pb_protocol "github.com/TheThingsNetwork/api/protocol"
+	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
 
 	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/handler/device"
@@ -48,7 +50,7 @@ func TestConvertFieldsUpCustom(t *testing.T) {
 	// No functions
 	{
 		ttnUp, appUp := buildCustomUplink(appID)
-		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev)
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev, nil)
 		a.So(err, ShouldBeNil)
 		a.So(appUp.PayloadFields, ShouldBeEmpty)
 	}
@@ -66,7 +68,7 @@ func TestConvertFieldsUpCustom(t *testing.T) {
 			h.applications.Delete(appID)
 		}()
 		ttnUp, appUp := buildCustomUplink(appID)
-		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev)
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev, app)
 		a.So(err, ShouldBeNil)
 		a.So(appUp.PayloadFields, ShouldResemble, map[string]interface{}{
 			"temperature": 21.6,
@@ -80,7 +82,7 @@ func TestConvertFieldsUpCustom(t *testing.T) {
 		app.CustomValidator = `function Validator (data) { return false; }`
 		h.applications.Set(app)
 		ttnUp, appUp := buildCustomUplink(appID)
-		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev)
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev, app)
 		a.So(err, ShouldNotBeNil)
 		a.So(appUp.PayloadFields, ShouldBeEmpty)
 	}
@@ -91,7 +93,7 @@ func TestConvertFieldsUpCustom(t *testing.T) {
 		app.CustomValidator = `function Validator (data) { throw new Error("expected"); }`
 		h.applications.Set(app)
 		ttnUp, appUp := buildCustomUplink(appID)
-		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev)
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev, app)
 		a.So(err, ShouldBeNil)
 		a.So(appUp.PayloadFields, ShouldBeEmpty)
 		a.So(len(h.qEvent), ShouldEqual, 1)
@@ -107,7 +109,7 @@ func TestConvertFieldsUpCustom(t *testing.T) {
 		app.CustomDecoder = `function Decoder (data) { return { infinite: 10/0 }; }`
 		h.applications.Set(app)
 		ttnUp, appUp := buildCustomUplink(appID)
-		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev)
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpCustom"), ttnUp, appUp, dev, app)
 		a.So(err, ShouldBeNil)
 		a.So(appUp.PayloadFields, ShouldBeEmpty)
 		a.So(len(h.qEvent), ShouldEqual, 1)
@@ -148,7 +150,7 @@ func TestConvertFieldsUpCayenneLPP(t *testing.T) {
 	// No application
 	{
 		ttnUp, appUp := buildCayenneLPPUplink(appID)
-		err := h.ConvertFieldsUp(ctx, ttnUp, appUp, dev)
+		err := h.ConvertFieldsUp(ctx, ttnUp, appUp, dev, nil)
 		a.So(err, ShouldBeNil)
 		a.So(appUp.PayloadFields, ShouldBeEmpty)
 	}
@@ -164,7 +166,7 @@ func TestConvertFieldsUpCayenneLPP(t *testing.T) {
 			h.applications.Delete(appID)
 		}()
 		ttnUp, appUp := buildCayenneLPPUplink(appID)
-		err := h.ConvertFieldsUp(ctx, ttnUp, appUp, dev)
+		err := h.ConvertFieldsUp(ctx, ttnUp, appUp, dev, app)
 		a.So(err, ShouldBeNil)
 		a.So(appUp.PayloadFields, ShouldResemble, map[string]interface{}{
 			"barometric_pressure_10": float32(1073.5),
@@ -224,6 +226,39 @@ func TestConvertFieldsDownCustom(t *testing.T) {
 	}
 }
 
+func TestConvertFieldsDownSchema(t *testing.T) {
+	a := New(t)
+	appID := "AppID-1"
+
+	h := &handler{
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-convert-fields-down-schema"),
+	}
+
+	h.applications.Set(&application.Application{
+		AppID:                appID,
+		PayloadFormat:        application.PayloadFormatCustom,
+		CustomEncoder:        `function Encoder (payload) { return [ payload.temperature ] }`,
+		DownlinkFieldsSchema: `{"required": ["temperature"], "properties": {"temperature": {"type": "integer", "maximum": 25}}}`,
+	})
+	defer func() {
+		h.applications.Delete(appID)
+	}()
+
+	ttnDown, appDown := buildCustomDownlink()
+	err := h.ConvertFieldsDown(GetLogger(t, "TestConvertFieldsDownSchema"), appDown, ttnDown, nil)
+	a.So(err, ShouldNotBeNil)
+	a.So(errorEventData(err).Fields, ShouldResemble, []types.FieldError{
+		{Field: "temperature", Error: "must be less than or equal to 25"},
+	})
+	a.So(appDown.PayloadRaw, ShouldBeEmpty)
+
+	ttnDown, appDown = buildCustomDownlink()
+	appDown.PayloadFields["temperature"] = 20
+	err = h.ConvertFieldsDown(GetLogger(t, "TestConvertFieldsDownSchema"), appDown, ttnDown, nil)
+	a.So(err, ShouldBeNil)
+	a.So(appDown.PayloadRaw, ShouldResemble, []byte{20})
+}
+
 func TestConvertFieldsDownCustomNoPort(t *testing.T) {
 	a := New(t)
 	appID := "AppID-1"
@@ -307,3 +342,160 @@ func TestConvertFieldsDownCayenneLPP(t *testing.T) {
 		a.So(appDown.PayloadRaw, ShouldResemble, []byte{7, 249, 232})
 	}
 }
+
+func TestConvertFieldsUpSchema(t *testing.T) {
+	a := New(t)
+	appID := "AppID-Schema"
+
+	h := &handler{
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-convert-fields-up-schema"),
+		qEvent:       make(chan *types.DeviceEvent, 1),
+	}
+
+	app := &application.Application{
+		AppID:              appID,
+		PayloadFormat:      application.PayloadFormatCustom,
+		CustomDecoder:      `function Decoder (data) { return { temperature: ((data[0] << 8) | data[1]) / 100 }; }`,
+		UplinkFieldsSchema: `{"properties": {"temperature": {"type": "number", "minimum": -40, "maximum": 85}}}`,
+	}
+	a.So(h.applications.Set(app), ShouldBeNil)
+	defer func() {
+		h.applications.Delete(appID)
+	}()
+
+	dev := new(device.Device)
+
+	// Valid fields
+	{
+		ttnUp, appUp := buildCustomUplink(appID)
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpSchema"), ttnUp, appUp, dev, app)
+		a.So(err, ShouldBeNil)
+		a.So(appUp.PayloadFields, ShouldResemble, map[string]interface{}{
+			"temperature": 21.6,
+		})
+	}
+
+	// Invalid fields are dropped
+	{
+		ttnUp, appUp := buildCustomUplink(appID)
+		appUp.PayloadRaw = []byte{0x27, 0x10}
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpSchema"), ttnUp, appUp, dev, app)
+		a.So(err, ShouldNotBeNil)
+		a.So(errorEventData(err).Fields, ShouldResemble, []types.FieldError{
+			{Field: "temperature", Error: "must be less than or equal to 85"},
+		})
+		a.So(appUp.PayloadFields, ShouldBeEmpty)
+	}
+
+	// Invalid fields are forwarded without fields
+	{
+		app.StartUpdate()
+		app.ForwardRawOnInvalidFields = true
+		h.applications.Set(app)
+		ttnUp, appUp := buildCustomUplink(appID)
+		appUp.PayloadRaw = []byte{0x27, 0x10}
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpSchema"), ttnUp, appUp, dev, app)
+		a.So(err, ShouldBeNil)
+		a.So(appUp.PayloadFields, ShouldBeEmpty)
+		a.So(len(h.qEvent), ShouldEqual, 1)
+		evt := <-h.qEvent
+		errEvt, ok := evt.Data.(types.ErrorEventData)
+		a.So(ok, ShouldBeTrue)
+		a.So(errEvt.Fields, ShouldHaveLength, 1)
+	}
+}
+
+func TestConvertFieldsUpState(t *testing.T) {
+	a := New(t)
+	appID := "AppID-State"
+
+	h := &handler{
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-convert-fields-up-state"),
+		qEvent:       make(chan *types.DeviceEvent, 1),
+	}
+
+	app := &application.Application{
+		AppID:         appID,
+		PayloadFormat: application.PayloadFormatCustom,
+		CustomDecoder: `function Decoder (data, port, context, state) {
+	state.count = (state.count || 0) + 1;
+	state.padding = new Array(data[0] * 100).join("x");
+	return { count: state.count };
+}`,
+	}
+	a.So(h.applications.Set(app), ShouldBeNil)
+	defer func() {
+		h.applications.Delete(appID)
+	}()
+
+	dev := new(device.Device)
+	dev.State = map[string]interface{}{"count": 1}
+
+	{
+		ttnUp, appUp := buildCustomUplink(appID)
+		appUp.PayloadRaw = []byte{0}
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpState"), ttnUp, appUp, dev, app)
+		a.So(err, ShouldBeNil)
+		a.So(appUp.PayloadFields["count"], ShouldEqual, 2)
+		a.So(dev.State["count"], ShouldEqual, 2)
+	}
+
+	// State too large
+	{
+		ttnUp, appUp := buildCustomUplink(appID)
+		appUp.PayloadRaw = []byte{20}
+		err := h.ConvertFieldsUp(GetLogger(t, "TestConvertFieldsUpState"), ttnUp, appUp, dev, app)
+		a.So(err, ShouldBeNil)
+		a.So(appUp.PayloadFields, ShouldBeEmpty)
+		a.So(dev.State["count"], ShouldEqual, 2)
+		a.So(len(h.qEvent), ShouldEqual, 1)
+		evt := <-h.qEvent
+		errEvt, ok := evt.Data.(types.ErrorEventData)
+		a.So(ok, ShouldBeTrue)
+		a.So(errEvt.Error, ShouldContainSubstring, "maximum size")
+	}
+}
+
+func TestPayloadContext(t *testing.T) {
+	a := New(t)
+
+	dev := &device.Device{
+		DevID:      "DevID-1",
+		DevEUI:     types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8},
+		Attributes: attributes,
+	}
+
+	_, appUp := buildCustomUplink("AppID-1")
+	appUp.HardwareSerial = dev.DevEUI.String()
+	appUp.FCnt = 42
+	appUp.Metadata.Frequency = 868.1
+	appUp.Metadata.DataRate = "SF7BW125"
+	appUp.Metadata.Gateways = []types.GatewayMetadata{{GtwID: "gtw-1"}, {GtwID: "gtw-2"}}
+
+	a.So(uplinkPayloadContext(appUp, dev), ShouldResemble, &PayloadContext{
+		DevID:          "DevID-1",
+		HardwareSerial: "0102030405060708",
+		Attributes:     attributes,
+		FCnt:           42,
+		Metadata: &PayloadContextMetadata{
+			Frequency:    868.1,
+			DataRate:     "SF7BW125",
+			GatewayCount: 2,
+		},
+	})
+
+	ttnDown := &pb_broker.DownlinkMessage{
+		DownlinkOption: &pb_broker.DownlinkOption{},
+	}
+	ttnDown.DownlinkOption.ProtocolConfiguration = pb_protocol.TxConfiguration{Protocol: &pb_protocol.TxConfiguration_LoRaWAN{
+		LoRaWAN: &pb_lorawan.TxConfiguration{FCnt: 7},
+	}}
+
+	a.So(downlinkPayloadContext(ttnDown, dev), ShouldResemble, &PayloadContext{
+		DevID:          "DevID-1",
+		HardwareSerial: "0102030405060708",
+		Attributes:     attributes,
+		FCnt:           7,
+	})
+	a.So(downlinkPayloadContext(nil, nil), ShouldResemble, &PayloadContext{})
+}
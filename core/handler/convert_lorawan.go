@@ -10,12 +10,13 @@ import (
 	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
 	"github.com/TheThingsNetwork/api/trace"
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/handler/device"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
 )
 
-func (h *handler) ConvertFromLoRaWAN(ctx ttnlog.Interface, ttnUp *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, dev *device.Device) (err error) {
+func (h *handler) ConvertFromLoRaWAN(ctx ttnlog.Interface, ttnUp *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, dev *device.Device, app *application.Application) (err error) {
 	if err := ttnUp.UnmarshalPayload(); err != nil {
 		return err
 	}
@@ -47,6 +48,24 @@ func (h *handler) ConvertFromLoRaWAN(ctx ttnlog.Interface, ttnUp *pb_broker.Dedu
 		appUp.Confirmed = true
 	}
 
+	lorawan := &types.LoRaWANMetadata{
+		MType:     phyPayload.MType.String(),
+		DevAddr:   macPayload.DevAddr,
+		ADR:       macPayload.ADR,
+		ADRAckReq: macPayload.ADRAckReq,
+		Ack:       macPayload.Ack,
+		FPending:  macPayload.FPending,
+	}
+	for _, cmd := range macPayload.FOpts {
+		lorawan.FOpts = append(lorawan.FOpts, types.MACCommand{
+			CID:     uint8(cmd.CID),
+			Payload: cmd.Payload,
+		})
+	}
+	if app != nil && app.LoRaWANMetadata {
+		appUp.LoRaWAN = lorawan
+	}
+
 	appUp.FPort = uint8(macPayload.FPort)
 	if macPayload.FPort > 0 {
 		if err := phyPayload.DecryptFRMPayload(dev.AppSKey); err != nil {
@@ -60,6 +79,13 @@ func (h *handler) ConvertFromLoRaWAN(ctx ttnlog.Interface, ttnUp *pb_broker.Dedu
 		if dev.CurrentDownlink.Confirmed {
 			// If it's confirmed, we can only unset it if we receive an ack.
 			if macPayload.Ack {
+				// The counter correlates the ack with the config of the "down/sent" event. Uplinks that acknowledge a
+				// downlink always contain the frame header, also for applications that did not opt in.
+				lorawan.AckedDownlink = &types.AckedDownlink{
+					FCnt:    dev.CurrentDownlinkFCnt,
+					Message: dev.CurrentDownlink,
+				}
+				appUp.LoRaWAN = lorawan
 				// Send event over MQTT
 				select {
 				case h.qEvent <- &types.DeviceEvent{
@@ -68,16 +94,19 @@ func (h *handler) ConvertFromLoRaWAN(ctx ttnlog.Interface, ttnUp *pb_broker.Dedu
 					Event: types.DownlinkAckEvent,
 					Data: types.DownlinkEventData{
 						Message: dev.CurrentDownlink,
+						Config:  &types.DownlinkEventConfigInfo{FCnt: uint(dev.CurrentDownlinkFCnt)},
 					},
 				}:
 				case <-time.After(eventPublishTimeout):
 					ctx.Warnf("Could not emit %q event", types.DownlinkAckEvent)
 				}
 				dev.CurrentDownlink = nil
+				dev.CurrentDownlinkFCnt = 0
 			}
 		} else {
 			// If it's unconfirmed, we can unset it.
 			dev.CurrentDownlink = nil
+			dev.CurrentDownlinkFCnt = 0
 		}
 	}
 
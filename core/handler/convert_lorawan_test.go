@@ -10,6 +10,7 @@ import (
 	pb_protocol "github.com/TheThingsNetwork/api/protocol"
 	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
 	"github.com/TheThingsNetwork/ttn/core/component"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/handler/device"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	. "github.com/TheThingsNetwork/ttn/utils/testing"
@@ -45,10 +46,11 @@ func TestConvertFromLoRaWAN(t *testing.T) {
 		CurrentDownlink: &types.DownlinkMessage{},
 	}
 	ttnUp, appUp := buildLoRaWANUplink([]byte{0x40, 0x04, 0x03, 0x02, 0x01, 0x20, 0x01, 0x00, 0x0A, 0x46, 0x55, 0x96, 0x42, 0x92, 0xF2})
-	err := h.ConvertFromLoRaWAN(h.Ctx, ttnUp, appUp, device)
+	err := h.ConvertFromLoRaWAN(h.Ctx, ttnUp, appUp, device, nil)
 	a.So(err, ShouldBeNil)
 	a.So(appUp.PayloadRaw, ShouldResemble, []byte{0xaa, 0xbc})
 	a.So(appUp.FCnt, ShouldEqual, 1)
+	a.So(appUp.LoRaWAN, ShouldBeNil)
 	a.So(device.CurrentDownlink, ShouldBeNil)
 
 	device.CurrentDownlink = &types.DownlinkMessage{Confirmed: true}
@@ -62,16 +64,19 @@ func TestConvertFromLoRaWAN(t *testing.T) {
 	ttnUp.Message.GetLoRaWAN().SetMIC(device.NwkSKey)
 	ttnUp.Payload = ttnUp.Message.GetLoRaWAN().PHYPayloadBytes()
 
-	err = h.ConvertFromLoRaWAN(h.Ctx, ttnUp, appUp, device)
+	err = h.ConvertFromLoRaWAN(h.Ctx, ttnUp, appUp, device, nil)
 	a.So(err, ShouldBeNil)
 	a.So(appUp.Confirmed, ShouldBeTrue)
 	a.So(device.CurrentDownlink, ShouldNotBeNil)
 
 	device.CurrentDownlink = &types.DownlinkMessage{Confirmed: true}
+	device.CurrentDownlinkFCnt = 5
 
 	wg.Add(1)
 	go func() {
-		<-h.qEvent
+		evt := <-h.qEvent
+		a.So(evt.Event, ShouldEqual, types.DownlinkAckEvent)
+		a.So(evt.Data.(types.DownlinkEventData).Config.FCnt, ShouldEqual, 5)
 		wg.Done()
 	}()
 
@@ -84,11 +89,39 @@ func TestConvertFromLoRaWAN(t *testing.T) {
 	ttnUp.Message.GetLoRaWAN().SetMIC(device.NwkSKey)
 	ttnUp.Payload = ttnUp.Message.GetLoRaWAN().PHYPayloadBytes()
 
-	err = h.ConvertFromLoRaWAN(h.Ctx, ttnUp, appUp, device)
+	err = h.ConvertFromLoRaWAN(h.Ctx, ttnUp, appUp, device, nil)
 	a.So(err, ShouldBeNil)
 	a.So(appUp.Confirmed, ShouldBeTrue)
+	a.So(appUp.LoRaWAN, ShouldNotBeNil)
+	a.So(appUp.LoRaWAN.MType, ShouldEqual, "CONFIRMED_UP")
+	a.So(appUp.LoRaWAN.DevAddr, ShouldEqual, types.DevAddr{0x01, 0x02, 0x03, 0x04})
+	a.So(appUp.LoRaWAN.Ack, ShouldBeTrue)
+	a.So(appUp.LoRaWAN.AckedDownlink, ShouldNotBeNil)
+	a.So(appUp.LoRaWAN.AckedDownlink.FCnt, ShouldEqual, 5)
+	a.So(appUp.LoRaWAN.AckedDownlink.Message.Confirmed, ShouldBeTrue)
+	a.So(device.CurrentDownlink, ShouldBeNil)
+	a.So(device.CurrentDownlinkFCnt, ShouldEqual, 0)
 
 	wg.Wait()
+
+	// Applications that opted in get the frame header of all uplinks
+	app := &application.Application{AppID: "appid", LoRaWANMetadata: true}
+
+	ttnUp.UnmarshalPayload()
+	ttnUp.Message.GetLoRaWAN().MType = pb_lorawan.MType_UNCONFIRMED_UP
+	ttnUp.Message.GetLoRaWAN().GetMACPayload().FCnt++
+	md = ttnUp.GetProtocolMetadata()
+	md.GetLoRaWAN().FCnt = ttnUp.Message.GetLoRaWAN().GetMACPayload().FCnt
+	ttnUp.Message.GetLoRaWAN().GetMACPayload().Ack = false
+	ttnUp.Message.GetLoRaWAN().SetMIC(device.NwkSKey)
+	ttnUp.Payload = ttnUp.Message.GetLoRaWAN().PHYPayloadBytes()
+
+	appUp = &types.UplinkMessage{AppID: "appid"}
+	err = h.ConvertFromLoRaWAN(h.Ctx, ttnUp, appUp, device, app)
+	a.So(err, ShouldBeNil)
+	a.So(appUp.LoRaWAN, ShouldNotBeNil)
+	a.So(appUp.LoRaWAN.MType, ShouldEqual, "UNCONFIRMED_UP")
+	a.So(appUp.LoRaWAN.AckedDownlink, ShouldBeNil)
 }
 
 func buildLoRaWANDownlink(payload []byte) (*types.DownlinkMessage, *pb_broker.DownlinkMessage) {
@@ -32,7 +32,7 @@ func TestConvertMetadata(t *testing.T) {
 		Latitude: 12.34,
 	}
 
-	err := h.ConvertMetadata(h.Ctx, ttnUp, appUp, device)
+	err := h.ConvertMetadata(h.Ctx, ttnUp, appUp, device, nil)
 	a.So(err, ShouldBeNil)
 	a.So(appUp.Metadata.Latitude, ShouldEqual, 12.34)
 
@@ -50,7 +50,7 @@ func TestConvertMetadata(t *testing.T) {
 		},
 	}
 
-	err = h.ConvertMetadata(h.Ctx, ttnUp, appUp, device)
+	err = h.ConvertMetadata(h.Ctx, ttnUp, appUp, device, nil)
 	a.So(err, ShouldBeNil)
 	a.So(appUp.Metadata.Gateways, ShouldHaveLength, 3)
 
@@ -61,7 +61,7 @@ func TestConvertMetadata(t *testing.T) {
 		},
 	}}
 
-	err = h.ConvertMetadata(h.Ctx, ttnUp, appUp, device)
+	err = h.ConvertMetadata(h.Ctx, ttnUp, appUp, device, nil)
 	a.So(err, ShouldBeNil)
 	a.So(appUp.Metadata.DataRate, ShouldEqual, "SF7BW125")
 
@@ -70,7 +70,7 @@ func TestConvertMetadata(t *testing.T) {
 		Latitude: 42,
 	}
 
-	err = h.ConvertMetadata(h.Ctx, ttnUp, appUp, device)
+	err = h.ConvertMetadata(h.Ctx, ttnUp, appUp, device, nil)
 	a.So(err, ShouldBeNil)
 	a.So(appUp.Metadata.Gateways[0].Latitude, ShouldEqual, 42)
 	a.So(time.Time(appUp.Metadata.Gateways[0].Time).UTC(), ShouldResemble, time.Date(2016, 06, 13, 15, 28, 56, 0, time.UTC))
@@ -0,0 +1,81 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package device
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/storage"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"gopkg.in/redis.v5"
+)
+
+// ClaimableDevice is a device from the manifest of an application that is not registered yet. The device can be
+// claimed into an application with its claim code, or register on join in the application that imported it.
+type ClaimableDevice struct {
+	AppID     string       `redis:"app_id" json:"-"`
+	DevEUI    types.DevEUI `redis:"dev_eui" json:"dev_eui"`
+	AppKey    types.AppKey `redis:"app_key" json:"app_key"`
+	ClaimCode string       `redis:"claim_code" json:"claim_code"`
+	CreatedAt time.Time    `redis:"created_at" json:"-"`
+}
+
+// ValidClaimCode returns true if the claim code is the claim code of the device
+func (d *ClaimableDevice) ValidClaimCode(claimCode string) bool {
+	return subtle.ConstantTimeCompare([]byte(d.ClaimCode), []byte(claimCode)) == 1
+}
+
+// ClaimStore interface for ClaimableDevices
+type ClaimStore interface {
+	Get(devEUI types.DevEUI) (*ClaimableDevice, error)
+	Set(new *ClaimableDevice) error
+	Delete(devEUI types.DevEUI) error
+}
+
+const redisClaimPrefix = "claim"
+
+// NewRedisClaimStore creates a new Redis-based ClaimableDevice store
+func NewRedisClaimStore(client *redis.Client, prefix string) *RedisClaimStore {
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	store := storage.NewRedisMapStore(client, prefix+":"+redisClaimPrefix)
+	store.SetBase(ClaimableDevice{}, "")
+	return &RedisClaimStore{
+		store: store,
+	}
+}
+
+// RedisClaimStore stores ClaimableDevices in Redis, separate from the registered devices.
+// - ClaimableDevices are stored as a Hash, by DevEUI
+type RedisClaimStore struct {
+	store *storage.RedisMapStore
+}
+
+// Get the ClaimableDevice with the DevEUI
+func (s *RedisClaimStore) Get(devEUI types.DevEUI) (*ClaimableDevice, error) {
+	deviceI, err := s.store.Get(devEUI.String())
+	if err != nil {
+		return nil, err
+	}
+	if device, ok := deviceI.(ClaimableDevice); ok {
+		return &device, nil
+	}
+	return nil, errors.New("Database did not return a ClaimableDevice")
+}
+
+// Set a new ClaimableDevice or update an existing one
+func (s *RedisClaimStore) Set(new *ClaimableDevice) error {
+	if new.CreatedAt.IsZero() {
+		new.CreatedAt = time.Now()
+	}
+	return s.store.Set(new.DevEUI.String(), *new)
+}
+
+// Delete a ClaimableDevice
+func (s *RedisClaimStore) Delete(devEUI types.DevEUI) error {
+	return s.store.Delete(devEUI.String())
+}
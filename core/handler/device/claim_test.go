@@ -0,0 +1,58 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package device
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestClaimStore(t *testing.T) {
+	a := New(t)
+
+	s := NewRedisClaimStore(GetRedisClient(), "handler-test-claim-store")
+
+	devEUI := types.DevEUI([8]byte{0, 0, 0, 0, 0, 0, 0, 1})
+
+	// Get non-existing
+	dev, err := s.Get(devEUI)
+	a.So(err, ShouldNotBeNil)
+	a.So(dev, ShouldBeNil)
+
+	// Create
+	err = s.Set(&ClaimableDevice{
+		AppID:     "AppID-1",
+		DevEUI:    devEUI,
+		AppKey:    types.AppKey([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}),
+		ClaimCode: "C1A1M",
+	})
+	a.So(err, ShouldBeNil)
+
+	defer func() {
+		s.Delete(devEUI)
+	}()
+
+	// Get existing
+	dev, err = s.Get(devEUI)
+	a.So(err, ShouldBeNil)
+	a.So(dev, ShouldNotBeNil)
+	a.So(dev.AppID, ShouldEqual, "AppID-1")
+	a.So(dev.AppKey, ShouldEqual, types.AppKey([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}))
+	a.So(dev.CreatedAt.IsZero(), ShouldBeFalse)
+	a.So(dev.ValidClaimCode("C1A1M"), ShouldBeTrue)
+	a.So(dev.ValidClaimCode("c1a1m"), ShouldBeFalse)
+	a.So(dev.ValidClaimCode(""), ShouldBeFalse)
+
+	// Delete
+	err = s.Delete(devEUI)
+	a.So(err, ShouldBeNil)
+
+	// Get deleted
+	dev, err = s.Get(devEUI)
+	a.So(err, ShouldNotBeNil)
+	a.So(dev, ShouldBeNil)
+}
@@ -49,12 +49,19 @@ type Device struct {
 	AppSKey types.AppSKey `redis:"app_s_key"`
 	FCntUp  uint32        `redis:"f_cnt_up"` // Only used to detect retries
 
-	CurrentDownlink *types.DownlinkMessage `redis:"current_downlink"`
+	CurrentDownlink     *types.DownlinkMessage `redis:"current_downlink"`
+	CurrentDownlinkFCnt uint32                 `redis:"current_downlink_f_cnt"` // Only set for confirmed downlinks that were sent
 
 	CreatedAt time.Time `redis:"created_at"`
 	UpdatedAt time.Time `redis:"updated_at"`
 
 	Attributes map[string]string `redis:"attributes"`
+
+	State map[string]interface{} `redis:"state"` // Only changed by the Decoder payload function
+
+	Geofences []string `redis:"geofences"` // Names of the geofences that the device is in
+
+	Suspension types.Suspension `redis:"suspension"`
 }
 
 // StartUpdate stores the state of the device
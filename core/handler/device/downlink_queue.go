@@ -16,6 +16,7 @@ type DownlinkQueue interface {
 	Length() (int, error)
 	Next() (*types.DownlinkMessage, error)
 	Replace(msg *types.DownlinkMessage) error
+	Clear() error
 	PushFirst(msg *types.DownlinkMessage) error
 	PushLast(msg *types.DownlinkMessage) error
 }
@@ -54,12 +55,17 @@ func (s *RedisDownlinkQueue) Next() (*types.DownlinkMessage, error) {
 
 // Replace the downlink queue with msg
 func (s *RedisDownlinkQueue) Replace(msg *types.DownlinkMessage) error {
-	if err := s.queues.Delete(s.key()); err != nil {
+	if err := s.Clear(); err != nil {
 		return err
 	}
 	return s.PushFirst(msg)
 }
 
+// Clear the downlink queue
+func (s *RedisDownlinkQueue) Clear() error {
+	return s.queues.Delete(s.key())
+}
+
 // PushFirst message to the downlink queue
 func (s *RedisDownlinkQueue) PushFirst(msg *types.DownlinkMessage) error {
 	qd, err := json.Marshal(msg)
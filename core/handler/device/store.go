@@ -4,6 +4,7 @@
 package device
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"time"
@@ -45,6 +46,9 @@ const (
 	maxDeviceAttributes           = 5
 )
 
+// MaxStateSize is the maximum size of the JSON encoded State of a device
+const MaxStateSize = 1024
+
 // NewRedisDeviceStore creates a new Redis-based Device store
 func NewRedisDeviceStore(client *redis.Client, prefix string) *RedisDeviceStore {
 	if prefix == "" {
@@ -180,6 +184,15 @@ func (s *RedisDeviceStore) Set(new *Device, properties ...string) (err error) {
 		}
 		customAttributeSlots--
 	}
+	if len(new.State) > 0 {
+		state, err := json.Marshal(new.State)
+		if err != nil {
+			return err
+		}
+		if len(state) > MaxStateSize {
+			return fmt.Errorf(`State exceeds maximum size (%d)`, MaxStateSize)
+		}
+	}
 	err = s.store.Set(key, *new, properties...)
 	if err != nil {
 		return
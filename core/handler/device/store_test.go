@@ -187,3 +187,33 @@ func TestRedisDeviceStoreAttributes(t *testing.T) {
 	err = store.Set(dev)
 	a.So(err, ShouldNotBeNil)
 }
+
+func TestRedisDeviceStoreState(t *testing.T) {
+	a := New(t)
+
+	store := NewRedisDeviceStore(GetRedisClient(), "handler-test-state")
+
+	state := map[string]interface{}{
+		"last": 12.5,
+		"fragments": []interface{}{
+			"AQI=",
+		},
+	}
+
+	err := store.Set(&Device{
+		AppID: "appID",
+		DevID: "devID",
+		State: state,
+	})
+	a.So(err, ShouldBeNil)
+	defer store.Delete("appID", "devID")
+
+	dev, err := store.Get("appID", "devID")
+	a.So(err, ShouldBeNil)
+	a.So(dev.State, ShouldResemble, state)
+
+	dev.StartUpdate()
+	dev.State = map[string]interface{}{"invalid": strings.Repeat("foo", MaxStateSize)}
+	err = store.Set(dev)
+	a.So(err, ShouldNotBeNil)
+}
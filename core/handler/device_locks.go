@@ -0,0 +1,65 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// DeviceLockStripes is the number of locks that serialize changes to devices. Devices that hash to the same stripe
+// can not be changed at the same time, so more stripes allow more devices to be processed concurrently.
+var DeviceLockStripes = 256
+
+// deviceLocks serializes the read-modify-write of devices in the device store, so that uplinks, downlinks, activations
+// and updates of the same device do not overwrite each other's changes. The zero value is ready to use.
+type deviceLocks struct {
+	init    sync.Once
+	stripes []sync.Mutex
+}
+
+func (l *deviceLocks) start() {
+	l.stripes = make([]sync.Mutex, DeviceLockStripes)
+}
+
+func (l *deviceLocks) stripe(appID, devID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(appID))
+	h.Write([]byte{0})
+	h.Write([]byte(devID))
+	return int(h.Sum32() % uint32(len(l.stripes)))
+}
+
+// Lock blocks until no other goroutine holds the lock of the device and then locks it. The returned func unlocks the
+// device; calling it more than once has no effect. A goroutine must not lock a device while holding the lock of
+// another device, as the devices may share a stripe; use LockPair to lock two devices.
+func (l *deviceLocks) Lock(appID, devID string) (unlock func()) {
+	l.init.Do(l.start)
+	mu := &l.stripes[l.stripe(appID, devID)]
+	mu.Lock()
+	var once sync.Once
+	return func() { once.Do(mu.Unlock) }
+}
+
+// LockPair locks two devices, in the order of their stripes so that it can not deadlock with other pairs. The
+// returned func unlocks both devices; calling it more than once has no effect.
+func (l *deviceLocks) LockPair(appID1, devID1, appID2, devID2 string) (unlock func()) {
+	l.init.Do(l.start)
+	first, second := l.stripe(appID1, devID1), l.stripe(appID2, devID2)
+	if first == second {
+		return l.Lock(appID1, devID1)
+	}
+	if second < first {
+		first, second = second, first
+	}
+	l.stripes[first].Lock()
+	l.stripes[second].Lock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.stripes[second].Unlock()
+			l.stripes[first].Unlock()
+		})
+	}
+}
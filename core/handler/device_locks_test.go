@@ -0,0 +1,100 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestDeviceLocks(t *testing.T) {
+	a := New(t)
+
+	var locks deviceLocks
+
+	// Changes to the same device are serialized
+	var wg sync.WaitGroup
+	var count int
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.Lock("app", "dev")
+			defer unlock()
+			count++
+		}()
+	}
+	wg.Wait()
+	a.So(count, ShouldEqual, 100)
+
+	// Unlocking more than once has no effect
+	unlock := locks.Lock("app", "dev")
+	unlock()
+	unlock()
+
+	// Other devices are not blocked by a locked device
+	unlock = locks.Lock("app", "dev")
+	defer unlock()
+	var other string
+	for i := 0; other == ""; i++ {
+		if devID := fmt.Sprintf("dev-%d", i); locks.stripe("app", devID) != locks.stripe("app", "dev") {
+			other = devID
+		}
+	}
+	locked := make(chan struct{})
+	go func() {
+		unlock := locks.Lock("app", other)
+		defer unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Other device is blocked by locked device")
+	}
+}
+
+func TestDeviceLocksPair(t *testing.T) {
+	a := New(t)
+
+	var locks deviceLocks
+
+	// Pairs that are locked in opposite order do not deadlock
+	var wg sync.WaitGroup
+	var count int
+	for i := 0; i < 100; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			unlock := locks.LockPair("app", "dev-1", "app", "dev-2")
+			defer unlock()
+			count++
+		}()
+		go func() {
+			defer wg.Done()
+			unlock := locks.LockPair("app", "dev-2", "app", "dev-1")
+			defer unlock()
+			count++
+		}()
+		go func() {
+			defer wg.Done()
+			unlock := locks.Lock("app", "dev-1")
+			defer unlock()
+			count++
+		}()
+	}
+	wg.Wait()
+	a.So(count, ShouldEqual, 300)
+
+	// The same device can be locked as pair
+	unlock := locks.LockPair("app", "dev", "app", "dev")
+	unlock()
+	unlock()
+	unlock = locks.Lock("app", "dev")
+	unlock()
+}
@@ -4,15 +4,18 @@
 package handler
 
 import (
+	"strconv"
 	"time"
 
 	pb_broker "github.com/TheThingsNetwork/api/broker"
 	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
 	"github.com/TheThingsNetwork/api/trace"
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
 	"github.com/TheThingsNetwork/ttn/utils/toa"
+	"google.golang.org/grpc/metadata"
 )
 
 func (h *handler) EnqueueDownlink(appDownlink *types.DownlinkMessage) (err error) {
@@ -31,6 +34,9 @@ func (h *handler) EnqueueDownlink(appDownlink *types.DownlinkMessage) (err error
 		}
 	}()
 
+	unlock := h.deviceLocks.Lock(appID, devID)
+	defer unlock()
+
 	// Check if device exists
 	dev, err := h.devices.Get(appID, devID)
 	if err != nil {
@@ -46,7 +52,7 @@ func (h *handler) EnqueueDownlink(appDownlink *types.DownlinkMessage) (err error
 				DevID: devID,
 				Event: types.DownlinkErrorEvent,
 				Data: types.DownlinkEventData{
-					ErrorEventData: types.ErrorEventData{Error: err.Error()},
+					ErrorEventData: errorEventData(err),
 					Message:        appDownlink,
 				},
 			}:
@@ -56,10 +62,20 @@ func (h *handler) EnqueueDownlink(appDownlink *types.DownlinkMessage) (err error
 		}
 	}()
 
+	if dev.Suspension.Suspended {
+		return types.ErrDeviceSuspended
+	}
+
 	if len(appDownlink.PayloadRaw) == 0 && len(appDownlink.PayloadFields) == 0 {
 		return errors.NewErrInvalidArgument("Downlink Payload", "empty")
 	}
 
+	if appDownlink.Hints != nil {
+		if err := appDownlink.Hints.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Clear redundant fields
 	appDownlink.AppID = ""
 	appDownlink.DevID = ""
@@ -75,6 +91,7 @@ func (h *handler) EnqueueDownlink(appDownlink *types.DownlinkMessage) (err error
 	switch schedule {
 	case types.ScheduleReplace, "": // Empty string for default
 		dev.CurrentDownlink = nil
+		dev.CurrentDownlinkFCnt = 0
 		err = queue.Replace(appDownlink)
 	case types.ScheduleFirst:
 		err = queue.PushFirst(appDownlink)
@@ -107,6 +124,59 @@ func (h *handler) EnqueueDownlink(appDownlink *types.DownlinkMessage) (err error
 	return nil
 }
 
+// clearDownlinkQueue removes all queued downlinks of the device, including the downlink for the next uplink
+func (h *handler) clearDownlinkQueue(appID, devID string) (err error) {
+	ctx := h.Ctx.WithFields(ttnlog.Fields{
+		"AppID": appID,
+		"DevID": devID,
+	})
+	defer func() {
+		if err != nil {
+			ctx.WithError(err).Warn("Could not clear downlink queue")
+		} else {
+			ctx.Debug("Cleared downlink queue")
+		}
+	}()
+
+	unlock := h.deviceLocks.Lock(appID, devID)
+	defer unlock()
+
+	dev, err := h.devices.Get(appID, devID)
+	if err != nil {
+		return err
+	}
+	dev.StartUpdate()
+
+	queue, err := h.devices.DownlinkQueue(appID, devID)
+	if err != nil {
+		return err
+	}
+	if err = queue.Clear(); err != nil {
+		return err
+	}
+
+	dev.CurrentDownlink = nil
+	dev.CurrentDownlinkFCnt = 0
+	return h.devices.Set(dev)
+}
+
+// downlinkQueueMetadata returns the number of queued downlinks of the device as metadata, including the downlink that
+// is sent in response to the next uplink
+func (h *handler) downlinkQueueMetadata(dev *device.Device) metadata.MD {
+	queue, err := h.devices.DownlinkQueue(dev.AppID, dev.DevID)
+	if err != nil {
+		return nil
+	}
+	length, err := queue.Length()
+	if err != nil {
+		return nil
+	}
+	if dev.CurrentDownlink != nil {
+		length++
+	}
+	return metadata.Pairs(DownlinkQueueKey, strconv.Itoa(length))
+}
+
 func (h *handler) HandleDownlink(appDownlink *types.DownlinkMessage, downlink *pb_broker.DownlinkMessage) (err error) {
 	appID, devID := appDownlink.AppID, appDownlink.DevID
 
@@ -125,7 +195,7 @@ func (h *handler) HandleDownlink(appDownlink *types.DownlinkMessage, downlink *p
 				DevID: devID,
 				Event: types.DownlinkErrorEvent,
 				Data: types.DownlinkEventData{
-					ErrorEventData: types.ErrorEventData{Error: err.Error()},
+					ErrorEventData: errorEventData(err),
 					Message:        appDownlink,
 				},
 			}:
@@ -140,6 +210,9 @@ func (h *handler) HandleDownlink(appDownlink *types.DownlinkMessage, downlink *p
 		// }
 	}()
 
+	unlock := h.deviceLocks.Lock(appID, devID)
+	defer unlock()
+
 	dev, err := h.devices.Get(appID, devID)
 	if err != nil {
 		return err
@@ -191,6 +264,9 @@ func (h *handler) HandleDownlink(appDownlink *types.DownlinkMessage, downlink *p
 		downlinkConfig.DataRate = lorawan.DataRate
 		downlinkConfig.BitRate = uint(lorawan.BitRate)
 		downlinkConfig.FCnt = uint(lorawan.FCnt)
+		if appDownlink.Confirmed {
+			dev.CurrentDownlinkFCnt = lorawan.FCnt
+		}
 		switch lorawan.Modulation {
 		case pb_lorawan.Modulation_LORA:
 			downlinkConfig.Airtime, _ = toa.ComputeLoRa(uint(len(downlink.Payload)), lorawan.DataRate, lorawan.CodingRate)
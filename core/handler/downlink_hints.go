@@ -0,0 +1,167 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	pb_broker "github.com/TheThingsNetwork/api/broker"
+	pb_gateway "github.com/TheThingsNetwork/api/gateway"
+	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
+	"github.com/TheThingsNetwork/ttn/core/band"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	lora "github.com/brocaar/lorawan/band"
+)
+
+// downlinkWindow is a time and configuration at which a gateway can send a downlink in response to an uplink
+type downlinkWindow struct {
+	timestamp uint32
+	frequency uint64
+	dataRate  lora.DataRate
+}
+
+// applyDownlinkHints changes the downlink option of the response to the uplink, so that it satisfies the scheduling
+// hints of the downlink. It sets the gateway and the timestamp of the receive window (or of the requested time) in the
+// option. The broker then selects the option of the uplink on that gateway and at that timestamp, and the router
+// reserves the transmission slot at that timestamp. An error is returned if the hints can not be satisfied.
+func applyDownlinkHints(uplink *pb_broker.DeduplicatedUplinkMessage, hints *types.DownlinkHints) error {
+	if err := hints.Validate(); err != nil {
+		return err
+	}
+	option := uplink.ResponseTemplate.GetDownlinkOption()
+	if option == nil {
+		return errors.NewErrInvalidArgument("Downlink Hints", "no downlink option available")
+	}
+
+	if t := time.Time(hints.Time); !t.IsZero() && t.Before(time.Now()) {
+		return errors.NewErrInvalidArgument("Downlink Hints", fmt.Sprintf("time %s has passed", t.UTC().Format(time.RFC3339)))
+	}
+
+	// The gateway of the selected option is tried first
+	var gateways []*pb_gateway.RxMetadata
+	for _, gateway := range uplink.GatewayMetadata {
+		switch {
+		case hints.GatewayID != "" && gateway.GatewayID != hints.GatewayID:
+		case gateway.GatewayID == option.GatewayID:
+			gateways = append([]*pb_gateway.RxMetadata{gateway}, gateways...)
+		default:
+			gateways = append(gateways, gateway)
+		}
+	}
+	if len(gateways) == 0 {
+		return errors.NewErrInvalidArgument("Downlink Hints", fmt.Sprintf("gateway %s did not receive the uplink", hints.GatewayID))
+	}
+
+	var minDataRate *types.DataRate
+	if hints.MinDataRate != "" {
+		minDataRate, _ = types.ParseDataRate(hints.MinDataRate)
+	}
+
+	for _, gateway := range gateways {
+		windows, err := downlinkWindows(uplink, gateway, hints)
+		if err != nil {
+			return err
+		}
+		for _, window := range windows {
+			if minDataRate != nil && window.dataRate.Modulation == lora.LoRaModulation {
+				if dataRate, _ := types.ConvertDataRate(window.dataRate); dataRate.Slower(*minDataRate) {
+					continue
+				}
+			}
+			option.GatewayID = gateway.GatewayID
+			option.GatewayConfiguration.Timestamp = window.timestamp
+			option.GatewayConfiguration.Frequency = window.frequency
+			if lorawan := option.ProtocolConfiguration.GetLoRaWAN(); lorawan != nil && window.dataRate.Modulation == lora.LoRaModulation {
+				dataRate, _ := types.ConvertDataRate(window.dataRate)
+				lorawan.DataRate = dataRate.String()
+			}
+			return nil
+		}
+	}
+
+	return errors.NewErrInvalidArgument("Downlink Hints", fmt.Sprintf("data rate %s or faster is not available", hints.MinDataRate))
+}
+
+// downlinkWindows returns the windows in which the gateway can send a downlink that satisfies the RX2 and time hints,
+// starting with the window of the selected downlink option. A requested time uses the configuration of RX2.
+func downlinkWindows(uplink *pb_broker.DeduplicatedUplinkMessage, gateway *pb_gateway.RxMetadata, hints *types.DownlinkHints) ([]downlinkWindow, error) {
+	lorawan := uplink.ProtocolMetadata.GetLoRaWAN()
+	if lorawan == nil {
+		return nil, errors.NewErrInvalidArgument("Uplink", "does not contain LoRaWAN metadata")
+	}
+	frequencyPlan, err := band.Get(frequencyPlanName(lorawan, gateway))
+	if err != nil {
+		return nil, err
+	}
+
+	rx2 := downlinkWindow{
+		timestamp: gateway.Timestamp + uint32(frequencyPlan.ReceiveDelay2/time.Microsecond),
+		frequency: uint64(frequencyPlan.RX2Frequency),
+		dataRate:  frequencyPlan.DataRates[frequencyPlan.RX2DataRate],
+	}
+
+	if t := time.Time(hints.Time); !t.IsZero() {
+		received := time.Unix(0, gateway.Time)
+		if gateway.Time == 0 {
+			received = time.Unix(0, uplink.ServerTime)
+		}
+		delay := t.Sub(received)
+		if delay > math.MaxInt32*time.Microsecond {
+			return nil, errors.NewErrInvalidArgument("Downlink Hints", fmt.Sprintf("time %s is too far in the future for the gateway", t.UTC().Format(time.RFC3339)))
+		}
+		rx2.timestamp = gateway.Timestamp + uint32(delay/time.Microsecond)
+		return []downlinkWindow{rx2}, nil
+	}
+
+	if hints.RX2 {
+		return []downlinkWindow{rx2}, nil
+	}
+
+	windows := []downlinkWindow{rx2}
+	if rx1, err := rx1Window(frequencyPlan, lorawan, gateway); err == nil {
+		windows = []downlinkWindow{rx1, rx2}
+	}
+	if option := uplink.ResponseTemplate.DownlinkOption; option.GatewayID == gateway.GatewayID && option.GatewayConfiguration.Timestamp == rx2.timestamp {
+		windows[0], windows[len(windows)-1] = windows[len(windows)-1], windows[0]
+	}
+	return windows, nil
+}
+
+// frequencyPlanName returns the frequency plan of the gateway status, that the router sets in the uplink metadata. The
+// frequency plan is guessed from the frequency of the gateway if it is missing. As EU_863_870 is the zero value, it is
+// also guessed, which results in EU_863_870 for frequencies in that plan.
+func frequencyPlanName(lorawan *pb_lorawan.Metadata, gateway *pb_gateway.RxMetadata) string {
+	if lorawan.FrequencyPlan != pb_lorawan.FrequencyPlan_EU_863_870 {
+		return lorawan.FrequencyPlan.String()
+	}
+	return band.Guess(gateway.Frequency)
+}
+
+// rx1Window returns the RX1 window of the gateway for the uplink
+func rx1Window(frequencyPlan band.FrequencyPlan, lorawan *pb_lorawan.Metadata, gateway *pb_gateway.RxMetadata) (downlinkWindow, error) {
+	dataRate, err := lorawan.GetLoRaWANDataRate()
+	if err != nil {
+		return downlinkWindow{}, err
+	}
+	upDR, err := frequencyPlan.GetDataRate(dataRate)
+	if err != nil {
+		return downlinkWindow{}, err
+	}
+	downDR, err := frequencyPlan.GetRX1DataRate(upDR, 0)
+	if err != nil {
+		return downlinkWindow{}, err
+	}
+	frequency, err := frequencyPlan.GetRX1Frequency(int(gateway.Frequency))
+	if err != nil {
+		return downlinkWindow{}, err
+	}
+	return downlinkWindow{
+		timestamp: gateway.Timestamp + uint32(frequencyPlan.ReceiveDelay1/time.Microsecond),
+		frequency: uint64(frequency),
+		dataRate:  frequencyPlan.DataRates[downDR],
+	}, nil
+}
@@ -0,0 +1,98 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	pb_broker "github.com/TheThingsNetwork/api/broker"
+	pb_gateway "github.com/TheThingsNetwork/api/gateway"
+	pb_protocol "github.com/TheThingsNetwork/api/protocol"
+	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
+	"github.com/TheThingsNetwork/ttn/core/band"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestApplyDownlinkHints(t *testing.T) {
+	a := New(t)
+
+	band.InitializeTables()
+
+	now := time.Now()
+	buildUplink := func(dataRate string) *pb_broker.DeduplicatedUplinkMessage {
+		return &pb_broker.DeduplicatedUplinkMessage{
+			ProtocolMetadata: pb_protocol.RxMetadata{Protocol: &pb_protocol.RxMetadata_LoRaWAN{LoRaWAN: &pb_lorawan.Metadata{
+				Modulation: pb_lorawan.Modulation_LORA,
+				DataRate:   dataRate,
+			}}},
+			GatewayMetadata: []*pb_gateway.RxMetadata{
+				{GatewayID: "gtw", Frequency: 868100000, Timestamp: 1000, Time: now.UnixNano()},
+				{GatewayID: "other", Frequency: 868100000, Timestamp: 5000},
+			},
+			ServerTime: now.UnixNano(),
+			ResponseTemplate: &pb_broker.DownlinkMessage{
+				DownlinkOption: &pb_broker.DownlinkOption{
+					GatewayID: "gtw",
+					ProtocolConfiguration: pb_protocol.TxConfiguration{Protocol: &pb_protocol.TxConfiguration_LoRaWAN{LoRaWAN: &pb_lorawan.TxConfiguration{
+						Modulation: pb_lorawan.Modulation_LORA,
+						DataRate:   dataRate,
+					}}},
+					GatewayConfiguration: pb_gateway.TxConfiguration{Timestamp: 1001000},
+				},
+			},
+		}
+	}
+
+	uplink := buildUplink("SF9BW125")
+	option := uplink.ResponseTemplate.DownlinkOption
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{}), ShouldBeNil)
+	a.So(option.GatewayID, ShouldEqual, "gtw")
+	a.So(option.GatewayConfiguration.Timestamp, ShouldEqual, 1001000)
+
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{GatewayID: "other"}), ShouldBeNil)
+	a.So(option.GatewayID, ShouldEqual, "other")
+	a.So(option.GatewayConfiguration.Timestamp, ShouldEqual, 1005000)
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{GatewayID: "unknown"}), ShouldNotBeNil)
+
+	uplink = buildUplink("SF9BW125")
+	option = uplink.ResponseTemplate.DownlinkOption
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{RX2: true}), ShouldBeNil)
+	a.So(option.GatewayID, ShouldEqual, "gtw")
+	a.So(option.GatewayConfiguration.Timestamp, ShouldEqual, 2001000)
+
+	// The requested time is converted to the timestamp of the gateway
+	uplink = buildUplink("SF9BW125")
+	option = uplink.ResponseTemplate.DownlinkOption
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{Time: types.JSONTime(now.Add(time.Minute))}), ShouldBeNil)
+	a.So(option.GatewayConfiguration.Timestamp, ShouldEqual, 60001000)
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{Time: types.JSONTime(now.Add(-1 * time.Minute))}), ShouldNotBeNil)
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{Time: types.JSONTime(now.Add(time.Hour))}), ShouldNotBeNil)
+
+	// RX1 uses the uplink data rate, RX2 uses SF9BW125 in EU_863_870
+	uplink = buildUplink("SF9BW125")
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{MinDataRate: "SF9BW125"}), ShouldBeNil)
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{MinDataRate: "SF12BW125"}), ShouldBeNil)
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{MinDataRate: "SF7BW125"}), ShouldNotBeNil)
+
+	uplink = buildUplink("SF12BW125")
+	option = uplink.ResponseTemplate.DownlinkOption
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{MinDataRate: "SF10BW125"}), ShouldBeNil)
+	a.So(option.GatewayConfiguration.Timestamp, ShouldEqual, 2001000)
+	a.So(option.ProtocolConfiguration.GetLoRaWAN().DataRate, ShouldEqual, "SF9BW125")
+
+	uplink = buildUplink("SF7BW125")
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{MinDataRate: "SF7BW125"}), ShouldBeNil)
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{MinDataRate: "SF7BW125", RX2: true}), ShouldNotBeNil)
+
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{MinDataRate: "DR5"}), ShouldNotBeNil)
+
+	// The frequency plan of the gateway status is used instead of the guessed frequency plan
+	uplink = buildUplink("SF9BW125")
+	uplink.ProtocolMetadata.GetLoRaWAN().FrequencyPlan = pb_lorawan.FrequencyPlan_US_902_928
+	option = uplink.ResponseTemplate.DownlinkOption
+	a.So(applyDownlinkHints(uplink, &types.DownlinkHints{RX2: true}), ShouldBeNil)
+	a.So(option.GatewayConfiguration.Frequency, ShouldEqual, 923300000)
+}
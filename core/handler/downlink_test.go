@@ -4,6 +4,7 @@
 package handler
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -91,6 +92,70 @@ func TestEnqueueDownlink(t *testing.T) {
 	downlink, _ := queue.Next()
 	a.So(downlink, ShouldNotBeNil)
 	a.So(downlink.PayloadFields, ShouldHaveLength, 3)
+
+	// Downlinks of suspended devices are refused
+	dev.StartUpdate()
+	dev.Suspension = types.Suspension{Suspended: true}
+	h.devices.Set(dev)
+	err = h.EnqueueDownlink(&types.DownlinkMessage{
+		AppID:      appID,
+		DevID:      devID,
+		PayloadRaw: []byte{0x03},
+	})
+	a.So(err, ShouldEqual, types.ErrDeviceSuspended)
+	qLen, _ = queue.Length()
+	a.So(qLen, ShouldEqual, 0)
+}
+
+func TestClearDownlinkQueue(t *testing.T) {
+	a := New(t)
+	appID, devID := "appid", "devid-clear-queue"
+	h := &handler{
+		Component: &component.Component{Ctx: GetLogger(t, "TestClearDownlinkQueue")},
+		devices:   device.NewRedisDeviceStore(GetRedisClient(), "handler-test-clear-downlink-queue"),
+	}
+
+	err := h.clearDownlinkQueue(appID, devID)
+	a.So(err, ShouldNotBeNil)
+
+	h.devices.Set(&device.Device{
+		AppID:           appID,
+		DevID:           devID,
+		CurrentDownlink: &types.DownlinkMessage{PayloadRaw: []byte{0x01}},
+	})
+	defer func() {
+		h.devices.Delete(appID, devID)
+	}()
+	queue, _ := h.devices.DownlinkQueue(appID, devID)
+	queue.PushLast(&types.DownlinkMessage{PayloadRaw: []byte{0x02}})
+	queue.PushLast(&types.DownlinkMessage{PayloadRaw: []byte{0x03}})
+
+	err = h.clearDownlinkQueue(appID, devID)
+	a.So(err, ShouldBeNil)
+	qLen, _ := queue.Length()
+	a.So(qLen, ShouldEqual, 0)
+	dev, _ := h.devices.Get(appID, devID)
+	a.So(dev.CurrentDownlink, ShouldBeNil)
+}
+
+func TestDownlinkQueueMetadata(t *testing.T) {
+	a := New(t)
+	appID, devID := "appid", "devid-queue-metadata"
+	h := &handler{
+		devices: device.NewRedisDeviceStore(GetRedisClient(), "handler-test-downlink-queue-metadata"),
+	}
+	dev := &device.Device{AppID: appID, DevID: devID}
+	h.devices.Set(dev)
+	defer func() {
+		h.devices.Delete(appID, devID)
+	}()
+
+	a.So(h.downlinkQueueMetadata(dev).Get(DownlinkQueueKey), ShouldResemble, []string{"0"})
+
+	queue, _ := h.devices.DownlinkQueue(appID, devID)
+	queue.PushLast(&types.DownlinkMessage{PayloadRaw: []byte{0x01}})
+	dev.CurrentDownlink = &types.DownlinkMessage{PayloadRaw: []byte{0x02}}
+	a.So(h.downlinkQueueMetadata(dev).Get(DownlinkQueueKey), ShouldResemble, []string{"2"})
 }
 
 func TestHandleDownlink(t *testing.T) {
@@ -189,3 +254,57 @@ func TestHandleDownlink(t *testing.T) {
 	a.So(err, ShouldBeNil)
 	wg.WaitFor(100 * time.Millisecond)
 }
+
+func TestEnqueueDownlinkWithConcurrentUplink(t *testing.T) {
+	a := New(t)
+	appID, devID := "appid", "devid-enqueue-concurrent"
+	h := &handler{
+		Component:    &component.Component{Ctx: GetLogger(t, "TestEnqueueDownlinkWithConcurrentUplink")},
+		devices:      device.NewRedisDeviceStore(GetRedisClient(), "handler-test-enqueue-downlink-concurrent"),
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-enqueue-downlink-concurrent"),
+		qUp:          make(chan *types.UplinkMessage, 100),
+		qEvent:       make(chan *types.DeviceEvent, 100),
+	}
+	h.InitStatus()
+	h.applications.Set(&application.Application{AppID: appID})
+	defer func() {
+		h.applications.Delete(appID)
+	}()
+	defer func() {
+		h.devices.Delete(appID, devID)
+	}()
+
+	for i := 0; i < 20; i++ {
+		h.devices.Set(&device.Device{
+			AppID:           appID,
+			DevID:           devID,
+			AppEUI:          types.AppEUI([8]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+			DevEUI:          types.DevEUI([8]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+			CurrentDownlink: &types.DownlinkMessage{PayloadRaw: []byte{0xaa, 0xbc}},
+		})
+
+		uplink, _ := buildLoRaWANUplink([]byte{0x40, 0x04, 0x03, 0x02, 0x01, 0x00, 0x01, 0x00, 0x0A, 0x4D, 0xDA, 0x23, 0x99, 0x61, 0xD4})
+		uplink.AppID, uplink.DevID = appID, devID
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.So(h.EnqueueDownlink(&types.DownlinkMessage{AppID: appID, DevID: devID, PayloadRaw: []byte{0x12, 0x34}}), ShouldBeNil)
+		}()
+		go func() {
+			defer wg.Done()
+			a.So(h.HandleUplink(uplink), ShouldBeNil)
+		}()
+		wg.Wait()
+
+		// Neither of them may overwrite the changes of the other
+		dev, err := h.devices.Get(appID, devID)
+		a.So(err, ShouldBeNil)
+		a.So(dev.FCntUp, ShouldEqual, 1)
+		a.So(dev.CurrentDownlink, ShouldBeNil)
+		queue, _ := h.devices.DownlinkQueue(appID, devID)
+		qLen, _ := queue.Length()
+		a.So(qLen, ShouldEqual, 1)
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"encoding/json"
 
 	pb "github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
 	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/handler/cayennelpp"
 	"github.com/TheThingsNetwork/ttn/core/handler/functions"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // DryUplink converts the uplink message payload by running the payload
@@ -26,12 +29,23 @@ func (h *handlerManager) DryUplink(ctx context.Context, in *pb.DryUplinkMessage)
 	var decoder PayloadDecoder
 	switch application.PayloadFormat(app.PayloadFormat) {
 	case "", application.PayloadFormatCustom:
-		decoder = &CustomUplinkFunctions{
+		custom := &CustomUplinkFunctions{
 			Decoder:   app.Decoder,
 			Converter: app.Converter,
 			Validator: app.Validator,
 			Logger:    functions.NewEntryLogger(),
 		}
+		if state := ttnctx.MetadataFromIncomingContext(ctx).Get(DryUplinkStateKey); len(state) > 0 {
+			if err := json.Unmarshal([]byte(state[0]), &custom.State); err != nil {
+				return nil, errors.NewErrInvalidArgument("State", err.Error())
+			}
+			defer func() {
+				if state, err := json.Marshal(custom.State); err == nil {
+					grpc.SetHeader(ctx, metadata.Pairs(DryUplinkStateKey, string(state)))
+				}
+			}()
+		}
+		decoder = custom
 	case application.PayloadFormatCayenneLPP:
 		decoder = &cayennelpp.Decoder{}
 	default:
@@ -12,6 +12,7 @@ import (
 	. "github.com/TheThingsNetwork/ttn/utils/testing"
 	. "github.com/smartystreets/assertions"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
 )
 
 type countingStore struct {
@@ -114,6 +115,41 @@ func TestDryUplinkFieldsCustom(t *testing.T) {
 	a.So(store.count("delete"), ShouldEqual, 0)
 }
 
+func TestDryUplinkState(t *testing.T) {
+	a := New(t)
+
+	h := &handler{}
+	m := &handlerManager{handler: h}
+
+	dryUplinkMessage := &pb.DryUplinkMessage{
+		Payload: []byte{5},
+		App: pb.Application{
+			AppID:         "DryUplinkState",
+			PayloadFormat: "custom",
+			Decoder: `function Decoder (bytes, port, context, state) {
+				var total = (state.total || 0) + bytes[0];
+				state.total = total;
+				return { total: total }}`,
+		},
+	}
+
+	// Without state
+	res, err := m.DryUplink(context.TODO(), dryUplinkMessage)
+	a.So(err, ShouldBeNil)
+	a.So(res.Fields, ShouldEqual, `{"total":5}`)
+
+	// With state
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs(DryUplinkStateKey, `{"total":10}`))
+	res, err = m.DryUplink(ctx, dryUplinkMessage)
+	a.So(err, ShouldBeNil)
+	a.So(res.Fields, ShouldEqual, `{"total":15}`)
+
+	// Invalid state
+	ctx = metadata.NewIncomingContext(context.TODO(), metadata.Pairs(DryUplinkStateKey, `[1, 2]`))
+	_, err = m.DryUplink(ctx, dryUplinkMessage)
+	a.So(err, ShouldNotBeNil)
+}
+
 func TestDryUplinkFieldsCayenneLPP(t *testing.T) {
 	a := New(t)
 
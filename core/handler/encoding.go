@@ -0,0 +1,91 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"github.com/bluele/gcache"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// EncodingCacheSize is the number of applications of which the encoding is cached
+var EncodingCacheSize = 10000
+
+// EncodingCacheExpiration is the time after which the encoding of an application is read from the store again
+var EncodingCacheExpiration = 10 * time.Second
+
+// encodingCache keeps the encodings of applications, so that the application is not read from the store for every
+// message that is published. The zero value is ready to use.
+type encodingCache struct {
+	once  sync.Once
+	cache gcache.Cache
+}
+
+func (c *encodingCache) init() {
+	c.once.Do(func() {
+		c.cache = gcache.New(EncodingCacheSize).Expiration(EncodingCacheExpiration).LRU().Build()
+	})
+}
+
+// Get returns the cached encoding of the application, or loads it if it is not cached
+func (c *encodingCache) Get(appID string, load func(appID string) types.Encoding) types.Encoding {
+	c.init()
+	if cached, err := c.cache.Get(appID); err == nil {
+		return cached.(types.Encoding)
+	}
+	encoding := load(appID)
+	c.cache.Set(appID, encoding)
+	return encoding
+}
+
+// Remove removes the encoding of the application from the cache, so that it is loaded again
+func (c *encodingCache) Remove(appID string) {
+	c.init()
+	c.cache.Remove(appID)
+}
+
+// encoding returns the encoding of MQTT and AMQP messages of the application
+func (h *handler) encoding(appID string) types.Encoding {
+	return h.encodings.Get(appID, h.loadEncoding)
+}
+
+// loadEncoding reads the encoding of the application from the store
+func (h *handler) loadEncoding(appID string) types.Encoding {
+	app, err := h.applications.Get(appID)
+	if err != nil || app.Encoding == "" {
+		return types.JSONEncoding
+	}
+	return app.Encoding
+}
+
+// encodingFromIncomingContext sets the encoding that is in the request metadata on the application. The encoding is
+// left unchanged if it is not in the metadata, an empty value resets it to JSON.
+func encodingFromIncomingContext(ctx context.Context, app *application.Application) error {
+	values := ttnctx.MetadataFromIncomingContext(ctx).Get(EncodingKey)
+	if len(values) == 0 {
+		return nil
+	}
+	encoding, err := types.ParseEncoding(values[0])
+	if err != nil {
+		return errors.NewErrInvalidArgument("Encoding", err.Error())
+	}
+	app.Encoding = encoding
+	return nil
+}
+
+// encodingMetadata returns the encoding of the application as metadata
+func encodingMetadata(app *application.Application) metadata.MD {
+	encoding := app.Encoding
+	if encoding == "" {
+		encoding = types.JSONEncoding
+	}
+	return metadata.Pairs(EncodingKey, string(encoding))
+}
@@ -0,0 +1,55 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestEncoding(t *testing.T) {
+	a := New(t)
+
+	h := &handler{
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-encoding"),
+	}
+	defer h.applications.Delete("app")
+
+	a.So(h.encoding("app"), ShouldEqual, types.JSONEncoding)
+
+	// The encoding is cached until it is removed from the cache or expires
+	h.applications.Set(&application.Application{AppID: "app", Encoding: types.CBOREncoding})
+	a.So(h.encoding("app"), ShouldEqual, types.JSONEncoding)
+	h.encodings.Remove("app")
+	a.So(h.encoding("app"), ShouldEqual, types.CBOREncoding)
+}
+
+func TestEncodingMetadata(t *testing.T) {
+	a := New(t)
+
+	app := &application.Application{AppID: "app"}
+
+	a.So(encodingFromIncomingContext(context.Background(), app), ShouldBeNil)
+	a.So(app.Encoding, ShouldBeEmpty)
+	a.So(encodingMetadata(app).Get(EncodingKey), ShouldResemble, []string{"json"})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(EncodingKey, "protobuf"))
+	a.So(encodingFromIncomingContext(ctx, app), ShouldBeNil)
+	a.So(app.Encoding, ShouldEqual, types.ProtobufEncoding)
+	a.So(encodingMetadata(app).Get(EncodingKey), ShouldResemble, []string{"protobuf"})
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(EncodingKey, "xml"))
+	a.So(encodingFromIncomingContext(ctx, app), ShouldNotBeNil)
+	a.So(app.Encoding, ShouldEqual, types.ProtobufEncoding)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(EncodingKey, ""))
+	a.So(encodingFromIncomingContext(ctx, app), ShouldBeNil)
+	a.So(app.Encoding, ShouldEqual, types.JSONEncoding)
+}
@@ -0,0 +1,116 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/handler/schema"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"github.com/bluele/gcache"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// FieldsSchemaCacheSize is the number of compiled payload fields schemas that are cached
+var FieldsSchemaCacheSize = 10000
+
+// fieldsSchemaCache keeps the compiled payload fields schemas of applications, so that the schemas are not parsed for
+// every message. The zero value is ready to use.
+type fieldsSchemaCache struct {
+	once  sync.Once
+	cache gcache.Cache
+}
+
+type cachedFieldsSchema struct {
+	source string
+	schema *schema.Schema
+}
+
+func (c *fieldsSchemaCache) init() {
+	c.once.Do(func() {
+		c.cache = gcache.New(FieldsSchemaCacheSize).LRU().Build()
+	})
+}
+
+// Get returns the compiled schema under the key. The schema is compiled again if its source changed.
+func (c *fieldsSchemaCache) Get(key, source string) (*schema.Schema, error) {
+	c.init()
+	if cached, err := c.cache.Get(key); err == nil && cached.(*cachedFieldsSchema).source == source {
+		return cached.(*cachedFieldsSchema).schema, nil
+	}
+	s, err := schema.Parse([]byte(source))
+	if err != nil {
+		return nil, errors.NewErrInvalidArgument("Fields Schema", err.Error())
+	}
+	c.cache.Set(key, &cachedFieldsSchema{source: source, schema: s})
+	return s, nil
+}
+
+// validateFields validates the payload fields against the uplink or downlink (direction "up" or "down") JSON Schema of
+// the application. The error is of type schema.Errors if the fields do not match the schema.
+func (h *handler) validateFields(appID, direction, fieldsSchema string, fields map[string]interface{}) error {
+	s, err := h.fieldsSchemas.Get(appID+"/"+direction, fieldsSchema)
+	if err != nil {
+		return err
+	}
+	return s.Validate(fields)
+}
+
+// errorEventData returns the data of an error event, including the invalid fields if the payload fields do not match
+// the schema of the application
+func errorEventData(err error) types.ErrorEventData {
+	data := types.ErrorEventData{Error: err.Error()}
+	if errs, ok := err.(schema.Errors); ok {
+		for _, fieldErr := range errs {
+			data.Fields = append(data.Fields, types.FieldError{Field: fieldErr.Field, Error: fieldErr.Message})
+		}
+	}
+	return data
+}
+
+// fieldsSchemasFromIncomingContext sets the payload fields schemas that are in the request metadata on the application.
+// Schemas that are not in the metadata are left unchanged, an empty schema removes it.
+func fieldsSchemasFromIncomingContext(ctx context.Context, app *application.Application) error {
+	md := ttnctx.MetadataFromIncomingContext(ctx)
+	for key, fieldsSchema := range map[string]*string{
+		UplinkFieldsSchemaKey:   &app.UplinkFieldsSchema,
+		DownlinkFieldsSchemaKey: &app.DownlinkFieldsSchema,
+	} {
+		values := md.Get(key)
+		if len(values) == 0 {
+			continue
+		}
+		if values[0] != "" {
+			if _, err := schema.Parse([]byte(values[0])); err != nil {
+				return errors.NewErrInvalidArgument("Fields Schema", err.Error())
+			}
+		}
+		*fieldsSchema = values[0]
+	}
+	if values := md.Get(ForwardRawOnInvalidFieldsKey); len(values) > 0 {
+		forwardRaw, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return errors.NewErrInvalidArgument("Forward Raw On Invalid Fields", err.Error())
+		}
+		app.ForwardRawOnInvalidFields = forwardRaw
+	}
+	return nil
+}
+
+// fieldsSchemasMetadata returns the payload fields schemas of the application as metadata
+func fieldsSchemasMetadata(app *application.Application) metadata.MD {
+	md := metadata.Pairs(ForwardRawOnInvalidFieldsKey, strconv.FormatBool(app.ForwardRawOnInvalidFields))
+	if app.UplinkFieldsSchema != "" {
+		md.Set(UplinkFieldsSchemaKey, app.UplinkFieldsSchema)
+	}
+	if app.DownlinkFieldsSchema != "" {
+		md.Set(DownlinkFieldsSchemaKey, app.DownlinkFieldsSchema)
+	}
+	return md
+}
@@ -0,0 +1,96 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestFieldsSchemaErrorEventData(t *testing.T) {
+	a := New(t)
+
+	h := &handler{}
+
+	err := h.validateFields("app", "up", `{"properties": {"temperature": {"maximum": 85}}}`, map[string]interface{}{"temperature": 90})
+	a.So(err, ShouldNotBeNil)
+	a.So(errorEventData(err), ShouldResemble, types.ErrorEventData{
+		Error:  err.Error(),
+		Fields: []types.FieldError{{Field: "temperature", Error: "Must be less than or equal to 85"}},
+	})
+
+	err = h.validateFields("app", "up", `{"type": "float"}`, map[string]interface{}{"temperature": 90})
+	a.So(err, ShouldNotBeNil)
+	a.So(errorEventData(err).Fields, ShouldBeEmpty)
+}
+
+func TestFieldsSchemaCache(t *testing.T) {
+	a := New(t)
+
+	var c fieldsSchemaCache
+
+	s1, err := c.Get("app/up", `{"required": ["temperature"]}`)
+	a.So(err, ShouldBeNil)
+	s2, err := c.Get("app/up", `{"required": ["temperature"]}`)
+	a.So(err, ShouldBeNil)
+	a.So(s2, ShouldPointTo, s1)
+
+	// The schema is compiled again when it changes
+	s3, err := c.Get("app/up", `{"required": ["humidity"]}`)
+	a.So(err, ShouldBeNil)
+	a.So(s3, ShouldNotPointTo, s1)
+	a.So(s3.Validate(map[string]interface{}{"humidity": 50}), ShouldBeNil)
+
+	s4, err := c.Get("app/down", `{"required": ["temperature"]}`)
+	a.So(err, ShouldBeNil)
+	a.So(s4, ShouldNotPointTo, s1)
+
+	_, err = c.Get("app/up", `{"type": "float"}`)
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestFieldsSchemasMetadata(t *testing.T) {
+	a := New(t)
+
+	app := &application.Application{
+		AppID:              "app",
+		UplinkFieldsSchema: `{"type": "object"}`,
+	}
+
+	// Nothing in metadata
+	err := fieldsSchemasFromIncomingContext(context.Background(), app)
+	a.So(err, ShouldBeNil)
+	a.So(app.UplinkFieldsSchema, ShouldEqual, `{"type": "object"}`)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		UplinkFieldsSchemaKey, "",
+		DownlinkFieldsSchemaKey, `{"required": ["led"]}`,
+		ForwardRawOnInvalidFieldsKey, "true",
+	))
+	err = fieldsSchemasFromIncomingContext(ctx, app)
+	a.So(err, ShouldBeNil)
+	a.So(app.UplinkFieldsSchema, ShouldBeEmpty)
+	a.So(app.DownlinkFieldsSchema, ShouldEqual, `{"required": ["led"]}`)
+	a.So(app.ForwardRawOnInvalidFields, ShouldBeTrue)
+
+	md := fieldsSchemasMetadata(app)
+	a.So(md.Get(UplinkFieldsSchemaKey), ShouldBeEmpty)
+	a.So(md.Get(DownlinkFieldsSchemaKey), ShouldResemble, []string{`{"required": ["led"]}`})
+	a.So(md.Get(ForwardRawOnInvalidFieldsKey), ShouldResemble, []string{"true"})
+
+	// Invalid schema
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(UplinkFieldsSchemaKey, `{"$ref": "http://localhost/schema.json"}`))
+	err = fieldsSchemasFromIncomingContext(ctx, app)
+	a.So(err, ShouldNotBeNil)
+
+	// Invalid bool
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(ForwardRawOnInvalidFieldsKey, "maybe"))
+	err = fieldsSchemasFromIncomingContext(ctx, app)
+	a.So(err, ShouldNotBeNil)
+}
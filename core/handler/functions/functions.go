@@ -33,6 +33,10 @@ func RunCode(name, code string, env map[string]interface{}, timeout time.Duratio
 	})
 	vm.Run("console.log = __log")
 
+	if err := loadLibrary(vm, code); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
 
 	defer func() {
@@ -0,0 +1,217 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package functions
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"github.com/robertkrimen/otto"
+)
+
+// LibraryVersion is the latest version of the helper library that is available to payload functions in the global
+// ttn object. Payload functions use the latest version, unless they pin a version with a comment like:
+//
+//	// @ttn-library 1
+//
+// Released versions of the library are never changed, so that pinned payload functions keep working.
+const LibraryVersion = "1"
+
+var libraryVersionRegexp = regexp.MustCompile(`(?m)^\s*//\s*@ttn-library\s+(\S+)\s*$`)
+
+var libraries = map[string]string{
+	"1": libraryV1,
+}
+
+var libraryScripts = make(map[string]*otto.Script)
+
+func init() {
+	vm := otto.New()
+	for version, source := range libraries {
+		script, err := vm.Compile(fmt.Sprintf("ttn-library-%s.js", version), source)
+		if err != nil {
+			panic(fmt.Sprintf("functions: could not compile library version %s: %s", version, err))
+		}
+		libraryScripts[version] = script
+	}
+}
+
+// libraryVersion returns the version of the helper library that is pinned in the code, or the latest version
+func libraryVersion(code string) (string, error) {
+	matches := libraryVersionRegexp.FindAllStringSubmatch(code, -1)
+	if len(matches) == 0 {
+		return LibraryVersion, nil
+	}
+	version := matches[0][1]
+	for _, match := range matches[1:] {
+		if match[1] != version {
+			return "", errors.NewErrInvalidArgument("Library Version", "multiple versions pinned")
+		}
+	}
+	if _, ok := libraries[version]; !ok {
+		return "", errors.NewErrInvalidArgument("Library Version", fmt.Sprintf("version %s does not exist", version))
+	}
+	return version, nil
+}
+
+// loadLibrary loads the helper library that is pinned in the code into the VM
+func loadLibrary(vm *otto.Otto, code string) error {
+	version, err := libraryVersion(code)
+	if err != nil {
+		return err
+	}
+	_, err = vm.Run(libraryScripts[version])
+	return err
+}
+
+const libraryV1 = `
+var ttn = (function () {
+	var lib = { version: "1" };
+
+	function byteAt(bytes, index) {
+		var b = bytes[index];
+		if (typeof b !== "number" || b < 0 || b > 255) {
+			throw new RangeError("no byte at index " + index);
+		}
+		return b;
+	}
+
+	function readUint(bytes, offset, length, littleEndian) {
+		offset = offset || 0;
+		var value = 0;
+		for (var i = 0; i < length; i++) {
+			value = value * 256 + byteAt(bytes, offset + (littleEndian ? length - 1 - i : i));
+		}
+		return value;
+	}
+
+	function readInt(bytes, offset, length, littleEndian) {
+		var value = readUint(bytes, offset, length, littleEndian);
+		var max = Math.pow(2, 8 * length);
+		return value >= max / 2 ? value - max : value;
+	}
+
+	lib.uint8 = function (bytes, offset) { return readUint(bytes, offset, 1, false); };
+	lib.int8 = function (bytes, offset) { return readInt(bytes, offset, 1, false); };
+	lib.uint16LE = function (bytes, offset) { return readUint(bytes, offset, 2, true); };
+	lib.uint16BE = function (bytes, offset) { return readUint(bytes, offset, 2, false); };
+	lib.int16LE = function (bytes, offset) { return readInt(bytes, offset, 2, true); };
+	lib.int16BE = function (bytes, offset) { return readInt(bytes, offset, 2, false); };
+	lib.uint24LE = function (bytes, offset) { return readUint(bytes, offset, 3, true); };
+	lib.uint24BE = function (bytes, offset) { return readUint(bytes, offset, 3, false); };
+	lib.int24LE = function (bytes, offset) { return readInt(bytes, offset, 3, true); };
+	lib.int24BE = function (bytes, offset) { return readInt(bytes, offset, 3, false); };
+	lib.uint32LE = function (bytes, offset) { return readUint(bytes, offset, 4, true); };
+	lib.uint32BE = function (bytes, offset) { return readUint(bytes, offset, 4, false); };
+	lib.int32LE = function (bytes, offset) { return readInt(bytes, offset, 4, true); };
+	lib.int32BE = function (bytes, offset) { return readInt(bytes, offset, 4, false); };
+
+	// bits returns length (default 1) bits of value, starting at bit start (0 is the least significant bit)
+	lib.bits = function (value, start, length) {
+		if (length === undefined) {
+			length = 1;
+		}
+		return Math.floor(value / Math.pow(2, start)) % Math.pow(2, length);
+	};
+
+	// bcd decodes length (default 1) bytes of binary-coded decimal digits
+	lib.bcd = function (bytes, offset, length) {
+		offset = offset || 0;
+		if (length === undefined) {
+			length = 1;
+		}
+		var value = 0;
+		for (var i = 0; i < length; i++) {
+			var b = byteAt(bytes, offset + i);
+			var high = b >> 4, low = b & 0x0f;
+			if (high > 9 || low > 9) {
+				throw new RangeError("invalid BCD digit at index " + (offset + i));
+			}
+			value = value * 100 + high * 10 + low;
+		}
+		return value;
+	};
+
+	function readFloat(bits, exponentBits, fractionBits) {
+		var bias = Math.pow(2, exponentBits - 1) - 1;
+		var sign = Math.floor(bits / Math.pow(2, exponentBits + fractionBits)) ? -1 : 1;
+		var exponent = Math.floor(bits / Math.pow(2, fractionBits)) % Math.pow(2, exponentBits);
+		var fraction = bits % Math.pow(2, fractionBits) / Math.pow(2, fractionBits);
+		if (exponent === 0) {
+			return sign * Math.pow(2, 1 - bias) * fraction;
+		}
+		if (exponent === Math.pow(2, exponentBits) - 1) {
+			return fraction ? NaN : sign * Infinity;
+		}
+		return sign * Math.pow(2, exponent - bias) * (1 + fraction);
+	}
+
+	lib.float16LE = function (bytes, offset) { return readFloat(readUint(bytes, offset, 2, true), 5, 10); };
+	lib.float16BE = function (bytes, offset) { return readFloat(readUint(bytes, offset, 2, false), 5, 10); };
+	lib.float32LE = function (bytes, offset) { return readFloat(readUint(bytes, offset, 4, true), 8, 23); };
+	lib.float32BE = function (bytes, offset) { return readFloat(readUint(bytes, offset, 4, false), 8, 23); };
+
+	var hexDigits = "0123456789abcdef";
+
+	lib.hexEncode = function (bytes) {
+		var str = "";
+		for (var i = 0; i < bytes.length; i++) {
+			var b = byteAt(bytes, i);
+			str += hexDigits.charAt(b >> 4) + hexDigits.charAt(b & 0x0f);
+		}
+		return str;
+	};
+
+	lib.hexDecode = function (str) {
+		if (str.length % 2 !== 0 || /[^0-9a-fA-F]/.test(str)) {
+			throw new TypeError("invalid hex string");
+		}
+		var bytes = [];
+		for (var i = 0; i < str.length; i += 2) {
+			bytes.push(parseInt(str.substr(i, 2), 16));
+		}
+		return bytes;
+	};
+
+	var base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+
+	lib.base64Encode = function (bytes) {
+		var str = "";
+		for (var i = 0; i < bytes.length; i += 3) {
+			var n = byteAt(bytes, i) * 65536;
+			if (i + 1 < bytes.length) {
+				n += byteAt(bytes, i + 1) * 256;
+			}
+			if (i + 2 < bytes.length) {
+				n += byteAt(bytes, i + 2);
+			}
+			str += base64Digits.charAt(n >> 18 & 0x3f) + base64Digits.charAt(n >> 12 & 0x3f);
+			str += i + 1 < bytes.length ? base64Digits.charAt(n >> 6 & 0x3f) : "=";
+			str += i + 2 < bytes.length ? base64Digits.charAt(n & 0x3f) : "=";
+		}
+		return str;
+	};
+
+	lib.base64Decode = function (str) {
+		str = str.replace(/=+$/, "");
+		if (str.length % 4 === 1 || /[^A-Za-z0-9+\/]/.test(str)) {
+			throw new TypeError("invalid base64 string");
+		}
+		var bytes = [];
+		var n = 0, bits = 0;
+		for (var i = 0; i < str.length; i++) {
+			n = (n << 6 | base64Digits.indexOf(str.charAt(i))) & 0xffffff;
+			bits += 6;
+			if (bits >= 8) {
+				bits -= 8;
+				bytes.push(n >> bits & 0xff);
+			}
+		}
+		return bytes;
+	};
+
+	return lib;
+})();
+`
@@ -0,0 +1,97 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package functions
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestLibraryVersion(t *testing.T) {
+	a := New(t)
+
+	version, err := libraryVersion(`function Decoder(bytes) { return {}; }`)
+	a.So(err, ShouldBeNil)
+	a.So(version, ShouldEqual, LibraryVersion)
+
+	version, err = libraryVersion(`
+		// @ttn-library 1
+		function Decoder(bytes) { return {}; }
+	`)
+	a.So(err, ShouldBeNil)
+	a.So(version, ShouldEqual, "1")
+
+	_, err = libraryVersion(`
+		// @ttn-library 0
+		function Decoder(bytes) { return {}; }
+	`)
+	a.So(err, ShouldNotBeNil)
+
+	_, err = libraryVersion(`
+		// @ttn-library 1
+		// @ttn-library 2
+	`)
+	a.So(err, ShouldNotBeNil)
+
+	_, err = RunCode("test", "// @ttn-library 0\n1", nil, time.Second, nil)
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestLibrary(t *testing.T) {
+	a := New(t)
+
+	run := func(code string, env map[string]interface{}) interface{} {
+		val, err := RunCode("test", code, env, time.Second, nil)
+		a.So(err, ShouldBeNil)
+		return val
+	}
+
+	a.So(run(`ttn.version`, nil), ShouldEqual, LibraryVersion)
+
+	env := map[string]interface{}{
+		"payload": []byte{0x01, 0x80, 0xff, 0x7f},
+	}
+	a.So(run(`ttn.uint8(payload.slice(0), 1)`, env), ShouldEqual, 0x80)
+	a.So(run(`ttn.int8(payload.slice(0), 1)`, env), ShouldEqual, -128)
+	a.So(run(`ttn.uint16LE(payload.slice(0))`, env), ShouldEqual, 0x8001)
+	a.So(run(`ttn.int16LE(payload.slice(0))`, env), ShouldEqual, -32767)
+	a.So(run(`ttn.uint16BE(payload.slice(0), 2)`, env), ShouldEqual, 0xff7f)
+	a.So(run(`ttn.int16BE(payload.slice(0), 2)`, env), ShouldEqual, -129)
+	a.So(run(`ttn.int24BE([0xff, 0xff, 0xfe])`, nil), ShouldEqual, -2)
+	a.So(run(`ttn.uint32BE(payload.slice(0))`, env), ShouldEqual, 0x0180ff7f)
+	a.So(run(`ttn.int32LE(payload.slice(0))`, env), ShouldEqual, 0x7fff8001)
+	a.So(run(`ttn.int32LE([0xfe, 0xff, 0xff, 0xff])`, nil), ShouldEqual, -2)
+
+	a.So(run(`ttn.bits(0xb4, 2, 3)`, nil), ShouldEqual, 5)
+	a.So(run(`ttn.bits(0xb4, 7)`, nil), ShouldEqual, 1)
+
+	a.So(run(`ttn.bcd([0x12, 0x34], 0, 2)`, nil), ShouldEqual, 1234)
+	a.So(run(`ttn.bcd([0x12, 0x34], 1)`, nil), ShouldEqual, 34)
+
+	a.So(run(`ttn.float16BE([0x3c, 0x00])`, nil), ShouldEqual, 1)
+	a.So(run(`ttn.float16LE([0x00, 0xc0])`, nil), ShouldEqual, -2)
+	a.So(run(`ttn.float16BE([0x7b, 0xff])`, nil), ShouldEqual, 65504)
+	a.So(run(`ttn.float16BE([0x7c, 0x00]) === Infinity`, nil), ShouldBeTrue)
+	a.So(run(`ttn.float32BE([0x3f, 0xc0, 0x00, 0x00])`, nil), ShouldEqual, 1.5)
+	a.So(run(`ttn.float32LE([0x00, 0x00, 0x10, 0xc0])`, nil), ShouldEqual, -2.25)
+	a.So(run(`isNaN(ttn.float32BE([0x7f, 0xc0, 0x00, 0x00]))`, nil), ShouldBeTrue)
+
+	a.So(run(`ttn.hexEncode(payload.slice(0))`, env), ShouldEqual, "0180ff7f")
+	a.So(run(`ttn.hexEncode(ttn.hexDecode("0180FF7F"))`, nil), ShouldEqual, "0180ff7f")
+	a.So(run(`ttn.base64Encode(payload.slice(0))`, env), ShouldEqual, "AYD/fw==")
+	a.So(run(`ttn.base64Encode([1, 2, 3])`, nil), ShouldEqual, "AQID")
+	a.So(run(`ttn.hexEncode(ttn.base64Decode("AYD/fw=="))`, nil), ShouldEqual, "0180ff7f")
+
+	for _, code := range []string{
+		`ttn.uint16LE([1])`,
+		`ttn.bcd([0x1a])`,
+		`ttn.hexDecode("abc")`,
+		`ttn.base64Decode("a")`,
+	} {
+		_, err := RunCode("test", code, nil, time.Second, nil)
+		a.So(err, ShouldNotBeNil)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	pb_broker "github.com/TheThingsNetwork/api/broker"
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultGeofenceHysteresis is the distance in meters that devices have to leave a geofence before they exit it, if
+// the geofence does not set its own hysteresis. This prevents devices near the border from flapping between enter and
+// exit events because of inaccurate locations.
+var DefaultGeofenceHysteresis = 20.0
+
+// UpdateGeofences evaluates the geofences of the application against the location of the device and emits events for
+// the geofences that the device entered or exited
+func (h *handler) UpdateGeofences(ctx ttnlog.Interface, _ *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, dev *device.Device, app *application.Application) error {
+	location := appUp.Metadata.LocationMetadata
+	if location.Latitude == 0 && location.Longitude == 0 {
+		return nil
+	}
+
+	if app == nil || len(app.Geofences) == 0 && len(dev.Geofences) == 0 {
+		return nil
+	}
+
+	entered, exited, geofences := evaluateGeofences(app.Geofences, dev.Geofences, float64(location.Latitude), float64(location.Longitude))
+	dev.Geofences = geofences
+
+	for _, change := range []struct {
+		event     types.EventType
+		geofences []string
+	}{
+		{types.GeofenceExitEvent, exited},
+		{types.GeofenceEnterEvent, entered},
+	} {
+		for _, geofence := range change.geofences {
+			select {
+			case h.qEvent <- &types.DeviceEvent{
+				AppID: appUp.AppID,
+				DevID: appUp.DevID,
+				Event: change.event,
+				Data: types.GeofenceEventData{
+					Geofence:  geofence,
+					Latitude:  location.Latitude,
+					Longitude: location.Longitude,
+				},
+			}:
+			case <-time.After(eventPublishTimeout):
+				ctx.Warnf("Could not emit %q event", change.event)
+			}
+		}
+	}
+
+	return nil
+}
+
+// evaluateGeofences returns the names of the geofences that a device at the given coordinate entered and exited, and
+// the names of all geofences that it is in. Devices enter a geofence when they are inside it, and exit it when they
+// are further outside it than its hysteresis. Geofences that no longer exist are left without exit event.
+func evaluateGeofences(geofences []application.Geofence, current []string, lat, lng float64) (entered, exited, in []string) {
+	wasIn := make(map[string]bool, len(current))
+	for _, name := range current {
+		wasIn[name] = true
+	}
+	for _, geofence := range geofences {
+		d := geofenceDistance(geofence, lat, lng)
+		hysteresis := geofence.Hysteresis
+		if hysteresis == 0 {
+			hysteresis = DefaultGeofenceHysteresis
+		}
+		switch {
+		case wasIn[geofence.Name] && d > hysteresis:
+			exited = append(exited, geofence.Name)
+		case wasIn[geofence.Name]:
+			in = append(in, geofence.Name)
+		case d <= 0:
+			entered = append(entered, geofence.Name)
+			in = append(in, geofence.Name)
+		}
+	}
+	sort.Strings(entered)
+	sort.Strings(exited)
+	sort.Strings(in)
+	return
+}
+
+// geofenceDistance returns the distance in meters from the coordinate to the border of the geofence. The distance is
+// negative if the coordinate is inside the geofence.
+func geofenceDistance(geofence application.Geofence, lat, lng float64) float64 {
+	if geofence.Center != nil {
+		return distance(geofence.Center.Latitude, geofence.Center.Longitude, lat, lng) - geofence.Radius
+	}
+
+	// Project the vertices on a plane (in meters) around the coordinate, which is accurate enough for geofences that
+	// are not hundreds of kilometers large
+	scale := earthRadius * math.Pi / 180
+	project := func(c application.Coordinate) (x, y float64) {
+		return (c.Longitude - lng) * scale * math.Cos(lat*math.Pi/180), (c.Latitude - lat) * scale
+	}
+
+	inside := false
+	minDistance := math.Inf(1)
+	for i := range geofence.Polygon {
+		x1, y1 := project(geofence.Polygon[i])
+		x2, y2 := project(geofence.Polygon[(i+1)%len(geofence.Polygon)])
+		if (y1 > 0) != (y2 > 0) && 0 < x1+(0-y1)*(x2-x1)/(y2-y1) {
+			inside = !inside
+		}
+		minDistance = math.Min(minDistance, originToSegment(x1, y1, x2, y2))
+	}
+	if inside {
+		return -minDistance
+	}
+	return minDistance
+}
+
+// originToSegment returns the distance from the origin to the segment between (x1, y1) and (x2, y2)
+func originToSegment(x1, y1, x2, y2 float64) float64 {
+	dx, dy := x2-x1, y2-y1
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, -(x1*dx+y1*dy)/length))
+	}
+	return math.Hypot(x1+t*dx, y1+t*dy)
+}
+
+// validateGeofences validates the geofences of an application
+func validateGeofences(geofences []application.Geofence) error {
+	validCoordinate := func(c application.Coordinate) bool {
+		return c.Latitude >= -90 && c.Latitude <= 90 && c.Longitude >= -180 && c.Longitude <= 180
+	}
+	names := make(map[string]bool, len(geofences))
+	for _, geofence := range geofences {
+		if geofence.Name == "" {
+			return errors.NewErrInvalidArgument("Geofences", "name required")
+		}
+		if names[geofence.Name] {
+			return errors.NewErrInvalidArgument("Geofences", fmt.Sprintf("duplicate name %s", geofence.Name))
+		}
+		names[geofence.Name] = true
+		if geofence.Hysteresis < 0 {
+			return errors.NewErrInvalidArgument("Geofences", fmt.Sprintf("%s: hysteresis can not be negative", geofence.Name))
+		}
+		switch {
+		case geofence.Center != nil && len(geofence.Polygon) > 0:
+			return errors.NewErrInvalidArgument("Geofences", fmt.Sprintf("%s: either polygon or center and radius", geofence.Name))
+		case geofence.Center != nil:
+			if !validCoordinate(*geofence.Center) || geofence.Radius <= 0 {
+				return errors.NewErrInvalidArgument("Geofences", fmt.Sprintf("%s: invalid circle", geofence.Name))
+			}
+		default:
+			if len(geofence.Polygon) < 3 {
+				return errors.NewErrInvalidArgument("Geofences", fmt.Sprintf("%s: polygon needs at least 3 vertices", geofence.Name))
+			}
+			for _, vertex := range geofence.Polygon {
+				if !validCoordinate(vertex) {
+					return errors.NewErrInvalidArgument("Geofences", fmt.Sprintf("%s: invalid polygon", geofence.Name))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// geofencesFromIncomingContext sets the geofences that are in the request metadata on the application. The geofences
+// are left unchanged if they are not in the metadata, an empty value removes them.
+func geofencesFromIncomingContext(ctx context.Context, app *application.Application) error {
+	values := ttnctx.MetadataFromIncomingContext(ctx).Get(GeofencesKey)
+	if len(values) == 0 {
+		return nil
+	}
+	if values[0] == "" {
+		app.Geofences = nil
+		return nil
+	}
+	var geofences []application.Geofence
+	if err := json.Unmarshal([]byte(values[0]), &geofences); err != nil {
+		return errors.NewErrInvalidArgument("Geofences", err.Error())
+	}
+	if err := validateGeofences(geofences); err != nil {
+		return err
+	}
+	app.Geofences = geofences
+	return nil
+}
+
+// geofencesMetadata returns the geofences of the application as metadata
+func geofencesMetadata(app *application.Application) metadata.MD {
+	md := metadata.MD{}
+	if len(app.Geofences) > 0 {
+		if data, err := json.Marshal(app.Geofences); err == nil {
+			md.Set(GeofencesKey, string(data))
+		}
+	}
+	return md
+}
@@ -0,0 +1,170 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// square is a geofence of about 1.1 by 1.1 km
+var square = application.Geofence{
+	Name: "square",
+	Polygon: []application.Coordinate{
+		{Latitude: 52.37, Longitude: 4.88},
+		{Latitude: 52.38, Longitude: 4.88},
+		{Latitude: 52.38, Longitude: 4.8964},
+		{Latitude: 52.37, Longitude: 4.8964},
+	},
+}
+
+var circle = application.Geofence{
+	Name:   "circle",
+	Center: &application.Coordinate{Latitude: 52.37, Longitude: 4.88},
+	Radius: 500,
+}
+
+func TestGeofenceDistance(t *testing.T) {
+	a := New(t)
+
+	a.So(geofenceDistance(circle, 52.37, 4.88), ShouldAlmostEqual, -500, 0.1)
+	a.So(geofenceDistance(circle, 52.38, 4.88), ShouldAlmostEqual, 612, 1)
+
+	a.So(geofenceDistance(square, 52.375, 4.8882), ShouldAlmostEqual, -556, 1) // In the middle
+	a.So(geofenceDistance(square, 52.371, 4.8882), ShouldAlmostEqual, -111, 1) // Near the southern border
+	a.So(geofenceDistance(square, 52.369, 4.8882), ShouldAlmostEqual, 111, 1)  // South of the square
+	a.So(geofenceDistance(square, 52.369, 4.8964), ShouldAlmostEqual, 111, 1)  // South of the south-eastern corner
+	a.So(geofenceDistance(square, 52.375, 4.87), ShouldBeGreaterThan, 0)       // West of the square
+	a.So(geofenceDistance(square, 52.39, 4.87), ShouldBeGreaterThan, 0)        // North-west of the square
+}
+
+func TestEvaluateGeofences(t *testing.T) {
+	a := New(t)
+
+	geofences := []application.Geofence{square, circle}
+
+	// Outside both
+	entered, exited, in := evaluateGeofences(geofences, nil, 52.39, 4.88)
+	a.So(entered, ShouldBeEmpty)
+	a.So(exited, ShouldBeEmpty)
+	a.So(in, ShouldBeEmpty)
+
+	// Enter both
+	entered, exited, in = evaluateGeofences(geofences, in, 52.371, 4.884)
+	a.So(entered, ShouldResemble, []string{"circle", "square"})
+	a.So(exited, ShouldBeEmpty)
+	a.So(in, ShouldResemble, []string{"circle", "square"})
+
+	// Just outside the square, within the hysteresis
+	entered, exited, in = evaluateGeofences(geofences, in, 52.3699, 4.884)
+	a.So(entered, ShouldBeEmpty)
+	a.So(exited, ShouldBeEmpty)
+	a.So(in, ShouldResemble, []string{"circle", "square"})
+
+	// Outside the square, beyond the hysteresis
+	entered, exited, in = evaluateGeofences(geofences, in, 52.369, 4.884)
+	a.So(entered, ShouldBeEmpty)
+	a.So(exited, ShouldResemble, []string{"square"})
+	a.So(in, ShouldResemble, []string{"circle"})
+
+	// Back inside the hysteresis does not enter the square
+	entered, exited, in = evaluateGeofences(geofences, in, 52.3699, 4.884)
+	a.So(entered, ShouldBeEmpty)
+	a.So(exited, ShouldBeEmpty)
+	a.So(in, ShouldResemble, []string{"circle"})
+
+	// Removed geofences are dropped without exit
+	entered, exited, in = evaluateGeofences([]application.Geofence{square}, in, 52.3699, 4.884)
+	a.So(entered, ShouldBeEmpty)
+	a.So(exited, ShouldBeEmpty)
+	a.So(in, ShouldBeEmpty)
+}
+
+func TestUpdateGeofences(t *testing.T) {
+	a := New(t)
+	appID := "AppID-1"
+
+	h := &handler{
+		qEvent: make(chan *types.DeviceEvent, 10),
+	}
+
+	app := &application.Application{
+		AppID:     appID,
+		Geofences: []application.Geofence{circle},
+	}
+
+	dev := &device.Device{AppID: appID, DevID: "DevID-1"}
+	uplink := func(lat, lng float32) {
+		appUp := &types.UplinkMessage{AppID: appID, DevID: "DevID-1"}
+		appUp.Metadata.LocationMetadata = types.LocationMetadata{Latitude: lat, Longitude: lng}
+		err := h.UpdateGeofences(GetLogger(t, "TestUpdateGeofences"), nil, appUp, dev, app)
+		a.So(err, ShouldBeNil)
+	}
+
+	// No location
+	uplink(0, 0)
+	a.So(h.qEvent, ShouldBeEmpty)
+
+	uplink(52.37, 4.88)
+	a.So(dev.Geofences, ShouldResemble, []string{"circle"})
+	a.So(h.qEvent, ShouldHaveLength, 1)
+	event := <-h.qEvent
+	a.So(event.Event, ShouldEqual, types.GeofenceEnterEvent)
+	a.So(event.Data, ShouldResemble, types.GeofenceEventData{Geofence: "circle", Latitude: 52.37, Longitude: 4.88})
+
+	uplink(52.371, 4.88)
+	a.So(h.qEvent, ShouldBeEmpty)
+
+	uplink(52.38, 4.88)
+	a.So(dev.Geofences, ShouldBeEmpty)
+	a.So(h.qEvent, ShouldHaveLength, 1)
+	event = <-h.qEvent
+	a.So(event.Event, ShouldEqual, types.GeofenceExitEvent)
+}
+
+func TestValidateGeofences(t *testing.T) {
+	a := New(t)
+
+	a.So(validateGeofences([]application.Geofence{square, circle}), ShouldBeNil)
+
+	for _, invalid := range [][]application.Geofence{
+		{{Polygon: square.Polygon}},
+		{square, square},
+		{{Name: "line", Polygon: square.Polygon[:2]}},
+		{{Name: "circle", Center: circle.Center}},
+		{{Name: "circle", Center: &application.Coordinate{Latitude: 91}, Radius: 10}},
+		{{Name: "both", Polygon: square.Polygon, Center: circle.Center, Radius: 10}},
+		{{Name: "circle", Center: circle.Center, Radius: 10, Hysteresis: -1}},
+	} {
+		a.So(validateGeofences(invalid), ShouldNotBeNil)
+	}
+}
+
+func TestGeofencesMetadata(t *testing.T) {
+	a := New(t)
+
+	app := &application.Application{AppID: "app"}
+
+	a.So(geofencesFromIncomingContext(context.Background(), app), ShouldBeNil)
+	a.So(geofencesMetadata(app), ShouldBeEmpty)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(GeofencesKey, `[{"name":"circle","center":{"latitude":52.37,"longitude":4.88},"radius":500}]`))
+	a.So(geofencesFromIncomingContext(ctx, app), ShouldBeNil)
+	a.So(app.Geofences, ShouldResemble, []application.Geofence{circle})
+	a.So(geofencesMetadata(app).Get(GeofencesKey), ShouldResemble, []string{`[{"name":"circle","center":{"latitude":52.37,"longitude":4.88},"radius":500}]`})
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(GeofencesKey, `[{"name":"circle"}]`))
+	a.So(geofencesFromIncomingContext(ctx, app), ShouldNotBeNil)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(GeofencesKey, ""))
+	a.So(geofencesFromIncomingContext(ctx, app), ShouldBeNil)
+	a.So(app.Geofences, ShouldBeNil)
+}
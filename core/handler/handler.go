@@ -5,16 +5,21 @@ package handler
 
 import (
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	pb_broker "github.com/TheThingsNetwork/api/broker"
 	"github.com/TheThingsNetwork/api/broker/brokerclient"
 	pb "github.com/TheThingsNetwork/api/handler"
 	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
 	"github.com/TheThingsNetwork/ttn/amqp"
+	"github.com/TheThingsNetwork/ttn/core/activationlimit"
 	"github.com/TheThingsNetwork/ttn/core/component"
 	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/handler/mqttbroker"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/mqtt"
 	"google.golang.org/grpc"
@@ -28,13 +33,20 @@ type Handler interface {
 
 	WithMQTT(username, password string, brokers ...string) Handler
 	WithMQTTFields(enabled bool) Handler
+	WithMQTTTopics(v2, v3 bool) Handler
+	WithMQTTClientOptions(options mqtt.ClientOptions) Handler
+	WithMQTTBroker(address string) Handler
 	WithAMQP(username, password, host, exchange string) Handler
+	WithAMQPApplicationQueues(enabled bool) Handler
 	WithDeviceAttributes(attribute ...string) Handler
+	WithActivationRateLimits(devEUIRate, appEUIRate int, backoff time.Duration) Handler
 
 	HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) error
 	HandleActivationChallenge(challenge *pb_broker.ActivationChallengeRequest) (*pb_broker.ActivationChallengeResponse, error)
 	HandleActivation(activation *pb_broker.DeduplicatedDeviceActivationRequest) (*pb.DeviceActivationResponse, error)
 	EnqueueDownlink(appDownlink *types.DownlinkMessage) error
+
+	MQTTAuthHandler() http.Handler
 }
 
 // Timeout for publishing events to prevent blocking critical path.
@@ -44,6 +56,7 @@ var eventPublishTimeout = 10 * time.Millisecond
 func NewRedisHandler(client *redis.Client, ttnBrokerID string) Handler {
 	return &handler{
 		devices:      device.NewRedisDeviceStore(client, "handler"),
+		claims:       device.NewRedisClaimStore(client, "handler"),
 		applications: application.NewRedisApplicationStore(client, "handler"),
 		ttnBrokerID:  ttnBrokerID,
 		qUp:          make(chan *types.UplinkMessage),
@@ -55,6 +68,9 @@ type handler struct {
 	*component.Component
 
 	devices      device.Store
+	deviceLocks  deviceLocks
+	claims       device.ClaimStore
+	claimLock    sync.Mutex
 	applications application.Store
 
 	ttnBrokerID      string
@@ -71,9 +87,18 @@ type handler struct {
 	mqttBrokers       []string
 	mqttEnabled       bool
 	mqttFieldsEnabled bool
+	mqttV2Disabled    bool
+	mqttV3Enabled     bool
+	mqttClientOptions mqtt.ClientOptions
 	mqttUp            chan *types.UplinkMessage
 	mqttEvent         chan *types.DeviceEvent
 
+	mqttBroker        *mqttbroker.Broker
+	mqttBrokerAddress string
+	mqttBrokerEnabled bool
+	mqttBrokerUp      chan *types.UplinkMessage
+	mqttBrokerEvent   chan *types.DeviceEvent
+
 	amqpClient   amqp.Client
 	amqpUsername string
 	amqpPassword string
@@ -83,6 +108,14 @@ type handler struct {
 	amqpUp       chan *types.UplinkMessage
 	amqpEvent    chan *types.DeviceEvent
 
+	amqpAppQueuesEnabled bool
+	amqpSubscriber       amqp.Subscriber
+
+	activationLimits *activationlimit.Limits
+
+	fieldsSchemas fieldsSchemaCache
+	encodings     encodingCache
+
 	qUp    chan *types.UplinkMessage
 	qEvent chan *types.DeviceEvent
 
@@ -93,6 +126,8 @@ type handler struct {
 var (
 	// AMQPDownlinkQueue is the AMQP queue to use for downlink
 	AMQPDownlinkQueue = "ttn-handler-downlink"
+	// AMQPDeadLetterSuffix is appended to the AMQP exchange and downlink queue to get the dead-letter exchange and queue
+	AMQPDeadLetterSuffix = ".dead-letter"
 )
 
 func (h *handler) WithMQTT(username, password string, brokers ...string) Handler {
@@ -108,6 +143,23 @@ func (h *handler) WithMQTTFields(enabled bool) Handler {
 	return h
 }
 
+func (h *handler) WithMQTTTopics(v2, v3 bool) Handler {
+	h.mqttV2Disabled = !v2
+	h.mqttV3Enabled = v3
+	return h
+}
+
+func (h *handler) WithMQTTClientOptions(options mqtt.ClientOptions) Handler {
+	h.mqttClientOptions = options
+	return h
+}
+
+func (h *handler) WithMQTTBroker(address string) Handler {
+	h.mqttBrokerAddress = address
+	h.mqttBrokerEnabled = true
+	return h
+}
+
 func (h *handler) WithAMQP(username, password, host, exchange string) Handler {
 	h.amqpUsername = username
 	h.amqpPassword = password
@@ -117,11 +169,23 @@ func (h *handler) WithAMQP(username, password, host, exchange string) Handler {
 	return h
 }
 
+func (h *handler) WithAMQPApplicationQueues(enabled bool) Handler {
+	h.amqpAppQueuesEnabled = enabled
+	return h
+}
+
 func (h *handler) WithDeviceAttributes(a ...string) Handler {
 	h.devices.AddBuiltinAttribute(a...)
 	return h
 }
 
+// WithActivationRateLimits limits the number of activations per DevEUI and per AppEUI in the backoff time. No
+// join-accept is sent for activations beyond the limit. A rate of zero disables the limit.
+func (h *handler) WithActivationRateLimits(devEUIRate, appEUIRate int, backoff time.Duration) Handler {
+	h.activationLimits = activationlimit.NewLimits(devEUIRate, appEUIRate, backoff)
+	return h
+}
+
 func (h *handler) Init(c *component.Component) error {
 	h.Component = c
 	h.InitStatus()
@@ -146,6 +210,13 @@ func (h *handler) Init(c *component.Component) error {
 		}
 	}
 
+	if h.mqttBrokerEnabled {
+		err = h.HandleMQTTBroker(h.mqttBrokerAddress)
+		if err != nil {
+			return err
+		}
+	}
+
 	if h.amqpEnabled {
 		err = h.HandleAMQP(h.amqpUsername, h.amqpPassword, h.amqpHost, h.amqpExchange, AMQPDownlinkQueue)
 		if err != nil {
@@ -158,17 +229,23 @@ func (h *handler) Init(c *component.Component) error {
 			select {
 			case up := <-h.qUp:
 				if h.mqttEnabled {
-					h.mqttUp <- up
+					h.forwardUplink(h.mqttUp, up, "MQTT")
+				}
+				if h.mqttBrokerEnabled {
+					h.forwardUplink(h.mqttBrokerUp, up, "MQTT broker")
 				}
 				if h.amqpEnabled {
-					h.amqpUp <- up
+					h.forwardUplink(h.amqpUp, up, "AMQP")
 				}
 			case event := <-h.qEvent:
 				if h.mqttEnabled {
-					h.mqttEvent <- event
+					h.forwardEvent(h.mqttEvent, event, "MQTT")
+				}
+				if h.mqttBrokerEnabled {
+					h.forwardEvent(h.mqttBrokerEvent, event, "MQTT broker")
 				}
 				if h.amqpEnabled {
-					h.amqpEvent <- event
+					h.forwardEvent(h.amqpEvent, event, "AMQP")
 				}
 			}
 		}
@@ -187,10 +264,32 @@ func (h *handler) Init(c *component.Component) error {
 	return nil
 }
 
+// forwardUplink forwards the uplink to the buffer of a publisher. The uplink is dropped if the buffer stays full, so
+// that a slow publisher does not block the other publishers and the handling of uplinks.
+func (h *handler) forwardUplink(buffer chan<- *types.UplinkMessage, up *types.UplinkMessage, publisher string) {
+	select {
+	case buffer <- up:
+	case <-time.After(eventPublishTimeout):
+		h.Ctx.WithFields(ttnlog.Fields{"AppID": up.AppID, "DevID": up.DevID}).Warnf("Could not forward uplink to %s", publisher)
+	}
+}
+
+// forwardEvent forwards the event to the buffer of a publisher. The event is dropped if the buffer stays full.
+func (h *handler) forwardEvent(buffer chan<- *types.DeviceEvent, event *types.DeviceEvent, publisher string) {
+	select {
+	case buffer <- event:
+	case <-time.After(eventPublishTimeout):
+		h.Ctx.WithFields(ttnlog.Fields{"AppID": event.AppID, "DevID": event.DevID}).Warnf("Could not forward %q event to %s", event.Event, publisher)
+	}
+}
+
 func (h *handler) Shutdown() {
 	if h.mqttEnabled {
 		h.mqttClient.Disconnect()
 	}
+	if h.mqttBrokerEnabled {
+		h.mqttBroker.Close()
+	}
 	if h.amqpEnabled {
 		h.amqpClient.Disconnect()
 	}
@@ -0,0 +1,211 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	pb_broker "github.com/TheThingsNetwork/api/broker"
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// earthRadius is the mean radius of the earth in meters
+const earthRadius = 6371008.8
+
+// payloadLocation is a location in the payload fields
+type payloadLocation struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	Accuracy  float64 // 0 if unknown
+}
+
+// UpdateLocation updates the location of the device from the payload fields, using the location fields of the
+// application
+func (h *handler) UpdateLocation(ctx ttnlog.Interface, _ *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, dev *device.Device, app *application.Application) error {
+	if len(appUp.PayloadFields) == 0 {
+		return nil
+	}
+
+	if app == nil || app.LocationFields == nil {
+		return nil
+	}
+
+	location, ok := locationFromFields(app.LocationFields, appUp.PayloadFields)
+	if !ok {
+		return nil
+	}
+	if app.LocationFields.MaxAccuracy > 0 && location.Accuracy > app.LocationFields.MaxAccuracy {
+		ctx.WithField("Accuracy", location.Accuracy).Debug("Ignore inaccurate location in payload fields")
+		return nil
+	}
+
+	eventData := types.LocationEventData{
+		Latitude:  float32(location.Latitude),
+		Longitude: float32(location.Longitude),
+		Altitude:  int32(location.Altitude),
+		Accuracy:  int32(location.Accuracy),
+	}
+	if dev.Latitude != 0 || dev.Longitude != 0 {
+		eventData.Distance = distance(float64(dev.Latitude), float64(dev.Longitude), location.Latitude, location.Longitude)
+		if eventData.Distance < app.LocationFields.MinDistance {
+			return nil
+		}
+	}
+
+	dev.Latitude = eventData.Latitude
+	dev.Longitude = eventData.Longitude
+	dev.Altitude = eventData.Altitude
+
+	// Keep the metadata consistent with the updated location of the device
+	appUp.Metadata.LocationMetadata = types.LocationMetadata{
+		Latitude:  eventData.Latitude,
+		Longitude: eventData.Longitude,
+		Altitude:  eventData.Altitude,
+		Accuracy:  eventData.Accuracy,
+		Source:    "gps",
+	}
+
+	select {
+	case h.qEvent <- &types.DeviceEvent{
+		AppID: appUp.AppID,
+		DevID: appUp.DevID,
+		Event: types.LocationUpdatedEvent,
+		Data:  eventData,
+	}:
+	case <-time.After(eventPublishTimeout):
+		ctx.Warnf("Could not emit %q event", types.LocationUpdatedEvent)
+	}
+
+	return nil
+}
+
+// locationFromFields returns the location in the payload fields. It returns false if the fields do not contain a
+// valid location.
+func locationFromFields(locationFields *application.LocationFields, fields map[string]interface{}) (location payloadLocation, ok bool) {
+	latitudeField, longitudeField, altitudeField := locationFields.Latitude, locationFields.Longitude, locationFields.Altitude
+	if locationFields.GPS != "" {
+		latitudeField = locationFields.GPS + ".latitude"
+		longitudeField = locationFields.GPS + ".longitude"
+		altitudeField = locationFields.GPS + ".altitude"
+	}
+	if location.Latitude, ok = numberField(fields, latitudeField); !ok {
+		return location, false
+	}
+	if location.Longitude, ok = numberField(fields, longitudeField); !ok {
+		return location, false
+	}
+	if location.Latitude < -90 || location.Latitude > 90 || location.Longitude < -180 || location.Longitude > 180 {
+		return location, false
+	}
+	if location.Latitude == 0 && location.Longitude == 0 {
+		return location, false // Devices without a GPS fix often report 0,0
+	}
+	if altitudeField != "" {
+		location.Altitude, _ = numberField(fields, altitudeField)
+	}
+	if locationFields.Accuracy != "" {
+		location.Accuracy, _ = numberField(fields, locationFields.Accuracy)
+	}
+	return location, true
+}
+
+// numberField returns the number in the (nested) payload field with the given name
+func numberField(fields map[string]interface{}, name string) (float64, bool) {
+	if name == "" {
+		return 0, false
+	}
+	var value interface{} = fields
+	for _, key := range strings.Split(name, ".") {
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+			return 0, false
+		}
+		elem := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		if !elem.IsValid() {
+			return 0, false
+		}
+		value = elem.Interface()
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v.Float()) || math.IsInf(v.Float(), 0) {
+			return 0, false
+		}
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// distance returns the great-circle distance in meters between two coordinates
+func distance(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// validateLocationFields validates the location fields of an application
+func validateLocationFields(locationFields *application.LocationFields) error {
+	if locationFields.GPS == "" && (locationFields.Latitude == "" || locationFields.Longitude == "") {
+		return errors.NewErrInvalidArgument("Location Fields", "gps or latitude and longitude fields required")
+	}
+	if locationFields.MinDistance < 0 {
+		return errors.NewErrInvalidArgument("Location Fields", "min_distance can not be negative")
+	}
+	if locationFields.MaxAccuracy < 0 {
+		return errors.NewErrInvalidArgument("Location Fields", "max_accuracy can not be negative")
+	}
+	return nil
+}
+
+// locationFieldsFromIncomingContext sets the location fields that are in the request metadata on the application.
+// The location fields are left unchanged if they are not in the metadata, an empty value removes them.
+func locationFieldsFromIncomingContext(ctx context.Context, app *application.Application) error {
+	values := ttnctx.MetadataFromIncomingContext(ctx).Get(LocationFieldsKey)
+	if len(values) == 0 {
+		return nil
+	}
+	if values[0] == "" {
+		app.LocationFields = nil
+		return nil
+	}
+	locationFields := new(application.LocationFields)
+	if err := json.Unmarshal([]byte(values[0]), locationFields); err != nil {
+		return errors.NewErrInvalidArgument("Location Fields", err.Error())
+	}
+	if err := validateLocationFields(locationFields); err != nil {
+		return err
+	}
+	app.LocationFields = locationFields
+	return nil
+}
+
+// locationFieldsMetadata returns the location fields of the application as metadata
+func locationFieldsMetadata(app *application.Application) metadata.MD {
+	md := metadata.MD{}
+	if app.LocationFields != nil {
+		if data, err := json.Marshal(app.LocationFields); err == nil {
+			md.Set(LocationFieldsKey, string(data))
+		}
+	}
+	return md
+}
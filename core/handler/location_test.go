@@ -0,0 +1,158 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNumberField(t *testing.T) {
+	a := New(t)
+
+	fields := map[string]interface{}{
+		"lat":   52.3736,
+		"acc":   uint8(10),
+		"gps_1": map[string]float32{"latitude": 52.5, "longitude": 4.5},
+		"str":   "1",
+	}
+
+	value, ok := numberField(fields, "lat")
+	a.So(ok, ShouldBeTrue)
+	a.So(value, ShouldEqual, 52.3736)
+
+	value, ok = numberField(fields, "acc")
+	a.So(ok, ShouldBeTrue)
+	a.So(value, ShouldEqual, 10)
+
+	value, ok = numberField(fields, "gps_1.longitude")
+	a.So(ok, ShouldBeTrue)
+	a.So(value, ShouldEqual, 4.5)
+
+	for _, name := range []string{"", "lng", "str", "lat.value", "gps_1.altitude"} {
+		_, ok = numberField(fields, name)
+		a.So(ok, ShouldBeFalse)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	a := New(t)
+	a.So(distance(52.3736, 4.8865, 52.3736, 4.8865), ShouldEqual, 0)
+	a.So(distance(0, 0, 0, 1), ShouldAlmostEqual, 111195, 1)
+	a.So(distance(52.3731, 4.8922, 51.9244, 4.4777), ShouldAlmostEqual, 57400, 500) // Amsterdam - Rotterdam
+}
+
+func TestUpdateLocation(t *testing.T) {
+	a := New(t)
+	appID := "AppID-1"
+
+	h := &handler{
+		qEvent: make(chan *types.DeviceEvent, 10),
+	}
+
+	app := &application.Application{
+		AppID: appID,
+		LocationFields: &application.LocationFields{
+			Latitude:    "lat",
+			Longitude:   "lng",
+			Accuracy:    "acc",
+			MinDistance: 100,
+			MaxAccuracy: 50,
+		},
+	}
+	dev := &device.Device{AppID: appID, DevID: "DevID-1"}
+	uplink := func(fields map[string]interface{}) *types.UplinkMessage {
+		appUp := &types.UplinkMessage{AppID: appID, DevID: "DevID-1", PayloadFields: fields}
+		err := h.UpdateLocation(GetLogger(t, "TestUpdateLocation"), nil, appUp, dev, app)
+		a.So(err, ShouldBeNil)
+		return appUp
+	}
+
+	// No location in fields
+	appUp := uplink(map[string]interface{}{"temperature": 21.5})
+	a.So(dev.Latitude, ShouldEqual, 0)
+	a.So(appUp.Metadata.LocationMetadata, ShouldResemble, types.LocationMetadata{})
+	a.So(h.qEvent, ShouldBeEmpty)
+
+	// No GPS fix
+	uplink(map[string]interface{}{"lat": 0, "lng": 0})
+	a.So(h.qEvent, ShouldBeEmpty)
+
+	// Not accurate enough
+	uplink(map[string]interface{}{"lat": 52.3736, "lng": 4.8865, "acc": 100})
+	a.So(dev.Latitude, ShouldEqual, 0)
+	a.So(h.qEvent, ShouldBeEmpty)
+
+	// First location
+	appUp = uplink(map[string]interface{}{"lat": 52.3736, "lng": 4.8865, "acc": 10})
+	a.So(dev.Latitude, ShouldEqual, float32(52.3736))
+	a.So(dev.Longitude, ShouldEqual, float32(4.8865))
+	a.So(appUp.Metadata.LocationMetadata, ShouldResemble, types.LocationMetadata{
+		Latitude:  52.3736,
+		Longitude: 4.8865,
+		Accuracy:  10,
+		Source:    "gps",
+	})
+	a.So(h.qEvent, ShouldHaveLength, 1)
+	event := <-h.qEvent
+	a.So(event.Event, ShouldEqual, types.LocationUpdatedEvent)
+	a.So(event.Data, ShouldResemble, types.LocationEventData{Latitude: 52.3736, Longitude: 4.8865, Accuracy: 10})
+
+	// Moved less than the minimum distance
+	uplink(map[string]interface{}{"lat": 52.3737, "lng": 4.8866})
+	a.So(dev.Latitude, ShouldEqual, float32(52.3736))
+	a.So(h.qEvent, ShouldBeEmpty)
+
+	// Moved
+	uplink(map[string]interface{}{"lat": 52.3836, "lng": 4.8865})
+	a.So(dev.Latitude, ShouldEqual, float32(52.3836))
+	a.So(h.qEvent, ShouldHaveLength, 1)
+	event = <-h.qEvent
+	a.So(event.Data.(types.LocationEventData).Distance, ShouldAlmostEqual, 1112, 1)
+
+	// CayenneLPP GPS channel
+	app.LocationFields = &application.LocationFields{GPS: "gps_1"}
+	appUp = uplink(map[string]interface{}{"gps_1": map[string]float32{"latitude": 52.5, "longitude": 4.5, "altitude": 12}})
+	a.So(dev.Latitude, ShouldEqual, 52.5)
+	a.So(dev.Longitude, ShouldEqual, 4.5)
+	a.So(dev.Altitude, ShouldEqual, 12)
+	a.So(appUp.Metadata.LocationMetadata.Source, ShouldEqual, "gps")
+	a.So(h.qEvent, ShouldHaveLength, 1)
+}
+
+func TestLocationFieldsMetadata(t *testing.T) {
+	a := New(t)
+
+	app := &application.Application{AppID: "app"}
+
+	// Nothing in metadata
+	a.So(locationFieldsFromIncomingContext(context.Background(), app), ShouldBeNil)
+	a.So(app.LocationFields, ShouldBeNil)
+	a.So(locationFieldsMetadata(app), ShouldBeEmpty)
+
+	// Set
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(LocationFieldsKey, `{"gps":"gps_1","min_distance":10}`))
+	a.So(locationFieldsFromIncomingContext(ctx, app), ShouldBeNil)
+	a.So(app.LocationFields, ShouldResemble, &application.LocationFields{GPS: "gps_1", MinDistance: 10})
+	a.So(locationFieldsMetadata(app).Get(LocationFieldsKey), ShouldResemble, []string{`{"gps":"gps_1","min_distance":10}`})
+
+	// Invalid
+	for _, invalid := range []string{`{`, `{"latitude":"lat"}`, `{"gps":"gps_1","max_accuracy":-1}`} {
+		ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(LocationFieldsKey, invalid))
+		a.So(locationFieldsFromIncomingContext(ctx, app), ShouldNotBeNil)
+	}
+	a.So(app.LocationFields, ShouldNotBeNil)
+
+	// Remove
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(LocationFieldsKey, ""))
+	a.So(locationFieldsFromIncomingContext(ctx, app), ShouldBeNil)
+	a.So(app.LocationFields, ShouldBeNil)
+}
@@ -0,0 +1,34 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"strconv"
+
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// lorawanMetadataFromIncomingContext sets the LoRaWAN metadata option that is in the request metadata on the
+// application. The option is left unchanged if it is not in the metadata.
+func lorawanMetadataFromIncomingContext(ctx context.Context, app *application.Application) error {
+	values := ttnctx.MetadataFromIncomingContext(ctx).Get(LoRaWANMetadataKey)
+	if len(values) == 0 {
+		return nil
+	}
+	lorawanMetadata, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return errors.NewErrInvalidArgument("LoRaWAN Metadata", err.Error())
+	}
+	app.LoRaWANMetadata = lorawanMetadata
+	return nil
+}
+
+// lorawanMetadataMetadata returns the LoRaWAN metadata option of the application as metadata
+func lorawanMetadataMetadata(app *application.Application) metadata.MD {
+	return metadata.Pairs(LoRaWANMetadataKey, strconv.FormatBool(app.LoRaWANMetadata))
+}
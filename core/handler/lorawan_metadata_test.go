@@ -0,0 +1,32 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLoRaWANMetadataMetadata(t *testing.T) {
+	a := New(t)
+
+	app := &application.Application{AppID: "app"}
+
+	a.So(lorawanMetadataFromIncomingContext(context.Background(), app), ShouldBeNil)
+	a.So(app.LoRaWANMetadata, ShouldBeFalse)
+	a.So(lorawanMetadataMetadata(app).Get(LoRaWANMetadataKey), ShouldResemble, []string{"false"})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(LoRaWANMetadataKey, "true"))
+	a.So(lorawanMetadataFromIncomingContext(ctx, app), ShouldBeNil)
+	a.So(app.LoRaWANMetadata, ShouldBeTrue)
+	a.So(lorawanMetadataMetadata(app).Get(LoRaWANMetadataKey), ShouldResemble, []string{"true"})
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(LoRaWANMetadataKey, "yes please"))
+	a.So(lorawanMetadataFromIncomingContext(ctx, app), ShouldNotBeNil)
+	a.So(app.LoRaWANMetadata, ShouldBeTrue)
+}
@@ -29,6 +29,9 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
+// errDeviceChanged is returned if a device is changed by another request while it is updated in the Broker
+var errDeviceChanged = errors.NewErrUnavailable("Device was changed by another request, try again")
+
 type handlerManager struct {
 	handler         *handler
 	devAddrManager  pb_lorawan.DevAddrManagerClient
@@ -122,7 +125,7 @@ func (h *handlerManager) GetDevice(ctx context.Context, in *pb_handler.DeviceIde
 		nsDev = dev.ToLoRaWANPb()
 		nsDev.AppKey = nil
 		nsDev.AppSKey = nil
-		_, err = h.handler.ttnDeviceManager.SetDevice(ttnctx.OutgoingContextWithToken(ctx, token), nsDev)
+		_, err = h.handler.ttnDeviceManager.SetDevice(h.handler.outgoingContextWithSuspension(ttnctx.OutgoingContextWithToken(ctx, token), dev), nsDev)
 		if err != nil {
 			return nil, errors.Wrap(errors.FromGRPCError(err), "Could not re-register missing device to Broker")
 		}
@@ -139,14 +142,25 @@ func (h *handlerManager) GetDevice(ctx context.Context, in *pb_handler.DeviceIde
 		dev.UsedDevNonces = nil
 	}
 
+	grpc.SetHeader(ctx, metadata.Join(suspensionMetadata(dev), h.handler.downlinkQueueMetadata(dev)))
+
 	return pbDev, nil
 }
 
-func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (*gogo.Empty, error) {
+func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (res *gogo.Empty, err error) {
 	if err := in.Validate(); err != nil {
 		return nil, errors.Wrap(err, "Invalid Device")
 	}
 
+	if from := moveFromIncomingContext(ctx); from != nil {
+		return h.MoveDevice(ctx, from, in)
+	}
+
+	suspension, err := suspensionFromIncomingContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, claims, err := h.validateTTNAuthAppContext(ctx, in.AppID)
 	if err != nil {
 		return nil, err
@@ -156,11 +170,18 @@ func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (
 	if err != nil {
 		return nil, err
 	}
+	if suspension != nil {
+		if err := h.handler.checkSuspensionRights(claims, in.AppID); err != nil {
+			return nil, err
+		}
+	}
 
 	if _, err := h.handler.applications.Get(in.AppID); err != nil {
 		return nil, errors.Wrap(err, "Application not registered to this Handler")
 	}
 
+	// The device is not locked during the requests to the Broker, so that its uplinks are not blocked by them. It is
+	// locked when the update is stored, see below.
 	dev, err := h.handler.devices.Get(in.AppID, in.DevID)
 	if err != nil && errors.GetErrType(err) != errors.NotFound {
 		return nil, err
@@ -171,8 +192,27 @@ func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (
 		return nil, errors.NewErrInvalidArgument("Device", "No LoRaWAN Device")
 	}
 
+	// Claim the device with the claim code, which gives the device the AppKey from the manifest
+	var claimed *device.ClaimableDevice
+	if claimCode := claimCodeFromIncomingContext(ctx); claimCode != "" {
+		if dev != nil {
+			return nil, errors.NewErrAlreadyExists("Device")
+		}
+		claimed, err = h.handler.claim(lorawan.DevEUI, withClaimCode(claimCode))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				h.handler.unclaim(claimed)
+			}
+		}()
+		lorawan.AppKey = &claimed.AppKey
+	}
+
 	var eventType types.EventType
-	if dev != nil {
+	exists := dev != nil
+	if exists {
 		eventType = types.UpdateEvent
 
 		// Not allowed to update join nonces after device is created
@@ -188,8 +228,6 @@ func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (
 				return nil, errors.Wrap(errors.FromGRPCError(err), "Broker did not delete device")
 			}
 		}
-
-		dev.StartUpdate()
 	} else {
 		eventType = types.CreateEvent
 		existingDevices, err := h.handler.devices.ListForApp(in.AppID, nil)
@@ -203,17 +241,28 @@ func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (
 		}
 		dev = new(device.Device)
 	}
+	appEUI, devEUI := dev.AppEUI, dev.DevEUI
 
-	if lorawan.AppKey != nil && dev.AppKey != *lorawan.AppKey {
-		// Reset join nonces when AppKey changes
-		dev.UsedDevNonces, dev.UsedAppNonces = []device.DevNonce{}, []device.AppNonce{}
-	}
+	// updateDevice applies the request to the device. It returns the suspension event, if any.
+	updateDevice := func(dev *device.Device) (suspensionEvent types.EventType) {
+		if lorawan.AppKey != nil && dev.AppKey != *lorawan.AppKey {
+			// Reset join nonces when AppKey changes
+			dev.UsedDevNonces, dev.UsedAppNonces = []device.DevNonce{}, []device.AppNonce{}
+		}
 
-	dev.FromPb(in)
+		dev.FromPb(in)
 
-	if dev.Options.ActivationConstraints == "" {
-		dev.Options.ActivationConstraints = "local"
+		if dev.Options.ActivationConstraints == "" {
+			dev.Options.ActivationConstraints = "local"
+		}
+
+		// The suspension is left unchanged if it is not in the request metadata
+		if suspension != nil {
+			suspensionEvent = setSuspension(dev, *suspension)
+		}
+		return suspensionEvent
 	}
+	updateDevice(dev)
 
 	// Update the device in the Broker (NetworkServer)
 	lorawanPb := dev.ToLoRaWANPb()
@@ -224,15 +273,37 @@ func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (
 	lorawanPb.FCntUp = lorawan.FCntUp
 	lorawanPb.FCntDown = lorawan.FCntDown
 
-	_, err = h.handler.ttnDeviceManager.SetDevice(ttnctx.OutgoingContextWithToken(ctx, token), lorawanPb)
+	_, err = h.handler.ttnDeviceManager.SetDevice(h.handler.outgoingContextWithSuspension(ttnctx.OutgoingContextWithToken(ctx, token), dev), lorawanPb)
 	if err != nil {
 		return nil, errors.Wrap(errors.FromGRPCError(err), "Broker did not set device")
 	}
 
+	// Get the device again, as uplinks, downlinks and activations may have changed it in the meantime, and apply the
+	// request to it. The request fails if the device was created, deleted or given other EUIs by another request.
+	unlock := h.handler.deviceLocks.Lock(in.AppID, in.DevID)
+	defer unlock()
+
+	dev, err = h.handler.devices.Get(in.AppID, in.DevID)
+	switch {
+	case err != nil && errors.GetErrType(err) == errors.NotFound && !exists:
+		dev, err = new(device.Device), nil
+	case err != nil:
+		return nil, err
+	case !exists:
+		return nil, errors.NewErrAlreadyExists("Device")
+	case dev.AppEUI != appEUI || dev.DevEUI != devEUI:
+		return nil, errDeviceChanged
+	default:
+		dev.StartUpdate()
+	}
+
+	suspensionEvent := updateDevice(dev)
+
 	err = h.handler.devices.Set(dev)
 	if err != nil {
 		return nil, err
 	}
+	unlock()
 
 	h.handler.qEvent <- &types.DeviceEvent{
 		AppID: dev.AppID,
@@ -240,6 +311,15 @@ func (h *handlerManager) SetDevice(ctx context.Context, in *pb_handler.Device) (
 		Event: eventType,
 		Data:  eventUpdatedFields(dev),
 	}
+	if suspensionEvent != "" {
+		data := dev.Suspension
+		h.handler.qEvent <- &types.DeviceEvent{
+			AppID: dev.AppID,
+			DevID: dev.DevID,
+			Event: suspensionEvent,
+			Data:  &data,
+		}
+	}
 
 	return &gogo.Empty{}, nil
 }
@@ -289,10 +369,23 @@ func (h *handlerManager) DeleteDevice(ctx context.Context, in *pb_handler.Device
 	if err != nil && errors.GetErrType(errors.FromGRPCError(err)) != errors.NotFound {
 		return nil, errors.Wrap(errors.FromGRPCError(err), "Broker did not delete device")
 	}
+
+	// The device is only locked after the request to the Broker, so check that it did not get other EUIs in the meantime
+	unlock := h.handler.deviceLocks.Lock(in.AppID, in.DevID)
+	defer unlock()
+
+	current, err := h.handler.devices.Get(in.AppID, in.DevID)
+	if err != nil {
+		return nil, err
+	}
+	if current.AppEUI != dev.AppEUI || current.DevEUI != dev.DevEUI {
+		return nil, errDeviceChanged
+	}
 	err = h.handler.devices.Delete(in.AppID, in.DevID)
 	if err != nil {
 		return nil, err
 	}
+	unlock()
 	h.handler.qEvent <- &types.DeviceEvent{
 		AppID: in.AppID,
 		DevID: in.DevID,
@@ -388,6 +481,7 @@ func (h *handlerManager) GetApplication(ctx context.Context, in *pb_handler.Appl
 			res.RegisterOnJoinAccessKey = "..."
 		}
 	}
+	grpc.SetHeader(ctx, metadata.Join(fieldsSchemasMetadata(app), locationFieldsMetadata(app), geofencesMetadata(app), encodingMetadata(app), lorawanMetadataMetadata(app)))
 	return res, nil
 }
 
@@ -432,6 +526,11 @@ func (h *handlerManager) RegisterApplication(ctx context.Context, in *pb_handler
 		h.handler.Ctx.WithField("AppID", in.AppID).WithError(err).Warn("Could not register Application with Broker")
 	}
 
+	err = h.handler.consumeAMQPAppDownlink(in.AppID)
+	if err != nil {
+		h.handler.Ctx.WithField("AppID", in.AppID).WithError(err).Warn("Could not create AMQP downlink queue for Application")
+	}
+
 	return &gogo.Empty{}, nil
 
 }
@@ -465,6 +564,39 @@ func (h *handlerManager) SetApplication(ctx context.Context, in *pb_handler.Appl
 		app.RegisterOnJoinAccessKey = in.RegisterOnJoinAccessKey
 	}
 
+	if err := fieldsSchemasFromIncomingContext(ctx, app); err != nil {
+		return nil, err
+	}
+
+	if err := locationFieldsFromIncomingContext(ctx, app); err != nil {
+		return nil, err
+	}
+
+	if err := geofencesFromIncomingContext(ctx, app); err != nil {
+		return nil, err
+	}
+
+	if err := encodingFromIncomingContext(ctx, app); err != nil {
+		return nil, err
+	}
+
+	if err := lorawanMetadataFromIncomingContext(ctx, app); err != nil {
+		return nil, err
+	}
+
+	manifest, err := claimableDevicesFromIncomingContext(ctx, in.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest) > 0 {
+		if err := checkAppRights(claims, in.AppID, rights.Devices); err != nil {
+			return nil, err
+		}
+		if err := h.handler.importClaimableDevices(manifest); err != nil {
+			return nil, err
+		}
+	}
+
 	if app.PayloadFormat == "" && (app.CustomDecoder != "" || app.CustomConverter != "" || app.CustomValidator != "" || app.CustomEncoder != "") {
 		app.PayloadFormat = application.PayloadFormatCustom
 	}
@@ -473,6 +605,7 @@ func (h *handlerManager) SetApplication(ctx context.Context, in *pb_handler.Appl
 	if err != nil {
 		return nil, err
 	}
+	h.handler.encodings.Remove(app.AppID)
 
 	return &gogo.Empty{}, nil
 }
@@ -506,7 +639,9 @@ func (h *handlerManager) DeleteApplication(ctx context.Context, in *pb_handler.A
 		if err != nil {
 			return nil, errors.Wrap(errors.FromGRPCError(err), "Broker did not delete device")
 		}
+		unlock := h.handler.deviceLocks.Lock(dev.AppID, dev.DevID)
 		err = h.handler.devices.Delete(dev.AppID, dev.DevID)
+		unlock()
 		if err != nil {
 			return nil, err
 		}
@@ -517,12 +652,18 @@ func (h *handlerManager) DeleteApplication(ctx context.Context, in *pb_handler.A
 	if err != nil {
 		return nil, err
 	}
+	h.handler.encodings.Remove(in.AppID)
 
 	err = h.handler.Discovery.RemoveAppID(in.AppID, token)
 	if err != nil {
 		h.handler.Ctx.WithField("AppID", in.AppID).WithError(errors.FromGRPCError(err)).Warn("Could not unregister Application from Discovery")
 	}
 
+	err = h.handler.deleteAMQPAppDownlink(in.AppID)
+	if err != nil {
+		h.handler.Ctx.WithField("AppID", in.AppID).WithError(err).Warn("Could not delete AMQP downlink queue of Application")
+	}
+
 	return &gogo.Empty{}, nil
 }
 
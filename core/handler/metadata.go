@@ -0,0 +1,29 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import "github.com/TheThingsNetwork/ttn/core/types"
+
+// The keys of the gRPC request metadata and response header of the ApplicationManager are defined in the types
+// package, so that clients do not have to import the Handler. See the types package for their use.
+const (
+	UplinkFieldsSchemaKey        = types.UplinkFieldsSchemaKey
+	DownlinkFieldsSchemaKey      = types.DownlinkFieldsSchemaKey
+	ForwardRawOnInvalidFieldsKey = types.ForwardRawOnInvalidFieldsKey
+	LocationFieldsKey            = types.LocationFieldsKey
+	GeofencesKey                 = types.GeofencesKey
+	ClaimableDevicesKey          = types.ClaimableDevicesKey
+	EncodingKey                  = types.EncodingKey
+	LoRaWANMetadataKey           = types.LoRaWANMetadataKey
+
+	ClaimCodeKey     = types.ClaimCodeKey
+	MoveFromAppIDKey = types.MoveFromAppIDKey
+	MoveFromDevIDKey = types.MoveFromDevIDKey
+	DownlinkQueueKey = types.DownlinkQueueKey
+
+	// SuspensionKey is also read by the Broker and NetworkServer
+	SuspensionKey = types.SuspensionKey
+
+	DryUplinkStateKey = types.DryUplinkStateKey
+)
@@ -0,0 +1,59 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"sync"
+
+	"github.com/TheThingsNetwork/ttn/mqtt"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var mqttBufferedMessages = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+	Namespace: "ttn",
+	Subsystem: "handler",
+	Name:      "mqtt_buffered_messages",
+	Help:      "MQTT messages that are buffered while the handler is disconnected.",
+}, func() float64 {
+	var buffered int
+	for _, client := range getMQTTClients() {
+		buffered += client.Stats().Buffered
+	}
+	return float64(buffered)
+})
+
+var mqttDroppedMessages = prometheus.NewCounterFunc(prometheus.CounterOpts{
+	Namespace: "ttn",
+	Subsystem: "handler",
+	Name:      "mqtt_dropped_messages",
+	Help:      "MQTT messages that were dropped because the handler was disconnected.",
+}, func() float64 {
+	var dropped uint64
+	for _, client := range getMQTTClients() {
+		dropped += client.Stats().Dropped
+	}
+	return float64(dropped)
+})
+
+func init() {
+	prometheus.Register(mqttBufferedMessages)
+	prometheus.Register(mqttDroppedMessages)
+}
+
+var (
+	mqttClientsMu sync.Mutex
+	mqttClients   []mqtt.Client
+)
+
+func countMQTTClient(client mqtt.Client) {
+	mqttClientsMu.Lock()
+	defer mqttClientsMu.Unlock()
+	mqttClients = append(mqttClients, client)
+}
+
+func getMQTTClients() []mqtt.Client {
+	mqttClientsMu.Lock()
+	defer mqttClientsMu.Unlock()
+	return mqttClients
+}
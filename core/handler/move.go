@@ -0,0 +1,249 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"time"
+
+	pb_handler "github.com/TheThingsNetwork/api/handler"
+	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
+	"github.com/TheThingsNetwork/go-account-lib/rights"
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	gogo "github.com/gogo/protobuf/types"
+	"golang.org/x/net/context"
+)
+
+// moveFromIncomingContext returns the identifier of the device that is moved, or nil if the request metadata does not
+// contain it
+func moveFromIncomingContext(ctx context.Context) *pb_handler.DeviceIdentifier {
+	md := ttnctx.MetadataFromIncomingContext(ctx)
+	appID, devID := md.Get(MoveFromAppIDKey), md.Get(MoveFromDevIDKey)
+	if len(appID) == 0 && len(devID) == 0 {
+		return nil
+	}
+	from := new(pb_handler.DeviceIdentifier)
+	if len(appID) > 0 {
+		from.AppID = appID[0]
+	}
+	if len(devID) > 0 {
+		from.DevID = devID[0]
+	}
+	return from
+}
+
+// MoveDevice moves a device to another application and/or device ID. The device keeps its keys, session, frame
+// counters, attributes, suspension and queued downlinks, so it does not have to rejoin. Only the AppID, DevID and AppEUI of the
+// new device are used; the AppEUI is not changed if it is empty. The caller needs the devices right on both
+// applications.
+func (h *handlerManager) MoveDevice(ctx context.Context, from *pb_handler.DeviceIdentifier, to *pb_handler.Device) (*gogo.Empty, error) {
+	if err := from.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Invalid Device Identifier")
+	}
+	if err := to.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Invalid Device")
+	}
+	if from.AppID == to.AppID && from.DevID == to.DevID {
+		return nil, errors.NewErrInvalidArgument("Device", "can not be moved to itself")
+	}
+
+	ctx, claims, err := h.validateTTNAuthAppContext(ctx, to.AppID)
+	if err != nil {
+		return nil, err
+	}
+	token, _ := ttnctx.TokenFromIncomingContext(ctx)
+	for _, appID := range []string{from.AppID, to.AppID} {
+		if err := checkAppRights(claims, appID, rights.Devices); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := h.handler.applications.Get(to.AppID); err != nil {
+		return nil, errors.Wrap(err, "Application not registered to this Handler")
+	}
+
+	if err := h.moveDevice(ttnctx.OutgoingContextWithToken(ctx, token), from, to); err != nil {
+		return nil, err
+	}
+
+	return &gogo.Empty{}, nil
+}
+
+// moveDevice moves the device, using the outgoing context for the requests to the Broker. The device locks are not held
+// during the requests to the Broker; if the device is changed by another request in the meantime, the move fails with
+// errDeviceChanged. If the move fails after the device was updated in the Broker, the device is restored in the Broker.
+func (h *handlerManager) moveDevice(ctx context.Context, from *pb_handler.DeviceIdentifier, to *pb_handler.Device) (err error) {
+	old, err := h.handler.devices.Get(from.AppID, from.DevID)
+	if err != nil {
+		return err
+	}
+	if err := h.checkMoveTarget(to.AppID, to.DevID); err != nil {
+		return err
+	}
+
+	nsDev, err := h.handler.ttnDeviceManager.GetDevice(ctx, &pb_lorawan.DeviceIdentifier{
+		AppEUI: old.AppEUI,
+		DevEUI: old.DevEUI,
+	})
+	if err != nil {
+		return errors.Wrap(errors.FromGRPCError(err), "Broker did not return device")
+	}
+
+	dev := old.Clone()
+	dev.AppID, dev.DevID = to.AppID, to.DevID
+	if lorawan := to.GetLoRaWANDevice(); lorawan != nil && !lorawan.AppEUI.IsEmpty() {
+		dev.AppEUI = lorawan.AppEUI
+	}
+
+	// Update the device in the Broker (NetworkServer). Devices are stored by AppEUI and DevEUI, so if the AppEUI does
+	// not change, this changes the AppID of the session in a single update.
+	_, err = h.handler.ttnDeviceManager.SetDevice(h.handler.outgoingContextWithSuspension(ctx, dev), movedLoRaWANPb(dev, nsDev))
+	if err != nil {
+		return errors.Wrap(errors.FromGRPCError(err), "Broker did not set device")
+	}
+
+	defer func() {
+		if err != nil {
+			h.rollbackMove(ctx, old, dev, nsDev)
+		}
+	}()
+
+	if dev.AppEUI != old.AppEUI {
+		_, err = h.handler.ttnDeviceManager.DeleteDevice(ctx, &pb_lorawan.DeviceIdentifier{
+			AppEUI: old.AppEUI,
+			DevEUI: old.DevEUI,
+		})
+		if err != nil && errors.GetErrType(errors.FromGRPCError(err)) != errors.NotFound {
+			return errors.Wrap(errors.FromGRPCError(err), "Broker did not delete device")
+		}
+		err = nil
+	}
+
+	moved, err := h.commitMove(old, dev)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range []*types.DeviceEvent{
+		{AppID: old.AppID, DevID: old.DevID, Event: types.DeleteEvent},
+		{AppID: moved.AppID, DevID: moved.DevID, Event: types.CreateEvent, Data: eventUpdatedFields(moved)},
+	} {
+		select {
+		case h.handler.qEvent <- event:
+		case <-time.After(eventPublishTimeout):
+			h.handler.Ctx.Warnf("Could not emit %q event", event.Event)
+		}
+	}
+
+	return nil
+}
+
+// checkMoveTarget returns an error if a device can not be moved to the application and device ID
+func (h *handlerManager) checkMoveTarget(appID, devID string) error {
+	if _, err := h.handler.devices.Get(appID, devID); err == nil {
+		return errors.NewErrAlreadyExists("Device")
+	} else if errors.GetErrType(err) != errors.NotFound {
+		return err
+	}
+	return nil
+}
+
+// commitMove moves the device in the Handler after it was moved in the Broker, and returns the moved device. The moved
+// device is built from the current device, so that changes that were made during the requests to the Broker are kept.
+// It returns errDeviceChanged if the old device got other EUIs or was deleted in the meantime.
+func (h *handlerManager) commitMove(old, moved *device.Device) (*device.Device, error) {
+	unlock := h.handler.deviceLocks.LockPair(old.AppID, old.DevID, moved.AppID, moved.DevID)
+	defer unlock()
+
+	current, err := h.handler.devices.Get(old.AppID, old.DevID)
+	if err != nil && errors.GetErrType(err) != errors.NotFound {
+		return nil, err
+	}
+	if err != nil || current.AppEUI != old.AppEUI || current.DevEUI != old.DevEUI {
+		return nil, errDeviceChanged
+	}
+	if err := h.checkMoveTarget(moved.AppID, moved.DevID); err != nil {
+		return nil, err
+	}
+
+	dev := current.Clone()
+	dev.AppID, dev.DevID, dev.AppEUI = moved.AppID, moved.DevID, moved.AppEUI
+	if err := h.handler.devices.Set(dev); err != nil {
+		return nil, err
+	}
+
+	err = h.moveDownlinkQueue(old.AppID, old.DevID, dev.AppID, dev.DevID)
+	if err == nil {
+		err = h.handler.devices.Delete(old.AppID, old.DevID)
+	}
+	if err != nil {
+		log := h.handler.Ctx.WithFields(ttnlog.Fields{"AppID": old.AppID, "DevID": old.DevID})
+		if err := h.moveDownlinkQueue(dev.AppID, dev.DevID, old.AppID, old.DevID); err != nil {
+			log.WithError(err).Warn("Could not move queued downlinks back after failed move")
+		}
+		if err := h.handler.devices.Delete(dev.AppID, dev.DevID); err != nil {
+			log.WithError(err).Warn("Could not delete moved device after failed move")
+		}
+		return nil, err
+	}
+
+	return dev, nil
+}
+
+// movedLoRaWANPb returns the device in the Broker (NetworkServer) after a move, with the frame counters of the device
+// in the Broker
+func movedLoRaWANPb(dev *device.Device, nsDev *pb_lorawan.Device) *pb_lorawan.Device {
+	lorawanPb := dev.ToLoRaWANPb()
+	lorawanPb.AppKey = nil
+	lorawanPb.AppSKey = nil
+	lorawanPb.UsedDevNonces = nil
+	lorawanPb.UsedAppNonces = nil
+	lorawanPb.FCntUp = nsDev.FCntUp
+	lorawanPb.FCntDown = nsDev.FCntDown
+	return lorawanPb
+}
+
+// rollbackMove restores the old device in the Broker after a failed move to dev
+func (h *handlerManager) rollbackMove(ctx context.Context, old, dev *device.Device, nsDev *pb_lorawan.Device) {
+	log := h.handler.Ctx.WithFields(ttnlog.Fields{"AppID": old.AppID, "DevID": old.DevID})
+	if _, err := h.handler.ttnDeviceManager.SetDevice(h.handler.outgoingContextWithSuspension(ctx, old), movedLoRaWANPb(old, nsDev)); err != nil {
+		log.WithError(errors.FromGRPCError(err)).Warn("Could not restore device in Broker after failed move")
+	}
+	if dev.AppEUI != old.AppEUI {
+		_, err := h.handler.ttnDeviceManager.DeleteDevice(ctx, &pb_lorawan.DeviceIdentifier{
+			AppEUI: dev.AppEUI,
+			DevEUI: dev.DevEUI,
+		})
+		if err != nil && errors.GetErrType(errors.FromGRPCError(err)) != errors.NotFound {
+			log.WithError(errors.FromGRPCError(err)).Warn("Could not delete moved device from Broker after failed move")
+		}
+	}
+}
+
+// moveDownlinkQueue moves the queued downlinks of a device to the queue of another device
+func (h *handlerManager) moveDownlinkQueue(fromAppID, fromDevID, toAppID, toDevID string) error {
+	from, err := h.handler.devices.DownlinkQueue(fromAppID, fromDevID)
+	if err != nil {
+		return err
+	}
+	to, err := h.handler.devices.DownlinkQueue(toAppID, toDevID)
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := from.Next()
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			return nil
+		}
+		if err := to.PushLast(msg); err != nil {
+			return err
+		}
+	}
+}
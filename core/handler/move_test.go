@@ -0,0 +1,177 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+
+	pb_handler "github.com/TheThingsNetwork/api/handler"
+	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
+	"github.com/TheThingsNetwork/ttn/core/component"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	gogo "github.com/gogo/protobuf/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMoveFromIncomingContext(t *testing.T) {
+	a := New(t)
+
+	a.So(moveFromIncomingContext(context.Background()), ShouldBeNil)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MoveFromAppIDKey, "app", MoveFromDevIDKey, "dev"))
+	a.So(moveFromIncomingContext(ctx), ShouldResemble, &pb_handler.DeviceIdentifier{AppID: "app", DevID: "dev"})
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(MoveFromAppIDKey, "app"))
+	from := moveFromIncomingContext(ctx)
+	a.So(from, ShouldNotBeNil)
+	a.So(from.Validate(), ShouldNotBeNil)
+}
+
+func TestMoveDownlinkQueue(t *testing.T) {
+	a := New(t)
+
+	h := &handlerManager{handler: &handler{
+		devices: device.NewRedisDeviceStore(GetRedisClient(), "handler-test-move-downlink-queue"),
+	}}
+
+	from, _ := h.handler.devices.DownlinkQueue("app-1", "dev")
+	to, _ := h.handler.devices.DownlinkQueue("app-2", "dev")
+	defer func() {
+		h.handler.devices.Delete("app-1", "dev")
+		h.handler.devices.Delete("app-2", "dev")
+	}()
+
+	from.PushLast(&types.DownlinkMessage{PayloadRaw: []byte{0x01}})
+	from.PushLast(&types.DownlinkMessage{PayloadRaw: []byte{0x02}})
+
+	a.So(h.moveDownlinkQueue("app-1", "dev", "app-2", "dev"), ShouldBeNil)
+
+	length, _ := from.Length()
+	a.So(length, ShouldEqual, 0)
+	length, _ = to.Length()
+	a.So(length, ShouldEqual, 2)
+
+	next, _ := to.Next()
+	a.So(next.PayloadRaw, ShouldResemble, []byte{0x01})
+	next, _ = to.Next()
+	a.So(next.PayloadRaw, ShouldResemble, []byte{0x02})
+}
+
+func TestMoveDevice(t *testing.T) {
+	a := New(t)
+
+	appEUI := types.AppEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	otherAppEUI := types.AppEUI{8, 7, 6, 5, 4, 3, 2, 1}
+	devEUI := types.DevEUI{1, 2, 3, 4, 5, 6, 7, 8}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ttnDeviceManager := pb_lorawan.NewMockDeviceManagerClient(ctrl)
+
+	h := &handlerManager{handler: &handler{
+		Component:        &component.Component{Ctx: GetLogger(t, "TestMoveDevice")},
+		devices:          device.NewRedisDeviceStore(GetRedisClient(), "handler-test-move-device"),
+		ttnDeviceManager: ttnDeviceManager,
+		qEvent:           make(chan *types.DeviceEvent, 10),
+	}}
+	defer func() {
+		h.handler.devices.Delete("app-1", "dev")
+		h.handler.devices.Delete("app-2", "dev")
+	}()
+
+	h.handler.devices.Set(&device.Device{AppID: "app-1", DevID: "dev", AppEUI: appEUI, DevEUI: devEUI})
+	queue, _ := h.handler.devices.DownlinkQueue("app-1", "dev")
+	queue.PushLast(&types.DownlinkMessage{PayloadRaw: []byte{0x01}})
+
+	from := &pb_handler.DeviceIdentifier{AppID: "app-1", DevID: "dev"}
+	to := &pb_handler.Device{AppID: "app-2", DevID: "dev", Device: &pb_handler.Device_LoRaWANDevice{LoRaWANDevice: &pb_lorawan.Device{
+		AppEUI: otherAppEUI,
+	}}}
+	nsDev := &pb_lorawan.Device{AppEUI: appEUI, DevEUI: devEUI, AppID: "app-1", FCntUp: 42}
+
+	assertNotMoved := func() {
+		_, err := h.handler.devices.Get("app-1", "dev")
+		a.So(err, ShouldBeNil)
+		_, err = h.handler.devices.Get("app-2", "dev")
+		a.So(err, ShouldNotBeNil)
+		length, _ := queue.Length()
+		a.So(length, ShouldEqual, 1)
+		a.So(h.handler.qEvent, ShouldBeEmpty)
+	}
+
+	// The Broker does not set the device
+	ttnDeviceManager.EXPECT().GetDevice(gomock.Any(), gomock.Any()).Return(nsDev, nil)
+	ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Return(nil, errors.NewErrInternal("failed"))
+	a.So(h.moveDevice(context.Background(), from, to), ShouldNotBeNil)
+	assertNotMoved()
+
+	// The Broker does not delete the device with the old AppEUI, so the old device is restored in the Broker
+	ttnDeviceManager.EXPECT().GetDevice(gomock.Any(), gomock.Any()).Return(nsDev, nil)
+	gomock.InOrder(
+		ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Do(func(_ interface{}, in *pb_lorawan.Device, _ ...interface{}) {
+			a.So(in.AppID, ShouldEqual, "app-2")
+			a.So(in.AppEUI, ShouldResemble, otherAppEUI)
+			a.So(in.FCntUp, ShouldEqual, 42)
+		}).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().DeleteDevice(gomock.Any(), &pb_lorawan.DeviceIdentifier{AppEUI: appEUI, DevEUI: devEUI}).Return(nil, errors.NewErrInternal("failed")),
+		ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Do(func(_ interface{}, in *pb_lorawan.Device, _ ...interface{}) {
+			a.So(in.AppID, ShouldEqual, "app-1")
+			a.So(in.AppEUI, ShouldResemble, appEUI)
+			a.So(in.FCntUp, ShouldEqual, 42)
+		}).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().DeleteDevice(gomock.Any(), &pb_lorawan.DeviceIdentifier{AppEUI: otherAppEUI, DevEUI: devEUI}).Return(new(gogo.Empty), nil),
+	)
+	a.So(h.moveDevice(context.Background(), from, to), ShouldNotBeNil)
+	assertNotMoved()
+
+	// The device is created by another request while the device is moved in the Broker
+	ttnDeviceManager.EXPECT().GetDevice(gomock.Any(), gomock.Any()).Return(nsDev, nil)
+	gomock.InOrder(
+		ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().DeleteDevice(gomock.Any(), gomock.Any()).Do(func(_ interface{}, _ *pb_lorawan.DeviceIdentifier, _ ...interface{}) {
+			h.handler.devices.Set(&device.Device{AppID: "app-2", DevID: "dev", AppEUI: otherAppEUI, DevEUI: devEUI})
+		}).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().DeleteDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil),
+	)
+	a.So(h.moveDevice(context.Background(), from, to), ShouldNotBeNil)
+	h.handler.devices.Delete("app-2", "dev")
+	assertNotMoved()
+
+	// The device gets another DevEUI while the device is moved in the Broker
+	otherDevEUI := types.DevEUI{8, 7, 6, 5, 4, 3, 2, 1}
+	ttnDeviceManager.EXPECT().GetDevice(gomock.Any(), gomock.Any()).Return(nsDev, nil)
+	gomock.InOrder(
+		ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Do(func(_ interface{}, _ *pb_lorawan.Device, _ ...interface{}) {
+			h.handler.devices.Set(&device.Device{AppID: "app-1", DevID: "dev", AppEUI: appEUI, DevEUI: otherDevEUI})
+		}).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().DeleteDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil),
+		ttnDeviceManager.EXPECT().DeleteDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil),
+	)
+	a.So(h.moveDevice(context.Background(), from, to), ShouldEqual, errDeviceChanged)
+	assertNotMoved()
+	h.handler.devices.Set(&device.Device{AppID: "app-1", DevID: "dev", AppEUI: appEUI, DevEUI: devEUI})
+
+	// The device is moved, and the events are not blocked by a full event channel
+	h.handler.qEvent = make(chan *types.DeviceEvent)
+	ttnDeviceManager.EXPECT().GetDevice(gomock.Any(), gomock.Any()).Return(nsDev, nil)
+	ttnDeviceManager.EXPECT().SetDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil)
+	ttnDeviceManager.EXPECT().DeleteDevice(gomock.Any(), gomock.Any()).Return(new(gogo.Empty), nil)
+	a.So(h.moveDevice(context.Background(), from, to), ShouldBeNil)
+	_, err := h.handler.devices.Get("app-1", "dev")
+	a.So(err, ShouldNotBeNil)
+	dev, err := h.handler.devices.Get("app-2", "dev")
+	a.So(err, ShouldBeNil)
+	a.So(dev.AppEUI, ShouldResemble, otherAppEUI)
+	movedQueue, _ := h.handler.devices.DownlinkQueue("app-2", "dev")
+	length, _ := movedQueue.Length()
+	a.So(length, ShouldEqual, 1)
+}
@@ -4,6 +4,7 @@
 package handler
 
 import (
+	"fmt"
 	"time"
 
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
@@ -18,7 +19,13 @@ var MQTTTimeout = 2 * time.Second
 var MQTTBufferSize = 10
 
 func (h *handler) HandleMQTT(username, password string, mqttBrokers ...string) error {
-	h.mqttClient = mqtt.NewClient(h.Ctx, "ttnhdl", username, password, mqttBrokers...)
+	options := h.mqttClientOptions
+	options.Encoding = h.encoding
+	if options.PersistentSession && options.SessionID == "" && h.Identity != nil {
+		options.SessionID = fmt.Sprintf("ttnhdl-%s", h.Identity.ID)
+	}
+	h.mqttClient = mqtt.NewClientWithOptions(h.Ctx, "ttnhdl", username, password, options, mqttBrokers...)
+	countMQTTClient(h.mqttClient)
 
 	err := h.mqttClient.Connect()
 	if err != nil {
@@ -28,15 +35,38 @@ func (h *handler) HandleMQTT(username, password string, mqttBrokers ...string) e
 	h.mqttUp = make(chan *types.UplinkMessage, MQTTBufferSize)
 	h.mqttEvent = make(chan *types.DeviceEvent, MQTTBufferSize)
 
-	token := h.mqttClient.SubscribeDownlink(func(client mqtt.Client, appID string, devID string, msg types.DownlinkMessage) {
-		down := &msg
-		down.DevID = devID
-		down.AppID = appID
-		go h.EnqueueDownlink(down)
-	})
-	token.Wait()
-	if token.Error() != nil {
-		return err
+	if !h.mqttV2Disabled {
+		token := h.mqttClient.SubscribeDownlink(func(client mqtt.Client, appID string, devID string, msg types.DownlinkMessage) {
+			down := &msg
+			down.DevID = devID
+			down.AppID = appID
+			go h.EnqueueDownlink(down)
+		})
+		token.Wait()
+		if token.Error() != nil {
+			return token.Error()
+		}
+	}
+
+	if h.mqttV3Enabled {
+		token := h.mqttClient.SubscribeV3Downlink(func(client mqtt.Client, appID string, devID string, replace bool, downlinks []types.DownlinkMessage) {
+			// Downlinks are enqueued in order, after the queue is cleared for a replace
+			go func() {
+				if replace {
+					if err := h.clearDownlinkQueue(appID, devID); err != nil {
+						return
+					}
+				}
+				for _, msg := range downlinks {
+					down := msg
+					h.EnqueueDownlink(&down)
+				}
+			}()
+		})
+		token.Wait()
+		if token.Error() != nil {
+			return token.Error()
+		}
 	}
 
 	ctx := h.Ctx.WithField("Protocol", "MQTT")
@@ -48,17 +78,31 @@ func (h *handler) HandleMQTT(username, password string, mqttBrokers ...string) e
 				"AppID": up.AppID,
 			})
 			ctx.Debug("Publish Uplink")
-			upToken := h.mqttClient.PublishUplink(*up)
-			go func(ctx ttnlog.Interface) {
-				if upToken.WaitTimeout(MQTTTimeout) {
-					if upToken.Error() != nil {
-						ctx.WithError(upToken.Error()).Warn("Could not publish Uplink")
+			if !h.mqttV2Disabled {
+				upToken := h.mqttClient.PublishUplink(*up)
+				go func(ctx ttnlog.Interface) {
+					if upToken.WaitTimeout(MQTTTimeout) {
+						if upToken.Error() != nil {
+							ctx.WithError(upToken.Error()).Warn("Could not publish Uplink")
+						}
+					} else {
+						ctx.Warn("Uplink publish timeout")
 					}
-				} else {
-					ctx.Warn("Uplink publish timeout")
-				}
-			}(ctx)
-			if h.mqttFieldsEnabled && len(up.PayloadFields) > 0 {
+				}(ctx)
+			}
+			if h.mqttV3Enabled {
+				v3Token := h.mqttClient.PublishV3Uplink(*up)
+				go func(ctx ttnlog.Interface) {
+					if v3Token.WaitTimeout(MQTTTimeout) {
+						if v3Token.Error() != nil {
+							ctx.WithError(v3Token.Error()).Warn("Could not publish v3 Uplink")
+						}
+					} else {
+						ctx.Warn("v3 Uplink publish timeout")
+					}
+				}(ctx)
+			}
+			if !h.mqttV2Disabled && h.mqttFieldsEnabled && len(up.PayloadFields) > 0 {
 				fieldsToken := h.mqttClient.PublishUplinkFields(up.AppID, up.DevID, up.PayloadFields)
 				go func(ctx ttnlog.Interface) {
 					if fieldsToken.WaitTimeout(MQTTTimeout) {
@@ -81,21 +125,40 @@ func (h *handler) HandleMQTT(username, password string, mqttBrokers ...string) e
 				"Event": event.Event,
 			})
 			ctx.Debug("Publish Event")
-			var token mqtt.Token
-			if event.DevID == "" {
-				token = h.mqttClient.PublishAppEvent(event.AppID, event.Event, event.Data)
-			} else {
-				token = h.mqttClient.PublishDeviceEvent(event.AppID, event.DevID, event.Event, event.Data)
-			}
-			go func() {
-				if token.WaitTimeout(MQTTTimeout) {
-					if token.Error() != nil {
-						ctx.WithError(token.Error()).Warn("Could not publish Event")
+			if !h.mqttV2Disabled {
+				var token mqtt.Token
+				if event.DevID == "" {
+					token = h.mqttClient.PublishAppEvent(event.AppID, event.Event, event.Data)
+				} else {
+					token = h.mqttClient.PublishDeviceEvent(event.AppID, event.DevID, event.Event, event.Data)
+				}
+				go func() {
+					if token.WaitTimeout(MQTTTimeout) {
+						if token.Error() != nil {
+							ctx.WithError(token.Error()).Warn("Could not publish Event")
+						}
+					} else {
+						ctx.Warn("Event publish timeout")
 					}
+				}()
+			}
+			if h.mqttV3Enabled {
+				var v3Token mqtt.Token
+				if event.DevID == "" {
+					v3Token = h.mqttClient.PublishV3AppEvent(event.AppID, event.Event, event.Data)
 				} else {
-					ctx.Warn("Event publish timeout")
+					v3Token = h.mqttClient.PublishV3DeviceEvent(event.AppID, event.DevID, event.Event, event.Data)
 				}
-			}()
+				go func() {
+					if v3Token.WaitTimeout(MQTTTimeout) {
+						if v3Token.Error() != nil {
+							ctx.WithError(v3Token.Error()).Warn("Could not publish v3 Event")
+						}
+					} else {
+						ctx.Warn("v3 Event publish timeout")
+					}
+				}()
+			}
 		}
 	}()
 
@@ -0,0 +1,271 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheThingsNetwork/go-account-lib/claims"
+	"github.com/TheThingsNetwork/go-account-lib/rights"
+	"github.com/TheThingsNetwork/ttn/mqtt"
+	"github.com/bluele/gcache"
+)
+
+// MQTTAuthCacheSize is the number of authenticated MQTT clients that are cached by the MQTT auth backend
+var MQTTAuthCacheSize = 10000
+
+// MQTTAuthCacheExpiration is the time after which the credentials of an MQTT client are validated again
+var MQTTAuthCacheExpiration = 5 * time.Minute
+
+// Access types used in ACL checks by mosquitto-auth-plug and mosquitto-go-auth
+const (
+	mqttAccessRead      = 1
+	mqttAccessWrite     = 2
+	mqttAccessSubscribe = 4
+)
+
+// authenticateMQTT validates the AppID and access key of an MQTT client and returns its authorizer
+func (h *handler) authenticateMQTT(username, password string) (*mqttAuthorizer, error) {
+	appID := username
+	if _, err := h.applications.Get(appID); err != nil {
+		return nil, err
+	}
+	token, err := h.ExchangeAppKeyForToken(appID, password)
+	if err != nil {
+		return nil, err
+	}
+	appClaims, err := claims.FromToken(h.TokenKeyProvider, token)
+	if err != nil {
+		return nil, err
+	}
+	return &mqttAuthorizer{appID: appID, claims: appClaims}, nil
+}
+
+type mqttAuthorizer struct {
+	appID  string
+	claims *claims.Claims
+}
+
+// aclTopic replaces the wildcards in a topic filter and removes the encoding extension, so that it can be
+// parsed as a topic
+func aclTopic(filter string) string {
+	filter, _ = mqtt.TopicEncoding(filter)
+	parts := strings.Split(filter, "/")
+	for i := 2; i < len(parts); i++ {
+		if parts[i] == "+" || parts[i] == "#" {
+			parts[i] = "wildcard"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (a *mqttAuthorizer) CanSubscribe(filter string) bool {
+	if !a.claims.AppRight(a.appID, rights.ReadUplink) {
+		return false
+	}
+	if topic, err := mqtt.ParseDeviceTopic(aclTopic(filter)); err == nil {
+		return topic.AppID == a.appID && (topic.Type == mqtt.DeviceUplink || topic.Type == mqtt.DeviceEvents)
+	}
+	if topic, err := mqtt.ParseApplicationTopic(aclTopic(filter)); err == nil {
+		return topic.AppID == a.appID
+	}
+	if topic, err := mqtt.ParseV3DeviceTopic(aclTopic(filter)); err == nil {
+		return topic.AppID == a.appID && topic.Type != mqtt.V3DeviceDownlinkPush && topic.Type != mqtt.V3DeviceDownlinkReplace
+	}
+	if topic, err := mqtt.ParseV3ApplicationEventTopic(aclTopic(filter)); err == nil {
+		return topic.AppID == a.appID
+	}
+	return false
+}
+
+func (a *mqttAuthorizer) CanPublish(topicName string) bool {
+	if !a.claims.AppRight(a.appID, rights.WriteDownlink) {
+		return false
+	}
+	topicName, _ = mqtt.TopicEncoding(topicName)
+	if topic, err := mqtt.ParseDeviceTopic(topicName); err == nil {
+		return topic.AppID == a.appID && topic.DevID != "" && topic.Type == mqtt.DeviceDownlink
+	}
+	if topic, err := mqtt.ParseV3DeviceTopic(topicName); err == nil {
+		return topic.AppID == a.appID && topic.DevID != "" && (topic.Type == mqtt.V3DeviceDownlinkPush || topic.Type == mqtt.V3DeviceDownlinkReplace)
+	}
+	return false
+}
+
+// mqttAuthServer is an HTTP authentication backend for external MQTT brokers. It is compatible with the
+// HTTP backends of mosquitto-auth-plug and mosquitto-go-auth: a request is allowed if the response status
+// is 200 OK, and denied otherwise.
+type mqttAuthServer struct {
+	handler *handler
+
+	// credentials caches authorizers by username and password hash
+	credentials gcache.Cache
+	// clients caches authorizers by username and client ID, as ACL requests don't contain the password. They expire
+	// like the credentials, so that clients lose access when their access key is revoked.
+	clients gcache.Cache
+}
+
+func newMQTTAuthServer(h *handler) *mqttAuthServer {
+	return &mqttAuthServer{
+		handler:     h,
+		credentials: gcache.New(MQTTAuthCacheSize).Expiration(MQTTAuthCacheExpiration).LRU().Build(),
+		clients:     gcache.New(MQTTAuthCacheSize).Expiration(MQTTAuthCacheExpiration).LRU().Build(),
+	}
+}
+
+// MQTTAuthHandler returns the HTTP handler of the authentication backend for external MQTT brokers
+func (h *handler) MQTTAuthHandler() http.Handler {
+	s := newMQTTAuthServer(h)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mqtt/auth", s.handleAuth)
+	mux.HandleFunc("/mqtt/superuser", s.handleSuperuser)
+	mux.HandleFunc("/mqtt/acl", s.handleACL)
+	return mux
+}
+
+func credentialsCacheKey(username, password string) string {
+	hash := sha256.Sum256([]byte(password))
+	return username + ":" + hex.EncodeToString(hash[:])
+}
+
+func clientsCacheKey(username, clientID string) string {
+	return username + ":" + clientID
+}
+
+// isSuperuser returns true if the credentials are the MQTT credentials of the handler itself
+func (s *mqttAuthServer) isSuperuser(username string) bool {
+	return s.handler.mqttUsername != "" && username == s.handler.mqttUsername
+}
+
+func (s *mqttAuthServer) authenticate(username, password string) (*mqttAuthorizer, error) {
+	key := credentialsCacheKey(username, password)
+	if cached, err := s.credentials.Get(key); err == nil {
+		return cached.(*mqttAuthorizer), nil
+	}
+	authorizer, err := s.handler.authenticateMQTT(username, password)
+	if err != nil {
+		return nil, err
+	}
+	s.credentials.Set(key, authorizer)
+	return authorizer, nil
+}
+
+func (s *mqttAuthServer) handleAuth(w http.ResponseWriter, r *http.Request) {
+	params, err := mqttAuthParams(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	username, password := params["username"], params["password"]
+	ctx := s.handler.Ctx.WithField("Username", username)
+
+	if s.isSuperuser(username) {
+		if subtle.ConstantTimeCompare([]byte(password), []byte(s.handler.mqttPassword)) != 1 {
+			ctx.Debug("MQTT auth: invalid superuser credentials")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	authorizer, err := s.authenticate(username, password)
+	if err != nil {
+		ctx.WithError(err).Debug("MQTT auth: could not authenticate client")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	s.clients.Set(clientsCacheKey(username, params["clientid"]), authorizer)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *mqttAuthServer) handleSuperuser(w http.ResponseWriter, r *http.Request) {
+	params, err := mqttAuthParams(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !s.isSuperuser(params["username"]) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *mqttAuthServer) handleACL(w http.ResponseWriter, r *http.Request) {
+	params, err := mqttAuthParams(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	username, topic := params["username"], params["topic"]
+	acc, err := strconv.Atoi(params["acc"])
+	if err != nil || topic == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ctx := s.handler.Ctx.WithField("Username", username).WithField("Topic", topic)
+
+	if s.isSuperuser(username) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	cached, err := s.clients.Get(clientsCacheKey(username, params["clientid"]))
+	if err != nil {
+		ctx.Debug("MQTT auth: ACL check for unauthenticated client")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	authorizer := cached.(*mqttAuthorizer)
+
+	allowed := acc&(mqttAccessRead|mqttAccessWrite|mqttAccessSubscribe) != 0
+	if acc&(mqttAccessRead|mqttAccessSubscribe) != 0 && !authorizer.CanSubscribe(topic) {
+		allowed = false
+	}
+	if acc&mqttAccessWrite != 0 && !authorizer.CanPublish(topic) {
+		allowed = false
+	}
+	if !allowed {
+		ctx.WithField("Access", acc).Debug("MQTT auth: topic not allowed")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// mqttAuthParams reads the parameters of an auth request from a JSON body, a form body or the query string
+func mqttAuthParams(r *http.Request) (map[string]string, error) {
+	params := make(map[string]string)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		for k, v := range body {
+			switch v := v.(type) {
+			case string:
+				params[k] = v
+			case float64:
+				params[k] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		return params, nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	for k := range r.Form {
+		params[k] = r.Form.Get(k)
+	}
+	return params, nil
+}
@@ -0,0 +1,165 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheThingsNetwork/go-account-lib/claims"
+	"github.com/TheThingsNetwork/go-account-lib/rights"
+	"github.com/TheThingsNetwork/go-account-lib/scope"
+	"github.com/TheThingsNetwork/ttn/core/component"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestMQTTAuthorizer(t *testing.T) {
+	a := New(t)
+
+	readOnly := &mqttAuthorizer{
+		appID: "app",
+		claims: &claims.Claims{
+			Scope: []string{scope.App("app")},
+			Apps:  map[string][]types.Right{"app": {rights.ReadUplink}},
+		},
+	}
+
+	a.So(readOnly.CanSubscribe("app/devices/dev/up"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("app/devices/+/up"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("app/devices/+/up/#"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("app/devices/dev/events/#"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("app/devices/dev/events/+"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("app/events/#"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("app/devices/+/up.cbor"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("app/devices/+/down.cbor"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("app/devices/dev/down"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("other/devices/dev/up"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("+/devices/+/up"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("#"), ShouldBeFalse)
+	a.So(readOnly.CanPublish("app/devices/dev/down"), ShouldBeFalse)
+
+	a.So(readOnly.CanSubscribe("v3/app/devices/dev/up"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("v3/app/devices/+/join"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("v3/app/devices/+/down/sent"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("v3/app/events/#"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("v3/app/events/activations/throttled"), ShouldBeTrue)
+	a.So(readOnly.CanSubscribe("v3/app/devices/+/down/push"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("v3/app/devices/dev/down/replace"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("v3/other/devices/dev/up"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("v3/+/devices/+/up"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("v3/other/events/#"), ShouldBeFalse)
+	a.So(readOnly.CanSubscribe("v3/#"), ShouldBeFalse)
+	a.So(readOnly.CanPublish("v3/app/devices/dev/down/push"), ShouldBeFalse)
+
+	readWrite := &mqttAuthorizer{
+		appID: "app",
+		claims: &claims.Claims{
+			Scope: []string{scope.App("app")},
+			Apps:  map[string][]types.Right{"app": {rights.ReadUplink, rights.WriteDownlink}},
+		},
+	}
+
+	a.So(readWrite.CanPublish("app/devices/dev/down"), ShouldBeTrue)
+	a.So(readWrite.CanPublish("app/devices/dev/down.protobuf"), ShouldBeTrue)
+	a.So(readWrite.CanPublish("app/devices/+/down"), ShouldBeFalse)
+	a.So(readWrite.CanPublish("other/devices/dev/down"), ShouldBeFalse)
+	a.So(readWrite.CanPublish("app/devices/dev/up"), ShouldBeFalse)
+
+	a.So(readWrite.CanPublish("v3/app/devices/dev/down/push"), ShouldBeTrue)
+	a.So(readWrite.CanPublish("v3/app/devices/dev/down/replace"), ShouldBeTrue)
+	a.So(readWrite.CanPublish("v3/app/devices/+/down/push"), ShouldBeFalse)
+	a.So(readWrite.CanPublish("v3/other/devices/dev/down/push"), ShouldBeFalse)
+	a.So(readWrite.CanPublish("v3/app/devices/dev/up"), ShouldBeFalse)
+	a.So(readWrite.CanPublish("v3/app/devices/dev/down/queued"), ShouldBeFalse)
+}
+
+func TestMQTTAuthServer(t *testing.T) {
+	a := New(t)
+
+	h := &handler{
+		Component:    &component.Component{Ctx: GetLogger(t, "TestMQTTAuthServer")},
+		mqttUsername: "handler",
+		mqttPassword: "secret",
+	}
+	s := newMQTTAuthServer(h)
+	s.clients.Set(clientsCacheKey("app", "client"), &mqttAuthorizer{
+		appID: "app",
+		claims: &claims.Claims{
+			Scope: []string{scope.App("app")},
+			Apps:  map[string][]types.Right{"app": {rights.ReadUplink}},
+		},
+	})
+
+	post := func(handle http.HandlerFunc, params url.Values) int {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handle(rec, req)
+		return rec.Code
+	}
+	postJSON := func(handle http.HandlerFunc, body string) int {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handle(rec, req)
+		return rec.Code
+	}
+
+	// Superuser
+	a.So(post(s.handleAuth, url.Values{"username": {"handler"}, "password": {"secret"}}), ShouldEqual, http.StatusOK)
+	a.So(post(s.handleAuth, url.Values{"username": {"handler"}, "password": {"wrong"}}), ShouldEqual, http.StatusForbidden)
+	a.So(post(s.handleSuperuser, url.Values{"username": {"handler"}}), ShouldEqual, http.StatusOK)
+	a.So(post(s.handleSuperuser, url.Values{"username": {"app"}}), ShouldEqual, http.StatusForbidden)
+	a.So(post(s.handleACL, url.Values{"username": {"handler"}, "topic": {"#"}, "acc": {"4"}}), ShouldEqual, http.StatusOK)
+
+	// ACL
+	a.So(post(s.handleACL, url.Values{"username": {"app"}, "clientid": {"client"}, "topic": {"app/devices/+/up"}, "acc": {"4"}}), ShouldEqual, http.StatusOK)
+	a.So(post(s.handleACL, url.Values{"username": {"app"}, "clientid": {"client"}, "topic": {"app/devices/dev/up"}, "acc": {"1"}}), ShouldEqual, http.StatusOK)
+	a.So(post(s.handleACL, url.Values{"username": {"app"}, "clientid": {"client"}, "topic": {"app/devices/dev/down"}, "acc": {"2"}}), ShouldEqual, http.StatusForbidden)
+	a.So(post(s.handleACL, url.Values{"username": {"app"}, "clientid": {"client"}, "topic": {"other/devices/+/up"}, "acc": {"4"}}), ShouldEqual, http.StatusForbidden)
+	a.So(post(s.handleACL, url.Values{"username": {"app"}, "clientid": {"other"}, "topic": {"app/devices/+/up"}, "acc": {"4"}}), ShouldEqual, http.StatusForbidden)
+	a.So(post(s.handleACL, url.Values{"username": {"app"}, "clientid": {"client"}, "topic": {"app/devices/+/up"}}), ShouldEqual, http.StatusBadRequest)
+	a.So(postJSON(s.handleACL, `{"username":"app","clientid":"client","topic":"app/devices/+/up","acc":4}`), ShouldEqual, http.StatusOK)
+	a.So(postJSON(s.handleACL, `{"username":"app","clientid":"client","topic":"app/devices/dev/down","acc":2}`), ShouldEqual, http.StatusForbidden)
+}
+
+func TestMQTTAuthServerExpiration(t *testing.T) {
+	a := New(t)
+
+	defer func(expiration time.Duration) { MQTTAuthCacheExpiration = expiration }(MQTTAuthCacheExpiration)
+	MQTTAuthCacheExpiration = 50 * time.Millisecond
+
+	h := &handler{
+		Component: &component.Component{Ctx: GetLogger(t, "TestMQTTAuthServerExpiration")},
+	}
+	s := newMQTTAuthServer(h)
+	s.clients.Set(clientsCacheKey("app", "client"), &mqttAuthorizer{
+		appID: "app",
+		claims: &claims.Claims{
+			Scope: []string{scope.App("app")},
+			Apps:  map[string][]types.Right{"app": {rights.ReadUplink}},
+		},
+	})
+
+	acl := func() int {
+		params := url.Values{"username": {"app"}, "clientid": {"client"}, "topic": {"app/devices/+/up"}, "acc": {"4"}}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		s.handleACL(rec, req)
+		return rec.Code
+	}
+
+	a.So(acl(), ShouldEqual, http.StatusOK)
+
+	time.Sleep(100 * time.Millisecond)
+
+	a.So(acl(), ShouldEqual, http.StatusForbidden)
+}
@@ -0,0 +1,98 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"net"
+
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/mqttbroker"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/mqtt"
+)
+
+// HandleMQTTBroker starts the embedded MQTT broker on the given address. Clients authenticate with their
+// AppID as username and an access key of the application as password.
+func (h *handler) HandleMQTTBroker(address string) error {
+	h.mqttBroker = mqttbroker.NewBroker(h.Ctx.WithField("Protocol", "MQTT"), &mqttBrokerHooks{h})
+
+	h.mqttBrokerUp = make(chan *types.UplinkMessage, MQTTBufferSize)
+	h.mqttBrokerEvent = make(chan *types.DeviceEvent, MQTTBufferSize)
+
+	ctx := h.Ctx.WithFields(ttnlog.Fields{"Protocol": "MQTT", "Address": address})
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := h.mqttBroker.Serve(lis); err != nil {
+			ctx.WithError(err).Error("MQTT broker stopped")
+		}
+	}()
+
+	go func() {
+		for up := range h.mqttBrokerUp {
+			encoding := h.encoding(up.AppID)
+			msg, err := encoding.Marshal(up)
+			if err != nil {
+				ctx.WithError(err).Warn("Could not marshal Uplink")
+				continue
+			}
+			topic := mqtt.DeviceTopic{AppID: up.AppID, DevID: up.DevID, Type: mqtt.DeviceUplink}
+			h.mqttBroker.Publish(mqtt.EncodedTopic(topic.String(), encoding), msg)
+		}
+	}()
+
+	go func() {
+		for event := range h.mqttBrokerEvent {
+			// The protobuf encoding contains the complete DeviceEvent message, the other encodings only the data
+			encoding := h.encoding(event.AppID)
+			var payload interface{} = event.Data
+			if encoding == types.ProtobufEncoding {
+				payload = event
+			}
+			msg, err := encoding.Marshal(payload)
+			if err != nil {
+				ctx.WithError(err).Warn("Could not marshal Event")
+				continue
+			}
+			var topic string
+			if event.DevID == "" {
+				topic = mqtt.ApplicationTopic{AppID: event.AppID, Type: mqtt.AppEvents, Field: string(event.Event)}.String()
+			} else {
+				topic = mqtt.DeviceTopic{AppID: event.AppID, DevID: event.DevID, Type: mqtt.DeviceEvents, Field: string(event.Event)}.String()
+			}
+			h.mqttBroker.Publish(mqtt.EncodedTopic(topic, encoding), msg)
+		}
+	}()
+
+	ctx.Info("Started MQTT broker")
+
+	return nil
+}
+
+type mqttBrokerHooks struct {
+	handler *handler
+}
+
+func (h *mqttBrokerHooks) Authenticate(clientID, username, password string) (mqttbroker.Authorizer, error) {
+	return h.handler.authenticateMQTT(username, password)
+}
+
+func (h *mqttBrokerHooks) Published(username, topicName string, payload []byte) {
+	name, encoding := mqtt.TopicEncoding(topicName)
+	topic, err := mqtt.ParseDeviceTopic(name)
+	if err != nil {
+		return
+	}
+	down := &types.DownlinkMessage{}
+	if err := encoding.Unmarshal(payload, down); err != nil {
+		h.handler.Ctx.WithField("Topic", topicName).WithError(err).Warn("Could not unmarshal Downlink")
+		return
+	}
+	down.AppID = topic.AppID
+	down.DevID = topic.DevID
+	go h.handler.EnqueueDownlink(down)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/TheThingsNetwork/ttn/core/component"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/handler/device"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/mqtt"
@@ -31,8 +32,9 @@ func TestHandleMQTT(t *testing.T) {
 	appID := "handler-mqtt-app1"
 	devID := "handler-mqtt-dev1"
 	h := &handler{
-		Component: &component.Component{Ctx: GetLogger(t, "TestHandleMQTT")},
-		devices:   device.NewRedisDeviceStore(GetRedisClient(), "handler-test-handle-mqtt"),
+		Component:    &component.Component{Ctx: GetLogger(t, "TestHandleMQTT")},
+		devices:      device.NewRedisDeviceStore(GetRedisClient(), "handler-test-handle-mqtt"),
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-handle-mqtt"),
 	}
 	h.devices.Set(&device.Device{
 		AppID: appID,
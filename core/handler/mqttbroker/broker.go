@@ -0,0 +1,193 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+// Package mqttbroker implements a minimal MQTT 3.1.1 broker that can be embedded in a component.
+//
+// Messages are delivered with QoS 0. Retained messages, wills and persistent sessions are not supported.
+package mqttbroker
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// ConnectTimeout is the time a client has to send its CONNECT packet
+var ConnectTimeout = 10 * time.Second
+
+// SendBufferSize is the number of messages that are buffered for a client. Messages to clients
+// that do not keep up are dropped.
+var SendBufferSize = 100
+
+// Hooks are called by the Broker to authenticate clients and to handle published messages
+type Hooks interface {
+	// Authenticate returns the Authorizer for the topics of the client, or an error if the client is not
+	// allowed to connect
+	Authenticate(clientID, username, password string) (Authorizer, error)
+	// Published is called for every message that is published by a client and allowed by its Authorizer
+	Published(username, topic string, payload []byte)
+}
+
+// Authorizer authorizes the topics of a client
+type Authorizer interface {
+	// CanSubscribe returns true if the client is allowed to subscribe to the topic filter
+	CanSubscribe(filter string) bool
+	// CanPublish returns true if the client is allowed to publish to the topic
+	CanPublish(topic string) bool
+}
+
+// Broker is an MQTT broker
+type Broker struct {
+	ctx   ttnlog.Interface
+	hooks Hooks
+
+	mu        sync.RWMutex
+	listeners []net.Listener
+	sessions  map[*session]struct{}
+	closed    bool
+}
+
+// NewBroker returns a new Broker
+func NewBroker(ctx ttnlog.Interface, hooks Hooks) *Broker {
+	return &Broker{
+		ctx:      ctx,
+		hooks:    hooks,
+		sessions: make(map[*session]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address and serves clients
+func (b *Broker) ListenAndServe(address string) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return b.Serve(lis)
+}
+
+// Serve accepts clients on the listener. It blocks until the listener is closed.
+func (b *Broker) Serve(lis net.Listener) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		lis.Close()
+		return errors.New("mqttbroker: broker closed")
+	}
+	b.listeners = append(b.listeners, lis)
+	b.mu.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			b.mu.RLock()
+			closed := b.closed
+			b.mu.RUnlock()
+			if closed {
+				return nil
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		go b.handle(conn)
+	}
+}
+
+// Close closes the listeners and disconnects all clients
+func (b *Broker) Close() {
+	b.mu.Lock()
+	b.closed = true
+	listeners := b.listeners
+	sessions := make([]*session, 0, len(b.sessions))
+	for s := range b.sessions {
+		sessions = append(sessions, s)
+	}
+	b.mu.Unlock()
+
+	for _, lis := range listeners {
+		lis.Close()
+	}
+	for _, s := range sessions {
+		s.close()
+	}
+}
+
+// Publish publishes a message to all clients that are subscribed to the topic
+func (b *Broker) Publish(topic string, payload []byte) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.sessions {
+		if s.subscribed(topic) {
+			s.send(topic, payload)
+		}
+	}
+}
+
+func (b *Broker) handle(conn net.Conn) {
+	s := &session{
+		broker:        b,
+		conn:          conn,
+		reader:        bufio.NewReader(conn),
+		outgoing:      make(chan packets.ControlPacket, SendBufferSize),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]struct{}),
+	}
+	ctx := b.ctx.WithField("RemoteAddr", conn.RemoteAddr().String())
+
+	if err := s.connect(); err != nil {
+		ctx.WithError(err).Debug("mqttbroker: client did not connect")
+		conn.Close()
+		return
+	}
+	s.ctx = ctx.WithFields(ttnlog.Fields{"ClientID": s.clientID, "Username": s.username})
+	s.ctx.Debug("mqttbroker: client connected")
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		conn.Close()
+		return
+	}
+	b.sessions[s] = struct{}{}
+	b.mu.Unlock()
+
+	go s.writeLoop()
+	err := s.readLoop()
+
+	b.mu.Lock()
+	delete(b.sessions, s)
+	b.mu.Unlock()
+	s.close()
+
+	if err != nil {
+		s.ctx.WithError(err).Debug("mqttbroker: client disconnected")
+	} else {
+		s.ctx.Debug("mqttbroker: client disconnected")
+	}
+}
+
+// matchTopic returns true if the topic matches the topic filter
+func matchTopic(filter, topic string) bool {
+	filterParts := strings.Split(filter, "/")
+	topicParts := strings.Split(topic, "/")
+	for i, part := range filterParts {
+		if part == "#" {
+			return i == len(filterParts)-1
+		}
+		if i >= len(topicParts) {
+			return false
+		}
+		if part != "+" && part != topicParts[i] {
+			return false
+		}
+	}
+	return len(filterParts) == len(topicParts)
+}
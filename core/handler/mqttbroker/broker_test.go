@@ -0,0 +1,111 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqttbroker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/mqtt"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+)
+
+type testHooks struct {
+	mu        sync.Mutex
+	published map[string][]byte
+}
+
+func (h *testHooks) Authenticate(clientID, username, password string) (Authorizer, error) {
+	if username != "app" || password != "key" {
+		return nil, errors.New("invalid credentials")
+	}
+	return testAuthorizer(username), nil
+}
+
+type testAuthorizer string
+
+func (a testAuthorizer) CanSubscribe(filter string) bool {
+	return strings.HasPrefix(filter, string(a)+"/")
+}
+
+func (a testAuthorizer) CanPublish(topic string) bool {
+	return strings.HasPrefix(topic, string(a)+"/")
+}
+
+func (h *testHooks) Published(username, topic string, payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.published[topic] = payload
+}
+
+func TestMatchTopic(t *testing.T) {
+	a := New(t)
+
+	a.So(matchTopic("app/devices/dev/up", "app/devices/dev/up"), ShouldBeTrue)
+	a.So(matchTopic("app/devices/+/up", "app/devices/dev/up"), ShouldBeTrue)
+	a.So(matchTopic("app/devices/+/up", "app/devices/dev/up/field"), ShouldBeFalse)
+	a.So(matchTopic("app/devices/dev/up/#", "app/devices/dev/up/field/sub"), ShouldBeTrue)
+	a.So(matchTopic("app/#", "app"), ShouldBeTrue)
+	a.So(matchTopic("app/+", "app"), ShouldBeFalse)
+	a.So(matchTopic("+/devices/+/events/#", "app/devices/dev/events/down/sent"), ShouldBeTrue)
+	a.So(matchTopic("app/devices/dev/down", "app/devices/dev/up"), ShouldBeFalse)
+}
+
+func TestBroker(t *testing.T) {
+	a := New(t)
+
+	hooks := &testHooks{published: make(map[string][]byte)}
+	broker := NewBroker(GetLogger(t, "TestBroker"), hooks)
+	defer broker.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	a.So(err, ShouldBeNil)
+	go broker.Serve(lis)
+
+	retries := mqtt.ConnectRetries
+	mqtt.ConnectRetries = 1
+	defer func() { mqtt.ConnectRetries = retries }()
+
+	unauthorized := mqtt.NewClient(GetLogger(t, "TestBroker"), "test", "app", "wrong", fmt.Sprintf("tcp://%s", lis.Addr()))
+	a.So(unauthorized.Connect(), ShouldNotBeNil)
+
+	c := mqtt.NewClient(GetLogger(t, "TestBroker"), "test", "app", "key", fmt.Sprintf("tcp://%s", lis.Addr()))
+	a.So(c.Connect(), ShouldBeNil)
+	defer c.Disconnect()
+
+	var wg WaitGroup
+	wg.Add(1)
+	token := c.SubscribeDeviceUplink("app", "dev", func(_ mqtt.Client, appID string, devID string, msg types.UplinkMessage) {
+		a.So(appID, ShouldEqual, "app")
+		a.So(devID, ShouldEqual, "dev")
+		a.So(msg.PayloadRaw, ShouldResemble, []byte{0x01, 0x02})
+		wg.Done()
+	})
+	token.Wait()
+	a.So(token.Error(), ShouldBeNil)
+
+	broker.Publish("other/devices/dev/up", []byte(`{"payload_raw":"AQI="}`))
+	broker.Publish("app/devices/dev/up", []byte(`{"payload_raw":"AQI="}`))
+	a.So(wg.WaitFor(time.Second), ShouldBeNil)
+
+	token = c.PublishDownlink(types.DownlinkMessage{AppID: "app", DevID: "dev", PayloadRaw: []byte{0x01}})
+	token.Wait()
+	a.So(token.Error(), ShouldBeNil)
+	token = c.PublishDownlink(types.DownlinkMessage{AppID: "other", DevID: "dev", PayloadRaw: []byte{0x01}})
+	token.Wait()
+	a.So(token.Error(), ShouldBeNil)
+
+	time.Sleep(50 * time.Millisecond)
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	a.So(hooks.published, ShouldContainKey, "app/devices/dev/down")
+	a.So(hooks.published, ShouldNotContainKey, "other/devices/dev/down")
+}
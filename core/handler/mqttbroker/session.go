@@ -0,0 +1,210 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqttbroker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// subackFailure is the SUBACK return code for a subscription that is refused
+const subackFailure = 0x80
+
+type session struct {
+	broker *Broker
+	ctx    ttnlog.Interface
+	conn   net.Conn
+	reader *bufio.Reader
+
+	clientID   string
+	username   string
+	keepAlive  time.Duration
+	authorizer Authorizer
+
+	outgoing  chan packets.ControlPacket
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu            sync.RWMutex
+	subscriptions map[string]struct{}
+}
+
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.conn.Close()
+	})
+}
+
+// connect reads the CONNECT packet, authenticates the client and writes the CONNACK packet
+func (s *session) connect() error {
+	s.conn.SetReadDeadline(time.Now().Add(ConnectTimeout))
+	packet, err := packets.ReadPacket(s.reader)
+	if err != nil {
+		return err
+	}
+	connect, ok := packet.(*packets.ConnectPacket)
+	if !ok {
+		return fmt.Errorf("expected CONNECT, got %s", packet)
+	}
+
+	connack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+	connack.ReturnCode = connect.Validate()
+	if connack.ReturnCode == packets.Accepted {
+		s.authorizer, err = s.broker.hooks.Authenticate(connect.ClientIdentifier, connect.Username, string(connect.Password))
+		if err != nil {
+			connack.ReturnCode = packets.ErrRefusedNotAuthorised
+		}
+	}
+	if err := connack.Write(s.conn); err != nil {
+		return err
+	}
+	if connack.ReturnCode != packets.Accepted {
+		return errors.New(packets.ConnackReturnCodes[connack.ReturnCode])
+	}
+
+	s.clientID = connect.ClientIdentifier
+	s.username = connect.Username
+	s.keepAlive = time.Duration(connect.Keepalive) * time.Second
+	return nil
+}
+
+func (s *session) readLoop() error {
+	for {
+		if s.keepAlive > 0 {
+			s.conn.SetReadDeadline(time.Now().Add(s.keepAlive * 3 / 2))
+		} else {
+			s.conn.SetReadDeadline(time.Time{})
+		}
+		packet, err := packets.ReadPacket(s.reader)
+		if err != nil {
+			select {
+			case <-s.done:
+				return nil
+			default:
+				return err
+			}
+		}
+		switch packet := packet.(type) {
+		case *packets.PublishPacket:
+			s.handlePublish(packet)
+		case *packets.PubrelPacket:
+			pubcomp := packets.NewControlPacket(packets.Pubcomp).(*packets.PubcompPacket)
+			pubcomp.MessageID = packet.MessageID
+			s.write(pubcomp)
+		case *packets.SubscribePacket:
+			s.handleSubscribe(packet)
+		case *packets.UnsubscribePacket:
+			s.handleUnsubscribe(packet)
+		case *packets.PingreqPacket:
+			s.write(packets.NewControlPacket(packets.Pingresp))
+		case *packets.PubackPacket, *packets.PubrecPacket, *packets.PubcompPacket:
+			// We only send QoS 0 messages
+		case *packets.DisconnectPacket:
+			return nil
+		default:
+			return fmt.Errorf("unexpected packet %s", packet)
+		}
+	}
+}
+
+func (s *session) writeLoop() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case packet := <-s.outgoing:
+			if err := packet.Write(s.conn); err != nil {
+				s.ctx.WithError(err).Debug("mqttbroker: could not write to client")
+				s.close()
+				return
+			}
+		}
+	}
+}
+
+// write queues a packet for the client, it blocks if the send buffer is full
+func (s *session) write(packet packets.ControlPacket) {
+	select {
+	case s.outgoing <- packet:
+	case <-s.done:
+	}
+}
+
+// send queues a message for the client, it drops the message if the send buffer is full
+func (s *session) send(topic string, payload []byte) {
+	publish := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	publish.TopicName = topic
+	publish.Payload = payload
+	select {
+	case s.outgoing <- publish:
+	case <-s.done:
+	default:
+		s.ctx.WithField("Topic", topic).Warn("mqttbroker: send buffer full, dropping message")
+	}
+}
+
+func (s *session) subscribed(topic string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for filter := range s.subscriptions {
+		if matchTopic(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *session) handlePublish(publish *packets.PublishPacket) {
+	switch publish.Qos {
+	case 1:
+		puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+		puback.MessageID = publish.MessageID
+		s.write(puback)
+	case 2:
+		pubrec := packets.NewControlPacket(packets.Pubrec).(*packets.PubrecPacket)
+		pubrec.MessageID = publish.MessageID
+		s.write(pubrec)
+	}
+	if !s.authorizer.CanPublish(publish.TopicName) {
+		s.ctx.WithField("Topic", publish.TopicName).Debug("mqttbroker: publish not allowed")
+		return
+	}
+	s.broker.hooks.Published(s.username, publish.TopicName, publish.Payload)
+}
+
+func (s *session) handleSubscribe(subscribe *packets.SubscribePacket) {
+	suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+	suback.MessageID = subscribe.MessageID
+	for _, filter := range subscribe.Topics {
+		if !s.authorizer.CanSubscribe(filter) {
+			s.ctx.WithField("Topic", filter).Debug("mqttbroker: subscribe not allowed")
+			suback.ReturnCodes = append(suback.ReturnCodes, subackFailure)
+			continue
+		}
+		s.mu.Lock()
+		s.subscriptions[filter] = struct{}{}
+		s.mu.Unlock()
+		suback.ReturnCodes = append(suback.ReturnCodes, 0)
+	}
+	s.write(suback)
+}
+
+func (s *session) handleUnsubscribe(unsubscribe *packets.UnsubscribePacket) {
+	s.mu.Lock()
+	for _, filter := range unsubscribe.Topics {
+		delete(s.subscriptions, filter)
+	}
+	s.mu.Unlock()
+	unsuback := packets.NewControlPacket(packets.Unsuback).(*packets.UnsubackPacket)
+	unsuback.MessageID = unsubscribe.MessageID
+	s.write(unsuback)
+}
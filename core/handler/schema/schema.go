@@ -0,0 +1,106 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+// Package schema validates payload fields against a JSON Schema (draft 7), using github.com/xeipuuv/gojsonschema.
+//
+// Schemas can reference their own definitions, but references to other documents are not loaded, so that a schema
+// can not make the Handler read files or send requests.
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Schema is a compiled JSON Schema. It is safe for concurrent use.
+type Schema struct {
+	schema *gojsonschema.Schema
+}
+
+// Parse parses and compiles a JSON Schema
+func Parse(data []byte) (*Schema, error) {
+	loader := gojsonschema.NewSchemaLoader()
+	loader.Draft = gojsonschema.Draft7
+	loader.AutoDetect = false
+	schema, err := loader.Compile(localLoader{gojsonschema.NewBytesLoader(data)})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{schema: schema}, nil
+}
+
+// localLoader is a JSONLoader that does not load referenced documents
+type localLoader struct {
+	gojsonschema.JSONLoader
+}
+
+func (localLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return referenceLoaderFactory{}
+}
+
+type referenceLoaderFactory struct{}
+
+func (referenceLoaderFactory) New(source string) gojsonschema.JSONLoader {
+	return referenceLoader{JSONLoader: gojsonschema.NewStringLoader(""), source: source}
+}
+
+// referenceLoader is the JSONLoader of a referenced document, which refuses to load it
+type referenceLoader struct {
+	gojsonschema.JSONLoader
+	source string
+}
+
+func (l referenceLoader) LoadJSON() (interface{}, error) {
+	return nil, fmt.Errorf("references to other documents are not supported: %s", l.source)
+}
+
+// Error is a field that does not match the schema. The Field is empty for the payload fields as a whole.
+type Error struct {
+	Field   string
+	Message string
+}
+
+// Errors are the fields that do not match the schema
+type Errors []Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		if err.Field == "" {
+			messages[i] = err.Message
+		} else {
+			messages[i] = fmt.Sprintf("%s: %s", err.Field, err.Message)
+		}
+	}
+	return fmt.Sprintf("Payload fields do not match the schema: %s", strings.Join(messages, ", "))
+}
+
+// Validate validates the value against the schema. The value is converted to JSON first, so that it is validated as
+// it will be published. The returned error is of type Errors if the value does not match the schema.
+func (s *Schema) Validate(value interface{}) error {
+	result, err := s.schema.Validate(gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+	errs := make(Errors, 0, len(result.Errors()))
+	for _, resultErr := range result.Errors() {
+		field := resultErr.Field()
+		if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			field = ""
+		}
+		errs = append(errs, Error{Field: field, Message: resultErr.Description()})
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Field != errs[j].Field {
+			return errs[i].Field < errs[j].Field
+		}
+		return errs[i].Message < errs[j].Message
+	})
+	return errs
+}
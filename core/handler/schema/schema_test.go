@@ -0,0 +1,152 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestParse(t *testing.T) {
+	a := New(t)
+
+	for _, valid := range []string{
+		`true`,
+		`{}`,
+		`{"type": "object", "properties": {"temperature": {"type": "number", "minimum": -40, "maximum": 85}}}`,
+		`{"type": ["number", "null"]}`,
+		`{"items": [{"type": "number"}], "additionalItems": false}`,
+		`{"anyOf": [{"type": "string"}, {"type": "number"}]}`,
+		`{"properties": {"temperature": {"$ref": "#/definitions/temperature"}}, "definitions": {"temperature": {"type": "number"}}}`,
+		`{"format": "date-time"}`,
+		`{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`,
+	} {
+		_, err := Parse([]byte(valid))
+		a.So(err, ShouldBeNil)
+	}
+
+	for _, invalid := range []string{
+		``,
+		`"object"`,
+		`{"type": "float"}`,
+		`{"minimum": "0"}`,
+		`{"minLength": -1}`,
+		`{"multipleOf": 0}`,
+		`{"pattern": "("}`,
+		`{"required": "temperature"}`,
+		`{"properties": {"temperature": {"$ref": "#/definitions/temperature"}}}`,
+		`{"$ref": "http://localhost/schema.json"}`,
+		`{"$ref": "file:///etc/passwd"}`,
+	} {
+		_, err := Parse([]byte(invalid))
+		a.So(err, ShouldNotBeNil)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	a := New(t)
+
+	s, err := Parse([]byte(`{
+		"type": "object",
+		"required": ["temperature"],
+		"properties": {
+			"temperature": {"type": "number", "minimum": -40, "maximum": 85},
+			"battery": {"type": "integer", "exclusiveMinimum": 0, "multipleOf": 5},
+			"version": {"const": 2},
+			"name": {"type": "string", "maxLength": 4, "pattern": "^[a-z]+$"},
+			"readings": {"type": "array", "items": {"type": "number"}, "uniqueItems": true},
+			"time": {"type": "string", "format": "date-time"},
+			"unit": {"$ref": "#/definitions/unit"},
+			"location": {
+				"type": "object",
+				"properties": {"lat": {"type": "number"}, "lng": {"type": "number"}},
+				"additionalProperties": false
+			}
+		},
+		"definitions": {"unit": {"enum": ["C", "F"]}}
+	}`))
+	a.So(err, ShouldBeNil)
+
+	a.So(s.Validate(map[string]interface{}{
+		"temperature": 21.5,
+		"battery":     int64(95),
+		"version":     2,
+		"name":        "abc",
+		"readings":    []float64{1, 2},
+		"time":        "2017-01-01T12:00:00Z",
+		"unit":        "C",
+		"location":    map[string]interface{}{"lat": 52.3, "lng": 4.9},
+		"other":       true,
+	}), ShouldBeNil)
+
+	err = s.Validate(map[string]interface{}{
+		"temperature": 90,
+		"battery":     float32(2.5),
+		"version":     3,
+		"name":        "ABCDE",
+		"readings":    []int{1, 1},
+		"time":        "yesterday",
+		"unit":        "K",
+		"location":    map[string]interface{}{"lat": "52.3", "alt": 10},
+	})
+	a.So(err, ShouldNotBeNil)
+	errs, ok := err.(Errors)
+	a.So(ok, ShouldBeTrue)
+	fields := make([]string, len(errs))
+	for i, err := range errs {
+		fields[i] = err.Field
+	}
+	a.So(fields, ShouldResemble, []string{
+		"battery", "location", "location.lat", "name", "name", "readings", "temperature", "time", "unit", "version",
+	})
+	a.So(err.Error(), ShouldContainSubstring, "temperature: Must be less than or equal to 85")
+
+	err = s.Validate(map[string]interface{}{})
+	a.So(err, ShouldResemble, Errors{{Field: "", Message: "temperature is required"}})
+
+	err = s.Validate(nil)
+	a.So(err, ShouldNotBeNil)
+	a.So(err.(Errors)[0].Field, ShouldBeEmpty)
+}
+
+func TestValidateCombinations(t *testing.T) {
+	a := New(t)
+
+	s, err := Parse([]byte(`{
+		"properties": {
+			"any": {"anyOf": [{"type": "string"}, {"type": "number", "minimum": 0}]},
+			"one": {"oneOf": [{"type": "number"}, {"type": "integer"}]},
+			"all": {"allOf": [{"minimum": 0}, {"maximum": 10}]},
+			"not": {"not": {"type": "null"}},
+			"tuple": {"items": [{"type": "string"}, {"type": "number"}], "additionalItems": false},
+			"cond": {"if": {"required": ["a"]}, "then": {"required": ["b"]}}
+		}
+	}`))
+	a.So(err, ShouldBeNil)
+
+	a.So(s.Validate(map[string]interface{}{
+		"any":   "foo",
+		"one":   1.5,
+		"all":   5,
+		"not":   0,
+		"tuple": []interface{}{"foo", 1},
+		"cond":  map[string]interface{}{"a": 1, "b": 2},
+	}), ShouldBeNil)
+
+	err = s.Validate(map[string]interface{}{
+		"any":   -1,
+		"one":   1,
+		"all":   11,
+		"not":   nil,
+		"tuple": []interface{}{"foo", 1, 2},
+		"cond":  map[string]interface{}{"a": 1},
+	})
+	a.So(err, ShouldNotBeNil)
+	fields := make(map[string]bool)
+	for _, err := range err.(Errors) {
+		fields[err.Field] = true
+	}
+	a.So(fields, ShouldResemble, map[string]bool{"any": true, "one": true, "all": true, "not": true, "tuple": true, "cond": true})
+}
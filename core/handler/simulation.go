@@ -9,6 +9,7 @@ import (
 	pb "github.com/TheThingsNetwork/api/handler"
 	"github.com/TheThingsNetwork/go-account-lib/rights"
 	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	gogo "github.com/gogo/protobuf/types"
 	"github.com/pkg/errors"
@@ -55,7 +56,12 @@ func (h *handlerManager) SimulateUplink(ctx context.Context, in *pb.SimulatedUpl
 		},
 	}
 
-	err = h.handler.ConvertFieldsUp(log, nil, uplink, dev)
+	var app *application.Application
+	if registered, err := h.handler.applications.Get(in.AppID); err == nil {
+		app = registered
+	}
+
+	err = h.handler.ConvertFieldsUp(log, nil, uplink, dev, app)
 	if err != nil {
 		return nil, err
 	}
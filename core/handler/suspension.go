@@ -0,0 +1,83 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/TheThingsNetwork/go-account-lib/claims"
+	"github.com/TheThingsNetwork/go-account-lib/rights"
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// suspensionFromIncomingContext returns the suspension in the request metadata, or nil if the metadata does not
+// contain it
+func suspensionFromIncomingContext(ctx context.Context) (*types.Suspension, error) {
+	values := ttnctx.MetadataFromIncomingContext(ctx).Get(SuspensionKey)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	suspension := new(types.Suspension)
+	if err := json.Unmarshal([]byte(values[0]), suspension); err != nil {
+		return nil, errors.NewErrInvalidArgument("Suspension", err.Error())
+	}
+	return suspension, nil
+}
+
+// suspensionMetadata returns the suspension of the device as metadata
+func suspensionMetadata(dev *device.Device) metadata.MD {
+	md := metadata.MD{}
+	if data, err := json.Marshal(dev.Suspension); err == nil {
+		md.Set(SuspensionKey, string(data))
+	}
+	return md
+}
+
+// outgoingContextWithSuspension adds the suspension of the device to the outgoing context, so that the NetworkServer
+// keeps the same suspension as the Handler. The token of the Handler is added, as the Broker only accepts the
+// suspension from the Handler of the application.
+func (h *handler) outgoingContextWithSuspension(ctx context.Context, dev *device.Device) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = metadata.Join(md, suspensionMetadata(dev))
+	if token, _ := h.BuildJWT(); token != "" {
+		md.Set(types.SuspensionTokenKey, token)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// checkSuspensionRights checks that the claims allow to suspend and resume the devices of the application. This
+// requires the settings right of the application, or the settings right of this Handler.
+func (h *handler) checkSuspensionRights(claims *claims.Claims, appID string) error {
+	if claims.AppRight(appID, rights.AppSettings) || (h.Identity != nil && claims.ComponentRight(h.Identity.ID, rights.ComponentSettings)) {
+		return nil
+	}
+	return errors.NewErrPermissionDenied(fmt.Sprintf(`No "%s" rights to Application "%s" to suspend or resume devices`, rights.AppSettings, appID))
+}
+
+// setSuspension suspends or resumes the device. It returns the event of the change, or an empty event type if the
+// device already was in that state, in which case only the reason of a suspension is updated.
+func setSuspension(dev *device.Device, suspension types.Suspension) types.EventType {
+	if suspension.Suspended == dev.Suspension.Suspended {
+		if suspension.Suspended {
+			dev.Suspension.Reason = suspension.Reason
+		}
+		return ""
+	}
+	if !suspension.Suspended {
+		suspension.Reason = ""
+	}
+	suspension.Time = types.JSONTime(time.Now())
+	dev.Suspension = suspension
+	if suspension.Suspended {
+		return types.SuspendedEvent
+	}
+	return types.ResumedEvent
+}
@@ -0,0 +1,92 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	pb_discovery "github.com/TheThingsNetwork/api/discovery"
+	"github.com/TheThingsNetwork/go-account-lib/claims"
+	"github.com/TheThingsNetwork/go-account-lib/rights"
+	"github.com/TheThingsNetwork/go-account-lib/scope"
+	"github.com/TheThingsNetwork/ttn/core/component"
+	"github.com/TheThingsNetwork/ttn/core/handler/device"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestSuspensionFromIncomingContext(t *testing.T) {
+	a := New(t)
+
+	suspension, err := suspensionFromIncomingContext(context.Background())
+	a.So(err, ShouldBeNil)
+	a.So(suspension, ShouldBeNil)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(SuspensionKey, `{"suspended":true,"reason":"unpaid"}`))
+	suspension, err = suspensionFromIncomingContext(ctx)
+	a.So(err, ShouldBeNil)
+	a.So(suspension, ShouldResemble, &types.Suspension{Suspended: true, Reason: "unpaid"})
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(SuspensionKey, "suspended"))
+	_, err = suspensionFromIncomingContext(ctx)
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestSetSuspension(t *testing.T) {
+	a := New(t)
+
+	dev := &device.Device{}
+
+	a.So(setSuspension(dev, types.Suspension{}), ShouldEqual, "")
+	a.So(time.Time(dev.Suspension.Time).IsZero(), ShouldBeTrue)
+
+	a.So(setSuspension(dev, types.Suspension{Suspended: true, Reason: "unpaid"}), ShouldEqual, types.SuspendedEvent)
+	a.So(dev.Suspension.Suspended, ShouldBeTrue)
+	a.So(dev.Suspension.Reason, ShouldEqual, "unpaid")
+	suspendedAt := time.Time(dev.Suspension.Time)
+	a.So(suspendedAt.IsZero(), ShouldBeFalse)
+
+	// Only the reason changes
+	a.So(setSuspension(dev, types.Suspension{Suspended: true, Reason: "misbehaving"}), ShouldEqual, "")
+	a.So(dev.Suspension.Reason, ShouldEqual, "misbehaving")
+	a.So(time.Time(dev.Suspension.Time), ShouldEqual, suspendedAt)
+
+	a.So(setSuspension(dev, types.Suspension{Reason: "paid"}), ShouldEqual, types.ResumedEvent)
+	a.So(dev.Suspension.Suspended, ShouldBeFalse)
+	a.So(dev.Suspension.Reason, ShouldBeEmpty)
+
+	md := suspensionMetadata(dev)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	suspension, err := suspensionFromIncomingContext(ctx)
+	a.So(err, ShouldBeNil)
+	a.So(suspension.Suspended, ShouldBeFalse)
+}
+
+func TestCheckSuspensionRights(t *testing.T) {
+	a := New(t)
+
+	h := &handler{Component: &component.Component{Identity: &pb_discovery.Announcement{ID: "handler"}}}
+
+	devices := &claims.Claims{
+		Scope: []string{scope.App("app")},
+		Apps:  map[string][]types.Right{"app": {rights.Devices}},
+	}
+	a.So(h.checkSuspensionRights(devices, "app"), ShouldNotBeNil)
+
+	settings := &claims.Claims{
+		Scope: []string{scope.App("app")},
+		Apps:  map[string][]types.Right{"app": {rights.Devices, rights.AppSettings}},
+	}
+	a.So(h.checkSuspensionRights(settings, "app"), ShouldBeNil)
+	a.So(h.checkSuspensionRights(settings, "other-app"), ShouldNotBeNil)
+
+	operator := &claims.Claims{
+		Scope:      []string{scope.Component("handler")},
+		Components: map[string][]types.Right{"handler": {rights.ComponentSettings}},
+	}
+	a.So(h.checkSuspensionRights(operator, "app"), ShouldBeNil)
+}
@@ -4,6 +4,7 @@
 package handler
 
 import (
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/handler/device"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
@@ -12,8 +13,9 @@ import (
 	ttnlog "github.com/TheThingsNetwork/go-utils/log"
 )
 
-// UplinkProcessor processes an uplink protobuf to an application-layer uplink message
-type UplinkProcessor func(ctx ttnlog.Interface, ttnUp *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, device *device.Device) error
+// UplinkProcessor processes an uplink protobuf to an application-layer uplink message. The application is nil if it
+// is not registered to this Handler.
+type UplinkProcessor func(ctx ttnlog.Interface, ttnUp *pb_broker.DeduplicatedUplinkMessage, appUp *types.UplinkMessage, device *device.Device, app *application.Application) error
 
 // DownlinkProcessor processes an application-layer downlink message to a downlik protobuf
 type DownlinkProcessor func(ctx ttnlog.Interface, appDown *types.DownlinkMessage, ttnDown *pb_broker.DownlinkMessage, device *device.Device) error
@@ -9,6 +9,7 @@ import (
 	pb_broker "github.com/TheThingsNetwork/api/broker"
 	"github.com/TheThingsNetwork/api/logfields"
 	"github.com/TheThingsNetwork/api/trace"
+	"github.com/TheThingsNetwork/ttn/core/handler/application"
 	"github.com/TheThingsNetwork/ttn/core/types"
 )
 
@@ -28,7 +29,7 @@ func (h *handler) HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) (err
 				AppID: appID,
 				DevID: devID,
 				Event: types.UplinkErrorEvent,
-				Data:  types.ErrorEventData{Error: err.Error()},
+				Data:  errorEventData(err),
 			}:
 			case <-time.After(eventPublishTimeout):
 				ctx.Warnf("Could not emit %q event", types.UplinkErrorEvent)
@@ -47,12 +48,21 @@ func (h *handler) HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) (err
 
 	uplink.Trace = uplink.Trace.WithEvent(trace.ReceiveEvent)
 
+	unlock := h.deviceLocks.Lock(appID, devID)
+	defer func() { unlock() }()
+
 	dev, err := h.devices.Get(appID, devID)
 	if err != nil {
 		return err
 	}
 	dev.StartUpdate()
 
+	// Get the application once for all processors; they skip the application settings if it is not registered
+	var app *application.Application
+	if registered, err := h.applications.Get(appID); err == nil {
+		app = registered
+	}
+
 	// Build AppUplink
 	appUplink := &types.UplinkMessage{
 		AppID: appID,
@@ -64,6 +74,8 @@ func (h *handler) HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) (err
 		h.ConvertFromLoRaWAN,
 		h.ConvertMetadata,
 		h.ConvertFieldsUp,
+		h.UpdateLocation,
+		h.UpdateGeofences,
 	}
 
 	ctx.WithField("NumProcessors", len(processors)).Debug("Running Uplink Processors")
@@ -71,7 +83,7 @@ func (h *handler) HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) (err
 
 	// Run Uplink Processors
 	for _, processor := range processors {
-		err = processor(ctx, uplink, appUplink, dev)
+		err = processor(ctx, uplink, appUplink, dev, app)
 		if err == ErrNotNeeded {
 			err = nil
 			return nil
@@ -84,7 +96,9 @@ func (h *handler) HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) (err
 	if err != nil {
 		return err
 	}
-	dev.StartUpdate()
+
+	// Unlock the device, so that the application can enqueue a downlink in response to the uplink
+	unlock()
 
 	// Publish Uplink
 	h.qUp <- appUplink
@@ -98,7 +112,18 @@ func (h *handler) HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) (err
 
 	if dev.CurrentDownlink == nil {
 		<-time.After(ResponseDeadline)
+	}
+
+	unlock = h.deviceLocks.Lock(appID, devID)
+
+	// Get the device again, as it may have changed while it was unlocked
+	dev, err = h.devices.Get(appID, devID)
+	if err != nil {
+		return err
+	}
+	dev.StartUpdate()
 
+	if dev.CurrentDownlink == nil {
 		queue, err := h.devices.DownlinkQueue(appID, devID)
 		if err != nil {
 			return err
@@ -133,11 +158,32 @@ func (h *handler) HandleUplink(uplink *pb_broker.DeduplicatedUplinkMessage) (err
 		return nil
 	}
 
+	// Drop the downlink if its scheduling hints can not be satisfied
+	if dev.CurrentDownlink != nil && dev.CurrentDownlink.Hints != nil {
+		if err := applyDownlinkHints(uplink, dev.CurrentDownlink.Hints); err != nil {
+			select {
+			case h.qEvent <- &types.DeviceEvent{
+				AppID: appID,
+				DevID: devID,
+				Event: types.DownlinkErrorEvent,
+				Data: types.DownlinkEventData{
+					ErrorEventData: types.ErrorEventData{Error: err.Error()},
+					Message:        dev.CurrentDownlink,
+				},
+			}:
+			case <-time.After(eventPublishTimeout):
+				ctx.Warnf("Could not emit %q event", types.DownlinkErrorEvent)
+			}
+			dev.CurrentDownlink = nil
+		}
+	}
+
 	// Save changes (if any)
 	err = h.devices.Set(dev)
 	if err != nil {
 		return err
 	}
+	unlock()
 
 	// Prepare Downlink
 	var appDownlink types.DownlinkMessage
@@ -4,6 +4,7 @@
 package handler
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -190,3 +191,53 @@ func TestHandleUplink(t *testing.T) {
 	a.So(dev.CurrentDownlink, ShouldNotBeNil)
 	a.So(dev.CurrentDownlink.PayloadRaw, ShouldResemble, []byte{0xaa, 0xbc})
 }
+
+func TestHandleUplinkWithConcurrentEnqueue(t *testing.T) {
+	a := New(t)
+	appID, devID := "appid", "devid-concurrent"
+	h := &handler{
+		Component:    &component.Component{Ctx: GetLogger(t, "TestHandleUplinkWithConcurrentEnqueue")},
+		devices:      device.NewRedisDeviceStore(GetRedisClient(), "handler-test-handle-uplink-concurrent"),
+		applications: application.NewRedisApplicationStore(GetRedisClient(), "handler-test-handle-uplink-concurrent"),
+		qUp:          make(chan *types.UplinkMessage, 100),
+		qEvent:       make(chan *types.DeviceEvent, 100),
+	}
+	h.InitStatus()
+	h.applications.Set(&application.Application{AppID: appID})
+	defer func() {
+		h.applications.Delete(appID)
+	}()
+	defer func() {
+		h.devices.Delete(appID, devID)
+	}()
+
+	for i := 0; i < 10; i++ {
+		h.devices.Set(&device.Device{
+			AppID:           appID,
+			DevID:           devID,
+			AppEUI:          types.AppEUI([8]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+			DevEUI:          types.DevEUI([8]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+			CurrentDownlink: &types.DownlinkMessage{PayloadRaw: []byte{0xaa, 0xbc}},
+		})
+
+		uplink, _ := buildLoRaWANUplink([]byte{0x40, 0x04, 0x03, 0x02, 0x01, 0x00, 0x01, 0x00, 0x0A, 0x4D, 0xDA, 0x23, 0x99, 0x61, 0xD4})
+		uplink.AppID, uplink.DevID = appID, devID
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.So(h.HandleUplink(uplink), ShouldBeNil)
+		}()
+		go func() {
+			defer wg.Done()
+			a.So(h.EnqueueDownlink(&types.DownlinkMessage{AppID: appID, DevID: devID, PayloadRaw: []byte{0x12, 0x34}}), ShouldBeNil)
+		}()
+		wg.Wait()
+
+		// The uplink must not restore the downlink that was replaced
+		dev, err := h.devices.Get(appID, devID)
+		a.So(err, ShouldBeNil)
+		a.So(dev.CurrentDownlink, ShouldBeNil)
+	}
+}
@@ -54,6 +54,9 @@ func (n *networkServer) HandlePrepareActivation(activation *pb_broker.Deduplicat
 	if err != nil {
 		return nil, err
 	}
+	if dev.Suspension.Suspended {
+		return nil, types.ErrDeviceSuspended
+	}
 	activation.AppID = dev.AppID
 	activation.DevID = dev.DevID
 
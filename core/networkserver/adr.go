@@ -147,6 +147,23 @@ func (n *networkServer) handleUplinkADR(message *pb_broker.DeduplicatedUplinkMes
 	return nil
 }
 
+// handleUplinkRepetitionADR merges the gateway metadata of a repetition of an unconfirmed uplink into the ADR history
+func (n *networkServer) handleUplinkRepetitionADR(message *pb_broker.DeduplicatedUplinkMessage, dev *device.Device) error {
+	lorawanUplinkMAC := message.GetMessage().GetLoRaWAN().GetMACPayload()
+	if !lorawanUplinkMAC.ADR {
+		return nil
+	}
+	history, err := n.devices.Frames(dev.AppEUI, dev.DevEUI)
+	if err != nil {
+		return err
+	}
+	return history.Merge(&device.Frame{
+		FCnt:         lorawanUplinkMAC.FCnt,
+		SNR:          bestSNR(message.GetGatewayMetadata()),
+		GatewayCount: uint32(len(message.GatewayMetadata)),
+	})
+}
+
 const maxADRFails = 10
 
 func (n *networkServer) setADR(mac *pb_lorawan.MACPayload, dev *device.Device) error {
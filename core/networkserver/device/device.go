@@ -36,6 +36,8 @@ type Device struct {
 	Options  Options       `redis:"options"`
 	ADR      ADRSettings   `redis:"adr,include"`
 
+	Suspension types.Suspension `redis:"suspension"`
+
 	CreatedAt   time.Time `redis:"created_at"`
 	UpdatedAt   time.Time `redis:"updated_at"`
 	ActivatedAt time.Time `redis:"activated_at"` // Indicates whether the device was activated via OTAA method
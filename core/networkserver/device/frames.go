@@ -14,6 +14,7 @@ import (
 // FrameHistory for a device
 type FrameHistory interface {
 	Push(frame *Frame) error
+	Merge(frame *Frame) error
 	Get() ([]*Frame, error)
 	Clear() error
 }
@@ -28,12 +29,8 @@ type RedisFrameHistory struct {
 // FramesHistorySize for ADR
 const FramesHistorySize = 20
 
-// Frame collected for ADR
-type Frame struct {
-	FCnt         uint32  `json:"f_cnt"`
-	SNR          float32 `json:"snr"`
-	GatewayCount uint32  `json:"gw_cnt"`
-}
+// Frame collected for ADR. It is defined in the types package, so that clients can decode the frame history.
+type Frame = types.Frame
 
 func (s *RedisFrameHistory) key() string {
 	return fmt.Sprintf("%s:%s", s.appEUI, s.devEUI)
@@ -51,6 +48,29 @@ func (s *RedisFrameHistory) Push(frame *Frame) error {
 	return s.Trim()
 }
 
+// Merge a Frame into the latest frame in the device's history if it has the same FCnt, keeping the best SNR and the
+// highest gateway count, or push it if it has a different FCnt
+func (s *RedisFrameHistory) Merge(frame *Frame) error {
+	frames, err := s.Get()
+	if err != nil {
+		return err
+	}
+	if len(frames) == 0 || frames[0].FCnt != frame.FCnt {
+		return s.Push(frame)
+	}
+	merged := *frames[0]
+	if frame.SNR > merged.SNR {
+		merged.SNR = frame.SNR
+	}
+	if frame.GatewayCount > merged.GatewayCount {
+		merged.GatewayCount = frame.GatewayCount
+	}
+	if _, err := s.store.Next(s.key()); err != nil {
+		return err
+	}
+	return s.Push(&merged)
+}
+
 // Get the last frames from the device's history
 func (s *RedisFrameHistory) Get() (out []*Frame, err error) {
 	frames, err := s.store.GetFront(s.key(), FramesHistorySize)
@@ -67,3 +67,32 @@ func TestFramesStore(t *testing.T) {
 	}
 
 }
+
+func TestFramesMerge(t *testing.T) {
+	a := New(t)
+	store := NewRedisDeviceStore(GetRedisClient(), "networkserver-test-frames-merge")
+
+	appEUI := types.AppEUI{0, 0, 0, 0, 0, 0, 0, 1}
+	devEUI := types.DevEUI{0, 0, 0, 0, 0, 0, 0, 1}
+
+	s, err := store.Frames(appEUI, devEUI)
+	a.So(err, ShouldBeNil)
+
+	defer s.Clear()
+
+	a.So(s.Merge(&Frame{FCnt: 1, SNR: -10.5, GatewayCount: 2}), ShouldBeNil)
+	a.So(s.Merge(&Frame{FCnt: 1, SNR: -5, GatewayCount: 1}), ShouldBeNil)
+
+	frames, err := s.Get()
+	a.So(err, ShouldBeNil)
+	a.So(frames, ShouldHaveLength, 1)
+	a.So(frames[0].SNR, ShouldEqual, -5)
+	a.So(frames[0].GatewayCount, ShouldEqual, 2)
+
+	a.So(s.Merge(&Frame{FCnt: 2, SNR: -7, GatewayCount: 1}), ShouldBeNil)
+
+	frames, err = s.Get()
+	a.So(err, ShouldBeNil)
+	a.So(frames, ShouldHaveLength, 2)
+	a.So(frames[0].FCnt, ShouldEqual, 2)
+}
@@ -4,6 +4,7 @@
 package networkserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"golang.org/x/net/context" // See https://github.com/grpc/grpc-go/issues/711"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 type networkServerManager struct {
@@ -68,6 +70,15 @@ func (n *networkServerManager) GetDevice(ctx context.Context, in *pb_lorawan.Dev
 		lastSeen = dev.LastSeen
 	}
 
+	// The frame history is returned in the response header, as the Device message has no field for it
+	if history, err := n.networkServer.devices.Frames(dev.AppEUI, dev.DevEUI); err == nil {
+		if frames, err := history.Get(); err == nil {
+			if data, err := json.Marshal(frames); err == nil {
+				grpc.SetHeader(ctx, metadata.Pairs(types.FrameHistoryKey, string(data)))
+			}
+		}
+	}
+
 	return &pb_lorawan.Device{
 		AppID:            dev.AppID,
 		AppEUI:           dev.AppEUI,
@@ -88,6 +99,11 @@ func (n *networkServerManager) SetDevice(ctx context.Context, in *pb_lorawan.Dev
 		return nil, errors.Wrap(err, "Invalid Device")
 	}
 
+	suspension, err := suspensionFromIncomingContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	claims, err := n.networkServer.Component.ValidateTTNAuthContext(ctx)
 	if err != nil {
 		return nil, err
@@ -127,6 +143,11 @@ func (n *networkServerManager) SetDevice(ctx context.Context, in *pb_lorawan.Dev
 		dev.NwkSKey = *in.NwkSKey
 	}
 
+	// The suspension is left unchanged if it is not in the request metadata
+	if suspension != nil {
+		dev.Suspension = *suspension
+	}
+
 	err = n.networkServer.devices.Set(dev)
 	if err != nil {
 		return nil, err
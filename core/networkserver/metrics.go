@@ -0,0 +1,21 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package networkserver
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var suspendedUplinksCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "ttn",
+		Subsystem: "networkserver",
+		Name:      "suspended_uplinks_total",
+		Help:      "Total number of dropped uplinks of suspended devices.",
+	},
+)
+
+func init() {
+	prometheus.Register(suspendedUplinksCounter)
+}
@@ -0,0 +1,27 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package networkserver
+
+import (
+	"encoding/json"
+
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"golang.org/x/net/context" // See https://github.com/grpc/grpc-go/issues/711"
+)
+
+// suspensionFromIncomingContext returns the suspension in the request metadata, or nil if the metadata does not
+// contain it. The Handler sends the suspension of the device with every SetDevice request.
+func suspensionFromIncomingContext(ctx context.Context) (*types.Suspension, error) {
+	values := ttnctx.MetadataFromIncomingContext(ctx).Get(types.SuspensionKey)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	suspension := new(types.Suspension)
+	if err := json.Unmarshal([]byte(values[0]), suspension); err != nil {
+		return nil, errors.NewErrInvalidArgument("Suspension", err.Error())
+	}
+	return suspension, nil
+}
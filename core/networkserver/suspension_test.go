@@ -0,0 +1,37 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package networkserver
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/smartystreets/assertions"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestSuspensionFromIncomingContext(t *testing.T) {
+	a := New(t)
+
+	suspension, err := suspensionFromIncomingContext(context.Background())
+	a.So(err, ShouldBeNil)
+	a.So(suspension, ShouldBeNil)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.SuspensionKey, `{"suspended":true,"reason":"unpaid"}`))
+	suspension, err = suspensionFromIncomingContext(ctx)
+	a.So(err, ShouldBeNil)
+	a.So(suspension, ShouldNotBeNil)
+	a.So(suspension.Suspended, ShouldBeTrue)
+	a.So(suspension.Reason, ShouldEqual, "unpaid")
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.SuspensionKey, `{"suspended":false}`))
+	suspension, err = suspensionFromIncomingContext(ctx)
+	a.So(err, ShouldBeNil)
+	a.So(suspension.Suspended, ShouldBeFalse)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.SuspensionKey, "suspended"))
+	_, err = suspensionFromIncomingContext(ctx)
+	a.So(err, ShouldNotBeNil)
+}
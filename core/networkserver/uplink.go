@@ -9,6 +9,8 @@ import (
 	pb_broker "github.com/TheThingsNetwork/api/broker"
 	"github.com/TheThingsNetwork/api/logfields"
 	"github.com/TheThingsNetwork/api/trace"
+	"github.com/TheThingsNetwork/ttn/core/repetition"
+	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/utils/errors"
 )
 
@@ -42,6 +44,13 @@ func (n *networkServer) HandleUplink(message *pb_broker.DeduplicatedUplinkMessag
 		return nil, err
 	}
 
+	// Uplinks of suspended devices are dropped without changing the state of the device
+	if dev.Suspension.Suspended {
+		suspendedUplinksCounter.Inc()
+		ctx.Debug("Dropped uplink of suspended device")
+		return nil, types.ErrDeviceSuspended
+	}
+
 	message.Trace = message.Trace.WithEvent(trace.UpdateStateEvent)
 
 	dev.StartUpdate()
@@ -58,6 +67,15 @@ func (n *networkServer) HandleUplink(message *pb_broker.DeduplicatedUplinkMessag
 	dev.FCntUp = lorawanUplinkMAC.FCnt
 	dev.LastSeen = time.Now()
 
+	// Repetitions of unconfirmed uplinks (NbTrans) only contribute their gateway metadata to ADR
+	if repetition.InTrace(message.Trace) {
+		err = n.handleUplinkRepetitionADR(message, dev)
+		if err != nil {
+			return nil, err
+		}
+		return message, nil
+	}
+
 	// Prepare Downlink
 	message.InitResponseTemplate()
 	lorawanDownlinkMsg := message.ResponseTemplate.Message.InitLoRaWAN()
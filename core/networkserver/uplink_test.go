@@ -13,6 +13,7 @@ import (
 	pb_lorawan "github.com/TheThingsNetwork/api/protocol/lorawan"
 	"github.com/TheThingsNetwork/ttn/core/component"
 	"github.com/TheThingsNetwork/ttn/core/networkserver/device"
+	"github.com/TheThingsNetwork/ttn/core/repetition"
 	"github.com/TheThingsNetwork/ttn/core/types"
 	. "github.com/TheThingsNetwork/ttn/utils/testing"
 	"github.com/brocaar/lorawan"
@@ -125,4 +126,52 @@ func TestHandleUplink(t *testing.T) {
 	dev, _ := ns.devices.Get(appEUI, devEUI)
 	a.So(dev.FCntUp, ShouldEqual, 1)
 	a.So(time.Now().Sub(dev.LastSeen), ShouldBeLessThan, 1*time.Second)
+
+	// Repetition of the uplink
+	message = &pb_broker.DeduplicatedUplinkMessage{
+		AppEUI:  &appEUI,
+		DevEUI:  &devEUI,
+		Payload: bytes,
+		GatewayMetadata: []*pb_gateway.RxMetadata{
+			&pb_gateway.RxMetadata{SNR: 5},
+			&pb_gateway.RxMetadata{SNR: 2},
+		},
+		ProtocolMetadata: pb_protocol.RxMetadata{Protocol: &pb_protocol.RxMetadata_LoRaWAN{
+			LoRaWAN: &pb_lorawan.Metadata{
+				DataRate: "SF7BW125",
+			},
+		}},
+		Trace: repetition.AddToTrace(nil),
+	}
+	res, err = ns.HandleUplink(message)
+	a.So(err, ShouldBeNil)
+	a.So(res.ResponseTemplate, ShouldBeNil)
+
+	// The gateway metadata of the repetition should have been merged into the ADR history
+	frames, _ := ns.devices.Frames(appEUI, devEUI)
+	history, _ := frames.Get()
+	a.So(history, ShouldHaveLength, 1)
+	a.So(history[0].SNR, ShouldEqual, 5)
+	a.So(history[0].GatewayCount, ShouldEqual, 2)
+
+	// Uplinks of suspended devices are dropped
+	dev, _ = ns.devices.Get(appEUI, devEUI)
+	dev.StartUpdate()
+	dev.Suspension = types.Suspension{Suspended: true, Reason: "unpaid"}
+	ns.devices.Set(dev)
+	lastSeen := dev.LastSeen
+	message = &pb_broker.DeduplicatedUplinkMessage{
+		AppEUI:  &appEUI,
+		DevEUI:  &devEUI,
+		Payload: bytes,
+		ProtocolMetadata: pb_protocol.RxMetadata{Protocol: &pb_protocol.RxMetadata_LoRaWAN{
+			LoRaWAN: &pb_lorawan.Metadata{
+				DataRate: "SF7BW125",
+			},
+		}},
+	}
+	_, err = ns.HandleUplink(message)
+	a.So(err, ShouldEqual, types.ErrDeviceSuspended)
+	dev, _ = ns.devices.Get(appEUI, devEUI)
+	a.So(dev.LastSeen.Equal(lastSeen), ShouldBeTrue)
 }
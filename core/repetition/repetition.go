@@ -0,0 +1,35 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+// Package repetition marks uplink messages that the broker recognised as repetitions of an unconfirmed uplink that the
+// device retransmitted because of NbTrans. The networkserver only uses the gateway metadata of repetitions.
+//
+// The gRPC messages between the components are defined in github.com/TheThingsNetwork/api and do not have a field
+// for this, so repetitions are marked with an event in the trace of the uplink message.
+package repetition
+
+import "github.com/TheThingsNetwork/api/trace"
+
+// TraceEvent is the trace event that marks an uplink message as repetition
+const TraceEvent = "uplink repetition"
+
+// AddToTrace returns the trace with an event that marks the uplink message as repetition
+func AddToTrace(t *trace.Trace) *trace.Trace {
+	return t.WithEvent(TraceEvent)
+}
+
+// InTrace returns true if the trace marks the uplink message as repetition
+func InTrace(t *trace.Trace) bool {
+	if t == nil {
+		return false
+	}
+	if t.Event == TraceEvent {
+		return true
+	}
+	for _, parent := range t.Parents {
+		if InTrace(parent) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package repetition
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/api/trace"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestTrace(t *testing.T) {
+	a := New(t)
+
+	a.So(InTrace(nil), ShouldBeFalse)
+
+	var tr *trace.Trace
+	tr = tr.WithEvent(trace.ReceiveEvent)
+	a.So(InTrace(tr), ShouldBeFalse)
+
+	tr = AddToTrace(tr)
+	tr = tr.WithEvent(trace.UpdateStateEvent)
+	a.So(InTrace(tr), ShouldBeTrue)
+}
@@ -83,9 +83,41 @@ func (r *router) HandleDownlink(downlink *pb_broker.DownlinkMessage) (err error)
 	}
 
 	gateway = r.getGateway(downlink.DownlinkOption.GatewayID)
+
+	// Options without a schedule identifier were moved to another time by the broker, to satisfy the scheduling hints
+	// of the downlink
+	if strings.HasSuffix(option.Identifier, ":") {
+		identifier, err = scheduleAt(gateway, downlinkMessage)
+		if err != nil {
+			return err
+		}
+	}
+
 	return gateway.HandleDownlink(identifier, downlinkMessage)
 }
 
+// scheduleAt gets a transmission slot at the timestamp of the downlink on the gateway schedule
+func scheduleAt(gtw *gateway.Gateway, downlink *pb.DownlinkMessage) (identifier string, err error) {
+	timestamp := downlink.GatewayConfiguration.Timestamp
+	if delay := time.Duration(int32(timestamp-gtw.Schedule.GetTimestamp(time.Now()))) * time.Microsecond; delay < gateway.Deadline {
+		return "", errors.NewErrInvalidArgument("Downlink time", "too late for the gateway")
+	}
+	var airtime time.Duration
+	if lorawan := downlink.ProtocolConfiguration.GetLoRaWAN(); lorawan != nil {
+		switch lorawan.Modulation {
+		case pb_lorawan.Modulation_LORA:
+			airtime, _ = toa.ComputeLoRa(uint(len(downlink.Payload)), lorawan.DataRate, lorawan.CodingRate)
+		case pb_lorawan.Modulation_FSK:
+			airtime, _ = toa.ComputeFSK(uint(len(downlink.Payload)), int(lorawan.BitRate))
+		}
+	}
+	identifier, conflicts := gtw.Schedule.GetOption(timestamp, uint32(airtime/time.Microsecond))
+	if conflicts >= 100 {
+		return "", errors.NewErrInvalidArgument("Downlink time", "conflicts with another downlink")
+	}
+	return identifier, nil
+}
+
 // buildDownlinkOption builds a DownlinkOption with default values
 func (r *router) buildDownlinkOption(gatewayID string, band band.FrequencyPlan) *pb_broker.DownlinkOption {
 	dataRate, _ := types.ConvertDataRate(band.DataRates[band.RX2DataRate])
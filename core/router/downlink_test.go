@@ -500,3 +500,48 @@ func TestComputeDownlinkScores(t *testing.T) {
 	a.So(testSubject1Score, ShouldBeGreaterThan, refScore) // Scheduling conflict with RX1
 	a.So(testSubject2Score, ShouldEqual, refScore)         // No scheduling conflicts
 }
+
+func TestHandleDownlinkAtTime(t *testing.T) {
+	a := New(t)
+
+	logger := GetLogger(t, "TestHandleDownlinkAtTime")
+	r := &router{
+		Component: &component.Component{
+			Context: context.Background(),
+			Ctx:     logger,
+			Monitor: monitorclient.NewMonitorClient(),
+		},
+		gateways: map[string]*gateway.Gateway{},
+	}
+	r.InitStatus()
+
+	gtwID := "eui-0102030405060708"
+	gtw := r.getGateway(gtwID)
+	gtw.Schedule.Sync(0)
+	id, _ := gtw.Schedule.GetOption(1000000, 10*1000)
+
+	downlink := func(identifier string, timestamp uint32) *pb_broker.DownlinkMessage {
+		return &pb_broker.DownlinkMessage{
+			Payload: []byte{},
+			DownlinkOption: &pb_broker.DownlinkOption{
+				GatewayID:            gtwID,
+				Identifier:           identifier,
+				GatewayConfiguration: pb_gateway.TxConfiguration{Timestamp: timestamp},
+			},
+		}
+	}
+
+	a.So(r.HandleDownlink(downlink(id, 1000000)), ShouldBeNil)
+
+	// Options without a schedule identifier are scheduled at their time
+	a.So(r.HandleDownlink(downlink("router:", 10)), ShouldNotBeNil)
+	a.So(r.HandleDownlink(downlink("router:", 5000000)), ShouldBeNil)
+
+	downlinkMessage := newReferenceDownlink()
+	downlinkMessage.GatewayConfiguration.Timestamp = 5000000
+	id, err := scheduleAt(gtw, downlinkMessage)
+	a.So(err, ShouldBeNil)
+	timestamp, ok := gtw.Schedule.GetOptionTimestamp(id)
+	a.So(ok, ShouldBeTrue)
+	a.So(timestamp, ShouldEqual, 5000000)
+}
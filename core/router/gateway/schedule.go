@@ -23,8 +23,12 @@ type Schedule interface {
 	fmt.GoStringer
 	// Synchronize the schedule with the gateway timestamp (in microseconds)
 	Sync(timestamp uint32)
+	// Get the gateway timestamp (in microseconds) for a time
+	GetTimestamp(t time.Time) uint32
 	// Get an "option" on a transmission slot at timestamp for the maximum duration of length (both in microseconds)
 	GetOption(timestamp uint32, length uint32) (id string, score uint)
+	// Get the gateway timestamp (in microseconds) of an option
+	GetOptionTimestamp(id string) (timestamp uint32, ok bool)
 	// Schedule a transmission on a slot
 	Schedule(id string, downlink *router_pb.DownlinkMessage) error
 	// Subscribe to downlink messages
@@ -143,6 +147,11 @@ func (s *schedule) Sync(timestamp uint32) {
 	atomic.StoreInt64(&s.offset, time.Now().UnixNano()-int64(timestamp)*1000)
 }
 
+// see interface
+func (s *schedule) GetTimestamp(t time.Time) uint32 {
+	return uint32((t.UnixNano() - atomic.LoadInt64(&s.offset)) / 1000)
+}
+
 // see interface
 func (s *schedule) GetOption(timestamp uint32, length uint32) (id string, score uint) {
 	id = random.String(32)
@@ -160,6 +169,16 @@ func (s *schedule) GetOption(timestamp uint32, length uint32) (id string, score
 	return id, score
 }
 
+// see interface
+func (s *schedule) GetOptionTimestamp(id string) (timestamp uint32, ok bool) {
+	s.RLock()
+	defer s.RUnlock()
+	if item, ok := s.items[id]; ok {
+		return item.timestamp, true
+	}
+	return 0, false
+}
+
 // see interface
 func (s *schedule) Schedule(id string, downlink *router_pb.DownlinkMessage) error {
 	ctx := s.ctx.WithFields(logfields.ForMessage(downlink)).WithFields(ttnlog.Fields{
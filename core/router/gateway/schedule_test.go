@@ -42,6 +42,25 @@ func TestScheduleRealtime(t *testing.T) {
 	a.So(tm.UnixNano(), ShouldAlmostEqual, time.Now().UnixNano()+9*1000, almostEqual)
 }
 
+func TestScheduleGetTimestamp(t *testing.T) {
+	a := New(t)
+	s := &schedule{}
+	s.Sync(1000)
+	a.So(s.GetTimestamp(time.Now().Add(10*time.Millisecond)), ShouldAlmostEqual, 11000, 1000)
+	a.So(s.GetTimestamp(s.realtime(123456789)), ShouldEqual, 123456789)
+}
+
+func TestScheduleGetOptionTimestamp(t *testing.T) {
+	a := New(t)
+	s := NewSchedule(GetLogger(t, "TestScheduleGetOptionTimestamp"))
+	id, _ := s.GetOption(1000000, 10)
+	timestamp, ok := s.GetOptionTimestamp(id)
+	a.So(ok, ShouldBeTrue)
+	a.So(timestamp, ShouldEqual, 1000000)
+	_, ok = s.GetOptionTimestamp("unknown")
+	a.So(ok, ShouldBeFalse)
+}
+
 func buildItems(items ...*scheduledItem) map[string]*scheduledItem {
 	m := make(map[string]*scheduledItem)
 	for idx, item := range items {
@@ -105,6 +105,15 @@ func (r *router) HandleUplink(gatewayID string, uplink *pb.UplinkMessage) (err e
 		return err
 	}
 
+	// The handler uses the frequency plan of the gateway for the scheduling hints of downlinks
+	if lorawan := uplink.ProtocolMetadata.GetLoRaWAN(); lorawan != nil {
+		if status, err := gateway.Status.Get(); err == nil {
+			if frequencyPlan, ok := pb_lorawan.FrequencyPlan_value[status.FrequencyPlan]; ok {
+				lorawan.FrequencyPlan = pb_lorawan.FrequencyPlan(frequencyPlan)
+			}
+		}
+	}
+
 	var downlinkOptions []*pb_broker.DownlinkOption
 	if gateway.Schedule.IsActive() {
 		downlinkOptions = r.buildDownlinkOptions(uplink, false, gateway)
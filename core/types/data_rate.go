@@ -47,6 +47,11 @@ func ConvertDataRate(input band.DataRate) (datr *DataRate, err error) {
 	return
 }
 
+// Slower returns true if the DataRate has a lower bit rate than the other DataRate
+func (datr DataRate) Slower(other DataRate) bool {
+	return datr.Bandwidth<<other.SpreadingFactor < other.Bandwidth<<datr.SpreadingFactor
+}
+
 // Bytes returns the DataRate as a byte slice
 func (datr DataRate) Bytes() []byte {
 	return []byte(datr.String())
@@ -84,3 +84,15 @@ func TestDataRate(t *testing.T) {
 	a.So(err, ShouldBeNil)
 	a.So(*uOut, ShouldResemble, datr)
 }
+
+func TestDataRateSlower(t *testing.T) {
+	a := New(t)
+	sf7, _ := ParseDataRate("SF7BW125")
+	sf8bw250, _ := ParseDataRate("SF8BW250")
+	sf12, _ := ParseDataRate("SF12BW125")
+	a.So(sf12.Slower(*sf7), ShouldBeTrue)
+	a.So(sf7.Slower(*sf12), ShouldBeFalse)
+	a.So(sf7.Slower(*sf7), ShouldBeFalse)
+	a.So(sf7.Slower(*sf8bw250), ShouldBeFalse)
+	a.So(sf8bw250.Slower(*sf7), ShouldBeFalse)
+}
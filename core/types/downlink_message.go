@@ -3,6 +3,12 @@
 
 package types
 
+import (
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+)
+
 // ScheduleType can be "replace" (default), "first", "last"
 type ScheduleType string
 
@@ -22,4 +28,26 @@ type DownlinkMessage struct {
 	Schedule      ScheduleType           `json:"schedule,omitempty"` // allowed values: "replace" (default), "first", "last"
 	PayloadRaw    []byte                 `json:"payload_raw,omitempty"`
 	PayloadFields map[string]interface{} `json:"payload_fields,omitempty"`
+	Hints         *DownlinkHints         `json:"hints,omitempty"`
+}
+
+// DownlinkHints are optional hints for scheduling a downlink message, all fields are optional
+type DownlinkHints struct {
+	GatewayID   string   `json:"gateway_id,omitempty"`    // Send the downlink through this gateway
+	RX2         bool     `json:"rx2,omitempty"`           // Send the downlink in the RX2 window
+	MinDataRate string   `json:"min_data_rate,omitempty"` // Send the downlink at this data rate or faster
+	Time        JSONTime `json:"time,omitempty"`          // Send the downlink at this time instead of in a receive window
+}
+
+// Validate the hints
+func (h DownlinkHints) Validate() error {
+	if h.MinDataRate != "" {
+		if _, err := ParseDataRate(h.MinDataRate); err != nil {
+			return errors.NewErrInvalidArgument("Min DataRate", err.Error())
+		}
+	}
+	if h.RX2 && !time.Time(h.Time).IsZero() {
+		return errors.NewErrInvalidArgument("Downlink Hints", "rx2 and time can not be combined")
+	}
+	return nil
 }
@@ -0,0 +1,19 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestDownlinkHintsValidate(t *testing.T) {
+	a := New(t)
+	a.So(DownlinkHints{}.Validate(), ShouldBeNil)
+	a.So(DownlinkHints{GatewayID: "gtw", RX2: true, MinDataRate: "SF9BW125"}.Validate(), ShouldBeNil)
+	a.So(DownlinkHints{MinDataRate: "DR3"}.Validate(), ShouldNotBeNil)
+	a.So(DownlinkHints{RX2: true, Time: JSONTime(time.Now())}.Validate(), ShouldNotBeNil)
+}
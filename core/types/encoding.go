@@ -0,0 +1,121 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Encoding is the encoding of application messages
+type Encoding string
+
+// Supported encodings
+const (
+	// JSONEncoding encodes messages as JSON. This is the default encoding.
+	JSONEncoding Encoding = "json"
+	// CBOREncoding encodes messages as CBOR (RFC 7049), using the same field names as JSON
+	CBOREncoding Encoding = "cbor"
+	// ProtobufEncoding encodes messages with the protocol buffers schema in messages.proto
+	ProtobufEncoding Encoding = "protobuf"
+)
+
+// Encodings contains all supported encodings
+var Encodings = []Encoding{JSONEncoding, CBOREncoding, ProtobufEncoding}
+
+var contentTypes = map[Encoding]string{
+	JSONEncoding:     "application/json",
+	CBOREncoding:     "application/cbor",
+	ProtobufEncoding: "application/x-protobuf",
+}
+
+// ParseEncoding parses an encoding. An empty string is parsed as JSONEncoding.
+func ParseEncoding(str string) (Encoding, error) {
+	if str == "" {
+		return JSONEncoding, nil
+	}
+	for _, encoding := range Encodings {
+		if string(encoding) == str {
+			return encoding, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown encoding %q", str)
+}
+
+// EncodingForContentType returns the encoding for a MIME content type. An empty content type is JSONEncoding.
+func EncodingForContentType(contentType string) (Encoding, error) {
+	if contentType == "" {
+		return JSONEncoding, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+	for encoding, encodingContentType := range contentTypes {
+		if mediaType == encodingContentType {
+			return encoding, nil
+		}
+	}
+	if mediaType == "application/protobuf" {
+		return ProtobufEncoding, nil
+	}
+	return "", fmt.Errorf("Unsupported content type %q", contentType)
+}
+
+// ContentType returns the MIME content type of the encoding
+func (e Encoding) ContentType() string {
+	if contentType, ok := contentTypes[e]; ok {
+		return contentType
+	}
+	return contentTypes[JSONEncoding]
+}
+
+// Marshal encodes v. The protobuf encoding only supports UplinkMessage, DownlinkMessage and DeviceEvent.
+func (e Encoding) Marshal(v interface{}) ([]byte, error) {
+	switch e {
+	case "", JSONEncoding:
+		return json.Marshal(v)
+	case CBOREncoding:
+		return cborEncMode.Marshal(v)
+	case ProtobufEncoding:
+		return marshalProtobuf(v)
+	}
+	return nil, fmt.Errorf("Unknown encoding %q", e)
+}
+
+// Unmarshal decodes data into v. The protobuf encoding only supports UplinkMessage, DownlinkMessage and
+// DeviceEvent.
+func (e Encoding) Unmarshal(data []byte, v interface{}) error {
+	switch e {
+	case "", JSONEncoding:
+		return json.Unmarshal(data, v)
+	case CBOREncoding:
+		return cborDecMode.Unmarshal(data, v)
+	case ProtobufEncoding:
+		return unmarshalProtobuf(data, v)
+	}
+	return fmt.Errorf("Unknown encoding %q", e)
+}
+
+var (
+	cborEncMode cbor.EncMode
+	cborDecMode cbor.DecMode
+)
+
+func init() {
+	var err error
+	if cborEncMode, err = (cbor.EncOptions{Time: cbor.TimeRFC3339Nano}).EncMode(); err != nil {
+		panic(err)
+	}
+	// Maps are decoded as map[string]interface{}, so that decoded payload fields can be converted to JSON
+	if cborDecMode, err = (cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}).DecMode(); err != nil {
+		panic(err)
+	}
+}
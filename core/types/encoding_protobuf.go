@@ -0,0 +1,354 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/TheThingsNetwork/ttn/core/types/messages"
+)
+
+// The protobuf encoding uses the messages that are generated from messages.proto
+
+func marshalProtobuf(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case UplinkMessage:
+		return marshalProtobuf(&v)
+	case *UplinkMessage:
+		msg, err := uplinkMessageToPb(v)
+		if err != nil {
+			return nil, err
+		}
+		return msg.Marshal()
+	case DownlinkMessage:
+		return marshalProtobuf(&v)
+	case *DownlinkMessage:
+		msg, err := downlinkMessageToPb(v)
+		if err != nil {
+			return nil, err
+		}
+		return msg.Marshal()
+	case DeviceEvent:
+		return marshalProtobuf(&v)
+	case *DeviceEvent:
+		msg, err := deviceEventToPb(v)
+		if err != nil {
+			return nil, err
+		}
+		return msg.Marshal()
+	}
+	return nil, fmt.Errorf("Protobuf encoding is not supported for %T", v)
+}
+
+func unmarshalProtobuf(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *UplinkMessage:
+		var msg pb.UplinkMessage
+		if err := msg.Unmarshal(data); err != nil {
+			return err
+		}
+		return uplinkMessageFromPb(&msg, v)
+	case *DownlinkMessage:
+		var msg pb.DownlinkMessage
+		if err := msg.Unmarshal(data); err != nil {
+			return err
+		}
+		return downlinkMessageFromPb(&msg, v)
+	case *DeviceEvent:
+		var msg pb.DeviceEvent
+		if err := msg.Unmarshal(data); err != nil {
+			return err
+		}
+		return deviceEventFromPb(&msg, v)
+	}
+	return fmt.Errorf("Protobuf encoding is not supported for %T", v)
+}
+
+// timeToPb returns the time in nanoseconds since the Unix epoch, or 0 for the zero time
+func timeToPb(t JSONTime) int64 {
+	if time.Time(t).IsZero() {
+		return 0
+	}
+	return time.Time(t).UnixNano()
+}
+
+func locationMetadataToPb(location *LocationMetadata) *pb.LocationMetadata {
+	return &pb.LocationMetadata{
+		Latitude:         location.Latitude,
+		Longitude:        location.Longitude,
+		Altitude:         location.Altitude,
+		LocationAccuracy: location.Accuracy,
+		LocationSource:   location.Source,
+	}
+}
+
+func locationMetadataFromPb(msg *pb.LocationMetadata, location *LocationMetadata) {
+	if msg == nil {
+		return
+	}
+	location.Latitude = msg.Latitude
+	location.Longitude = msg.Longitude
+	location.Altitude = msg.Altitude
+	location.Accuracy = msg.LocationAccuracy
+	location.Source = msg.LocationSource
+}
+
+func metadataToPb(metadata *Metadata) *pb.Metadata {
+	msg := &pb.Metadata{
+		Time:       timeToPb(metadata.Time),
+		Frequency:  metadata.Frequency,
+		Modulation: metadata.Modulation,
+		DataRate:   metadata.DataRate,
+		BitRate:    metadata.Bitrate,
+		Airtime:    int64(metadata.Airtime),
+		CodingRate: metadata.CodingRate,
+		Location:   locationMetadataToPb(&metadata.LocationMetadata),
+	}
+	for i := range metadata.Gateways {
+		gateway := &metadata.Gateways[i]
+		msg.Gateways = append(msg.Gateways, &pb.GatewayMetadata{
+			GtwId:                  gateway.GtwID,
+			GtwTrusted:             gateway.GtwTrusted,
+			Timestamp:              gateway.Timestamp,
+			FineTimestamp:          gateway.FineTimestamp,
+			FineTimestampEncrypted: gateway.FineTimestampEncrypted,
+			Time:                   timeToPb(gateway.Time),
+			Antenna:                uint32(gateway.Antenna),
+			Channel:                gateway.Channel,
+			Rssi:                   gateway.RSSI,
+			Snr:                    gateway.SNR,
+			RfChain:                gateway.RFChain,
+			Location:               locationMetadataToPb(&gateway.LocationMetadata),
+		})
+	}
+	return msg
+}
+
+func metadataFromPb(msg *pb.Metadata, metadata *Metadata) {
+	if msg == nil {
+		return
+	}
+	metadata.Time = BuildTime(msg.Time)
+	metadata.Frequency = msg.Frequency
+	metadata.Modulation = msg.Modulation
+	metadata.DataRate = msg.DataRate
+	metadata.Bitrate = msg.BitRate
+	metadata.Airtime = time.Duration(msg.Airtime)
+	metadata.CodingRate = msg.CodingRate
+	locationMetadataFromPb(msg.Location, &metadata.LocationMetadata)
+	for _, gtw := range msg.Gateways {
+		gateway := GatewayMetadata{
+			GtwID:                  gtw.GtwId,
+			GtwTrusted:             gtw.GtwTrusted,
+			Timestamp:              gtw.Timestamp,
+			FineTimestamp:          gtw.FineTimestamp,
+			FineTimestampEncrypted: gtw.FineTimestampEncrypted,
+			Time:                   BuildTime(gtw.Time),
+			Antenna:                uint8(gtw.Antenna),
+			Channel:                gtw.Channel,
+			RSSI:                   gtw.Rssi,
+			SNR:                    gtw.Snr,
+			RFChain:                gtw.RfChain,
+		}
+		locationMetadataFromPb(gtw.Location, &gateway.LocationMetadata)
+		metadata.Gateways = append(metadata.Gateways, gateway)
+	}
+}
+
+func uplinkMessageToPb(up *UplinkMessage) (*pb.UplinkMessage, error) {
+	var payloadFields []byte
+	if len(up.PayloadFields) > 0 {
+		var err error
+		if payloadFields, err = json.Marshal(up.PayloadFields); err != nil {
+			return nil, err
+		}
+	}
+	msg := &pb.UplinkMessage{
+		AppId:          up.AppID,
+		DevId:          up.DevID,
+		HardwareSerial: up.HardwareSerial,
+		Port:           uint32(up.FPort),
+		Counter:        up.FCnt,
+		Confirmed:      up.Confirmed,
+		IsRetry:        up.IsRetry,
+		PayloadRaw:     up.PayloadRaw,
+		PayloadFields:  payloadFields,
+		Metadata:       metadataToPb(&up.Metadata),
+		Attributes:     up.Attributes,
+	}
+	if up.LoRaWAN != nil {
+		var err error
+		if msg.Lorawan, err = lorawanMetadataToPb(up.LoRaWAN); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+func uplinkMessageFromPb(msg *pb.UplinkMessage, up *UplinkMessage) error {
+	up.AppID = msg.AppId
+	up.DevID = msg.DevId
+	up.HardwareSerial = msg.HardwareSerial
+	up.FPort = uint8(msg.Port)
+	up.FCnt = msg.Counter
+	up.Confirmed = msg.Confirmed
+	up.IsRetry = msg.IsRetry
+	up.PayloadRaw = msg.PayloadRaw
+	if len(msg.PayloadFields) > 0 {
+		if err := json.Unmarshal(msg.PayloadFields, &up.PayloadFields); err != nil {
+			return err
+		}
+	}
+	metadataFromPb(msg.Metadata, &up.Metadata)
+	if len(msg.Attributes) > 0 {
+		up.Attributes = msg.Attributes
+	}
+	if msg.Lorawan != nil {
+		up.LoRaWAN = new(LoRaWANMetadata)
+		return lorawanMetadataFromPb(msg.Lorawan, up.LoRaWAN)
+	}
+	return nil
+}
+
+func lorawanMetadataToPb(lorawan *LoRaWANMetadata) (*pb.LoRaWANMetadata, error) {
+	msg := &pb.LoRaWANMetadata{
+		Mtype:     lorawan.MType,
+		Adr:       lorawan.ADR,
+		AdrAckReq: lorawan.ADRAckReq,
+		Ack:       lorawan.Ack,
+		FPending:  lorawan.FPending,
+	}
+	if !lorawan.DevAddr.IsEmpty() {
+		msg.DevAddr = lorawan.DevAddr.Bytes()
+	}
+	for _, cmd := range lorawan.FOpts {
+		msg.FOpts = append(msg.FOpts, &pb.MACCommand{Cid: uint32(cmd.CID), Payload: cmd.Payload})
+	}
+	if acked := lorawan.AckedDownlink; acked != nil {
+		msg.AckedDownlink = &pb.AckedDownlink{Counter: acked.FCnt}
+		if acked.Message != nil {
+			var err error
+			if msg.AckedDownlink.Message, err = downlinkMessageToPb(acked.Message); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return msg, nil
+}
+
+func lorawanMetadataFromPb(msg *pb.LoRaWANMetadata, lorawan *LoRaWANMetadata) error {
+	lorawan.MType = msg.Mtype
+	if len(msg.DevAddr) > 0 {
+		if err := lorawan.DevAddr.Unmarshal(msg.DevAddr); err != nil {
+			return err
+		}
+	}
+	lorawan.ADR = msg.Adr
+	lorawan.ADRAckReq = msg.AdrAckReq
+	lorawan.Ack = msg.Ack
+	lorawan.FPending = msg.FPending
+	for _, cmd := range msg.FOpts {
+		lorawan.FOpts = append(lorawan.FOpts, MACCommand{CID: uint8(cmd.Cid), Payload: cmd.Payload})
+	}
+	if acked := msg.AckedDownlink; acked != nil {
+		lorawan.AckedDownlink = &AckedDownlink{FCnt: acked.Counter}
+		if acked.Message != nil {
+			lorawan.AckedDownlink.Message = new(DownlinkMessage)
+			return downlinkMessageFromPb(acked.Message, lorawan.AckedDownlink.Message)
+		}
+	}
+	return nil
+}
+
+func downlinkMessageToPb(down *DownlinkMessage) (*pb.DownlinkMessage, error) {
+	var payloadFields []byte
+	if len(down.PayloadFields) > 0 {
+		var err error
+		if payloadFields, err = json.Marshal(down.PayloadFields); err != nil {
+			return nil, err
+		}
+	}
+	msg := &pb.DownlinkMessage{
+		AppId:         down.AppID,
+		DevId:         down.DevID,
+		Port:          uint32(down.FPort),
+		Confirmed:     down.Confirmed,
+		Schedule:      string(down.Schedule),
+		PayloadRaw:    down.PayloadRaw,
+		PayloadFields: payloadFields,
+	}
+	if hints := down.Hints; hints != nil {
+		msg.Hints = &pb.DownlinkHints{
+			GatewayId:   hints.GatewayID,
+			Rx2:         hints.RX2,
+			MinDataRate: hints.MinDataRate,
+			Time:        timeToPb(hints.Time),
+		}
+	}
+	return msg, nil
+}
+
+func downlinkMessageFromPb(msg *pb.DownlinkMessage, down *DownlinkMessage) error {
+	down.AppID = msg.AppId
+	down.DevID = msg.DevId
+	down.FPort = uint8(msg.Port)
+	down.Confirmed = msg.Confirmed
+	down.Schedule = ScheduleType(msg.Schedule)
+	down.PayloadRaw = msg.PayloadRaw
+	if len(msg.PayloadFields) > 0 {
+		if err := json.Unmarshal(msg.PayloadFields, &down.PayloadFields); err != nil {
+			return err
+		}
+	}
+	if hints := msg.Hints; hints != nil {
+		down.Hints = &DownlinkHints{
+			GatewayID:   hints.GatewayId,
+			RX2:         hints.Rx2,
+			MinDataRate: hints.MinDataRate,
+			Time:        BuildTime(hints.Time),
+		}
+	}
+	return nil
+}
+
+func deviceEventToPb(event *DeviceEvent) (*pb.DeviceEvent, error) {
+	var data []byte
+	if event.Data != nil {
+		var err error
+		if data, err = json.Marshal(event.Data); err != nil {
+			return nil, err
+		}
+	}
+	return &pb.DeviceEvent{
+		AppId: event.AppID,
+		DevId: event.DevID,
+		Event: string(event.Event),
+		Data:  data,
+	}, nil
+}
+
+// deviceEventFromPb decodes the event data into the type that belongs to the event type, if it has one
+func deviceEventFromPb(msg *pb.DeviceEvent, event *DeviceEvent) error {
+	event.AppID = msg.AppId
+	event.DevID = msg.DevId
+	event.Event = EventType(msg.Event)
+	if msg.Data == nil {
+		return nil
+	}
+	if typed := event.Event.Data(); typed != nil {
+		if err := json.Unmarshal(msg.Data, typed); err != nil {
+			return err
+		}
+		event.Data = typed
+		return nil
+	}
+	var generic interface{}
+	if err := json.Unmarshal(msg.Data, &generic); err != nil {
+		return err
+	}
+	event.Data = generic
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestParseEncoding(t *testing.T) {
+	a := New(t)
+
+	for str, expected := range map[string]Encoding{
+		"":         JSONEncoding,
+		"json":     JSONEncoding,
+		"cbor":     CBOREncoding,
+		"protobuf": ProtobufEncoding,
+	} {
+		encoding, err := ParseEncoding(str)
+		a.So(err, ShouldBeNil)
+		a.So(encoding, ShouldEqual, expected)
+	}
+
+	_, err := ParseEncoding("xml")
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestEncodingContentType(t *testing.T) {
+	a := New(t)
+
+	for _, encoding := range Encodings {
+		parsed, err := EncodingForContentType(encoding.ContentType())
+		a.So(err, ShouldBeNil)
+		a.So(parsed, ShouldEqual, encoding)
+	}
+
+	encoding, err := EncodingForContentType("")
+	a.So(err, ShouldBeNil)
+	a.So(encoding, ShouldEqual, JSONEncoding)
+
+	encoding, err = EncodingForContentType("application/json; charset=utf-8")
+	a.So(err, ShouldBeNil)
+	a.So(encoding, ShouldEqual, JSONEncoding)
+
+	_, err = EncodingForContentType("text/plain")
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestEncodingUplink(t *testing.T) {
+	up := UplinkMessage{
+		AppID:          "app",
+		DevID:          "dev",
+		HardwareSerial: "0102030405060708",
+		FPort:          1,
+		FCnt:           42,
+		Confirmed:      true,
+		PayloadRaw:     []byte{0x01, 0x02},
+		PayloadFields: map[string]interface{}{
+			"temperature": 21.5,
+			"nested":      map[string]interface{}{"on": true},
+		},
+		Metadata: Metadata{
+			Time:       BuildTime(1465831736000000000),
+			Frequency:  868.1,
+			Modulation: "LORA",
+			DataRate:   "SF7BW125",
+			Airtime:    41 * time.Millisecond,
+			CodingRate: "4/5",
+			Gateways: []GatewayMetadata{
+				{GtwID: "gtw", Timestamp: 12345, Channel: 1, RSSI: -42, SNR: 7.5, LocationMetadata: LocationMetadata{Latitude: 52.5, Altitude: -5}},
+				{GtwID: "other", Time: BuildTime(1465831736000001000)},
+			},
+			LocationMetadata: LocationMetadata{Latitude: 52.3, Longitude: 4.9, Source: "registry"},
+		},
+		Attributes: map[string]string{"foo": "bar", "baz": "qux"},
+		LoRaWAN: &LoRaWANMetadata{
+			MType:   "CONFIRMED_UP",
+			DevAddr: DevAddr{0x26, 0x01, 0x12, 0x34},
+			ADR:     true,
+			Ack:     true,
+			FOpts:   []MACCommand{{CID: 0x02}, {CID: 0x03, Payload: []byte{0x07}}},
+			AckedDownlink: &AckedDownlink{
+				FCnt:    7,
+				Message: &DownlinkMessage{FPort: 1, Confirmed: true, PayloadRaw: []byte{0x01}},
+			},
+		},
+	}
+
+	for _, encoding := range Encodings {
+		t.Run(string(encoding), func(t *testing.T) {
+			a := New(t)
+			data, err := encoding.Marshal(up)
+			a.So(err, ShouldBeNil)
+			var decoded UplinkMessage
+			a.So(encoding.Unmarshal(data, &decoded), ShouldBeNil)
+			a.So(decoded.AppID, ShouldEqual, up.AppID)
+			a.So(decoded.HardwareSerial, ShouldEqual, up.HardwareSerial)
+			a.So(decoded.FCnt, ShouldEqual, up.FCnt)
+			a.So(decoded.Confirmed, ShouldBeTrue)
+			a.So(decoded.PayloadRaw, ShouldResemble, up.PayloadRaw)
+			a.So(decoded.PayloadFields["temperature"], ShouldEqual, 21.5)
+			a.So(decoded.PayloadFields["nested"], ShouldResemble, map[string]interface{}{"on": true})
+			a.So(time.Time(decoded.Metadata.Time).Equal(time.Time(up.Metadata.Time)), ShouldBeTrue)
+			a.So(decoded.Metadata.Frequency, ShouldEqual, up.Metadata.Frequency)
+			a.So(decoded.Metadata.Airtime, ShouldEqual, up.Metadata.Airtime)
+			a.So(decoded.Metadata.LocationMetadata, ShouldResemble, up.Metadata.LocationMetadata)
+			a.So(decoded.Metadata.Gateways, ShouldHaveLength, 2)
+			a.So(decoded.Metadata.Gateways[0].RSSI, ShouldEqual, -42)
+			a.So(decoded.Metadata.Gateways[0].LocationMetadata, ShouldResemble, up.Metadata.Gateways[0].LocationMetadata)
+			a.So(time.Time(decoded.Metadata.Gateways[1].Time).Equal(time.Time(up.Metadata.Gateways[1].Time)), ShouldBeTrue)
+			a.So(decoded.Attributes, ShouldResemble, up.Attributes)
+			a.So(decoded.LoRaWAN, ShouldResemble, up.LoRaWAN)
+		})
+	}
+}
+
+func TestEncodingDownlink(t *testing.T) {
+	down := DownlinkMessage{
+		AppID:         "app",
+		DevID:         "dev",
+		FPort:         2,
+		Confirmed:     true,
+		Schedule:      ScheduleLast,
+		PayloadRaw:    []byte{0xaa},
+		PayloadFields: map[string]interface{}{"led": "on"},
+		Hints: &DownlinkHints{
+			GatewayID:   "gtw",
+			MinDataRate: "SF9BW125",
+			Time:        BuildTime(1465831736000000000),
+		},
+	}
+
+	for _, encoding := range Encodings {
+		t.Run(string(encoding), func(t *testing.T) {
+			a := New(t)
+			data, err := encoding.Marshal(&down)
+			a.So(err, ShouldBeNil)
+			var decoded DownlinkMessage
+			a.So(encoding.Unmarshal(data, &decoded), ShouldBeNil)
+			a.So(decoded, ShouldResemble, down)
+		})
+	}
+}
+
+func TestEncodingDeviceEvent(t *testing.T) {
+	a := New(t)
+
+	event := DeviceEvent{
+		AppID: "app",
+		DevID: "dev",
+		Event: UplinkErrorEvent,
+		Data:  ErrorEventData{Error: "broken"},
+	}
+	data, err := ProtobufEncoding.Marshal(event)
+	a.So(err, ShouldBeNil)
+	var decoded DeviceEvent
+	a.So(ProtobufEncoding.Unmarshal(data, &decoded), ShouldBeNil)
+	a.So(decoded.Event, ShouldEqual, UplinkErrorEvent)
+	a.So(decoded.Data, ShouldResemble, &ErrorEventData{Error: "broken"})
+
+	_, err = ProtobufEncoding.Marshal(map[string]interface{}{"foo": "bar"})
+	a.So(err, ShouldNotBeNil)
+
+	a.So(ProtobufEncoding.Unmarshal([]byte{0x0a, 0x10}, &decoded), ShouldNotBeNil)
+}
@@ -17,8 +17,17 @@ const (
 	DownlinkErrorEvent     EventType = "down/errors"
 	DownlinkAckEvent       EventType = "down/acks"
 
-	ActivationEvent      EventType = "activations"
-	ActivationErrorEvent EventType = "activations/errors"
+	ActivationEvent          EventType = "activations"
+	ActivationErrorEvent     EventType = "activations/errors"
+	ActivationThrottledEvent EventType = "activations/throttled"
+
+	LocationUpdatedEvent EventType = "location/updated"
+
+	GeofenceEnterEvent EventType = "geofence/enter"
+	GeofenceExitEvent  EventType = "geofence/exit"
+
+	SuspendedEvent EventType = "suspended"
+	ResumedEvent   EventType = "resumed"
 
 	CreateEvent EventType = "create"
 	UpdateEvent EventType = "update"
@@ -32,8 +41,14 @@ func (e EventType) Data() interface{} {
 		return new(ErrorEventData)
 	case DownlinkScheduledEvent, DownlinkSentEvent, DownlinkErrorEvent, DownlinkAckEvent:
 		return new(DownlinkEventData)
-	case ActivationEvent, ActivationErrorEvent:
+	case ActivationEvent, ActivationErrorEvent, ActivationThrottledEvent:
 		return new(ActivationEventData)
+	case LocationUpdatedEvent:
+		return new(LocationEventData)
+	case GeofenceEnterEvent, GeofenceExitEvent:
+		return new(GeofenceEventData)
+	case SuspendedEvent, ResumedEvent:
+		return new(Suspension)
 	case CreateEvent, UpdateEvent, DeleteEvent:
 		return nil
 	}
@@ -50,7 +65,14 @@ type DeviceEvent struct {
 
 // ErrorEventData is added to error events
 type ErrorEventData struct {
-	Error string `json:"error,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError is a payload field that does not match the schema of the application
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
 }
 
 // ActivationEventData is added to activation events
@@ -62,6 +84,22 @@ type ActivationEventData struct {
 	Metadata Metadata `json:"metadata"`
 }
 
+// LocationEventData is added to location events
+type LocationEventData struct {
+	Latitude  float32 `json:"latitude"`
+	Longitude float32 `json:"longitude"`
+	Altitude  int32   `json:"altitude,omitempty"`
+	Accuracy  int32   `json:"accuracy,omitempty"` // Accuracy in meters
+	Distance  float64 `json:"distance,omitempty"` // Distance in meters to the previous location
+}
+
+// GeofenceEventData is added to geofence events
+type GeofenceEventData struct {
+	Geofence  string  `json:"geofence"`
+	Latitude  float32 `json:"latitude"`
+	Longitude float32 `json:"longitude"`
+}
+
 // DownlinkEventConfigInfo contains configuration information for a downlink message, all fields are optional
 type DownlinkEventConfigInfo struct {
 	Modulation string        `json:"modulation,omitempty"`
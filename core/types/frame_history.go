@@ -0,0 +1,15 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+// FrameHistoryKey is the gRPC metadata key of the JSON-encoded recent frames of a device, which the NetworkServer keeps
+// for ADR. The NetworkServer returns them in the header of GetDevice responses, and the Broker forwards them.
+const FrameHistoryKey = "frame-history"
+
+// Frame is a frame in the frame history of a device
+type Frame struct {
+	FCnt         uint32  `json:"f_cnt"`
+	SNR          float32 `json:"snr"`
+	GatewayCount uint32  `json:"gw_cnt"`
+}
@@ -9,6 +9,8 @@ replace github.com/brocaar/lorawan => github.com/ThethingsIndustries/legacy-lora
 require (
 	github.com/TheThingsNetwork/ttn/utils/errors v0.0.0-20200807123328-b39cc6b19c87
 	github.com/brocaar/lorawan v0.0.0-20200726141338-ee070f85d494
+	github.com/fxamacker/cbor/v2 v2.4.0
+	github.com/gogo/protobuf v1.3.1
 	github.com/gopherjs/gopherjs v0.0.0-20200217142428-fce0ec30dd00 // indirect
 	github.com/jacobsa/crypto v0.0.0-20190317225127-9f44e2d11115 // indirect
 	github.com/jacobsa/oglematchers v0.0.0-20150720000706-141901ea67cd // indirect
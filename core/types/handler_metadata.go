@@ -0,0 +1,34 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+// The messages and services of the ApplicationManager of the Handler are defined in the external api repository, and
+// have no fields for a number of Handler features. These features are therefore set with the following keys in the
+// gRPC request metadata, and where they are stored, returned with the same keys in the response header:
+//
+//   - GetApplication and SetApplication: the payload fields schemas, the location fields, the geofences, the claimable
+//     devices, the encoding and the LoRaWAN metadata option of the application.
+//   - GetDevice and SetDevice: the suspension of the device (SuspensionKey), the claim code that is used to claim a
+//     device, and the device that is moved to the application of the request. GetDevice also returns the number of
+//     queued downlinks.
+//   - DryUplink: the state of the Decoder, which is returned after the dry run.
+//
+// Values that are not strings are encoded as JSON. Settings that are not in the request metadata are left unchanged.
+const (
+	UplinkFieldsSchemaKey        = "uplink-fields-schema-bin"
+	DownlinkFieldsSchemaKey      = "downlink-fields-schema-bin"
+	ForwardRawOnInvalidFieldsKey = "forward-raw-on-invalid-fields"
+	LocationFieldsKey            = "location-fields"
+	GeofencesKey                 = "geofences"
+	ClaimableDevicesKey          = "claimable-devices"
+	EncodingKey                  = "encoding"
+	LoRaWANMetadataKey           = "lorawan-metadata"
+
+	ClaimCodeKey     = "claim-code"
+	MoveFromAppIDKey = "move-from-app-id"
+	MoveFromDevIDKey = "move-from-dev-id"
+	DownlinkQueueKey = "downlink-queue"
+
+	DryUplinkStateKey = "payload-state"
+)
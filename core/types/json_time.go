@@ -38,3 +38,21 @@ func BuildTime(unixNano int64) JSONTime {
 	}
 	return JSONTime(time.Unix(0, 0).Add(time.Duration(unixNano)).UTC())
 }
+
+// MarshalCBOR implements the cbor.Marshaler interface
+func (t JSONTime) MarshalCBOR() ([]byte, error) {
+	text, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return cborEncMode.Marshal(string(text))
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface
+func (t *JSONTime) UnmarshalCBOR(data []byte) error {
+	var text string
+	if err := cborDecMode.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(text))
+}
@@ -0,0 +1,28 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+// LoRaWANMetadata contains the LoRaWAN MAC header and frame header of an uplink message
+type LoRaWANMetadata struct {
+	MType         string         `json:"mtype"`
+	DevAddr       DevAddr        `json:"dev_addr"`
+	ADR           bool           `json:"adr"`
+	ADRAckReq     bool           `json:"adr_ack_req"`
+	Ack           bool           `json:"ack"`
+	FPending      bool           `json:"f_pending"`
+	FOpts         []MACCommand   `json:"f_opts,omitempty"`
+	AckedDownlink *AckedDownlink `json:"acked_downlink,omitempty"`
+}
+
+// MACCommand is a MAC command that was piggybacked in the FOpts of a message
+type MACCommand struct {
+	CID     uint8  `json:"cid"`
+	Payload []byte `json:"payload,omitempty"`
+}
+
+// AckedDownlink is the confirmed downlink that is acknowledged by an uplink message
+type AckedDownlink struct {
+	FCnt    uint32           `json:"counter"`
+	Message *DownlinkMessage `json:"message,omitempty"`
+}
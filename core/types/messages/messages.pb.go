@@ -0,0 +1,5643 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: github.com/TheThingsNetwork/ttn/core/types/messages.proto
+
+package messages
+
+import (
+	bytes "bytes"
+	encoding_binary "encoding/binary"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strings "strings"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type LocationMetadata struct {
+	Latitude             float32  `protobuf:"fixed32,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude            float32  `protobuf:"fixed32,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Altitude             int32    `protobuf:"varint,3,opt,name=altitude,proto3" json:"altitude,omitempty"`
+	LocationAccuracy     int32    `protobuf:"varint,4,opt,name=location_accuracy,json=locationAccuracy,proto3" json:"location_accuracy,omitempty"`
+	LocationSource       string   `protobuf:"bytes,5,opt,name=location_source,json=locationSource,proto3" json:"location_source,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LocationMetadata) Reset()      { *m = LocationMetadata{} }
+func (*LocationMetadata) ProtoMessage() {}
+func (*LocationMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{0}
+}
+func (m *LocationMetadata) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LocationMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LocationMetadata.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LocationMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LocationMetadata.Merge(m, src)
+}
+func (m *LocationMetadata) XXX_Size() int {
+	return m.Size()
+}
+func (m *LocationMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_LocationMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LocationMetadata proto.InternalMessageInfo
+
+func (m *LocationMetadata) GetLatitude() float32 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
+}
+
+func (m *LocationMetadata) GetLongitude() float32 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
+}
+
+func (m *LocationMetadata) GetAltitude() int32 {
+	if m != nil {
+		return m.Altitude
+	}
+	return 0
+}
+
+func (m *LocationMetadata) GetLocationAccuracy() int32 {
+	if m != nil {
+		return m.LocationAccuracy
+	}
+	return 0
+}
+
+func (m *LocationMetadata) GetLocationSource() string {
+	if m != nil {
+		return m.LocationSource
+	}
+	return ""
+}
+
+type GatewayMetadata struct {
+	GtwId                  string `protobuf:"bytes,1,opt,name=gtw_id,json=gtwId,proto3" json:"gtw_id,omitempty"`
+	GtwTrusted             bool   `protobuf:"varint,2,opt,name=gtw_trusted,json=gtwTrusted,proto3" json:"gtw_trusted,omitempty"`
+	Timestamp              uint32 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	FineTimestamp          uint64 `protobuf:"varint,4,opt,name=fine_timestamp,json=fineTimestamp,proto3" json:"fine_timestamp,omitempty"`
+	FineTimestampEncrypted []byte `protobuf:"bytes,5,opt,name=fine_timestamp_encrypted,json=fineTimestampEncrypted,proto3" json:"fine_timestamp_encrypted,omitempty"`
+	// Time in nanoseconds since the Unix epoch
+	Time                 int64             `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	Antenna              uint32            `protobuf:"varint,7,opt,name=antenna,proto3" json:"antenna,omitempty"`
+	Channel              uint32            `protobuf:"varint,8,opt,name=channel,proto3" json:"channel,omitempty"`
+	Rssi                 float32           `protobuf:"fixed32,9,opt,name=rssi,proto3" json:"rssi,omitempty"`
+	Snr                  float32           `protobuf:"fixed32,10,opt,name=snr,proto3" json:"snr,omitempty"`
+	RfChain              uint32            `protobuf:"varint,11,opt,name=rf_chain,json=rfChain,proto3" json:"rf_chain,omitempty"`
+	Location             *LocationMetadata `protobuf:"bytes,12,opt,name=location,proto3" json:"location,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *GatewayMetadata) Reset()      { *m = GatewayMetadata{} }
+func (*GatewayMetadata) ProtoMessage() {}
+func (*GatewayMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{1}
+}
+func (m *GatewayMetadata) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GatewayMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GatewayMetadata.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GatewayMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GatewayMetadata.Merge(m, src)
+}
+func (m *GatewayMetadata) XXX_Size() int {
+	return m.Size()
+}
+func (m *GatewayMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_GatewayMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GatewayMetadata proto.InternalMessageInfo
+
+func (m *GatewayMetadata) GetGtwId() string {
+	if m != nil {
+		return m.GtwId
+	}
+	return ""
+}
+
+func (m *GatewayMetadata) GetGtwTrusted() bool {
+	if m != nil {
+		return m.GtwTrusted
+	}
+	return false
+}
+
+func (m *GatewayMetadata) GetTimestamp() uint32 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetFineTimestamp() uint64 {
+	if m != nil {
+		return m.FineTimestamp
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetFineTimestampEncrypted() []byte {
+	if m != nil {
+		return m.FineTimestampEncrypted
+	}
+	return nil
+}
+
+func (m *GatewayMetadata) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetAntenna() uint32 {
+	if m != nil {
+		return m.Antenna
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetChannel() uint32 {
+	if m != nil {
+		return m.Channel
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetRssi() float32 {
+	if m != nil {
+		return m.Rssi
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetSnr() float32 {
+	if m != nil {
+		return m.Snr
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetRfChain() uint32 {
+	if m != nil {
+		return m.RfChain
+	}
+	return 0
+}
+
+func (m *GatewayMetadata) GetLocation() *LocationMetadata {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+type Metadata struct {
+	// Time in nanoseconds since the Unix epoch
+	Time       int64   `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Frequency  float32 `protobuf:"fixed32,2,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	Modulation string  `protobuf:"bytes,3,opt,name=modulation,proto3" json:"modulation,omitempty"`
+	DataRate   string  `protobuf:"bytes,4,opt,name=data_rate,json=dataRate,proto3" json:"data_rate,omitempty"`
+	BitRate    uint32  `protobuf:"varint,5,opt,name=bit_rate,json=bitRate,proto3" json:"bit_rate,omitempty"`
+	// Airtime in nanoseconds
+	Airtime              int64              `protobuf:"varint,6,opt,name=airtime,proto3" json:"airtime,omitempty"`
+	CodingRate           string             `protobuf:"bytes,7,opt,name=coding_rate,json=codingRate,proto3" json:"coding_rate,omitempty"`
+	Gateways             []*GatewayMetadata `protobuf:"bytes,8,rep,name=gateways,proto3" json:"gateways,omitempty"`
+	Location             *LocationMetadata  `protobuf:"bytes,9,opt,name=location,proto3" json:"location,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *Metadata) Reset()      { *m = Metadata{} }
+func (*Metadata) ProtoMessage() {}
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{2}
+}
+func (m *Metadata) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Metadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Metadata.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Metadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Metadata.Merge(m, src)
+}
+func (m *Metadata) XXX_Size() int {
+	return m.Size()
+}
+func (m *Metadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_Metadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Metadata proto.InternalMessageInfo
+
+func (m *Metadata) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Metadata) GetFrequency() float32 {
+	if m != nil {
+		return m.Frequency
+	}
+	return 0
+}
+
+func (m *Metadata) GetModulation() string {
+	if m != nil {
+		return m.Modulation
+	}
+	return ""
+}
+
+func (m *Metadata) GetDataRate() string {
+	if m != nil {
+		return m.DataRate
+	}
+	return ""
+}
+
+func (m *Metadata) GetBitRate() uint32 {
+	if m != nil {
+		return m.BitRate
+	}
+	return 0
+}
+
+func (m *Metadata) GetAirtime() int64 {
+	if m != nil {
+		return m.Airtime
+	}
+	return 0
+}
+
+func (m *Metadata) GetCodingRate() string {
+	if m != nil {
+		return m.CodingRate
+	}
+	return ""
+}
+
+func (m *Metadata) GetGateways() []*GatewayMetadata {
+	if m != nil {
+		return m.Gateways
+	}
+	return nil
+}
+
+func (m *Metadata) GetLocation() *LocationMetadata {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+type UplinkMessage struct {
+	AppId          string `protobuf:"bytes,1,opt,name=app_id,json=appId,proto3" json:"app_id,omitempty"`
+	DevId          string `protobuf:"bytes,2,opt,name=dev_id,json=devId,proto3" json:"dev_id,omitempty"`
+	HardwareSerial string `protobuf:"bytes,3,opt,name=hardware_serial,json=hardwareSerial,proto3" json:"hardware_serial,omitempty"`
+	Port           uint32 `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	Counter        uint32 `protobuf:"varint,5,opt,name=counter,proto3" json:"counter,omitempty"`
+	Confirmed      bool   `protobuf:"varint,6,opt,name=confirmed,proto3" json:"confirmed,omitempty"`
+	IsRetry        bool   `protobuf:"varint,7,opt,name=is_retry,json=isRetry,proto3" json:"is_retry,omitempty"`
+	PayloadRaw     []byte `protobuf:"bytes,8,opt,name=payload_raw,json=payloadRaw,proto3" json:"payload_raw,omitempty"`
+	// JSON encoded object
+	PayloadFields        []byte            `protobuf:"bytes,9,opt,name=payload_fields,json=payloadFields,proto3" json:"payload_fields,omitempty"`
+	Metadata             *Metadata         `protobuf:"bytes,10,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Attributes           map[string]string `protobuf:"bytes,11,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Lorawan              *LoRaWANMetadata  `protobuf:"bytes,12,opt,name=lorawan,proto3" json:"lorawan,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *UplinkMessage) Reset()      { *m = UplinkMessage{} }
+func (*UplinkMessage) ProtoMessage() {}
+func (*UplinkMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{3}
+}
+func (m *UplinkMessage) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *UplinkMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_UplinkMessage.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *UplinkMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UplinkMessage.Merge(m, src)
+}
+func (m *UplinkMessage) XXX_Size() int {
+	return m.Size()
+}
+func (m *UplinkMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_UplinkMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UplinkMessage proto.InternalMessageInfo
+
+func (m *UplinkMessage) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *UplinkMessage) GetDevId() string {
+	if m != nil {
+		return m.DevId
+	}
+	return ""
+}
+
+func (m *UplinkMessage) GetHardwareSerial() string {
+	if m != nil {
+		return m.HardwareSerial
+	}
+	return ""
+}
+
+func (m *UplinkMessage) GetPort() uint32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *UplinkMessage) GetCounter() uint32 {
+	if m != nil {
+		return m.Counter
+	}
+	return 0
+}
+
+func (m *UplinkMessage) GetConfirmed() bool {
+	if m != nil {
+		return m.Confirmed
+	}
+	return false
+}
+
+func (m *UplinkMessage) GetIsRetry() bool {
+	if m != nil {
+		return m.IsRetry
+	}
+	return false
+}
+
+func (m *UplinkMessage) GetPayloadRaw() []byte {
+	if m != nil {
+		return m.PayloadRaw
+	}
+	return nil
+}
+
+func (m *UplinkMessage) GetPayloadFields() []byte {
+	if m != nil {
+		return m.PayloadFields
+	}
+	return nil
+}
+
+func (m *UplinkMessage) GetMetadata() *Metadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *UplinkMessage) GetAttributes() map[string]string {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *UplinkMessage) GetLorawan() *LoRaWANMetadata {
+	if m != nil {
+		return m.Lorawan
+	}
+	return nil
+}
+
+type MACCommand struct {
+	Cid                  uint32   `protobuf:"varint,1,opt,name=cid,proto3" json:"cid,omitempty"`
+	Payload              []byte   `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MACCommand) Reset()      { *m = MACCommand{} }
+func (*MACCommand) ProtoMessage() {}
+func (*MACCommand) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{4}
+}
+func (m *MACCommand) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MACCommand) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MACCommand.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MACCommand) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MACCommand.Merge(m, src)
+}
+func (m *MACCommand) XXX_Size() int {
+	return m.Size()
+}
+func (m *MACCommand) XXX_DiscardUnknown() {
+	xxx_messageInfo_MACCommand.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MACCommand proto.InternalMessageInfo
+
+func (m *MACCommand) GetCid() uint32 {
+	if m != nil {
+		return m.Cid
+	}
+	return 0
+}
+
+func (m *MACCommand) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+type AckedDownlink struct {
+	Counter              uint32           `protobuf:"varint,1,opt,name=counter,proto3" json:"counter,omitempty"`
+	Message              *DownlinkMessage `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *AckedDownlink) Reset()      { *m = AckedDownlink{} }
+func (*AckedDownlink) ProtoMessage() {}
+func (*AckedDownlink) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{5}
+}
+func (m *AckedDownlink) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AckedDownlink) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AckedDownlink.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AckedDownlink) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AckedDownlink.Merge(m, src)
+}
+func (m *AckedDownlink) XXX_Size() int {
+	return m.Size()
+}
+func (m *AckedDownlink) XXX_DiscardUnknown() {
+	xxx_messageInfo_AckedDownlink.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AckedDownlink proto.InternalMessageInfo
+
+func (m *AckedDownlink) GetCounter() uint32 {
+	if m != nil {
+		return m.Counter
+	}
+	return 0
+}
+
+func (m *AckedDownlink) GetMessage() *DownlinkMessage {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+type LoRaWANMetadata struct {
+	Mtype                string         `protobuf:"bytes,1,opt,name=mtype,proto3" json:"mtype,omitempty"`
+	DevAddr              []byte         `protobuf:"bytes,2,opt,name=dev_addr,json=devAddr,proto3" json:"dev_addr,omitempty"`
+	Adr                  bool           `protobuf:"varint,3,opt,name=adr,proto3" json:"adr,omitempty"`
+	AdrAckReq            bool           `protobuf:"varint,4,opt,name=adr_ack_req,json=adrAckReq,proto3" json:"adr_ack_req,omitempty"`
+	Ack                  bool           `protobuf:"varint,5,opt,name=ack,proto3" json:"ack,omitempty"`
+	FPending             bool           `protobuf:"varint,6,opt,name=f_pending,json=fPending,proto3" json:"f_pending,omitempty"`
+	FOpts                []*MACCommand  `protobuf:"bytes,7,rep,name=f_opts,json=fOpts,proto3" json:"f_opts,omitempty"`
+	AckedDownlink        *AckedDownlink `protobuf:"bytes,8,opt,name=acked_downlink,json=ackedDownlink,proto3" json:"acked_downlink,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *LoRaWANMetadata) Reset()      { *m = LoRaWANMetadata{} }
+func (*LoRaWANMetadata) ProtoMessage() {}
+func (*LoRaWANMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{6}
+}
+func (m *LoRaWANMetadata) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LoRaWANMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LoRaWANMetadata.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LoRaWANMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoRaWANMetadata.Merge(m, src)
+}
+func (m *LoRaWANMetadata) XXX_Size() int {
+	return m.Size()
+}
+func (m *LoRaWANMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoRaWANMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoRaWANMetadata proto.InternalMessageInfo
+
+func (m *LoRaWANMetadata) GetMtype() string {
+	if m != nil {
+		return m.Mtype
+	}
+	return ""
+}
+
+func (m *LoRaWANMetadata) GetDevAddr() []byte {
+	if m != nil {
+		return m.DevAddr
+	}
+	return nil
+}
+
+func (m *LoRaWANMetadata) GetAdr() bool {
+	if m != nil {
+		return m.Adr
+	}
+	return false
+}
+
+func (m *LoRaWANMetadata) GetAdrAckReq() bool {
+	if m != nil {
+		return m.AdrAckReq
+	}
+	return false
+}
+
+func (m *LoRaWANMetadata) GetAck() bool {
+	if m != nil {
+		return m.Ack
+	}
+	return false
+}
+
+func (m *LoRaWANMetadata) GetFPending() bool {
+	if m != nil {
+		return m.FPending
+	}
+	return false
+}
+
+func (m *LoRaWANMetadata) GetFOpts() []*MACCommand {
+	if m != nil {
+		return m.FOpts
+	}
+	return nil
+}
+
+func (m *LoRaWANMetadata) GetAckedDownlink() *AckedDownlink {
+	if m != nil {
+		return m.AckedDownlink
+	}
+	return nil
+}
+
+type DownlinkMessage struct {
+	AppId     string `protobuf:"bytes,1,opt,name=app_id,json=appId,proto3" json:"app_id,omitempty"`
+	DevId     string `protobuf:"bytes,2,opt,name=dev_id,json=devId,proto3" json:"dev_id,omitempty"`
+	Port      uint32 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Confirmed bool   `protobuf:"varint,4,opt,name=confirmed,proto3" json:"confirmed,omitempty"`
+	// replace (default), first or last
+	Schedule   string `protobuf:"bytes,5,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	PayloadRaw []byte `protobuf:"bytes,6,opt,name=payload_raw,json=payloadRaw,proto3" json:"payload_raw,omitempty"`
+	// JSON encoded object
+	PayloadFields        []byte         `protobuf:"bytes,7,opt,name=payload_fields,json=payloadFields,proto3" json:"payload_fields,omitempty"`
+	Hints                *DownlinkHints `protobuf:"bytes,8,opt,name=hints,proto3" json:"hints,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *DownlinkMessage) Reset()      { *m = DownlinkMessage{} }
+func (*DownlinkMessage) ProtoMessage() {}
+func (*DownlinkMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{7}
+}
+func (m *DownlinkMessage) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DownlinkMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DownlinkMessage.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DownlinkMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DownlinkMessage.Merge(m, src)
+}
+func (m *DownlinkMessage) XXX_Size() int {
+	return m.Size()
+}
+func (m *DownlinkMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_DownlinkMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DownlinkMessage proto.InternalMessageInfo
+
+func (m *DownlinkMessage) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *DownlinkMessage) GetDevId() string {
+	if m != nil {
+		return m.DevId
+	}
+	return ""
+}
+
+func (m *DownlinkMessage) GetPort() uint32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *DownlinkMessage) GetConfirmed() bool {
+	if m != nil {
+		return m.Confirmed
+	}
+	return false
+}
+
+func (m *DownlinkMessage) GetSchedule() string {
+	if m != nil {
+		return m.Schedule
+	}
+	return ""
+}
+
+func (m *DownlinkMessage) GetPayloadRaw() []byte {
+	if m != nil {
+		return m.PayloadRaw
+	}
+	return nil
+}
+
+func (m *DownlinkMessage) GetPayloadFields() []byte {
+	if m != nil {
+		return m.PayloadFields
+	}
+	return nil
+}
+
+func (m *DownlinkMessage) GetHints() *DownlinkHints {
+	if m != nil {
+		return m.Hints
+	}
+	return nil
+}
+
+type DownlinkHints struct {
+	GatewayId   string `protobuf:"bytes,1,opt,name=gateway_id,json=gatewayId,proto3" json:"gateway_id,omitempty"`
+	Rx2         bool   `protobuf:"varint,2,opt,name=rx2,proto3" json:"rx2,omitempty"`
+	MinDataRate string `protobuf:"bytes,3,opt,name=min_data_rate,json=minDataRate,proto3" json:"min_data_rate,omitempty"`
+	// Time in nanoseconds since the Unix epoch
+	Time                 int64    `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DownlinkHints) Reset()      { *m = DownlinkHints{} }
+func (*DownlinkHints) ProtoMessage() {}
+func (*DownlinkHints) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{8}
+}
+func (m *DownlinkHints) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DownlinkHints) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DownlinkHints.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DownlinkHints) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DownlinkHints.Merge(m, src)
+}
+func (m *DownlinkHints) XXX_Size() int {
+	return m.Size()
+}
+func (m *DownlinkHints) XXX_DiscardUnknown() {
+	xxx_messageInfo_DownlinkHints.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DownlinkHints proto.InternalMessageInfo
+
+func (m *DownlinkHints) GetGatewayId() string {
+	if m != nil {
+		return m.GatewayId
+	}
+	return ""
+}
+
+func (m *DownlinkHints) GetRx2() bool {
+	if m != nil {
+		return m.Rx2
+	}
+	return false
+}
+
+func (m *DownlinkHints) GetMinDataRate() string {
+	if m != nil {
+		return m.MinDataRate
+	}
+	return ""
+}
+
+func (m *DownlinkHints) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+type DeviceEvent struct {
+	AppId string `protobuf:"bytes,1,opt,name=app_id,json=appId,proto3" json:"app_id,omitempty"`
+	DevId string `protobuf:"bytes,2,opt,name=dev_id,json=devId,proto3" json:"dev_id,omitempty"`
+	Event string `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`
+	// JSON encoded event data
+	Data                 []byte   `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeviceEvent) Reset()      { *m = DeviceEvent{} }
+func (*DeviceEvent) ProtoMessage() {}
+func (*DeviceEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bb3581dcec3bc069, []int{9}
+}
+func (m *DeviceEvent) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DeviceEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DeviceEvent.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DeviceEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeviceEvent.Merge(m, src)
+}
+func (m *DeviceEvent) XXX_Size() int {
+	return m.Size()
+}
+func (m *DeviceEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeviceEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeviceEvent proto.InternalMessageInfo
+
+func (m *DeviceEvent) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *DeviceEvent) GetDevId() string {
+	if m != nil {
+		return m.DevId
+	}
+	return ""
+}
+
+func (m *DeviceEvent) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *DeviceEvent) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*LocationMetadata)(nil), "ttn.types.LocationMetadata")
+	proto.RegisterType((*GatewayMetadata)(nil), "ttn.types.GatewayMetadata")
+	proto.RegisterType((*Metadata)(nil), "ttn.types.Metadata")
+	proto.RegisterType((*UplinkMessage)(nil), "ttn.types.UplinkMessage")
+	proto.RegisterMapType((map[string]string)(nil), "ttn.types.UplinkMessage.AttributesEntry")
+	proto.RegisterType((*MACCommand)(nil), "ttn.types.MACCommand")
+	proto.RegisterType((*AckedDownlink)(nil), "ttn.types.AckedDownlink")
+	proto.RegisterType((*LoRaWANMetadata)(nil), "ttn.types.LoRaWANMetadata")
+	proto.RegisterType((*DownlinkMessage)(nil), "ttn.types.DownlinkMessage")
+	proto.RegisterType((*DownlinkHints)(nil), "ttn.types.DownlinkHints")
+	proto.RegisterType((*DeviceEvent)(nil), "ttn.types.DeviceEvent")
+}
+
+func init() {
+	proto.RegisterFile("github.com/TheThingsNetwork/ttn/core/types/messages.proto", fileDescriptor_bb3581dcec3bc069)
+}
+
+var fileDescriptor_bb3581dcec3bc069 = []byte{
+	// 1148 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0x4f, 0x6f, 0x1b, 0x45,
+	0x14, 0xef, 0xfa, 0x4f, 0xbc, 0x7e, 0x8e, 0x93, 0x30, 0xb4, 0x68, 0x49, 0x8b, 0xb1, 0x2c, 0x21,
+	0x2c, 0x81, 0x6c, 0x29, 0xad, 0x20, 0x20, 0x21, 0x64, 0x92, 0x40, 0x23, 0x91, 0x82, 0xa6, 0xa9,
+	0x90, 0xb8, 0xac, 0x26, 0x3b, 0x63, 0x7b, 0xe4, 0xdd, 0xd9, 0xcd, 0xec, 0xd8, 0xae, 0x6f, 0x5c,
+	0x7a, 0xe1, 0x84, 0xf8, 0x14, 0x7c, 0x0a, 0xc4, 0x91, 0x23, 0x47, 0x8e, 0x6d, 0xf8, 0x22, 0x68,
+	0x66, 0x77, 0xd6, 0x6b, 0xd3, 0x03, 0xe9, 0x6d, 0xde, 0xef, 0xbd, 0xb7, 0x33, 0xef, 0xf7, 0x7e,
+	0x6f, 0x66, 0xe1, 0xb3, 0x09, 0x57, 0xd3, 0xf9, 0xd5, 0x20, 0x88, 0xa3, 0xe1, 0xe5, 0x94, 0x5d,
+	0x4e, 0xb9, 0x98, 0xa4, 0x4f, 0x98, 0x5a, 0xc6, 0x72, 0x36, 0x54, 0x4a, 0x0c, 0x83, 0x58, 0xb2,
+	0xa1, 0x5a, 0x25, 0x2c, 0x1d, 0x46, 0x2c, 0x4d, 0xc9, 0x84, 0xa5, 0x83, 0x44, 0xc6, 0x2a, 0x46,
+	0x4d, 0xa5, 0xc4, 0xc0, 0x78, 0x7a, 0xbf, 0x3b, 0x70, 0xf0, 0x6d, 0x1c, 0x10, 0xc5, 0x63, 0x71,
+	0xc1, 0x14, 0xa1, 0x44, 0x11, 0x74, 0x08, 0x6e, 0x48, 0x14, 0x57, 0x73, 0xca, 0x3c, 0xa7, 0xeb,
+	0xf4, 0x2b, 0xb8, 0xb0, 0xd1, 0x03, 0x68, 0x86, 0xb1, 0x98, 0x64, 0xce, 0x8a, 0x71, 0xae, 0x01,
+	0x9d, 0x49, 0xc2, 0x3c, 0xb3, 0xda, 0x75, 0xfa, 0x75, 0x5c, 0xd8, 0xe8, 0x23, 0x78, 0x2b, 0xcc,
+	0x77, 0xf2, 0x49, 0x10, 0xcc, 0x25, 0x09, 0x56, 0x5e, 0xcd, 0x04, 0x1d, 0x58, 0xc7, 0x28, 0xc7,
+	0xd1, 0x87, 0xb0, 0x5f, 0x04, 0xa7, 0xf1, 0x5c, 0x06, 0xcc, 0xab, 0x77, 0x9d, 0x7e, 0x13, 0xef,
+	0x59, 0xf8, 0xa9, 0x41, 0x7b, 0x3f, 0x57, 0x61, 0xff, 0x1b, 0xa2, 0xd8, 0x92, 0xac, 0x8a, 0xf3,
+	0xdf, 0x83, 0x9d, 0x89, 0x5a, 0xfa, 0x9c, 0x9a, 0xd3, 0x37, 0x71, 0x7d, 0xa2, 0x96, 0xe7, 0x14,
+	0xbd, 0x0f, 0x2d, 0x0d, 0x2b, 0x39, 0x4f, 0x15, 0xa3, 0xe6, 0xf0, 0x2e, 0x86, 0x89, 0x5a, 0x5e,
+	0x66, 0x88, 0xae, 0x4d, 0xf1, 0x88, 0xa5, 0x8a, 0x44, 0x89, 0x39, 0x7e, 0x1b, 0xaf, 0x01, 0xf4,
+	0x01, 0xec, 0x8d, 0xb9, 0x60, 0xfe, 0x3a, 0x44, 0x1f, 0xbe, 0x86, 0xdb, 0x1a, 0xbd, 0x2c, 0xc2,
+	0x8e, 0xc1, 0xdb, 0x0c, 0xf3, 0x99, 0x08, 0xe4, 0x2a, 0xd1, 0x5b, 0xea, 0x12, 0x76, 0xf1, 0x3b,
+	0x1b, 0x09, 0x67, 0xd6, 0x8b, 0x10, 0xd4, 0x74, 0x92, 0xb7, 0xd3, 0x75, 0xfa, 0x55, 0x6c, 0xd6,
+	0xc8, 0x83, 0x06, 0x11, 0x8a, 0x09, 0x41, 0xbc, 0x86, 0x39, 0x90, 0x35, 0xb5, 0x27, 0x98, 0x12,
+	0x21, 0x58, 0xe8, 0xb9, 0x99, 0x27, 0x37, 0xf5, 0x77, 0x64, 0x9a, 0x72, 0xaf, 0x69, 0xba, 0x63,
+	0xd6, 0xe8, 0x00, 0xaa, 0xa9, 0x90, 0x1e, 0x18, 0x48, 0x2f, 0xd1, 0xbb, 0xe0, 0xca, 0xb1, 0x1f,
+	0x4c, 0x09, 0x17, 0x5e, 0x2b, 0xfb, 0x80, 0x1c, 0x9f, 0x68, 0x13, 0x7d, 0x0a, 0xae, 0x65, 0xd9,
+	0xdb, 0xed, 0x3a, 0xfd, 0xd6, 0xd1, 0xfd, 0x41, 0x21, 0x99, 0xc1, 0xb6, 0x5c, 0x70, 0x11, 0xdc,
+	0xfb, 0xa3, 0x02, 0x6e, 0xd1, 0x05, 0x5b, 0x8e, 0x53, 0x2a, 0xe7, 0x01, 0x34, 0xc7, 0x92, 0x5d,
+	0xcf, 0x99, 0x08, 0x56, 0x56, 0x3d, 0x05, 0x80, 0x3a, 0x00, 0x51, 0x4c, 0xe7, 0x61, 0xb6, 0x73,
+	0xd5, 0xf4, 0xae, 0x84, 0xa0, 0xfb, 0xd0, 0xd4, 0x5f, 0xf6, 0x25, 0x51, 0xcc, 0x90, 0xdf, 0xc4,
+	0xae, 0x39, 0x01, 0x51, 0x4c, 0xd7, 0x73, 0xc5, 0x55, 0xe6, 0xab, 0x67, 0xf5, 0x5c, 0x71, 0x65,
+	0x5c, 0x9a, 0x44, 0x2e, 0x4b, 0xdc, 0x5a, 0x53, 0x4b, 0x22, 0x88, 0x29, 0x17, 0x93, 0x2c, 0xaf,
+	0x91, 0x6d, 0x99, 0x41, 0x26, 0xf5, 0x13, 0x70, 0x27, 0x99, 0xba, 0x52, 0xcf, 0xed, 0x56, 0xfb,
+	0xad, 0xa3, 0xc3, 0x12, 0x15, 0x5b, 0xc2, 0xc3, 0x45, 0xec, 0x06, 0x85, 0xcd, 0xdb, 0x50, 0xf8,
+	0xa2, 0x06, 0xed, 0x67, 0x49, 0xc8, 0xc5, 0xec, 0x22, 0x1b, 0x5a, 0xad, 0x66, 0x92, 0x24, 0x25,
+	0x35, 0x93, 0x24, 0x39, 0xa7, 0x1a, 0xa6, 0x6c, 0xa1, 0xe1, 0x4a, 0x06, 0x53, 0xb6, 0x38, 0xa7,
+	0x7a, 0x70, 0xa6, 0x44, 0xd2, 0x25, 0x91, 0xcc, 0x4f, 0x99, 0xe4, 0x24, 0xcc, 0x89, 0xdc, 0xb3,
+	0xf0, 0x53, 0x83, 0xea, 0xf6, 0x24, 0xb1, 0x54, 0x86, 0xc7, 0x36, 0x36, 0x6b, 0xa3, 0xa9, 0x78,
+	0x2e, 0x14, 0x93, 0x96, 0xc2, 0xdc, 0xd4, 0x8d, 0x0b, 0x62, 0x31, 0xe6, 0x32, 0x62, 0xd4, 0x90,
+	0xe8, 0xe2, 0x35, 0xa0, 0xb9, 0xe7, 0xa9, 0x2f, 0x99, 0x92, 0x2b, 0xc3, 0xa1, 0x8b, 0x1b, 0x3c,
+	0xc5, 0xda, 0xd4, 0x0c, 0x27, 0x64, 0x15, 0xc6, 0x84, 0xfa, 0x92, 0x2c, 0x8d, 0x54, 0x77, 0x31,
+	0xe4, 0x10, 0x26, 0x4b, 0x3d, 0x56, 0x36, 0x60, 0xcc, 0x59, 0x48, 0x53, 0xc3, 0xd7, 0x2e, 0x6e,
+	0xe7, 0xe8, 0xd7, 0x06, 0x44, 0x43, 0x70, 0xa3, 0x9c, 0x2d, 0xa3, 0xe2, 0xd6, 0xd1, 0xdb, 0x25,
+	0x42, 0xd7, 0x44, 0xda, 0x20, 0xf4, 0x18, 0x80, 0x28, 0x25, 0xf9, 0xd5, 0x5c, 0xb1, 0xd4, 0x6b,
+	0x99, 0xde, 0xf5, 0x4b, 0x29, 0x1b, 0x24, 0x0f, 0x46, 0x45, 0xe8, 0x99, 0x50, 0x72, 0x85, 0x4b,
+	0xb9, 0xe8, 0x11, 0x34, 0xc2, 0x58, 0x92, 0x25, 0xb1, 0xd3, 0x70, 0xb8, 0xd1, 0x4a, 0x4c, 0x7e,
+	0x18, 0x3d, 0x29, 0x0e, 0x60, 0x43, 0x0f, 0xbf, 0x80, 0xfd, 0xad, 0x8f, 0xea, 0x21, 0x9c, 0xb1,
+	0x55, 0xde, 0x46, 0xbd, 0x44, 0x77, 0xa1, 0xbe, 0x20, 0xe1, 0x9c, 0xd9, 0x1e, 0x1a, 0xe3, 0xf3,
+	0xca, 0xb1, 0xd3, 0x3b, 0x06, 0xb8, 0x18, 0x9d, 0x9c, 0xc4, 0x51, 0x44, 0x04, 0xd5, 0x99, 0x41,
+	0x2e, 0x80, 0x36, 0xd6, 0x4b, 0xdd, 0xaa, 0x9c, 0x20, 0x93, 0xbb, 0x8b, 0xad, 0xd9, 0xf3, 0xa1,
+	0x3d, 0x0a, 0x66, 0x8c, 0x9e, 0xc6, 0x4b, 0xa1, 0x4b, 0x2c, 0x77, 0xd5, 0xd9, 0xec, 0xea, 0x23,
+	0x68, 0xe4, 0x4f, 0x83, 0x57, 0xf9, 0x4f, 0x65, 0x36, 0x3f, 0xa7, 0x08, 0xdb, 0xd0, 0xde, 0xaf,
+	0x15, 0xd8, 0xdf, 0x2a, 0x5b, 0x17, 0x12, 0xe9, 0x34, 0xab, 0x51, 0x63, 0x68, 0x5d, 0x68, 0x8d,
+	0x12, 0x4a, 0xa5, 0x3d, 0x25, 0x65, 0x8b, 0x11, 0xa5, 0x52, 0x57, 0x44, 0xa8, 0x34, 0xda, 0x74,
+	0xb1, 0x5e, 0xa2, 0x0e, 0xb4, 0x08, 0x95, 0x3e, 0x09, 0x66, 0xbe, 0x64, 0xd7, 0x46, 0x97, 0x2e,
+	0x6e, 0x12, 0x2a, 0x47, 0xc1, 0x0c, 0xb3, 0x6b, 0x93, 0x11, 0xcc, 0xbc, 0x7a, 0x9e, 0x11, 0xcc,
+	0xf4, 0x7d, 0x30, 0xf6, 0x13, 0x26, 0xf4, 0xb4, 0xe6, 0xa2, 0x74, 0xc7, 0xdf, 0x67, 0x36, 0xfa,
+	0x18, 0x76, 0xc6, 0x7e, 0x9c, 0xa8, 0xd4, 0x6b, 0x98, 0xde, 0xdf, 0x2b, 0xcb, 0xa5, 0x60, 0x16,
+	0xd7, 0xc7, 0xdf, 0x25, 0x2a, 0x45, 0x5f, 0xc2, 0x1e, 0xd1, 0xa4, 0xf9, 0x34, 0xaf, 0xda, 0x28,
+	0xb5, 0x75, 0xe4, 0x95, 0xb2, 0x36, 0x58, 0xc5, 0x6d, 0x52, 0x36, 0x7b, 0x2f, 0x2a, 0xb0, 0xbf,
+	0xc5, 0xd8, 0x2d, 0x27, 0xd7, 0x0e, 0x64, 0xb5, 0x34, 0x90, 0x1b, 0x63, 0x57, 0xdb, 0x1e, 0xbb,
+	0x43, 0x70, 0xd3, 0x60, 0xca, 0xe8, 0x3c, 0xb4, 0xaf, 0x63, 0x61, 0x6f, 0xcf, 0xdd, 0xce, 0xff,
+	0x98, 0xbb, 0xc6, 0xeb, 0xe6, 0x6e, 0x00, 0xf5, 0x29, 0x17, 0x2a, 0x7d, 0x0d, 0x1f, 0xb6, 0xdc,
+	0xc7, 0xda, 0x8f, 0xb3, 0xb0, 0xde, 0x73, 0x68, 0x6f, 0xe0, 0xe8, 0x3d, 0x80, 0xfc, 0x56, 0x5c,
+	0x13, 0xd1, 0xcc, 0x91, 0x73, 0xa3, 0x6c, 0xf9, 0xfc, 0x28, 0x7f, 0x8c, 0xf5, 0x12, 0xf5, 0xa0,
+	0x1d, 0x71, 0xe1, 0xaf, 0x6f, 0xfa, 0xec, 0xfe, 0x6a, 0x45, 0x5c, 0x9c, 0xda, 0xcb, 0xde, 0xbe,
+	0x2d, 0xb5, 0xf5, 0xdb, 0xd2, 0x63, 0xd0, 0x3a, 0x65, 0x0b, 0x1e, 0xb0, 0xb3, 0x05, 0x13, 0xea,
+	0x96, 0xe4, 0xdf, 0x85, 0x3a, 0xd3, 0x69, 0xf9, 0x66, 0x99, 0xa1, 0xb7, 0x31, 0x17, 0x4e, 0xcd,
+	0x30, 0x63, 0xd6, 0x5f, 0x3d, 0xfb, 0xfb, 0x55, 0xe7, 0xce, 0xcb, 0x57, 0x1d, 0xe7, 0xa7, 0x9b,
+	0x8e, 0xf3, 0xdb, 0x4d, 0xc7, 0xf9, 0xf3, 0xa6, 0xe3, 0xfc, 0x75, 0xd3, 0x71, 0x5e, 0xde, 0x74,
+	0x9c, 0x5f, 0xfe, 0xe9, 0xdc, 0xf9, 0xf1, 0xe1, 0x1b, 0xfc, 0x99, 0x5d, 0xed, 0x98, 0x5f, 0xb3,
+	0x87, 0xff, 0x0e, 0x00, 0x4b, 0x0c, 0xfe, 0x80, 0xd7, 0x09, 0x00, 0x00,
+}
+
+func (this *LocationMetadata) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*LocationMetadata)
+	if !ok {
+		that2, ok := that.(LocationMetadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *LocationMetadata")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *LocationMetadata but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *LocationMetadata but is not nil && this == nil")
+	}
+	if this.Latitude != that1.Latitude {
+		return fmt.Errorf("Latitude this(%v) Not Equal that(%v)", this.Latitude, that1.Latitude)
+	}
+	if this.Longitude != that1.Longitude {
+		return fmt.Errorf("Longitude this(%v) Not Equal that(%v)", this.Longitude, that1.Longitude)
+	}
+	if this.Altitude != that1.Altitude {
+		return fmt.Errorf("Altitude this(%v) Not Equal that(%v)", this.Altitude, that1.Altitude)
+	}
+	if this.LocationAccuracy != that1.LocationAccuracy {
+		return fmt.Errorf("LocationAccuracy this(%v) Not Equal that(%v)", this.LocationAccuracy, that1.LocationAccuracy)
+	}
+	if this.LocationSource != that1.LocationSource {
+		return fmt.Errorf("LocationSource this(%v) Not Equal that(%v)", this.LocationSource, that1.LocationSource)
+	}
+	return nil
+}
+func (this *LocationMetadata) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*LocationMetadata)
+	if !ok {
+		that2, ok := that.(LocationMetadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Latitude != that1.Latitude {
+		return false
+	}
+	if this.Longitude != that1.Longitude {
+		return false
+	}
+	if this.Altitude != that1.Altitude {
+		return false
+	}
+	if this.LocationAccuracy != that1.LocationAccuracy {
+		return false
+	}
+	if this.LocationSource != that1.LocationSource {
+		return false
+	}
+	return true
+}
+func (this *GatewayMetadata) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*GatewayMetadata)
+	if !ok {
+		that2, ok := that.(GatewayMetadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *GatewayMetadata")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *GatewayMetadata but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *GatewayMetadata but is not nil && this == nil")
+	}
+	if this.GtwId != that1.GtwId {
+		return fmt.Errorf("GtwId this(%v) Not Equal that(%v)", this.GtwId, that1.GtwId)
+	}
+	if this.GtwTrusted != that1.GtwTrusted {
+		return fmt.Errorf("GtwTrusted this(%v) Not Equal that(%v)", this.GtwTrusted, that1.GtwTrusted)
+	}
+	if this.Timestamp != that1.Timestamp {
+		return fmt.Errorf("Timestamp this(%v) Not Equal that(%v)", this.Timestamp, that1.Timestamp)
+	}
+	if this.FineTimestamp != that1.FineTimestamp {
+		return fmt.Errorf("FineTimestamp this(%v) Not Equal that(%v)", this.FineTimestamp, that1.FineTimestamp)
+	}
+	if !bytes.Equal(this.FineTimestampEncrypted, that1.FineTimestampEncrypted) {
+		return fmt.Errorf("FineTimestampEncrypted this(%v) Not Equal that(%v)", this.FineTimestampEncrypted, that1.FineTimestampEncrypted)
+	}
+	if this.Time != that1.Time {
+		return fmt.Errorf("Time this(%v) Not Equal that(%v)", this.Time, that1.Time)
+	}
+	if this.Antenna != that1.Antenna {
+		return fmt.Errorf("Antenna this(%v) Not Equal that(%v)", this.Antenna, that1.Antenna)
+	}
+	if this.Channel != that1.Channel {
+		return fmt.Errorf("Channel this(%v) Not Equal that(%v)", this.Channel, that1.Channel)
+	}
+	if this.Rssi != that1.Rssi {
+		return fmt.Errorf("Rssi this(%v) Not Equal that(%v)", this.Rssi, that1.Rssi)
+	}
+	if this.Snr != that1.Snr {
+		return fmt.Errorf("Snr this(%v) Not Equal that(%v)", this.Snr, that1.Snr)
+	}
+	if this.RfChain != that1.RfChain {
+		return fmt.Errorf("RfChain this(%v) Not Equal that(%v)", this.RfChain, that1.RfChain)
+	}
+	if !this.Location.Equal(that1.Location) {
+		return fmt.Errorf("Location this(%v) Not Equal that(%v)", this.Location, that1.Location)
+	}
+	return nil
+}
+func (this *GatewayMetadata) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*GatewayMetadata)
+	if !ok {
+		that2, ok := that.(GatewayMetadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.GtwId != that1.GtwId {
+		return false
+	}
+	if this.GtwTrusted != that1.GtwTrusted {
+		return false
+	}
+	if this.Timestamp != that1.Timestamp {
+		return false
+	}
+	if this.FineTimestamp != that1.FineTimestamp {
+		return false
+	}
+	if !bytes.Equal(this.FineTimestampEncrypted, that1.FineTimestampEncrypted) {
+		return false
+	}
+	if this.Time != that1.Time {
+		return false
+	}
+	if this.Antenna != that1.Antenna {
+		return false
+	}
+	if this.Channel != that1.Channel {
+		return false
+	}
+	if this.Rssi != that1.Rssi {
+		return false
+	}
+	if this.Snr != that1.Snr {
+		return false
+	}
+	if this.RfChain != that1.RfChain {
+		return false
+	}
+	if !this.Location.Equal(that1.Location) {
+		return false
+	}
+	return true
+}
+func (this *Metadata) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*Metadata)
+	if !ok {
+		that2, ok := that.(Metadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *Metadata")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *Metadata but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *Metadata but is not nil && this == nil")
+	}
+	if this.Time != that1.Time {
+		return fmt.Errorf("Time this(%v) Not Equal that(%v)", this.Time, that1.Time)
+	}
+	if this.Frequency != that1.Frequency {
+		return fmt.Errorf("Frequency this(%v) Not Equal that(%v)", this.Frequency, that1.Frequency)
+	}
+	if this.Modulation != that1.Modulation {
+		return fmt.Errorf("Modulation this(%v) Not Equal that(%v)", this.Modulation, that1.Modulation)
+	}
+	if this.DataRate != that1.DataRate {
+		return fmt.Errorf("DataRate this(%v) Not Equal that(%v)", this.DataRate, that1.DataRate)
+	}
+	if this.BitRate != that1.BitRate {
+		return fmt.Errorf("BitRate this(%v) Not Equal that(%v)", this.BitRate, that1.BitRate)
+	}
+	if this.Airtime != that1.Airtime {
+		return fmt.Errorf("Airtime this(%v) Not Equal that(%v)", this.Airtime, that1.Airtime)
+	}
+	if this.CodingRate != that1.CodingRate {
+		return fmt.Errorf("CodingRate this(%v) Not Equal that(%v)", this.CodingRate, that1.CodingRate)
+	}
+	if len(this.Gateways) != len(that1.Gateways) {
+		return fmt.Errorf("Gateways this(%v) Not Equal that(%v)", len(this.Gateways), len(that1.Gateways))
+	}
+	for i := range this.Gateways {
+		if !this.Gateways[i].Equal(that1.Gateways[i]) {
+			return fmt.Errorf("Gateways this[%v](%v) Not Equal that[%v](%v)", i, this.Gateways[i], i, that1.Gateways[i])
+		}
+	}
+	if !this.Location.Equal(that1.Location) {
+		return fmt.Errorf("Location this(%v) Not Equal that(%v)", this.Location, that1.Location)
+	}
+	return nil
+}
+func (this *Metadata) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Metadata)
+	if !ok {
+		that2, ok := that.(Metadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Time != that1.Time {
+		return false
+	}
+	if this.Frequency != that1.Frequency {
+		return false
+	}
+	if this.Modulation != that1.Modulation {
+		return false
+	}
+	if this.DataRate != that1.DataRate {
+		return false
+	}
+	if this.BitRate != that1.BitRate {
+		return false
+	}
+	if this.Airtime != that1.Airtime {
+		return false
+	}
+	if this.CodingRate != that1.CodingRate {
+		return false
+	}
+	if len(this.Gateways) != len(that1.Gateways) {
+		return false
+	}
+	for i := range this.Gateways {
+		if !this.Gateways[i].Equal(that1.Gateways[i]) {
+			return false
+		}
+	}
+	if !this.Location.Equal(that1.Location) {
+		return false
+	}
+	return true
+}
+func (this *UplinkMessage) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*UplinkMessage)
+	if !ok {
+		that2, ok := that.(UplinkMessage)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *UplinkMessage")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *UplinkMessage but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *UplinkMessage but is not nil && this == nil")
+	}
+	if this.AppId != that1.AppId {
+		return fmt.Errorf("AppId this(%v) Not Equal that(%v)", this.AppId, that1.AppId)
+	}
+	if this.DevId != that1.DevId {
+		return fmt.Errorf("DevId this(%v) Not Equal that(%v)", this.DevId, that1.DevId)
+	}
+	if this.HardwareSerial != that1.HardwareSerial {
+		return fmt.Errorf("HardwareSerial this(%v) Not Equal that(%v)", this.HardwareSerial, that1.HardwareSerial)
+	}
+	if this.Port != that1.Port {
+		return fmt.Errorf("Port this(%v) Not Equal that(%v)", this.Port, that1.Port)
+	}
+	if this.Counter != that1.Counter {
+		return fmt.Errorf("Counter this(%v) Not Equal that(%v)", this.Counter, that1.Counter)
+	}
+	if this.Confirmed != that1.Confirmed {
+		return fmt.Errorf("Confirmed this(%v) Not Equal that(%v)", this.Confirmed, that1.Confirmed)
+	}
+	if this.IsRetry != that1.IsRetry {
+		return fmt.Errorf("IsRetry this(%v) Not Equal that(%v)", this.IsRetry, that1.IsRetry)
+	}
+	if !bytes.Equal(this.PayloadRaw, that1.PayloadRaw) {
+		return fmt.Errorf("PayloadRaw this(%v) Not Equal that(%v)", this.PayloadRaw, that1.PayloadRaw)
+	}
+	if !bytes.Equal(this.PayloadFields, that1.PayloadFields) {
+		return fmt.Errorf("PayloadFields this(%v) Not Equal that(%v)", this.PayloadFields, that1.PayloadFields)
+	}
+	if !this.Metadata.Equal(that1.Metadata) {
+		return fmt.Errorf("Metadata this(%v) Not Equal that(%v)", this.Metadata, that1.Metadata)
+	}
+	if len(this.Attributes) != len(that1.Attributes) {
+		return fmt.Errorf("Attributes this(%v) Not Equal that(%v)", len(this.Attributes), len(that1.Attributes))
+	}
+	for i := range this.Attributes {
+		if this.Attributes[i] != that1.Attributes[i] {
+			return fmt.Errorf("Attributes this[%v](%v) Not Equal that[%v](%v)", i, this.Attributes[i], i, that1.Attributes[i])
+		}
+	}
+	if !this.Lorawan.Equal(that1.Lorawan) {
+		return fmt.Errorf("Lorawan this(%v) Not Equal that(%v)", this.Lorawan, that1.Lorawan)
+	}
+	return nil
+}
+func (this *UplinkMessage) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*UplinkMessage)
+	if !ok {
+		that2, ok := that.(UplinkMessage)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.AppId != that1.AppId {
+		return false
+	}
+	if this.DevId != that1.DevId {
+		return false
+	}
+	if this.HardwareSerial != that1.HardwareSerial {
+		return false
+	}
+	if this.Port != that1.Port {
+		return false
+	}
+	if this.Counter != that1.Counter {
+		return false
+	}
+	if this.Confirmed != that1.Confirmed {
+		return false
+	}
+	if this.IsRetry != that1.IsRetry {
+		return false
+	}
+	if !bytes.Equal(this.PayloadRaw, that1.PayloadRaw) {
+		return false
+	}
+	if !bytes.Equal(this.PayloadFields, that1.PayloadFields) {
+		return false
+	}
+	if !this.Metadata.Equal(that1.Metadata) {
+		return false
+	}
+	if len(this.Attributes) != len(that1.Attributes) {
+		return false
+	}
+	for i := range this.Attributes {
+		if this.Attributes[i] != that1.Attributes[i] {
+			return false
+		}
+	}
+	if !this.Lorawan.Equal(that1.Lorawan) {
+		return false
+	}
+	return true
+}
+func (this *MACCommand) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*MACCommand)
+	if !ok {
+		that2, ok := that.(MACCommand)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *MACCommand")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *MACCommand but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *MACCommand but is not nil && this == nil")
+	}
+	if this.Cid != that1.Cid {
+		return fmt.Errorf("Cid this(%v) Not Equal that(%v)", this.Cid, that1.Cid)
+	}
+	if !bytes.Equal(this.Payload, that1.Payload) {
+		return fmt.Errorf("Payload this(%v) Not Equal that(%v)", this.Payload, that1.Payload)
+	}
+	return nil
+}
+func (this *MACCommand) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MACCommand)
+	if !ok {
+		that2, ok := that.(MACCommand)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Cid != that1.Cid {
+		return false
+	}
+	if !bytes.Equal(this.Payload, that1.Payload) {
+		return false
+	}
+	return true
+}
+func (this *AckedDownlink) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*AckedDownlink)
+	if !ok {
+		that2, ok := that.(AckedDownlink)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *AckedDownlink")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *AckedDownlink but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *AckedDownlink but is not nil && this == nil")
+	}
+	if this.Counter != that1.Counter {
+		return fmt.Errorf("Counter this(%v) Not Equal that(%v)", this.Counter, that1.Counter)
+	}
+	if !this.Message.Equal(that1.Message) {
+		return fmt.Errorf("Message this(%v) Not Equal that(%v)", this.Message, that1.Message)
+	}
+	return nil
+}
+func (this *AckedDownlink) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*AckedDownlink)
+	if !ok {
+		that2, ok := that.(AckedDownlink)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Counter != that1.Counter {
+		return false
+	}
+	if !this.Message.Equal(that1.Message) {
+		return false
+	}
+	return true
+}
+func (this *LoRaWANMetadata) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*LoRaWANMetadata)
+	if !ok {
+		that2, ok := that.(LoRaWANMetadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *LoRaWANMetadata")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *LoRaWANMetadata but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *LoRaWANMetadata but is not nil && this == nil")
+	}
+	if this.Mtype != that1.Mtype {
+		return fmt.Errorf("Mtype this(%v) Not Equal that(%v)", this.Mtype, that1.Mtype)
+	}
+	if !bytes.Equal(this.DevAddr, that1.DevAddr) {
+		return fmt.Errorf("DevAddr this(%v) Not Equal that(%v)", this.DevAddr, that1.DevAddr)
+	}
+	if this.Adr != that1.Adr {
+		return fmt.Errorf("Adr this(%v) Not Equal that(%v)", this.Adr, that1.Adr)
+	}
+	if this.AdrAckReq != that1.AdrAckReq {
+		return fmt.Errorf("AdrAckReq this(%v) Not Equal that(%v)", this.AdrAckReq, that1.AdrAckReq)
+	}
+	if this.Ack != that1.Ack {
+		return fmt.Errorf("Ack this(%v) Not Equal that(%v)", this.Ack, that1.Ack)
+	}
+	if this.FPending != that1.FPending {
+		return fmt.Errorf("FPending this(%v) Not Equal that(%v)", this.FPending, that1.FPending)
+	}
+	if len(this.FOpts) != len(that1.FOpts) {
+		return fmt.Errorf("FOpts this(%v) Not Equal that(%v)", len(this.FOpts), len(that1.FOpts))
+	}
+	for i := range this.FOpts {
+		if !this.FOpts[i].Equal(that1.FOpts[i]) {
+			return fmt.Errorf("FOpts this[%v](%v) Not Equal that[%v](%v)", i, this.FOpts[i], i, that1.FOpts[i])
+		}
+	}
+	if !this.AckedDownlink.Equal(that1.AckedDownlink) {
+		return fmt.Errorf("AckedDownlink this(%v) Not Equal that(%v)", this.AckedDownlink, that1.AckedDownlink)
+	}
+	return nil
+}
+func (this *LoRaWANMetadata) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*LoRaWANMetadata)
+	if !ok {
+		that2, ok := that.(LoRaWANMetadata)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Mtype != that1.Mtype {
+		return false
+	}
+	if !bytes.Equal(this.DevAddr, that1.DevAddr) {
+		return false
+	}
+	if this.Adr != that1.Adr {
+		return false
+	}
+	if this.AdrAckReq != that1.AdrAckReq {
+		return false
+	}
+	if this.Ack != that1.Ack {
+		return false
+	}
+	if this.FPending != that1.FPending {
+		return false
+	}
+	if len(this.FOpts) != len(that1.FOpts) {
+		return false
+	}
+	for i := range this.FOpts {
+		if !this.FOpts[i].Equal(that1.FOpts[i]) {
+			return false
+		}
+	}
+	if !this.AckedDownlink.Equal(that1.AckedDownlink) {
+		return false
+	}
+	return true
+}
+func (this *DownlinkMessage) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*DownlinkMessage)
+	if !ok {
+		that2, ok := that.(DownlinkMessage)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *DownlinkMessage")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *DownlinkMessage but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *DownlinkMessage but is not nil && this == nil")
+	}
+	if this.AppId != that1.AppId {
+		return fmt.Errorf("AppId this(%v) Not Equal that(%v)", this.AppId, that1.AppId)
+	}
+	if this.DevId != that1.DevId {
+		return fmt.Errorf("DevId this(%v) Not Equal that(%v)", this.DevId, that1.DevId)
+	}
+	if this.Port != that1.Port {
+		return fmt.Errorf("Port this(%v) Not Equal that(%v)", this.Port, that1.Port)
+	}
+	if this.Confirmed != that1.Confirmed {
+		return fmt.Errorf("Confirmed this(%v) Not Equal that(%v)", this.Confirmed, that1.Confirmed)
+	}
+	if this.Schedule != that1.Schedule {
+		return fmt.Errorf("Schedule this(%v) Not Equal that(%v)", this.Schedule, that1.Schedule)
+	}
+	if !bytes.Equal(this.PayloadRaw, that1.PayloadRaw) {
+		return fmt.Errorf("PayloadRaw this(%v) Not Equal that(%v)", this.PayloadRaw, that1.PayloadRaw)
+	}
+	if !bytes.Equal(this.PayloadFields, that1.PayloadFields) {
+		return fmt.Errorf("PayloadFields this(%v) Not Equal that(%v)", this.PayloadFields, that1.PayloadFields)
+	}
+	if !this.Hints.Equal(that1.Hints) {
+		return fmt.Errorf("Hints this(%v) Not Equal that(%v)", this.Hints, that1.Hints)
+	}
+	return nil
+}
+func (this *DownlinkMessage) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DownlinkMessage)
+	if !ok {
+		that2, ok := that.(DownlinkMessage)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.AppId != that1.AppId {
+		return false
+	}
+	if this.DevId != that1.DevId {
+		return false
+	}
+	if this.Port != that1.Port {
+		return false
+	}
+	if this.Confirmed != that1.Confirmed {
+		return false
+	}
+	if this.Schedule != that1.Schedule {
+		return false
+	}
+	if !bytes.Equal(this.PayloadRaw, that1.PayloadRaw) {
+		return false
+	}
+	if !bytes.Equal(this.PayloadFields, that1.PayloadFields) {
+		return false
+	}
+	if !this.Hints.Equal(that1.Hints) {
+		return false
+	}
+	return true
+}
+func (this *DownlinkHints) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*DownlinkHints)
+	if !ok {
+		that2, ok := that.(DownlinkHints)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *DownlinkHints")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *DownlinkHints but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *DownlinkHints but is not nil && this == nil")
+	}
+	if this.GatewayId != that1.GatewayId {
+		return fmt.Errorf("GatewayId this(%v) Not Equal that(%v)", this.GatewayId, that1.GatewayId)
+	}
+	if this.Rx2 != that1.Rx2 {
+		return fmt.Errorf("Rx2 this(%v) Not Equal that(%v)", this.Rx2, that1.Rx2)
+	}
+	if this.MinDataRate != that1.MinDataRate {
+		return fmt.Errorf("MinDataRate this(%v) Not Equal that(%v)", this.MinDataRate, that1.MinDataRate)
+	}
+	if this.Time != that1.Time {
+		return fmt.Errorf("Time this(%v) Not Equal that(%v)", this.Time, that1.Time)
+	}
+	return nil
+}
+func (this *DownlinkHints) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DownlinkHints)
+	if !ok {
+		that2, ok := that.(DownlinkHints)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.GatewayId != that1.GatewayId {
+		return false
+	}
+	if this.Rx2 != that1.Rx2 {
+		return false
+	}
+	if this.MinDataRate != that1.MinDataRate {
+		return false
+	}
+	if this.Time != that1.Time {
+		return false
+	}
+	return true
+}
+func (this *DeviceEvent) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*DeviceEvent)
+	if !ok {
+		that2, ok := that.(DeviceEvent)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *DeviceEvent")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *DeviceEvent but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *DeviceEvent but is not nil && this == nil")
+	}
+	if this.AppId != that1.AppId {
+		return fmt.Errorf("AppId this(%v) Not Equal that(%v)", this.AppId, that1.AppId)
+	}
+	if this.DevId != that1.DevId {
+		return fmt.Errorf("DevId this(%v) Not Equal that(%v)", this.DevId, that1.DevId)
+	}
+	if this.Event != that1.Event {
+		return fmt.Errorf("Event this(%v) Not Equal that(%v)", this.Event, that1.Event)
+	}
+	if !bytes.Equal(this.Data, that1.Data) {
+		return fmt.Errorf("Data this(%v) Not Equal that(%v)", this.Data, that1.Data)
+	}
+	return nil
+}
+func (this *DeviceEvent) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DeviceEvent)
+	if !ok {
+		that2, ok := that.(DeviceEvent)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.AppId != that1.AppId {
+		return false
+	}
+	if this.DevId != that1.DevId {
+		return false
+	}
+	if this.Event != that1.Event {
+		return false
+	}
+	if !bytes.Equal(this.Data, that1.Data) {
+		return false
+	}
+	return true
+}
+func (m *LocationMetadata) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LocationMetadata) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *LocationMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.LocationSource) > 0 {
+		i -= len(m.LocationSource)
+		copy(dAtA[i:], m.LocationSource)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.LocationSource)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.LocationAccuracy != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.LocationAccuracy))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Altitude != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Altitude))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Longitude != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Longitude))))
+		i--
+		dAtA[i] = 0x15
+	}
+	if m.Latitude != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Latitude))))
+		i--
+		dAtA[i] = 0xd
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GatewayMetadata) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GatewayMetadata) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GatewayMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Location != nil {
+		{
+			size, err := m.Location.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintMessages(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x62
+	}
+	if m.RfChain != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.RfChain))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.Snr != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Snr))))
+		i--
+		dAtA[i] = 0x55
+	}
+	if m.Rssi != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Rssi))))
+		i--
+		dAtA[i] = 0x4d
+	}
+	if m.Channel != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Channel))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Antenna != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Antenna))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Time != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Time))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.FineTimestampEncrypted) > 0 {
+		i -= len(m.FineTimestampEncrypted)
+		copy(dAtA[i:], m.FineTimestampEncrypted)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.FineTimestampEncrypted)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.FineTimestamp != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.FineTimestamp))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Timestamp != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Timestamp))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.GtwTrusted {
+		i--
+		if m.GtwTrusted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.GtwId) > 0 {
+		i -= len(m.GtwId)
+		copy(dAtA[i:], m.GtwId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.GtwId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Metadata) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Metadata) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Metadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Location != nil {
+		{
+			size, err := m.Location.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintMessages(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x4a
+	}
+	if len(m.Gateways) > 0 {
+		for iNdEx := len(m.Gateways) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Gateways[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintMessages(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if len(m.CodingRate) > 0 {
+		i -= len(m.CodingRate)
+		copy(dAtA[i:], m.CodingRate)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.CodingRate)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.Airtime != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Airtime))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.BitRate != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.BitRate))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.DataRate) > 0 {
+		i -= len(m.DataRate)
+		copy(dAtA[i:], m.DataRate)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.DataRate)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Modulation) > 0 {
+		i -= len(m.Modulation)
+		copy(dAtA[i:], m.Modulation)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.Modulation)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Frequency != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Frequency))))
+		i--
+		dAtA[i] = 0x15
+	}
+	if m.Time != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Time))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *UplinkMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UplinkMessage) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *UplinkMessage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Lorawan != nil {
+		{
+			size, err := m.Lorawan.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintMessages(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x62
+	}
+	if len(m.Attributes) > 0 {
+		for k := range m.Attributes {
+			v := m.Attributes[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintMessages(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintMessages(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintMessages(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x5a
+		}
+	}
+	if m.Metadata != nil {
+		{
+			size, err := m.Metadata.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintMessages(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x52
+	}
+	if len(m.PayloadFields) > 0 {
+		i -= len(m.PayloadFields)
+		copy(dAtA[i:], m.PayloadFields)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.PayloadFields)))
+		i--
+		dAtA[i] = 0x4a
+	}
+	if len(m.PayloadRaw) > 0 {
+		i -= len(m.PayloadRaw)
+		copy(dAtA[i:], m.PayloadRaw)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.PayloadRaw)))
+		i--
+		dAtA[i] = 0x42
+	}
+	if m.IsRetry {
+		i--
+		if m.IsRetry {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Confirmed {
+		i--
+		if m.Confirmed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Counter != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Counter))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Port != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Port))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.HardwareSerial) > 0 {
+		i -= len(m.HardwareSerial)
+		copy(dAtA[i:], m.HardwareSerial)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.HardwareSerial)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.DevId) > 0 {
+		i -= len(m.DevId)
+		copy(dAtA[i:], m.DevId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.DevId)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.AppId) > 0 {
+		i -= len(m.AppId)
+		copy(dAtA[i:], m.AppId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.AppId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MACCommand) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MACCommand) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MACCommand) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Payload) > 0 {
+		i -= len(m.Payload)
+		copy(dAtA[i:], m.Payload)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.Payload)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Cid != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Cid))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AckedDownlink) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AckedDownlink) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AckedDownlink) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Message != nil {
+		{
+			size, err := m.Message.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintMessages(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Counter != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Counter))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *LoRaWANMetadata) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LoRaWANMetadata) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *LoRaWANMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.AckedDownlink != nil {
+		{
+			size, err := m.AckedDownlink.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintMessages(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x42
+	}
+	if len(m.FOpts) > 0 {
+		for iNdEx := len(m.FOpts) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.FOpts[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintMessages(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
+	if m.FPending {
+		i--
+		if m.FPending {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Ack {
+		i--
+		if m.Ack {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.AdrAckReq {
+		i--
+		if m.AdrAckReq {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Adr {
+		i--
+		if m.Adr {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.DevAddr) > 0 {
+		i -= len(m.DevAddr)
+		copy(dAtA[i:], m.DevAddr)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.DevAddr)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Mtype) > 0 {
+		i -= len(m.Mtype)
+		copy(dAtA[i:], m.Mtype)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.Mtype)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DownlinkMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DownlinkMessage) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DownlinkMessage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Hints != nil {
+		{
+			size, err := m.Hints.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintMessages(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x42
+	}
+	if len(m.PayloadFields) > 0 {
+		i -= len(m.PayloadFields)
+		copy(dAtA[i:], m.PayloadFields)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.PayloadFields)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if len(m.PayloadRaw) > 0 {
+		i -= len(m.PayloadRaw)
+		copy(dAtA[i:], m.PayloadRaw)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.PayloadRaw)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Schedule) > 0 {
+		i -= len(m.Schedule)
+		copy(dAtA[i:], m.Schedule)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.Schedule)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Confirmed {
+		i--
+		if m.Confirmed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Port != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Port))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.DevId) > 0 {
+		i -= len(m.DevId)
+		copy(dAtA[i:], m.DevId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.DevId)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.AppId) > 0 {
+		i -= len(m.AppId)
+		copy(dAtA[i:], m.AppId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.AppId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DownlinkHints) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DownlinkHints) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DownlinkHints) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Time != 0 {
+		i = encodeVarintMessages(dAtA, i, uint64(m.Time))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.MinDataRate) > 0 {
+		i -= len(m.MinDataRate)
+		copy(dAtA[i:], m.MinDataRate)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.MinDataRate)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Rx2 {
+		i--
+		if m.Rx2 {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.GatewayId) > 0 {
+		i -= len(m.GatewayId)
+		copy(dAtA[i:], m.GatewayId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.GatewayId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DeviceEvent) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeviceEvent) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DeviceEvent) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Event) > 0 {
+		i -= len(m.Event)
+		copy(dAtA[i:], m.Event)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.Event)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.DevId) > 0 {
+		i -= len(m.DevId)
+		copy(dAtA[i:], m.DevId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.DevId)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.AppId) > 0 {
+		i -= len(m.AppId)
+		copy(dAtA[i:], m.AppId)
+		i = encodeVarintMessages(dAtA, i, uint64(len(m.AppId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintMessages(dAtA []byte, offset int, v uint64) int {
+	offset -= sovMessages(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *LocationMetadata) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Latitude != 0 {
+		n += 5
+	}
+	if m.Longitude != 0 {
+		n += 5
+	}
+	if m.Altitude != 0 {
+		n += 1 + sovMessages(uint64(m.Altitude))
+	}
+	if m.LocationAccuracy != 0 {
+		n += 1 + sovMessages(uint64(m.LocationAccuracy))
+	}
+	l = len(m.LocationSource)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *GatewayMetadata) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.GtwId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.GtwTrusted {
+		n += 2
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovMessages(uint64(m.Timestamp))
+	}
+	if m.FineTimestamp != 0 {
+		n += 1 + sovMessages(uint64(m.FineTimestamp))
+	}
+	l = len(m.FineTimestampEncrypted)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Time != 0 {
+		n += 1 + sovMessages(uint64(m.Time))
+	}
+	if m.Antenna != 0 {
+		n += 1 + sovMessages(uint64(m.Antenna))
+	}
+	if m.Channel != 0 {
+		n += 1 + sovMessages(uint64(m.Channel))
+	}
+	if m.Rssi != 0 {
+		n += 5
+	}
+	if m.Snr != 0 {
+		n += 5
+	}
+	if m.RfChain != 0 {
+		n += 1 + sovMessages(uint64(m.RfChain))
+	}
+	if m.Location != nil {
+		l = m.Location.Size()
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *Metadata) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Time != 0 {
+		n += 1 + sovMessages(uint64(m.Time))
+	}
+	if m.Frequency != 0 {
+		n += 5
+	}
+	l = len(m.Modulation)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.DataRate)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.BitRate != 0 {
+		n += 1 + sovMessages(uint64(m.BitRate))
+	}
+	if m.Airtime != 0 {
+		n += 1 + sovMessages(uint64(m.Airtime))
+	}
+	l = len(m.CodingRate)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if len(m.Gateways) > 0 {
+		for _, e := range m.Gateways {
+			l = e.Size()
+			n += 1 + l + sovMessages(uint64(l))
+		}
+	}
+	if m.Location != nil {
+		l = m.Location.Size()
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *UplinkMessage) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.AppId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.DevId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.HardwareSerial)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Port != 0 {
+		n += 1 + sovMessages(uint64(m.Port))
+	}
+	if m.Counter != 0 {
+		n += 1 + sovMessages(uint64(m.Counter))
+	}
+	if m.Confirmed {
+		n += 2
+	}
+	if m.IsRetry {
+		n += 2
+	}
+	l = len(m.PayloadRaw)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.PayloadFields)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Metadata != nil {
+		l = m.Metadata.Size()
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if len(m.Attributes) > 0 {
+		for k, v := range m.Attributes {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovMessages(uint64(len(k))) + 1 + len(v) + sovMessages(uint64(len(v)))
+			n += mapEntrySize + 1 + sovMessages(uint64(mapEntrySize))
+		}
+	}
+	if m.Lorawan != nil {
+		l = m.Lorawan.Size()
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *MACCommand) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Cid != 0 {
+		n += 1 + sovMessages(uint64(m.Cid))
+	}
+	l = len(m.Payload)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *AckedDownlink) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Counter != 0 {
+		n += 1 + sovMessages(uint64(m.Counter))
+	}
+	if m.Message != nil {
+		l = m.Message.Size()
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *LoRaWANMetadata) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Mtype)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.DevAddr)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Adr {
+		n += 2
+	}
+	if m.AdrAckReq {
+		n += 2
+	}
+	if m.Ack {
+		n += 2
+	}
+	if m.FPending {
+		n += 2
+	}
+	if len(m.FOpts) > 0 {
+		for _, e := range m.FOpts {
+			l = e.Size()
+			n += 1 + l + sovMessages(uint64(l))
+		}
+	}
+	if m.AckedDownlink != nil {
+		l = m.AckedDownlink.Size()
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *DownlinkMessage) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.AppId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.DevId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Port != 0 {
+		n += 1 + sovMessages(uint64(m.Port))
+	}
+	if m.Confirmed {
+		n += 2
+	}
+	l = len(m.Schedule)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.PayloadRaw)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.PayloadFields)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Hints != nil {
+		l = m.Hints.Size()
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func (m *DownlinkHints) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.GatewayId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Rx2 {
+		n += 2
+	}
+	l = len(m.MinDataRate)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	if m.Time != 0 {
+		n += 1 + sovMessages(uint64(m.Time))
+	}
+	return n
+}
+
+func (m *DeviceEvent) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.AppId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.DevId)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.Event)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovMessages(uint64(l))
+	}
+	return n
+}
+
+func sovMessages(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozMessages(x uint64) (n int) {
+	return sovMessages(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *LocationMetadata) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LocationMetadata{`,
+		`Latitude:` + fmt.Sprintf("%v", this.Latitude) + `,`,
+		`Longitude:` + fmt.Sprintf("%v", this.Longitude) + `,`,
+		`Altitude:` + fmt.Sprintf("%v", this.Altitude) + `,`,
+		`LocationAccuracy:` + fmt.Sprintf("%v", this.LocationAccuracy) + `,`,
+		`LocationSource:` + fmt.Sprintf("%v", this.LocationSource) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *GatewayMetadata) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&GatewayMetadata{`,
+		`GtwId:` + fmt.Sprintf("%v", this.GtwId) + `,`,
+		`GtwTrusted:` + fmt.Sprintf("%v", this.GtwTrusted) + `,`,
+		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
+		`FineTimestamp:` + fmt.Sprintf("%v", this.FineTimestamp) + `,`,
+		`FineTimestampEncrypted:` + fmt.Sprintf("%v", this.FineTimestampEncrypted) + `,`,
+		`Time:` + fmt.Sprintf("%v", this.Time) + `,`,
+		`Antenna:` + fmt.Sprintf("%v", this.Antenna) + `,`,
+		`Channel:` + fmt.Sprintf("%v", this.Channel) + `,`,
+		`Rssi:` + fmt.Sprintf("%v", this.Rssi) + `,`,
+		`Snr:` + fmt.Sprintf("%v", this.Snr) + `,`,
+		`RfChain:` + fmt.Sprintf("%v", this.RfChain) + `,`,
+		`Location:` + strings.Replace(this.Location.String(), "LocationMetadata", "LocationMetadata", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Metadata) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForGateways := "[]*GatewayMetadata{"
+	for _, f := range this.Gateways {
+		repeatedStringForGateways += strings.Replace(f.String(), "GatewayMetadata", "GatewayMetadata", 1) + ","
+	}
+	repeatedStringForGateways += "}"
+	s := strings.Join([]string{`&Metadata{`,
+		`Time:` + fmt.Sprintf("%v", this.Time) + `,`,
+		`Frequency:` + fmt.Sprintf("%v", this.Frequency) + `,`,
+		`Modulation:` + fmt.Sprintf("%v", this.Modulation) + `,`,
+		`DataRate:` + fmt.Sprintf("%v", this.DataRate) + `,`,
+		`BitRate:` + fmt.Sprintf("%v", this.BitRate) + `,`,
+		`Airtime:` + fmt.Sprintf("%v", this.Airtime) + `,`,
+		`CodingRate:` + fmt.Sprintf("%v", this.CodingRate) + `,`,
+		`Gateways:` + repeatedStringForGateways + `,`,
+		`Location:` + strings.Replace(this.Location.String(), "LocationMetadata", "LocationMetadata", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *UplinkMessage) String() string {
+	if this == nil {
+		return "nil"
+	}
+	keysForAttributes := make([]string, 0, len(this.Attributes))
+	for k, _ := range this.Attributes {
+		keysForAttributes = append(keysForAttributes, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForAttributes)
+	mapStringForAttributes := "map[string]string{"
+	for _, k := range keysForAttributes {
+		mapStringForAttributes += fmt.Sprintf("%v: %v,", k, this.Attributes[k])
+	}
+	mapStringForAttributes += "}"
+	s := strings.Join([]string{`&UplinkMessage{`,
+		`AppId:` + fmt.Sprintf("%v", this.AppId) + `,`,
+		`DevId:` + fmt.Sprintf("%v", this.DevId) + `,`,
+		`HardwareSerial:` + fmt.Sprintf("%v", this.HardwareSerial) + `,`,
+		`Port:` + fmt.Sprintf("%v", this.Port) + `,`,
+		`Counter:` + fmt.Sprintf("%v", this.Counter) + `,`,
+		`Confirmed:` + fmt.Sprintf("%v", this.Confirmed) + `,`,
+		`IsRetry:` + fmt.Sprintf("%v", this.IsRetry) + `,`,
+		`PayloadRaw:` + fmt.Sprintf("%v", this.PayloadRaw) + `,`,
+		`PayloadFields:` + fmt.Sprintf("%v", this.PayloadFields) + `,`,
+		`Metadata:` + strings.Replace(this.Metadata.String(), "Metadata", "Metadata", 1) + `,`,
+		`Attributes:` + mapStringForAttributes + `,`,
+		`Lorawan:` + strings.Replace(this.Lorawan.String(), "LoRaWANMetadata", "LoRaWANMetadata", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MACCommand) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MACCommand{`,
+		`Cid:` + fmt.Sprintf("%v", this.Cid) + `,`,
+		`Payload:` + fmt.Sprintf("%v", this.Payload) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *AckedDownlink) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&AckedDownlink{`,
+		`Counter:` + fmt.Sprintf("%v", this.Counter) + `,`,
+		`Message:` + strings.Replace(this.Message.String(), "DownlinkMessage", "DownlinkMessage", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LoRaWANMetadata) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForFOpts := "[]*MACCommand{"
+	for _, f := range this.FOpts {
+		repeatedStringForFOpts += strings.Replace(f.String(), "MACCommand", "MACCommand", 1) + ","
+	}
+	repeatedStringForFOpts += "}"
+	s := strings.Join([]string{`&LoRaWANMetadata{`,
+		`Mtype:` + fmt.Sprintf("%v", this.Mtype) + `,`,
+		`DevAddr:` + fmt.Sprintf("%v", this.DevAddr) + `,`,
+		`Adr:` + fmt.Sprintf("%v", this.Adr) + `,`,
+		`AdrAckReq:` + fmt.Sprintf("%v", this.AdrAckReq) + `,`,
+		`Ack:` + fmt.Sprintf("%v", this.Ack) + `,`,
+		`FPending:` + fmt.Sprintf("%v", this.FPending) + `,`,
+		`FOpts:` + repeatedStringForFOpts + `,`,
+		`AckedDownlink:` + strings.Replace(this.AckedDownlink.String(), "AckedDownlink", "AckedDownlink", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DownlinkMessage) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DownlinkMessage{`,
+		`AppId:` + fmt.Sprintf("%v", this.AppId) + `,`,
+		`DevId:` + fmt.Sprintf("%v", this.DevId) + `,`,
+		`Port:` + fmt.Sprintf("%v", this.Port) + `,`,
+		`Confirmed:` + fmt.Sprintf("%v", this.Confirmed) + `,`,
+		`Schedule:` + fmt.Sprintf("%v", this.Schedule) + `,`,
+		`PayloadRaw:` + fmt.Sprintf("%v", this.PayloadRaw) + `,`,
+		`PayloadFields:` + fmt.Sprintf("%v", this.PayloadFields) + `,`,
+		`Hints:` + strings.Replace(this.Hints.String(), "DownlinkHints", "DownlinkHints", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DownlinkHints) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DownlinkHints{`,
+		`GatewayId:` + fmt.Sprintf("%v", this.GatewayId) + `,`,
+		`Rx2:` + fmt.Sprintf("%v", this.Rx2) + `,`,
+		`MinDataRate:` + fmt.Sprintf("%v", this.MinDataRate) + `,`,
+		`Time:` + fmt.Sprintf("%v", this.Time) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DeviceEvent) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DeviceEvent{`,
+		`AppId:` + fmt.Sprintf("%v", this.AppId) + `,`,
+		`DevId:` + fmt.Sprintf("%v", this.DevId) + `,`,
+		`Event:` + fmt.Sprintf("%v", this.Event) + `,`,
+		`Data:` + fmt.Sprintf("%v", this.Data) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringMessages(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *LocationMetadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LocationMetadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LocationMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Latitude", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Latitude = float32(math.Float32frombits(v))
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Longitude", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Longitude = float32(math.Float32frombits(v))
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Altitude", wireType)
+			}
+			m.Altitude = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Altitude |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LocationAccuracy", wireType)
+			}
+			m.LocationAccuracy = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LocationAccuracy |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LocationSource", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LocationSource = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GatewayMetadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GatewayMetadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GatewayMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GtwId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.GtwId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GtwTrusted", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.GtwTrusted = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FineTimestamp", wireType)
+			}
+			m.FineTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FineTimestamp |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FineTimestampEncrypted", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FineTimestampEncrypted = append(m.FineTimestampEncrypted[:0], dAtA[iNdEx:postIndex]...)
+			if m.FineTimestampEncrypted == nil {
+				m.FineTimestampEncrypted = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			m.Time = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Time |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Antenna", wireType)
+			}
+			m.Antenna = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Antenna |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Channel", wireType)
+			}
+			m.Channel = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Channel |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rssi", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Rssi = float32(math.Float32frombits(v))
+		case 10:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Snr", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Snr = float32(math.Float32frombits(v))
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RfChain", wireType)
+			}
+			m.RfChain = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RfChain |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Location", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Location == nil {
+				m.Location = &LocationMetadata{}
+			}
+			if err := m.Location.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Metadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Metadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Metadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			m.Time = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Time |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Frequency", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Frequency = float32(math.Float32frombits(v))
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Modulation", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Modulation = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataRate", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DataRate = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BitRate", wireType)
+			}
+			m.BitRate = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BitRate |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Airtime", wireType)
+			}
+			m.Airtime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Airtime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CodingRate", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CodingRate = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Gateways", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Gateways = append(m.Gateways, &GatewayMetadata{})
+			if err := m.Gateways[len(m.Gateways)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Location", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Location == nil {
+				m.Location = &LocationMetadata{}
+			}
+			if err := m.Location.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *UplinkMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: UplinkMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: UplinkMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AppId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DevId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DevId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HardwareSerial", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HardwareSerial = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Port", wireType)
+			}
+			m.Port = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Port |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Counter", wireType)
+			}
+			m.Counter = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Counter |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Confirmed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Confirmed = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsRetry", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsRetry = bool(v != 0)
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PayloadRaw", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PayloadRaw = append(m.PayloadRaw[:0], dAtA[iNdEx:postIndex]...)
+			if m.PayloadRaw == nil {
+				m.PayloadRaw = []byte{}
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PayloadFields", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PayloadFields = append(m.PayloadFields[:0], dAtA[iNdEx:postIndex]...)
+			if m.PayloadFields == nil {
+				m.PayloadFields = []byte{}
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metadata == nil {
+				m.Metadata = &Metadata{}
+			}
+			if err := m.Metadata.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Attributes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Attributes == nil {
+				m.Attributes = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMessages
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMessages
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthMessages
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthMessages
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMessages
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthMessages
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthMessages
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipMessages(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthMessages
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Attributes[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Lorawan", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Lorawan == nil {
+				m.Lorawan = &LoRaWANMetadata{}
+			}
+			if err := m.Lorawan.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MACCommand) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MACCommand: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MACCommand: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cid", wireType)
+			}
+			m.Cid = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Cid |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Payload", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Payload = append(m.Payload[:0], dAtA[iNdEx:postIndex]...)
+			if m.Payload == nil {
+				m.Payload = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AckedDownlink) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AckedDownlink: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AckedDownlink: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Counter", wireType)
+			}
+			m.Counter = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Counter |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Message == nil {
+				m.Message = &DownlinkMessage{}
+			}
+			if err := m.Message.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LoRaWANMetadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LoRaWANMetadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LoRaWANMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mtype", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Mtype = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DevAddr", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DevAddr = append(m.DevAddr[:0], dAtA[iNdEx:postIndex]...)
+			if m.DevAddr == nil {
+				m.DevAddr = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Adr", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Adr = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AdrAckReq", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.AdrAckReq = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ack", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Ack = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FPending", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.FPending = bool(v != 0)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FOpts", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FOpts = append(m.FOpts, &MACCommand{})
+			if err := m.FOpts[len(m.FOpts)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AckedDownlink", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AckedDownlink == nil {
+				m.AckedDownlink = &AckedDownlink{}
+			}
+			if err := m.AckedDownlink.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DownlinkMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DownlinkMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DownlinkMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AppId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DevId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DevId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Port", wireType)
+			}
+			m.Port = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Port |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Confirmed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Confirmed = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Schedule", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Schedule = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PayloadRaw", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PayloadRaw = append(m.PayloadRaw[:0], dAtA[iNdEx:postIndex]...)
+			if m.PayloadRaw == nil {
+				m.PayloadRaw = []byte{}
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PayloadFields", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PayloadFields = append(m.PayloadFields[:0], dAtA[iNdEx:postIndex]...)
+			if m.PayloadFields == nil {
+				m.PayloadFields = []byte{}
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hints", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Hints == nil {
+				m.Hints = &DownlinkHints{}
+			}
+			if err := m.Hints.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DownlinkHints) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DownlinkHints: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DownlinkHints: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GatewayId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.GatewayId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rx2", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Rx2 = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinDataRate", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MinDataRate = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			m.Time = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Time |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DeviceEvent) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeviceEvent: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeviceEvent: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AppId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DevId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DevId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Event", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Event = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessages
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessages(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessages
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipMessages(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowMessages
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowMessages
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthMessages
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupMessages
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthMessages
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthMessages        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowMessages          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupMessages = fmt.Errorf("proto: unexpected end of group")
+)
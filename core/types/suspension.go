@@ -0,0 +1,31 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+import "github.com/TheThingsNetwork/ttn/utils/errors"
+
+const (
+	// SuspensionKey is the gRPC metadata key of the JSON-encoded Suspension of a device
+	SuspensionKey = "suspension"
+
+	// SuspensionTokenKey is the gRPC metadata key of the token of the Handler that sets the Suspension of a device. The
+	// Broker only accepts a Suspension from the Handler of the application.
+	SuspensionTokenKey = "suspension-token"
+)
+
+// ErrDeviceSuspended is returned for the uplinks, activations and downlinks of suspended devices
+var ErrDeviceSuspended = errors.NewErrPermissionDenied("Device is suspended")
+
+// IsDeviceSuspended returns true if err, which may be received over gRPC, is ErrDeviceSuspended
+func IsDeviceSuspended(err error) bool {
+	return err != nil && errors.FromGRPCError(err).Error() == ErrDeviceSuspended.Error()
+}
+
+// Suspension is the suspended state of a device. A suspended device keeps its keys, frame counters and session, but its
+// uplinks are dropped, its joins are rejected and its downlinks are refused.
+type Suspension struct {
+	Suspended bool     `json:"suspended"`
+	Reason    string   `json:"reason,omitempty"`
+	Time      JSONTime `json:"time,omitempty"` // Time of the last suspension or resumption
+}
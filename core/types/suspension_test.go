@@ -0,0 +1,21 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestIsDeviceSuspended(t *testing.T) {
+	a := New(t)
+
+	a.So(IsDeviceSuspended(nil), ShouldBeFalse)
+	a.So(IsDeviceSuspended(ErrDeviceSuspended), ShouldBeTrue)
+	a.So(IsDeviceSuspended(errors.BuildGRPCError(ErrDeviceSuspended)), ShouldBeTrue)
+	a.So(IsDeviceSuspended(errors.NewErrPermissionDenied("No access")), ShouldBeFalse)
+	a.So(IsDeviceSuspended(errors.BuildGRPCError(errors.NewErrPermissionDenied("No access"))), ShouldBeFalse)
+}
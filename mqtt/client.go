@@ -4,6 +4,7 @@
 package mqtt
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -29,6 +30,7 @@ type Client interface {
 	Disconnect()
 
 	IsConnected() bool
+	Stats() ClientStats
 
 	// Uplink pub/sub
 	PublishUplink(payload types.UplinkMessage) Token
@@ -65,6 +67,14 @@ type Client interface {
 	UnsubscribeDeviceActivations(appID string, devID string) Token
 	UnsubscribeAppActivations(appID string) Token
 	UnsubscribeActivations() Token
+
+	// TTN v3 layout pub/sub
+	PublishV3Uplink(payload types.UplinkMessage) Token
+	PublishV3DeviceEvent(appID string, devID string, eventType types.EventType, payload interface{}) Token
+	PublishV3AppEvent(appID string, eventType types.EventType, payload interface{}) Token
+	SubscribeV3DeviceDownlink(appID string, devID string, handler V3DownlinkHandler) Token
+	SubscribeV3AppDownlink(appID string, handler V3DownlinkHandler) Token
+	SubscribeV3Downlink(handler V3DownlinkHandler) Token
 }
 
 // Token is returned on asyncronous functions
@@ -136,71 +146,242 @@ func (t *token) flowComplete() {
 	close(t.complete)
 }
 
+// completeWith completes the token with the result of the MQTT token
+func (t *token) completeWith(mqttToken MQTT.Token) {
+	mqttToken.Wait()
+	t.err = mqttToken.Error()
+	t.flowComplete()
+}
+
+// waitAll returns a token that completes when all tokens are complete, with the first error of the tokens
+func waitAll(tokens ...Token) Token {
+	t := newToken()
+	go func() {
+		for _, token := range tokens {
+			token.Wait()
+			if err := token.Error(); err != nil && t.err == nil {
+				t.err = err
+			}
+		}
+		t.flowComplete()
+	}()
+	return t
+}
+
 func (t *token) Error() error {
 	t.RLock()
 	defer t.RUnlock()
 	return t.err
 }
 
+// MessageClass is a class of messages that can be published and subscribed with its own QoS
+type MessageClass string
+
+// Message classes
+const (
+	UplinkMessages   MessageClass = "uplink"
+	DownlinkMessages MessageClass = "downlink"
+	EventMessages    MessageClass = "events"
+)
+
+// ClientOptions are the options for the delivery of messages by the DefaultClient
+type ClientOptions struct {
+	// QoS per message class. Classes that are not set use PublishQoS and SubscribeQoS
+	QoS map[MessageClass]byte
+
+	// PersistentSession subscribes to downlink messages in a separate non-clean session, so that the broker
+	// keeps downlink messages that are published while the client is disconnected. The broker only keeps
+	// messages with QoS 1 or 2, so downlinks are subscribed with QoS 1 if their QoS is not set.
+	PersistentSession bool
+	// SessionID is the client ID of the persistent session. It should be unique and stable for each client.
+	SessionID string
+
+	// PublishBufferSize is the number of messages that are buffered while the client is disconnected. When
+	// the buffer is full, the oldest message is dropped. A size of 0 disables the buffer.
+	PublishBufferSize int
+
+	// Encoding returns the encoding of the messages that are published for an application. If it is not set
+	// or returns an empty encoding, messages are published as JSON. Messages are received in all encodings.
+	Encoding func(appID string) types.Encoding
+}
+
+// ClientStats contains statistics of the delivery of messages by a Client
+type ClientStats struct {
+	// Buffered is the number of messages in the publish buffer
+	Buffered int
+	// Dropped is the number of messages that were dropped because the client was disconnected
+	Dropped uint64
+}
+
+// ErrPublishBufferFull is returned for messages that were dropped from a full publish buffer
+var ErrPublishBufferFull = errors.New("mqtt: publish buffer full")
+
+type subscription struct {
+	class   MessageClass
+	handler MQTT.MessageHandler
+}
+
+type bufferedMessage struct {
+	topic   string
+	qos     byte
+	payload []byte
+	token   *token
+}
+
 // DefaultClient is the default MQTT client for The Things Network
 type DefaultClient struct {
-	opts          *MQTT.ClientOptions
-	mqtt          MQTT.Client
-	ctx           log.Interface
-	subscriptions map[string]MQTT.MessageHandler
+	opts    *MQTT.ClientOptions
+	mqtt    MQTT.Client
+	ctx     log.Interface
+	options ClientOptions
+
+	// session is the client for the persistent downlink session, or nil if PersistentSession is disabled
+	sessionOpts *MQTT.ClientOptions
+	session     MQTT.Client
+
+	mu            sync.Mutex
+	subscriptions map[string]subscription
+	connected     bool
+	buffer        []bufferedMessage
+	dropped       uint64
 }
 
 // NewClient creates a new DefaultClient
 func NewClient(ctx log.Interface, id, username, password string, brokers ...string) Client {
+	return NewClientWithOptions(ctx, id, username, password, ClientOptions{}, brokers...)
+}
+
+// NewClientWithOptions creates a new DefaultClient with the given ClientOptions
+func NewClientWithOptions(ctx log.Interface, id, username, password string, options ClientOptions, brokers ...string) Client {
 	if ctx == nil {
 		ctx = log.Get()
 	}
 
+	qos := make(map[MessageClass]byte, len(options.QoS))
+	for class, level := range options.QoS {
+		qos[class] = level
+	}
+	if _, ok := qos[DownlinkMessages]; options.PersistentSession && !ok {
+		qos[DownlinkMessages] = 0x01
+	}
+	options.QoS = qos
+
 	ttnClient := &DefaultClient{
-		opts:          MQTT.NewClientOptions(),
 		ctx:           ctx,
-		subscriptions: make(map[string]MQTT.MessageHandler),
+		options:       options,
+		subscriptions: make(map[string]subscription),
 	}
 
+	ttnClient.opts = newClientOptions(ctx, fmt.Sprintf("%s-%s", id, random.String(16)), username, password, brokers...)
+	ttnClient.opts.SetCleanSession(true)
+	ttnClient.opts.SetConnectionLostHandler(func(client MQTT.Client, err error) {
+		ctx.Warnf("mqtt: disconnected (%s), reconnecting...", err)
+		ttnClient.mu.Lock()
+		ttnClient.connected = false
+		ttnClient.mu.Unlock()
+	})
+	ttnClient.opts.SetOnConnectHandler(func(client MQTT.Client) {
+		ctx.Info("mqtt: connected")
+		ttnClient.resubscribe(client, false)
+		ttnClient.flush()
+	})
+	ttnClient.mqtt = MQTT.NewClient(ttnClient.opts)
+
+	if options.PersistentSession {
+		sessionID := options.SessionID
+		if sessionID == "" {
+			sessionID = fmt.Sprintf("%s-session", id)
+		}
+		ttnClient.sessionOpts = newClientOptions(ctx, sessionID, username, password, brokers...)
+		ttnClient.sessionOpts.SetCleanSession(false)
+		ttnClient.sessionOpts.SetConnectionLostHandler(func(client MQTT.Client, err error) {
+			ctx.Warnf("mqtt: persistent session disconnected (%s), reconnecting...", err)
+		})
+		ttnClient.sessionOpts.SetOnConnectHandler(func(client MQTT.Client) {
+			ctx.Info("mqtt: persistent session connected")
+			ttnClient.resubscribe(client, true)
+		})
+		ttnClient.session = MQTT.NewClient(ttnClient.sessionOpts)
+	}
+
+	return ttnClient
+}
+
+func newClientOptions(ctx log.Interface, clientID, username, password string, brokers ...string) *MQTT.ClientOptions {
+	opts := MQTT.NewClientOptions()
+
 	for _, broker := range brokers {
-		ttnClient.opts.AddBroker(broker)
+		opts.AddBroker(broker)
 	}
 
-	ttnClient.opts.SetClientID(fmt.Sprintf("%s-%s", id, random.String(16)))
-	ttnClient.opts.SetUsername(username)
-	ttnClient.opts.SetPassword(password)
+	opts.SetClientID(clientID)
+	opts.SetUsername(username)
+	opts.SetPassword(password)
 
 	// TODO: Some tuning of these values probably won't hurt:
-	ttnClient.opts.SetKeepAlive(30 * time.Second)
-	ttnClient.opts.SetPingTimeout(10 * time.Second)
+	opts.SetKeepAlive(30 * time.Second)
+	opts.SetPingTimeout(10 * time.Second)
 
-	ttnClient.opts.SetCleanSession(true)
-
-	ttnClient.opts.SetDefaultPublishHandler(func(client MQTT.Client, msg MQTT.Message) {
+	opts.SetDefaultPublishHandler(func(client MQTT.Client, msg MQTT.Message) {
 		ctx.Warnf("mqtt: received unhandled message: %v", msg)
 	})
 
-	var reconnecting bool
-
-	ttnClient.opts.SetConnectionLostHandler(func(client MQTT.Client, err error) {
-		ctx.Warnf("mqtt: disconnected (%s), reconnecting...", err)
-		reconnecting = true
-	})
+	return opts
+}
 
-	ttnClient.opts.SetOnConnectHandler(func(client MQTT.Client) {
-		ctx.Info("mqtt: connected")
-		if reconnecting {
-			for topic, handler := range ttnClient.subscriptions {
-				ctx.Infof("mqtt: re-subscribing to topic: %s", topic)
-				ttnClient.subscribe(topic, handler)
-			}
-			reconnecting = false
+// resubscribe subscribes the (re)connected client to the topics that belong to it
+func (c *DefaultClient) resubscribe(client MQTT.Client, session bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for topic, sub := range c.subscriptions {
+		if c.inSession(sub.class) != session {
+			continue
 		}
-	})
+		c.ctx.Infof("mqtt: subscribing to topic: %s", topic)
+		client.Subscribe(topic, c.subscribeQoS(sub.class), sub.handler)
+	}
+}
 
-	ttnClient.mqtt = MQTT.NewClient(ttnClient.opts)
+// flush publishes the buffered messages after the client (re)connected
+func (c *DefaultClient) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connected = true
+	if len(c.buffer) > 0 {
+		c.ctx.Infof("mqtt: publishing %d buffered messages", len(c.buffer))
+	}
+	for _, msg := range c.buffer {
+		go msg.token.completeWith(c.mqtt.Publish(msg.topic, msg.qos, false, msg.payload))
+	}
+	c.buffer = nil
+}
 
-	return ttnClient
+func (c *DefaultClient) inSession(class MessageClass) bool {
+	return c.session != nil && class == DownlinkMessages
+}
+
+func (c *DefaultClient) publishQoS(class MessageClass) byte {
+	if qos, ok := c.options.QoS[class]; ok {
+		return qos
+	}
+	return PublishQoS
+}
+
+// encoding returns the encoding of the messages that are published for the application
+func (c *DefaultClient) encoding(appID string) types.Encoding {
+	if c.options.Encoding != nil {
+		if encoding := c.options.Encoding(appID); encoding != "" {
+			return encoding
+		}
+	}
+	return types.JSONEncoding
+}
+
+func (c *DefaultClient) subscribeQoS(class MessageClass) byte {
+	if qos, ok := c.options.QoS[class]; ok {
+		return qos
+	}
+	return SubscribeQoS
 }
 
 var (
@@ -212,12 +393,24 @@ var (
 
 // Connect to the MQTT broker. It will retry for ConnectRetries times with a delay of ConnectRetryDelay between retries
 func (c *DefaultClient) Connect() error {
-	if c.mqtt.IsConnected() {
+	if err := c.connect(c.mqtt); err != nil {
+		return err
+	}
+	if c.session != nil {
+		if err := c.connect(c.session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *DefaultClient) connect(client MQTT.Client) error {
+	if client.IsConnected() {
 		return nil
 	}
 	var err error
 	for retries := 0; retries < ConnectRetries; retries++ {
-		token := c.mqtt.Connect()
+		token := client.Connect()
 		finished := token.WaitTimeout(1 * time.Second)
 		if !finished {
 			c.ctx.Warn("mqtt: connection took longer than expected...")
@@ -236,30 +429,100 @@ func (c *DefaultClient) Connect() error {
 	return nil
 }
 
-func (c *DefaultClient) publish(topic string, msg []byte) Token {
-	return c.mqtt.Publish(topic, PublishQoS, false, msg)
+func (c *DefaultClient) publish(class MessageClass, topic string, msg []byte) Token {
+	qos := c.publishQoS(class)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if open := c.mqtt.IsConnectionOpen(); !open || !c.connected {
+		// Messages are also buffered while the connect handler did not yet flush the buffer
+		if c.options.PublishBufferSize > 0 {
+			if len(c.buffer) >= c.options.PublishBufferSize {
+				dropped := c.buffer[0]
+				c.buffer = c.buffer[1:]
+				c.dropped++
+				dropped.token.err = ErrPublishBufferFull
+				dropped.token.flowComplete()
+			}
+			t := newToken()
+			c.buffer = append(c.buffer, bufferedMessage{topic: topic, qos: qos, payload: msg, token: t})
+			return t
+		}
+		if !open && qos == 0 {
+			// The MQTT client silently drops QoS 0 messages while it is reconnecting
+			c.dropped++
+		}
+	}
+	return c.mqtt.Publish(topic, qos, false, msg)
 }
 
-func (c *DefaultClient) subscribe(topic string, handler MQTT.MessageHandler) Token {
-	c.subscriptions[topic] = handler
-	return c.mqtt.Subscribe(topic, SubscribeQoS, handler)
+func (c *DefaultClient) subscribe(class MessageClass, topic string, handler MQTT.MessageHandler) Token {
+	c.mu.Lock()
+	c.subscriptions[topic] = subscription{class: class, handler: handler}
+	c.mu.Unlock()
+	if c.inSession(class) {
+		return c.session.Subscribe(topic, c.subscribeQoS(class), handler)
+	}
+	return c.mqtt.Subscribe(topic, c.subscribeQoS(class), handler)
+}
+
+// subscribeEncoded subscribes to the topic in all encodings, see EncodedTopic
+func (c *DefaultClient) subscribeEncoded(class MessageClass, topic string, handler MQTT.MessageHandler) Token {
+	tokens := make([]Token, 0, len(types.Encodings))
+	for _, encoding := range types.Encodings {
+		tokens = append(tokens, c.subscribe(class, EncodedTopic(topic, encoding), handler))
+	}
+	return waitAll(tokens...)
+}
+
+// unsubscribeEncoded unsubscribes from the topic in all encodings
+func (c *DefaultClient) unsubscribeEncoded(topic string) Token {
+	tokens := make([]Token, 0, len(types.Encodings))
+	for _, encoding := range types.Encodings {
+		tokens = append(tokens, c.unsubscribe(EncodedTopic(topic, encoding)))
+	}
+	return waitAll(tokens...)
 }
 
 func (c *DefaultClient) unsubscribe(topic string) Token {
+	c.mu.Lock()
+	sub := c.subscriptions[topic]
 	delete(c.subscriptions, topic)
+	c.mu.Unlock()
+	if c.inSession(sub.class) {
+		return c.session.Unsubscribe(topic)
+	}
 	return c.mqtt.Unsubscribe(topic)
 }
 
 // Disconnect from the MQTT broker
 func (c *DefaultClient) Disconnect() {
+	if c.session != nil && c.session.IsConnected() {
+		c.session.Disconnect(25)
+	}
 	if !c.mqtt.IsConnected() {
 		return
 	}
 	c.ctx.Debug("mqtt: disconnecting")
+	c.mu.Lock()
+	c.connected = false
+	c.mu.Unlock()
 	c.mqtt.Disconnect(25)
 }
 
 // IsConnected returns true if there is a connection to the MQTT broker
 func (c *DefaultClient) IsConnected() bool {
+	if c.session != nil && !c.session.IsConnected() {
+		return false
+	}
 	return c.mqtt.IsConnected()
 }
+
+// Stats returns the statistics of the delivery of messages by the client
+func (c *DefaultClient) Stats() ClientStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ClientStats{
+		Buffered: len(c.buffer),
+		Dropped:  c.dropped,
+	}
+}
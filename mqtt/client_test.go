@@ -13,6 +13,7 @@ import (
 
 	"github.com/TheThingsNetwork/go-utils/log/apex"
 	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
 	. "github.com/smartystreets/assertions"
 )
 
@@ -172,6 +173,72 @@ func TestRandomTopicPublish(t *testing.T) {
 	ctx.Info("This test should have printed one message.")
 }
 
+func TestClientOptions(t *testing.T) {
+	a := New(t)
+
+	c := NewClientWithOptions(getLogger(t, "Test"), "test", "", "", ClientOptions{
+		QoS:               map[MessageClass]byte{UplinkMessages: 1},
+		PersistentSession: true,
+		SessionID:         "test-session",
+	}, fmt.Sprintf("tcp://%s", host)).(*DefaultClient)
+
+	a.So(c.session, ShouldNotBeNil)
+	a.So(c.sessionOpts.ClientID, ShouldEqual, "test-session")
+	a.So(c.sessionOpts.CleanSession, ShouldBeFalse)
+	a.So(c.opts.CleanSession, ShouldBeTrue)
+	a.So(c.publishQoS(UplinkMessages), ShouldEqual, 1)
+	a.So(c.subscribeQoS(DownlinkMessages), ShouldEqual, 1)
+	a.So(c.publishQoS(EventMessages), ShouldEqual, PublishQoS)
+	a.So(c.inSession(DownlinkMessages), ShouldBeTrue)
+	a.So(c.inSession(UplinkMessages), ShouldBeFalse)
+
+	c.Connect()
+	defer c.Disconnect()
+	a.So(c.IsConnected(), ShouldBeTrue)
+}
+
+func TestPublishBuffer(t *testing.T) {
+	a := New(t)
+	ctx := getLogger(t, "TestPublishBuffer")
+
+	sub := NewClient(ctx, "test", "", "", fmt.Sprintf("tcp://%s", host))
+	sub.Connect()
+	defer sub.Disconnect()
+
+	var wg WaitGroup
+	wg.Add(2)
+	var received []byte
+	subToken := sub.SubscribeDeviceUplink("buffer-app", "buffer-dev", func(_ Client, _ string, _ string, msg types.UplinkMessage) {
+		received = append(received, msg.PayloadRaw...)
+		wg.Done()
+	})
+	waitForOK(subToken, a)
+
+	c := NewClientWithOptions(ctx, "test", "", "", ClientOptions{PublishBufferSize: 2}, fmt.Sprintf("tcp://%s", host))
+
+	// Messages are buffered while the client is not connected, the oldest message is dropped
+	dropped := c.PublishUplink(types.UplinkMessage{AppID: "buffer-app", DevID: "buffer-dev", PayloadRaw: []byte{0x01}})
+	first := c.PublishUplink(types.UplinkMessage{AppID: "buffer-app", DevID: "buffer-dev", PayloadRaw: []byte{0x02}})
+	second := c.PublishUplink(types.UplinkMessage{AppID: "buffer-app", DevID: "buffer-dev", PayloadRaw: []byte{0x03}})
+
+	a.So(dropped.WaitTimeout(10*time.Millisecond), ShouldBeTrue)
+	a.So(dropped.Error(), ShouldEqual, ErrPublishBufferFull)
+	a.So(first.WaitTimeout(10*time.Millisecond), ShouldBeFalse)
+	a.So(c.Stats(), ShouldResemble, ClientStats{Buffered: 2, Dropped: 1})
+
+	// Buffered messages are published after connecting
+	a.So(c.Connect(), ShouldBeNil)
+	defer c.Disconnect()
+
+	a.So(first.WaitTimeout(time.Second), ShouldBeTrue)
+	a.So(first.Error(), ShouldBeNil)
+	a.So(second.WaitTimeout(time.Second), ShouldBeTrue)
+	a.So(second.Error(), ShouldBeNil)
+	a.So(wg.WaitFor(time.Second), ShouldBeNil)
+	a.So(received, ShouldResemble, []byte{0x02, 0x03})
+	a.So(c.Stats(), ShouldResemble, ClientStats{Buffered: 0, Dropped: 1})
+}
+
 func ExampleNewClient() {
 	ctx := apex.Stdout().WithField("Example", "NewClient")
 	exampleClient := NewClient(ctx, "ttnctl", "my-app-id", "my-access-key", "eu.thethings.network:1883")
@@ -4,7 +4,6 @@
 package mqtt
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
@@ -14,24 +13,27 @@ import (
 // DownlinkHandler is called for downlink messages
 type DownlinkHandler func(client Client, appID string, devID string, req types.DownlinkMessage)
 
-// PublishDownlink publishes a downlink message
+// PublishDownlink publishes a downlink message in the encoding of the application
 func (c *DefaultClient) PublishDownlink(dataDown types.DownlinkMessage) Token {
 	topic := DeviceTopic{dataDown.AppID, dataDown.DevID, DeviceDownlink, ""}
+	encoding := c.encoding(dataDown.AppID)
 	dataDown.AppID = ""
 	dataDown.DevID = ""
-	msg, err := json.Marshal(dataDown)
+	msg, err := encoding.Marshal(dataDown)
 	if err != nil {
 		return &simpleToken{fmt.Errorf("Unable to marshal the message payload: %s", err)}
 	}
-	return c.publish(topic.String(), msg)
+	return c.publish(DownlinkMessages, EncodedTopic(topic.String(), encoding), msg)
 }
 
-// SubscribeDeviceDownlink subscribes to all downlink messages for the given application and device
+// SubscribeDeviceDownlink subscribes to all downlink messages for the given application and device, in all
+// encodings
 func (c *DefaultClient) SubscribeDeviceDownlink(appID string, devID string, handler DownlinkHandler) Token {
 	topic := DeviceTopic{appID, devID, DeviceDownlink, ""}
-	return c.subscribe(topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
+	return c.subscribeEncoded(DownlinkMessages, topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
 		// Determine the actual topic
-		topic, err := ParseDeviceTopic(msg.Topic())
+		name, encoding := TopicEncoding(msg.Topic())
+		topic, err := ParseDeviceTopic(name)
 		if err != nil {
 			c.ctx.Warnf("mqtt: received message on invalid downlink topic: %s", msg.Topic())
 			return
@@ -39,7 +41,7 @@ func (c *DefaultClient) SubscribeDeviceDownlink(appID string, devID string, hand
 
 		// Unmarshal the payload
 		dataDown := &types.DownlinkMessage{}
-		err = json.Unmarshal(msg.Payload(), dataDown)
+		err = encoding.Unmarshal(msg.Payload(), dataDown)
 		if err != nil {
 			c.ctx.Warnf("mqtt: could not unmarshal downlink: %s", err)
 			return
@@ -65,7 +67,7 @@ func (c *DefaultClient) SubscribeDownlink(handler DownlinkHandler) Token {
 // UnsubscribeDeviceDownlink unsubscribes from the downlink messages for the given application and device
 func (c *DefaultClient) UnsubscribeDeviceDownlink(appID string, devID string) Token {
 	topic := DeviceTopic{appID, devID, DeviceDownlink, ""}
-	return c.unsubscribe(topic.String())
+	return c.unsubscribeEncoded(topic.String())
 }
 
 // UnsubscribeAppDownlink unsubscribes from the downlink messages for the given application
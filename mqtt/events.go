@@ -4,47 +4,54 @@
 package mqtt
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
 	MQTT "github.com/eclipse/paho.mqtt.golang"
 )
 
-// AppEventHandler is called for events
+// AppEventHandler is called for events. The payload is in the encoding of the application.
 type AppEventHandler func(client Client, appID string, eventType types.EventType, payload []byte)
 
-// DeviceEventHandler is called for events
+// DeviceEventHandler is called for events. The payload is in the encoding of the application.
 type DeviceEventHandler func(client Client, appID string, devID string, eventType types.EventType, payload []byte)
 
 // PublishAppEvent publishes an event to the topic for application events of the given type
-// it will marshal the payload to json
+// it will marshal the payload in the encoding of the application
 func (c *DefaultClient) PublishAppEvent(appID string, eventType types.EventType, payload interface{}) Token {
 	topic := ApplicationTopic{appID, AppEvents, string(eventType)}
-	msg, err := json.Marshal(payload)
-	if err != nil {
-		return &simpleToken{fmt.Errorf("Unable to marshal the message payload: %s", err)}
-	}
-	return c.publish(topic.String(), msg)
+	return c.publishEvent(topic.String(), types.DeviceEvent{AppID: appID, Event: eventType, Data: payload})
 }
 
 // PublishDeviceEvent publishes an event to the topic for device events of the given type
-// it will marshal the payload to json
+// it will marshal the payload in the encoding of the application
 func (c *DefaultClient) PublishDeviceEvent(appID string, devID string, eventType types.EventType, payload interface{}) Token {
 	topic := DeviceTopic{appID, devID, DeviceEvents, string(eventType)}
-	msg, err := json.Marshal(payload)
+	return c.publishEvent(topic.String(), types.DeviceEvent{AppID: appID, DevID: devID, Event: eventType, Data: payload})
+}
+
+// publishEvent publishes the event data, or the complete DeviceEvent message if the application uses protobuf
+func (c *DefaultClient) publishEvent(topic string, event types.DeviceEvent) Token {
+	encoding := c.encoding(event.AppID)
+	var payload interface{} = event.Data
+	if encoding == types.ProtobufEncoding {
+		payload = event
+	}
+	msg, err := encoding.Marshal(payload)
 	if err != nil {
 		return &simpleToken{fmt.Errorf("Unable to marshal the message payload: %s", err)}
 	}
-	return c.publish(topic.String(), msg)
+	return c.publish(EventMessages, EncodedTopic(topic, encoding), msg)
 }
 
 // SubscribeAppEvents subscribes to events of the given type for the given application. In order to subscribe to
 // application events from all applications the user has access to, pass an empty string as appID.
 func (c *DefaultClient) SubscribeAppEvents(appID string, eventType types.EventType, handler AppEventHandler) Token {
 	topic := ApplicationTopic{appID, AppEvents, string(eventType)}
-	return c.subscribe(topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
-		topic, err := ParseApplicationTopic(msg.Topic())
+	return c.subscribeEvents(topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
+		name, _ := TopicEncoding(msg.Topic())
+		topic, err := ParseApplicationTopic(name)
 		if err != nil {
 			c.ctx.Warnf("mqtt: received message on invalid events topic: %s", msg.Topic())
 			return
@@ -58,8 +65,9 @@ func (c *DefaultClient) SubscribeAppEvents(appID string, eventType types.EventTy
 // events from all devices in all applications the user has access to, pass an empty string as appID.
 func (c *DefaultClient) SubscribeDeviceEvents(appID string, devID string, eventType types.EventType, handler DeviceEventHandler) Token {
 	topic := DeviceTopic{appID, devID, DeviceEvents, string(eventType)}
-	return c.subscribe(topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
-		topic, err := ParseDeviceTopic(msg.Topic())
+	return c.subscribeEvents(topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
+		name, _ := TopicEncoding(msg.Topic())
+		topic, err := ParseDeviceTopic(name)
 		if err != nil {
 			c.ctx.Warnf("mqtt: received message on invalid events topic: %s", msg.Topic())
 			return
@@ -71,11 +79,27 @@ func (c *DefaultClient) SubscribeDeviceEvents(appID string, devID string, eventT
 // UnsubscribeAppEvents unsubscribes from the events that were subscribed to by SubscribeAppEvents
 func (c *DefaultClient) UnsubscribeAppEvents(appID string, eventType types.EventType) Token {
 	topic := ApplicationTopic{appID, AppEvents, string(eventType)}
-	return c.unsubscribe(topic.String())
+	return c.unsubscribeEvents(topic.String())
 }
 
 // UnsubscribeDeviceEvents unsubscribes from the events that were subscribed to by SubscribeDeviceEvents
 func (c *DefaultClient) UnsubscribeDeviceEvents(appID string, devID string, eventType types.EventType) Token {
 	topic := DeviceTopic{appID, devID, DeviceEvents, string(eventType)}
-	return c.unsubscribe(topic.String())
+	return c.unsubscribeEvents(topic.String())
+}
+
+// subscribeEvents subscribes to the events topic in all encodings. A topic that ends with a wildcard already
+// matches the topics of all encodings.
+func (c *DefaultClient) subscribeEvents(topic string, handler MQTT.MessageHandler) Token {
+	if strings.HasSuffix(topic, simpleWildcard) || strings.HasSuffix(topic, wildcard) {
+		return c.subscribe(EventMessages, topic, handler)
+	}
+	return c.subscribeEncoded(EventMessages, topic, handler)
+}
+
+func (c *DefaultClient) unsubscribeEvents(topic string) Token {
+	if strings.HasSuffix(topic, simpleWildcard) || strings.HasSuffix(topic, wildcard) {
+		return c.unsubscribe(topic)
+	}
+	return c.unsubscribeEncoded(topic)
 }
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
 )
 
 const simpleWildcard = "+"
@@ -127,3 +129,22 @@ func (t ApplicationTopic) String() string {
 	}
 	return topic
 }
+
+// EncodedTopic returns the topic for messages in the given encoding. JSON messages are published on the topic
+// itself, messages in other encodings on the topic with the encoding as extension (for example up.cbor).
+func EncodedTopic(topic string, encoding types.Encoding) string {
+	if encoding == "" || encoding == types.JSONEncoding {
+		return topic
+	}
+	return topic + "." + string(encoding)
+}
+
+// TopicEncoding returns the topic without encoding extension and the encoding of messages on the topic
+func TopicEncoding(topic string) (string, types.Encoding) {
+	if i := strings.LastIndex(topic, "."); i > strings.LastIndex(topic, "/") {
+		if encoding, err := types.ParseEncoding(topic[i+1:]); err == nil && topic[i+1:] != "" {
+			return topic[:i], encoding
+		}
+	}
+	return topic, types.JSONEncoding
+}
@@ -6,6 +6,7 @@ package mqtt
 import (
 	"testing"
 
+	"github.com/TheThingsNetwork/ttn/core/types"
 	. "github.com/smartystreets/assertions"
 )
 
@@ -139,3 +140,27 @@ func TestAppTopicParseAndString(t *testing.T) {
 	}
 
 }
+
+func TestTopicEncoding(t *testing.T) {
+	a := New(t)
+
+	a.So(EncodedTopic("appid/devices/devid/up", types.JSONEncoding), ShouldEqual, "appid/devices/devid/up")
+	a.So(EncodedTopic("appid/devices/devid/up", types.CBOREncoding), ShouldEqual, "appid/devices/devid/up.cbor")
+
+	for _, tt := range []struct {
+		topic    string
+		name     string
+		encoding types.Encoding
+	}{
+		{"appid/devices/devid/up", "appid/devices/devid/up", types.JSONEncoding},
+		{"appid/devices/devid/up.json", "appid/devices/devid/up", types.JSONEncoding},
+		{"appid/devices/devid/up.cbor", "appid/devices/devid/up", types.CBOREncoding},
+		{"appid/devices/devid/down.protobuf", "appid/devices/devid/down", types.ProtobufEncoding},
+		{"appid/devices/devid/events/down/sent.cbor", "appid/devices/devid/events/down/sent", types.CBOREncoding},
+		{"appid/devices/devid/events/down/sent.xml", "appid/devices/devid/events/down/sent.xml", types.JSONEncoding},
+	} {
+		name, encoding := TopicEncoding(tt.topic)
+		a.So(name, ShouldEqual, tt.name)
+		a.So(encoding, ShouldEqual, tt.encoding)
+	}
+}
@@ -4,7 +4,6 @@
 package mqtt
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/TheThingsNetwork/ttn/core/types"
@@ -14,18 +13,24 @@ import (
 // UplinkHandler is called for uplink messages
 type UplinkHandler func(client Client, appID string, devID string, req types.UplinkMessage)
 
-// PublishUplink publishes an uplink message to the MQTT broker
+// PublishUplink publishes an uplink message to the MQTT broker in the encoding of the application
 func (c *DefaultClient) PublishUplink(dataUp types.UplinkMessage) Token {
 	topic := DeviceTopic{dataUp.AppID, dataUp.DevID, DeviceUplink, ""}
-	msg, err := json.Marshal(dataUp)
+	encoding := c.encoding(dataUp.AppID)
+	msg, err := encoding.Marshal(dataUp)
 	if err != nil {
 		return &simpleToken{fmt.Errorf("Unable to marshal the message payload: %s", err)}
 	}
-	return c.publish(topic.String(), msg)
+	return c.publish(UplinkMessages, EncodedTopic(topic.String(), encoding), msg)
 }
 
-// PublishUplinkFields publishes uplink fields to MQTT
+// PublishUplinkFields publishes uplink fields to MQTT. The fields are published in the encoding of the
+// application, or in JSON if the application uses protobuf, as the protobuf schema has no messages for fields.
 func (c *DefaultClient) PublishUplinkFields(appID string, devID string, fields map[string]interface{}) Token {
+	encoding := c.encoding(appID)
+	if encoding == types.ProtobufEncoding {
+		encoding = types.JSONEncoding
+	}
 	flattenedFields := make(map[string]interface{})
 	flatten("", "/", fields, flattenedFields)
 	tokens := make([]Token, 0, len(flattenedFields))
@@ -34,8 +39,8 @@ func (c *DefaultClient) PublishUplinkFields(appID string, devID string, fields m
 		if !topic.ValidField() {
 			continue
 		}
-		pld, _ := json.Marshal(value)
-		token := c.publish(topic.String(), pld)
+		pld, _ := encoding.Marshal(value)
+		token := c.publish(UplinkMessages, EncodedTopic(topic.String(), encoding), pld)
 		tokens = append(tokens, token)
 	}
 	t := newToken()
@@ -69,12 +74,13 @@ func flatten(prefix, sep string, in, out map[string]interface{}) {
 	}
 }
 
-// SubscribeDeviceUplink subscribes to all uplink messages for the given application and device
+// SubscribeDeviceUplink subscribes to all uplink messages for the given application and device, in all encodings
 func (c *DefaultClient) SubscribeDeviceUplink(appID string, devID string, handler UplinkHandler) Token {
 	topic := DeviceTopic{appID, devID, DeviceUplink, ""}
-	return c.subscribe(topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
+	return c.subscribeEncoded(UplinkMessages, topic.String(), func(mqtt MQTT.Client, msg MQTT.Message) {
 		// Determine the actual topic
-		topic, err := ParseDeviceTopic(msg.Topic())
+		name, encoding := TopicEncoding(msg.Topic())
+		topic, err := ParseDeviceTopic(name)
 		if err != nil {
 			c.ctx.Warnf("mqtt: received message on invalid uplink topic: %s", msg.Topic())
 			return
@@ -82,7 +88,7 @@ func (c *DefaultClient) SubscribeDeviceUplink(appID string, devID string, handle
 
 		// Unmarshal the payload
 		dataUp := &types.UplinkMessage{}
-		err = json.Unmarshal(msg.Payload(), dataUp)
+		err = encoding.Unmarshal(msg.Payload(), dataUp)
 		dataUp.AppID = topic.AppID
 		dataUp.DevID = topic.DevID
 
@@ -109,7 +115,7 @@ func (c *DefaultClient) SubscribeUplink(handler UplinkHandler) Token {
 // UnsubscribeDeviceUplink unsubscribes from the uplink messages for the given application and device
 func (c *DefaultClient) UnsubscribeDeviceUplink(appID string, devID string) Token {
 	topic := DeviceTopic{appID, devID, DeviceUplink, ""}
-	return c.unsubscribe(topic.String())
+	return c.unsubscribeEncoded(topic.String())
 }
 
 // UnsubscribeAppUplink unsubscribes from the uplink messages for the given application
@@ -183,6 +183,58 @@ func TestPubSubUplink(t *testing.T) {
 	waitForOK(unsubToken, a)
 }
 
+func TestPubSubEncodedUplink(t *testing.T) {
+	a := New(t)
+	encodings := map[string]types.Encoding{"app-cbor": types.CBOREncoding, "app-protobuf": types.ProtobufEncoding}
+	c := NewClientWithOptions(getLogger(t, "Test"), "test", "", "", ClientOptions{
+		Encoding: func(appID string) types.Encoding { return encodings[appID] },
+	}, fmt.Sprintf("tcp://%s", host))
+	c.Connect()
+	defer c.Disconnect()
+
+	topics := make(chan string, 2)
+	rawToken := c.(*DefaultClient).mqtt.Subscribe("+/devices/dev1/#", SubscribeQoS, func(_ MQTT.Client, msg MQTT.Message) {
+		topics <- msg.Topic()
+	})
+	waitForOK(rawToken, a)
+	defer c.(*DefaultClient).mqtt.Unsubscribe("+/devices/dev1/#")
+
+	for _, appID := range []string{"app-cbor", "app-protobuf"} {
+		waitChan := make(chan bool, 1)
+		subToken := c.SubscribeAppUplink(appID, func(client Client, appID string, devID string, req types.UplinkMessage) {
+			a.So(devID, ShouldEqual, "dev1")
+			a.So(req.PayloadRaw, ShouldResemble, []byte{0x01, 0x02, 0x03, 0x04})
+			a.So(req.PayloadFields, ShouldResemble, map[string]interface{}{"on": true})
+			waitChan <- true
+		})
+		waitForOK(subToken, a)
+
+		pubToken := c.PublishUplink(types.UplinkMessage{
+			AppID:         appID,
+			DevID:         "dev1",
+			PayloadRaw:    []byte{0x01, 0x02, 0x03, 0x04},
+			PayloadFields: map[string]interface{}{"on": true},
+		})
+		waitForOK(pubToken, a)
+
+		select {
+		case <-waitChan:
+		case <-time.After(1 * time.Second):
+			panic("Did not receive Uplink")
+		}
+
+		select {
+		case topic := <-topics:
+			a.So(topic, ShouldEqual, EncodedTopic(appID+"/devices/dev1/up", c.(*DefaultClient).encoding(appID)))
+		case <-time.After(1 * time.Second):
+			panic("Did not receive Uplink on topic")
+		}
+
+		unsubToken := c.UnsubscribeAppUplink(appID)
+		waitForOK(unsubToken, a)
+	}
+}
+
 func TestPubSubAppUplink(t *testing.T) {
 	a := New(t)
 	c := NewClient(getLogger(t, "Test"), "test", "", "", fmt.Sprintf("tcp://%s", host))
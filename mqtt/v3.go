@@ -0,0 +1,112 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+// V3DownlinkHandler is called for downlink messages that are published on topics in the TTN v3 layout.
+// The downlinks are scheduled with ScheduleLast. For the down/replace topic, replace is true and the
+// existing queue should be cleared before the downlinks are queued, also if there are no downlinks.
+type V3DownlinkHandler func(client Client, appID string, devID string, replace bool, downlinks []types.DownlinkMessage)
+
+// PublishV3Uplink publishes an uplink message to the MQTT broker on the topic in the TTN v3 layout
+func (c *DefaultClient) PublishV3Uplink(dataUp types.UplinkMessage) Token {
+	topic := V3DeviceTopic{dataUp.AppID, dataUp.DevID, V3DeviceUplink}
+	msg, err := json.Marshal(NewV3ApplicationUplink(dataUp))
+	if err != nil {
+		return &simpleToken{fmt.Errorf("Unable to marshal the message payload: %s", err)}
+	}
+	return c.publish(UplinkMessages, topic.String(), msg)
+}
+
+// PublishV3DeviceEvent publishes a device event to the MQTT broker on the topic in the TTN v3 layout.
+// Events that have no equivalent in the TTN v3 layout are not published.
+func (c *DefaultClient) PublishV3DeviceEvent(appID string, devID string, eventType types.EventType, payload interface{}) Token {
+	up, topicType, ok := NewV3ApplicationEvent(appID, devID, eventType, payload)
+	if !ok {
+		return &simpleToken{}
+	}
+	topic := V3DeviceTopic{appID, devID, topicType}
+	msg, err := json.Marshal(up)
+	if err != nil {
+		return &simpleToken{fmt.Errorf("Unable to marshal the message payload: %s", err)}
+	}
+	return c.publish(EventMessages, topic.String(), msg)
+}
+
+// PublishV3AppEvent publishes an application event to the MQTT broker on the topic in the TTN v3 layout
+func (c *DefaultClient) PublishV3AppEvent(appID string, eventType types.EventType, payload interface{}) Token {
+	topic := V3ApplicationEventTopic{appID, string(eventType)}
+	msg, err := json.Marshal(NewV3AppEvent(appID, eventType, payload))
+	if err != nil {
+		return &simpleToken{fmt.Errorf("Unable to marshal the message payload: %s", err)}
+	}
+	return c.publish(EventMessages, topic.String(), msg)
+}
+
+func (c *DefaultClient) v3DownlinkMessageHandler(handler V3DownlinkHandler) MQTT.MessageHandler {
+	return func(mqtt MQTT.Client, msg MQTT.Message) {
+		// Determine the actual topic
+		topic, err := ParseV3DeviceTopic(msg.Topic())
+		if err != nil || topic.AppID == "" || topic.DevID == "" {
+			c.ctx.Warnf("mqtt: received message on invalid downlink topic: %s", msg.Topic())
+			return
+		}
+
+		// Unmarshal the payload
+		v3Downlinks := &V3Downlinks{}
+		err = json.Unmarshal(msg.Payload(), v3Downlinks)
+		if err != nil {
+			c.ctx.Warnf("mqtt: could not unmarshal downlink: %s", err)
+			return
+		}
+
+		downlinks := make([]types.DownlinkMessage, 0, len(v3Downlinks.Downlinks))
+		for _, downlink := range v3Downlinks.Downlinks {
+			downlinks = append(downlinks, downlink.DownlinkMessage(topic.AppID, topic.DevID, types.ScheduleLast))
+		}
+
+		// Call the Downlink handler
+		handler(c, topic.AppID, topic.DevID, topic.Type == V3DeviceDownlinkReplace, downlinks)
+	}
+}
+
+// SubscribeV3DeviceDownlink subscribes to the down/push and down/replace topics in the TTN v3 layout
+// for the given application and device
+func (c *DefaultClient) SubscribeV3DeviceDownlink(appID string, devID string, handler V3DownlinkHandler) Token {
+	messageHandler := c.v3DownlinkMessageHandler(handler)
+	tokens := []Token{
+		c.subscribe(DownlinkMessages, V3DeviceTopic{appID, devID, V3DeviceDownlinkPush}.String(), messageHandler),
+		c.subscribe(DownlinkMessages, V3DeviceTopic{appID, devID, V3DeviceDownlinkReplace}.String(), messageHandler),
+	}
+	t := newToken()
+	go func() {
+		for _, token := range tokens {
+			token.Wait()
+			if token.Error() != nil {
+				t.err = token.Error()
+			}
+		}
+		t.flowComplete()
+	}()
+	return t
+}
+
+// SubscribeV3AppDownlink subscribes to the down/push and down/replace topics in the TTN v3 layout
+// for the given application
+func (c *DefaultClient) SubscribeV3AppDownlink(appID string, handler V3DownlinkHandler) Token {
+	return c.SubscribeV3DeviceDownlink(appID, "", handler)
+}
+
+// SubscribeV3Downlink subscribes to the down/push and down/replace topics in the TTN v3 layout
+// that the current user has access to
+func (c *DefaultClient) SubscribeV3Downlink(handler V3DownlinkHandler) Token {
+	return c.SubscribeV3DeviceDownlink("", "", handler)
+}
@@ -0,0 +1,338 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqtt
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+)
+
+// V3ApplicationIdentifiers identifies an application in the TTN v3 message format
+type V3ApplicationIdentifiers struct {
+	ApplicationID string `json:"application_id"`
+}
+
+// V3EndDeviceIdentifiers identifies a device in the TTN v3 message format
+type V3EndDeviceIdentifiers struct {
+	DeviceID       string                   `json:"device_id"`
+	ApplicationIDs V3ApplicationIdentifiers `json:"application_ids"`
+	DevEUI         *types.DevEUI            `json:"dev_eui,omitempty"`
+	JoinEUI        *types.AppEUI            `json:"join_eui,omitempty"`
+	DevAddr        *types.DevAddr           `json:"dev_addr,omitempty"`
+}
+
+// V3GatewayIdentifiers identifies a gateway in the TTN v3 message format
+type V3GatewayIdentifiers struct {
+	GatewayID string `json:"gateway_id"`
+}
+
+// V3Location is a location in the TTN v3 message format
+type V3Location struct {
+	Latitude  float32 `json:"latitude"`
+	Longitude float32 `json:"longitude"`
+	Altitude  int32   `json:"altitude,omitempty"`
+	Accuracy  int32   `json:"accuracy,omitempty"`
+	Source    string  `json:"source,omitempty"`
+}
+
+// V3RxMetadata is the metadata of a gateway that received an uplink in the TTN v3 message format
+type V3RxMetadata struct {
+	GatewayIDs  V3GatewayIdentifiers `json:"gateway_ids"`
+	Time        *types.JSONTime      `json:"time,omitempty"`
+	Timestamp   uint32               `json:"timestamp,omitempty"`
+	RSSI        float32              `json:"rssi"`
+	ChannelRSSI float32              `json:"channel_rssi"`
+	SNR         float32              `json:"snr"`
+	Channel     uint32               `json:"channel_index,omitempty"`
+	Location    *V3Location          `json:"location,omitempty"`
+}
+
+// V3LoRaDataRate is a LoRa data rate in the TTN v3 message format
+type V3LoRaDataRate struct {
+	Bandwidth       uint32 `json:"bandwidth"`
+	SpreadingFactor uint32 `json:"spreading_factor"`
+}
+
+// V3DataRate is a data rate in the TTN v3 message format
+type V3DataRate struct {
+	LoRa *V3LoRaDataRate `json:"lora,omitempty"`
+}
+
+// V3TxSettings are the transmission settings of an uplink in the TTN v3 message format
+type V3TxSettings struct {
+	DataRate   V3DataRate      `json:"data_rate"`
+	CodingRate string          `json:"coding_rate,omitempty"`
+	Frequency  string          `json:"frequency,omitempty"`
+	Time       *types.JSONTime `json:"time,omitempty"`
+}
+
+// V3Uplink is an uplink message in the TTN v3 message format
+type V3Uplink struct {
+	FPort           uint8                  `json:"f_port,omitempty"`
+	FCnt            uint32                 `json:"f_cnt,omitempty"`
+	FRMPayload      []byte                 `json:"frm_payload,omitempty"`
+	DecodedPayload  map[string]interface{} `json:"decoded_payload,omitempty"`
+	RxMetadata      []V3RxMetadata         `json:"rx_metadata,omitempty"`
+	Settings        V3TxSettings           `json:"settings"`
+	ReceivedAt      *types.JSONTime        `json:"received_at,omitempty"`
+	Confirmed       bool                   `json:"confirmed,omitempty"`
+	ConsumedAirtime string                 `json:"consumed_airtime,omitempty"`
+}
+
+// V3JoinAccept is a join-accept in the TTN v3 message format
+type V3JoinAccept struct {
+	ReceivedAt *types.JSONTime `json:"received_at,omitempty"`
+}
+
+// V3Downlink is a downlink message in the TTN v3 message format
+type V3Downlink struct {
+	FPort          uint8                  `json:"f_port,omitempty"`
+	FCnt           uint32                 `json:"f_cnt,omitempty"`
+	FRMPayload     []byte                 `json:"frm_payload,omitempty"`
+	DecodedPayload map[string]interface{} `json:"decoded_payload,omitempty"`
+	Confirmed      bool                   `json:"confirmed,omitempty"`
+	Priority       string                 `json:"priority,omitempty"`
+}
+
+// V3Downlinks is the message that is published on the down/push and down/replace topics in the TTN v3 layout
+type V3Downlinks struct {
+	Downlinks []V3Downlink `json:"downlinks"`
+}
+
+// V3ErrorDetails is an error in the TTN v3 message format
+type V3ErrorDetails struct {
+	MessageFormat string `json:"message_format"`
+}
+
+// V3DownlinkFailed is a failed downlink in the TTN v3 message format
+type V3DownlinkFailed struct {
+	Downlink V3Downlink     `json:"downlink"`
+	Error    V3ErrorDetails `json:"error"`
+}
+
+// V3EntityIdentifiers identifies the entity of an event in the TTN v3 message format
+type V3EntityIdentifiers struct {
+	ApplicationIDs *V3ApplicationIdentifiers `json:"application_ids,omitempty"`
+}
+
+// V3Event is the message that is published on application event topics in the TTN v3 layout
+type V3Event struct {
+	Name        string                `json:"name"`
+	Time        *types.JSONTime       `json:"time,omitempty"`
+	Identifiers []V3EntityIdentifiers `json:"identifiers"`
+	Data        interface{}           `json:"data,omitempty"`
+}
+
+// NewV3AppEvent converts an application event to the TTN v3 message format
+func NewV3AppEvent(appID string, eventType types.EventType, data interface{}) *V3Event {
+	return &V3Event{
+		Name:        fmt.Sprintf("application.%s", strings.Replace(string(eventType), "/", ".", -1)),
+		Time:        v3Time(types.JSONTime(time.Now())),
+		Identifiers: []V3EntityIdentifiers{{ApplicationIDs: &V3ApplicationIdentifiers{ApplicationID: appID}}},
+		Data:        data,
+	}
+}
+
+// V3ApplicationUp is the message that is published on device topics in the TTN v3 layout.
+// Exactly one of the message fields is set.
+type V3ApplicationUp struct {
+	EndDeviceIDs   V3EndDeviceIdentifiers `json:"end_device_ids"`
+	ReceivedAt     *types.JSONTime        `json:"received_at,omitempty"`
+	UplinkMessage  *V3Uplink              `json:"uplink_message,omitempty"`
+	JoinAccept     *V3JoinAccept          `json:"join_accept,omitempty"`
+	DownlinkQueued *V3Downlink            `json:"downlink_queued,omitempty"`
+	DownlinkSent   *V3Downlink            `json:"downlink_sent,omitempty"`
+	DownlinkAck    *V3Downlink            `json:"downlink_ack,omitempty"`
+	DownlinkFailed *V3DownlinkFailed      `json:"downlink_failed,omitempty"`
+}
+
+func v3Time(t types.JSONTime) *types.JSONTime {
+	if time.Time(t).IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func v3Location(location types.LocationMetadata) *V3Location {
+	if location.Latitude == 0 && location.Longitude == 0 {
+		return nil
+	}
+	v3Location := &V3Location{
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Altitude:  location.Altitude,
+		Accuracy:  location.Accuracy,
+	}
+	switch location.Source {
+	case "gps":
+		v3Location.Source = "SOURCE_GPS"
+	case "config", "registry":
+		v3Location.Source = "SOURCE_REGISTRY"
+	case "ip_geolocation":
+		v3Location.Source = "SOURCE_IP_GEOLOCATION"
+	}
+	return v3Location
+}
+
+func v3Identifiers(appID, devID string) V3EndDeviceIdentifiers {
+	return V3EndDeviceIdentifiers{
+		DeviceID:       devID,
+		ApplicationIDs: V3ApplicationIdentifiers{ApplicationID: appID},
+	}
+}
+
+// NewV3ApplicationUplink converts an uplink message to the TTN v3 message format
+func NewV3ApplicationUplink(up types.UplinkMessage) *V3ApplicationUp {
+	ids := v3Identifiers(up.AppID, up.DevID)
+	if devEUI, err := types.ParseDevEUI(up.HardwareSerial); err == nil {
+		ids.DevEUI = &devEUI
+	}
+
+	uplink := &V3Uplink{
+		FPort:          up.FPort,
+		FCnt:           up.FCnt,
+		FRMPayload:     up.PayloadRaw,
+		DecodedPayload: up.PayloadFields,
+		ReceivedAt:     v3Time(up.Metadata.Time),
+		Confirmed:      up.Confirmed,
+		Settings: V3TxSettings{
+			CodingRate: up.Metadata.CodingRate,
+			Time:       v3Time(up.Metadata.Time),
+		},
+	}
+	if dataRate, err := types.ParseDataRate(up.Metadata.DataRate); err == nil {
+		uplink.Settings.DataRate.LoRa = &V3LoRaDataRate{
+			Bandwidth:       uint32(dataRate.Bandwidth) * 1000,
+			SpreadingFactor: uint32(dataRate.SpreadingFactor),
+		}
+	}
+	if up.Metadata.Frequency != 0 {
+		uplink.Settings.Frequency = strconv.FormatUint(uint64(math.Round(float64(up.Metadata.Frequency)*1000))*1000, 10)
+	}
+	if up.Metadata.Airtime != 0 {
+		uplink.ConsumedAirtime = fmt.Sprintf("%gs", up.Metadata.Airtime.Seconds())
+	}
+	for _, gateway := range up.Metadata.Gateways {
+		uplink.RxMetadata = append(uplink.RxMetadata, V3RxMetadata{
+			GatewayIDs:  V3GatewayIdentifiers{GatewayID: gateway.GtwID},
+			Time:        v3Time(gateway.Time),
+			Timestamp:   gateway.Timestamp,
+			RSSI:        gateway.RSSI,
+			ChannelRSSI: gateway.RSSI,
+			SNR:         gateway.SNR,
+			Channel:     gateway.Channel,
+			Location:    v3Location(gateway.LocationMetadata),
+		})
+	}
+
+	return &V3ApplicationUp{
+		EndDeviceIDs:  ids,
+		ReceivedAt:    uplink.ReceivedAt,
+		UplinkMessage: uplink,
+	}
+}
+
+// NewV3Downlink converts a downlink message to the TTN v3 message format
+func NewV3Downlink(down *types.DownlinkMessage) V3Downlink {
+	if down == nil {
+		return V3Downlink{}
+	}
+	return V3Downlink{
+		FPort:          down.FPort,
+		FRMPayload:     down.PayloadRaw,
+		DecodedPayload: down.PayloadFields,
+		Confirmed:      down.Confirmed,
+	}
+}
+
+// DownlinkMessage converts the downlink in the TTN v3 message format to a downlink message for the given device
+func (d V3Downlink) DownlinkMessage(appID, devID string, schedule types.ScheduleType) types.DownlinkMessage {
+	return types.DownlinkMessage{
+		AppID:         appID,
+		DevID:         devID,
+		FPort:         d.FPort,
+		Confirmed:     d.Confirmed,
+		Schedule:      schedule,
+		PayloadRaw:    d.FRMPayload,
+		PayloadFields: d.DecodedPayload,
+	}
+}
+
+// NewV3ApplicationEvent converts a device event to the TTN v3 message format. It returns the topic type of the
+// event in the TTN v3 layout, or false if the event has no equivalent in the TTN v3 layout.
+func NewV3ApplicationEvent(appID, devID string, eventType types.EventType, data interface{}) (*V3ApplicationUp, V3DeviceTopicType, bool) {
+	msg := &V3ApplicationUp{
+		EndDeviceIDs: v3Identifiers(appID, devID),
+		ReceivedAt:   v3Time(types.JSONTime(time.Now())),
+	}
+
+	switch data := data.(type) {
+	case *types.ActivationEventData:
+		if data == nil {
+			return nil, "", false
+		}
+		return NewV3ApplicationEvent(appID, devID, eventType, *data)
+	case *types.DownlinkEventData:
+		if data == nil {
+			return nil, "", false
+		}
+		return NewV3ApplicationEvent(appID, devID, eventType, *data)
+	case *types.ErrorEventData:
+		if data == nil {
+			return nil, "", false
+		}
+		return NewV3ApplicationEvent(appID, devID, eventType, *data)
+
+	case types.ActivationEventData:
+		if eventType != types.ActivationEvent {
+			return nil, "", false
+		}
+		msg.EndDeviceIDs.DevEUI = &data.DevEUI
+		msg.EndDeviceIDs.JoinEUI = &data.AppEUI
+		msg.EndDeviceIDs.DevAddr = &data.DevAddr
+		msg.JoinAccept = &V3JoinAccept{ReceivedAt: v3Time(data.Metadata.Time)}
+		if msg.JoinAccept.ReceivedAt != nil {
+			msg.ReceivedAt = msg.JoinAccept.ReceivedAt
+		}
+		return msg, V3DeviceJoin, true
+
+	case types.DownlinkEventData:
+		downlink := NewV3Downlink(data.Message)
+		if data.Config != nil {
+			downlink.FCnt = uint32(data.Config.FCnt)
+		}
+		switch eventType {
+		case types.DownlinkScheduledEvent:
+			msg.DownlinkQueued = &downlink
+			return msg, V3DeviceDownlinkQueued, true
+		case types.DownlinkSentEvent:
+			msg.DownlinkSent = &downlink
+			return msg, V3DeviceDownlinkSent, true
+		case types.DownlinkAckEvent:
+			msg.DownlinkAck = &downlink
+			return msg, V3DeviceDownlinkAck, true
+		case types.DownlinkErrorEvent:
+			msg.DownlinkFailed = &V3DownlinkFailed{
+				Downlink: downlink,
+				Error:    V3ErrorDetails{MessageFormat: data.Error},
+			}
+			return msg, V3DeviceDownlinkFailed, true
+		}
+
+	case types.ErrorEventData:
+		if eventType == types.DownlinkErrorEvent {
+			msg.DownlinkFailed = &V3DownlinkFailed{
+				Error: V3ErrorDetails{MessageFormat: data.Error},
+			}
+			return msg, V3DeviceDownlinkFailed, true
+		}
+	}
+
+	return nil, "", false
+}
@@ -0,0 +1,131 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestNewV3ApplicationUplink(t *testing.T) {
+	a := New(t)
+
+	up := NewV3ApplicationUplink(types.UplinkMessage{
+		AppID:          "appid",
+		DevID:          "devid",
+		HardwareSerial: "0102030405060708",
+		FPort:          1,
+		FCnt:           42,
+		PayloadRaw:     []byte{0x01, 0x02},
+		PayloadFields:  map[string]interface{}{"temperature": 21.5},
+		Metadata: types.Metadata{
+			Time:      types.BuildTime(time.Date(2017, 5, 2, 11, 4, 15, 0, time.UTC).UnixNano()),
+			Frequency: 868.1,
+			DataRate:  "SF7BW125",
+			Airtime:   46336 * time.Microsecond,
+			Gateways: []types.GatewayMetadata{
+				{GtwID: "gtwid", Timestamp: 1234, RSSI: -42, SNR: 7.5},
+			},
+		},
+	})
+
+	a.So(up.EndDeviceIDs.DeviceID, ShouldEqual, "devid")
+	a.So(up.EndDeviceIDs.ApplicationIDs.ApplicationID, ShouldEqual, "appid")
+	a.So(up.EndDeviceIDs.DevEUI.String(), ShouldEqual, "0102030405060708")
+	a.So(up.UplinkMessage.FCnt, ShouldEqual, 42)
+	a.So(up.UplinkMessage.Settings.Frequency, ShouldEqual, "868100000")
+	a.So(up.UplinkMessage.Settings.DataRate.LoRa, ShouldResemble, &V3LoRaDataRate{Bandwidth: 125000, SpreadingFactor: 7})
+	a.So(up.UplinkMessage.ConsumedAirtime, ShouldEqual, "0.046336s")
+	a.So(up.UplinkMessage.RxMetadata, ShouldHaveLength, 1)
+	a.So(up.UplinkMessage.RxMetadata[0].GatewayIDs.GatewayID, ShouldEqual, "gtwid")
+
+	msg, err := json.Marshal(up)
+	a.So(err, ShouldBeNil)
+	a.So(string(msg), ShouldContainSubstring, `"received_at":"2017-05-02T11:04:15Z"`)
+	a.So(string(msg), ShouldContainSubstring, `"frm_payload":"AQI="`)
+	a.So(string(msg), ShouldContainSubstring, `"decoded_payload":{"temperature":21.5}`)
+}
+
+func TestNewV3ApplicationEvent(t *testing.T) {
+	a := New(t)
+
+	_, _, ok := NewV3ApplicationEvent("appid", "devid", types.UplinkErrorEvent, types.ErrorEventData{Error: "err"})
+	a.So(ok, ShouldBeFalse)
+
+	up, topicType, ok := NewV3ApplicationEvent("appid", "devid", types.ActivationEvent, types.ActivationEventData{
+		DevAddr: types.DevAddr{0x26, 0x00, 0x1A, 0xDA},
+	})
+	a.So(ok, ShouldBeTrue)
+	a.So(topicType, ShouldEqual, V3DeviceJoin)
+	a.So(up.JoinAccept, ShouldNotBeNil)
+	a.So(up.EndDeviceIDs.DevAddr.String(), ShouldEqual, "26001ADA")
+
+	down := &types.DownlinkMessage{FPort: 2, PayloadRaw: []byte{0x01}}
+
+	up, topicType, ok = NewV3ApplicationEvent("appid", "devid", types.DownlinkScheduledEvent, types.DownlinkEventData{Message: down})
+	a.So(ok, ShouldBeTrue)
+	a.So(topicType, ShouldEqual, V3DeviceDownlinkQueued)
+	a.So(up.DownlinkQueued.FPort, ShouldEqual, 2)
+
+	up, topicType, ok = NewV3ApplicationEvent("appid", "devid", types.DownlinkSentEvent, &types.DownlinkEventData{
+		Message: down,
+		Config:  &types.DownlinkEventConfigInfo{FCnt: 3},
+	})
+	a.So(ok, ShouldBeTrue)
+	a.So(topicType, ShouldEqual, V3DeviceDownlinkSent)
+	a.So(up.DownlinkSent.FCnt, ShouldEqual, 3)
+
+	up, topicType, ok = NewV3ApplicationEvent("appid", "devid", types.DownlinkAckEvent, types.DownlinkEventData{Message: down})
+	a.So(ok, ShouldBeTrue)
+	a.So(topicType, ShouldEqual, V3DeviceDownlinkAck)
+	a.So(up.DownlinkAck, ShouldNotBeNil)
+
+	up, topicType, ok = NewV3ApplicationEvent("appid", "devid", types.DownlinkErrorEvent, types.ErrorEventData{Error: "No gateways available for downlink"})
+	a.So(ok, ShouldBeTrue)
+	a.So(topicType, ShouldEqual, V3DeviceDownlinkFailed)
+	a.So(up.DownlinkFailed.Error.MessageFormat, ShouldEqual, "No gateways available for downlink")
+}
+
+func TestNewV3AppEvent(t *testing.T) {
+	a := New(t)
+
+	event := NewV3AppEvent("appid", types.UpdateEvent, nil)
+	a.So(event.Name, ShouldEqual, "application.update")
+	a.So(event.Time, ShouldNotBeNil)
+	a.So(event.Identifiers, ShouldHaveLength, 1)
+	a.So(event.Identifiers[0].ApplicationIDs.ApplicationID, ShouldEqual, "appid")
+
+	event = NewV3AppEvent("appid", types.EventType("down/errors"), types.ErrorEventData{Error: "err"})
+	a.So(event.Name, ShouldEqual, "application.down.errors")
+	a.So(event.Data, ShouldResemble, types.ErrorEventData{Error: "err"})
+}
+
+func TestV3DownlinkMessage(t *testing.T) {
+	a := New(t)
+
+	downlinks := &V3Downlinks{}
+	err := json.Unmarshal([]byte(`{"downlinks":[{"f_port":1,"frm_payload":"AQI=","priority":"NORMAL"},{"f_port":2,"decoded_payload":{"on":true},"confirmed":true}]}`), downlinks)
+	a.So(err, ShouldBeNil)
+	a.So(downlinks.Downlinks, ShouldHaveLength, 2)
+
+	a.So(downlinks.Downlinks[0].DownlinkMessage("appid", "devid", types.ScheduleReplace), ShouldResemble, types.DownlinkMessage{
+		AppID:      "appid",
+		DevID:      "devid",
+		FPort:      1,
+		Schedule:   types.ScheduleReplace,
+		PayloadRaw: []byte{0x01, 0x02},
+	})
+	a.So(downlinks.Downlinks[1].DownlinkMessage("appid", "devid", types.ScheduleLast), ShouldResemble, types.DownlinkMessage{
+		AppID:         "appid",
+		DevID:         "devid",
+		FPort:         2,
+		Confirmed:     true,
+		Schedule:      types.ScheduleLast,
+		PayloadFields: map[string]interface{}{"on": true},
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqtt
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/TheThingsNetwork/ttn/utils/testing"
+	. "github.com/smartystreets/assertions"
+)
+
+func TestPublishV3Uplink(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "Test"), "test", "", "", fmt.Sprintf("tcp://%s", host))
+	c.Connect()
+	defer c.Disconnect()
+
+	token := c.PublishV3Uplink(types.UplinkMessage{
+		AppID:      "someid",
+		DevID:      "someid",
+		PayloadRaw: []byte{0x01, 0x02, 0x03, 0x04},
+	})
+	waitForOK(token, a)
+}
+
+func TestPublishV3DeviceEvent(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "Test"), "test", "", "", fmt.Sprintf("tcp://%s", host))
+	c.Connect()
+	defer c.Disconnect()
+
+	token := c.PublishV3DeviceEvent("someid", "someid", types.DownlinkScheduledEvent, types.DownlinkEventData{})
+	waitForOK(token, a)
+
+	// Not published
+	token = c.PublishV3DeviceEvent("someid", "someid", types.CreateEvent, nil)
+	waitForOK(token, a)
+}
+
+func TestPublishV3AppEvent(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "Test"), "test", "", "", fmt.Sprintf("tcp://%s", host))
+	c.Connect()
+	defer c.Disconnect()
+
+	token := c.PublishV3AppEvent("someid", types.UpdateEvent, nil)
+	waitForOK(token, a)
+}
+
+func TestPubSubV3Downlink(t *testing.T) {
+	a := New(t)
+	c := NewClient(getLogger(t, "Test"), "test", "", "", fmt.Sprintf("tcp://%s", host))
+	c.Connect()
+	defer c.Disconnect()
+
+	var wg WaitGroup
+
+	var mu sync.Mutex
+	var received []types.DownlinkMessage
+	var replaced []bool
+	token := c.SubscribeV3DeviceDownlink("app5", "dev1", func(client Client, appID string, devID string, replace bool, downlinks []types.DownlinkMessage) {
+		a.So(appID, ShouldEqual, "app5")
+		a.So(devID, ShouldEqual, "dev1")
+		mu.Lock()
+		received = append(received, downlinks...)
+		replaced = append(replaced, replace)
+		mu.Unlock()
+		wg.Done()
+	})
+	waitForOK(token, a)
+
+	client := c.(*DefaultClient)
+	wg.Add(1)
+	waitForOK(client.publish(DownlinkMessages, "v3/app5/devices/dev1/down/push", []byte(`{"downlinks":[{"f_port":1,"frm_payload":"AQ=="}]}`)), a)
+	a.So(wg.WaitFor(200*time.Millisecond), ShouldBeNil)
+	wg.Add(1)
+	waitForOK(client.publish(DownlinkMessages, "v3/app5/devices/dev1/down/replace", []byte(`{"downlinks":[{"f_port":2},{"f_port":3}]}`)), a)
+	a.So(wg.WaitFor(200*time.Millisecond), ShouldBeNil)
+	wg.Add(1)
+	waitForOK(client.publish(DownlinkMessages, "v3/app5/devices/dev1/down/replace", []byte(`{"downlinks":[]}`)), a)
+	a.So(wg.WaitFor(200*time.Millisecond), ShouldBeNil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	a.So(replaced, ShouldResemble, []bool{false, true, true})
+	a.So(received, ShouldHaveLength, 3)
+	for _, downlink := range received {
+		a.So(downlink.Schedule, ShouldEqual, types.ScheduleLast)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqtt
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// V3TopicPrefix is the prefix of topics in the layout of The Things Stack (TTN v3)
+const V3TopicPrefix = "v3"
+
+// V3DeviceTopicType represents the type of a device topic in the TTN v3 layout
+type V3DeviceTopicType string
+
+// Topic types for Devices in the TTN v3 layout
+const (
+	V3DeviceUplink          V3DeviceTopicType = "up"
+	V3DeviceJoin            V3DeviceTopicType = "join"
+	V3DeviceDownlinkQueued  V3DeviceTopicType = "down/queued"
+	V3DeviceDownlinkSent    V3DeviceTopicType = "down/sent"
+	V3DeviceDownlinkAck     V3DeviceTopicType = "down/ack"
+	V3DeviceDownlinkFailed  V3DeviceTopicType = "down/failed"
+	V3DeviceDownlinkPush    V3DeviceTopicType = "down/push"
+	V3DeviceDownlinkReplace V3DeviceTopicType = "down/replace"
+)
+
+// V3DeviceTopic represents an MQTT topic for devices in the TTN v3 layout
+type V3DeviceTopic struct {
+	AppID string
+	DevID string
+	Type  V3DeviceTopicType
+}
+
+// ParseV3DeviceTopic parses an MQTT device topic string in the TTN v3 layout to a V3DeviceTopic struct
+func ParseV3DeviceTopic(topic string) (*V3DeviceTopic, error) {
+	pattern := regexp.MustCompile("^v3/([0-9a-z](?:[_-]?[0-9a-z]){1,35}|\\+)/(devices)/([0-9a-z](?:[_-]?[0-9a-z]){1,35}|\\+)/(up|join|down/queued|down/sent|down/ack|down/failed|down/push|down/replace)$")
+	matches := pattern.FindStringSubmatch(topic)
+	if len(matches) < 5 {
+		return nil, fmt.Errorf("Invalid topic format")
+	}
+	var appID string
+	if matches[1] != simpleWildcard {
+		appID = matches[1]
+	}
+	var devID string
+	if matches[3] != simpleWildcard {
+		devID = matches[3]
+	}
+	return &V3DeviceTopic{appID, devID, V3DeviceTopicType(matches[4])}, nil
+}
+
+// String implements the Stringer interface
+func (t V3DeviceTopic) String() string {
+	appID := simpleWildcard
+	if t.AppID != "" {
+		appID = t.AppID
+	}
+	devID := simpleWildcard
+	if t.DevID != "" {
+		devID = t.DevID
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", V3TopicPrefix, appID, "devices", devID, t.Type)
+}
+
+// V3ApplicationEventTopic represents an MQTT topic for application events in the TTN v3 layout
+type V3ApplicationEventTopic struct {
+	AppID string
+	Event string
+}
+
+// ParseV3ApplicationEventTopic parses an MQTT application event topic string in the TTN v3 layout to a
+// V3ApplicationEventTopic struct
+func ParseV3ApplicationEventTopic(topic string) (*V3ApplicationEventTopic, error) {
+	pattern := regexp.MustCompile("^v3/([0-9a-z](?:[_-]?[0-9a-z]){1,35}|\\+)/(events)/([0-9a-z/-]+|#)$")
+	matches := pattern.FindStringSubmatch(topic)
+	if len(matches) < 4 {
+		return nil, fmt.Errorf("Invalid topic format")
+	}
+	var appID string
+	if matches[1] != simpleWildcard {
+		appID = matches[1]
+	}
+	var event string
+	if matches[3] != wildcard {
+		event = matches[3]
+	}
+	return &V3ApplicationEventTopic{appID, event}, nil
+}
+
+// String implements the Stringer interface
+func (t V3ApplicationEventTopic) String() string {
+	appID := simpleWildcard
+	if t.AppID != "" {
+		appID = t.AppID
+	}
+	event := wildcard
+	if t.Event != "" {
+		event = t.Event
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", V3TopicPrefix, appID, "events", event)
+}
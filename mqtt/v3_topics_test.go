@@ -0,0 +1,87 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package mqtt
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/assertions"
+)
+
+func TestParseV3DeviceTopic(t *testing.T) {
+	a := New(t)
+
+	got, err := ParseV3DeviceTopic("v3/appid-1/devices/devid-1/down/push")
+	a.So(err, ShouldBeNil)
+	a.So(got, ShouldResemble, &V3DeviceTopic{
+		AppID: "appid-1",
+		DevID: "devid-1",
+		Type:  V3DeviceDownlinkPush,
+	})
+}
+
+func TestParseV3DeviceTopicInvalid(t *testing.T) {
+	a := New(t)
+
+	_, err := ParseV3DeviceTopic("appid-1/devices/devid-1/up") // v2 topic
+	a.So(err, ShouldNotBeNil)
+
+	_, err = ParseV3DeviceTopic("v3/appid-1/devices/devid-1/down")
+	a.So(err, ShouldNotBeNil)
+
+	_, err = ParseV3DeviceTopic("v3/appid-1/devices/devid-1/events/activations")
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestV3DeviceTopicParseAndString(t *testing.T) {
+	a := New(t)
+
+	expectedList := []string{
+		"v3/appid/devices/devid/up",
+		"v3/appid/devices/devid/join",
+		"v3/appid/devices/devid/down/queued",
+		"v3/appid/devices/devid/down/sent",
+		"v3/appid/devices/devid/down/ack",
+		"v3/appid/devices/devid/down/failed",
+		"v3/appid/devices/devid/down/push",
+		"v3/appid/devices/devid/down/replace",
+		// Wildcards
+		"v3/+/devices/+/up",
+		"v3/appid/devices/+/down/push",
+	}
+
+	for _, expected := range expectedList {
+		topic, err := ParseV3DeviceTopic(expected)
+		a.So(err, ShouldBeNil)
+		a.So(topic.String(), ShouldEqual, expected)
+	}
+}
+
+func TestV3ApplicationEventTopicString(t *testing.T) {
+	a := New(t)
+
+	a.So(V3ApplicationEventTopic{"appid", "update"}.String(), ShouldEqual, "v3/appid/events/update")
+	a.So(V3ApplicationEventTopic{"appid", ""}.String(), ShouldEqual, "v3/appid/events/#")
+	a.So(V3ApplicationEventTopic{"", "update"}.String(), ShouldEqual, "v3/+/events/update")
+}
+
+func TestParseV3ApplicationEventTopic(t *testing.T) {
+	a := New(t)
+
+	for _, expected := range []string{
+		"v3/appid/events/update",
+		"v3/appid/events/activations/throttled",
+		"v3/appid/events/#",
+		"v3/+/events/update",
+	} {
+		topic, err := ParseV3ApplicationEventTopic(expected)
+		a.So(err, ShouldBeNil)
+		a.So(topic.String(), ShouldEqual, expected)
+	}
+
+	_, err := ParseV3ApplicationEventTopic("appid/events/update") // v2 topic
+	a.So(err, ShouldNotBeNil)
+	_, err = ParseV3ApplicationEventTopic("v3/appid/events")
+	a.So(err, ShouldNotBeNil)
+}
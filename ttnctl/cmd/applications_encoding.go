@@ -0,0 +1,62 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var applicationsEncodingCmd = &cobra.Command{
+	Use:   "encoding",
+	Short: "Show the encoding of MQTT and AMQP messages",
+	Long: `ttnctl applications encoding shows the encoding of the MQTT and AMQP messages that
+the Handler publishes for an application.`,
+	Example: `$ ttnctl applications encoding
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Found application                        AppID=test Encoding=json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 0, 0)
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		var md metadata.MD
+		_, err := handler.NewApplicationManagerClient(conn).GetApplication(manager.GetContext(), &handler.ApplicationIdentifier{AppID: appID}, grpc.Header(&md))
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get application.")
+		}
+
+		encoding := string(types.JSONEncoding)
+		if values := md.Get(types.EncodingKey); len(values) > 0 && values[0] != "" {
+			encoding = values[0]
+		}
+
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, struct {
+				AppID    string `json:"app_id"`
+				Encoding string `json:"encoding"`
+			}{appID, encoding})
+			return
+		}
+
+		ctx.WithFields(log.Fields{
+			"AppID":    appID,
+			"Encoding": encoding,
+		}).Info("Found application")
+	},
+}
+
+func init() {
+	applicationsCmd.AddCommand(applicationsEncodingCmd)
+}
@@ -0,0 +1,59 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+var applicationsEncodingSetCmd = &cobra.Command{
+	Use:   "set [json/cbor/protobuf]",
+	Short: "Set the encoding of MQTT and AMQP messages",
+	Long: `ttnctl applications encoding set sets the encoding of the MQTT and AMQP messages that
+the Handler publishes for an application. Messages in other encodings are published on topics
+with the encoding as extension (for example up.cbor) and with the content type of the encoding.
+Downlink messages are accepted in all encodings.`,
+	Example: `$ ttnctl applications encoding set cbor
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Updated encoding                         AppID=test Encoding=cbor
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 1, 1)
+
+		encoding, err := types.ParseEncoding(args[0])
+		if err != nil {
+			ctx.WithError(err).Fatal("Invalid encoding")
+		}
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		app, err := manager.GetApplication(appID)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get application.")
+		}
+
+		_, err = handler.NewApplicationManagerClient(conn).SetApplication(metadata.AppendToOutgoingContext(manager.GetContext(), types.EncodingKey, string(encoding)), app)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not update encoding")
+		}
+
+		ctx.WithFields(log.Fields{
+			"AppID":    appID,
+			"Encoding": encoding,
+		}).Infof("Updated encoding")
+	},
+}
+
+func init() {
+	applicationsEncodingCmd.AddCommand(applicationsEncodingSetCmd)
+}
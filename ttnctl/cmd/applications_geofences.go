@@ -0,0 +1,77 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var applicationsGeofencesCmd = &cobra.Command{
+	Use:   "geofences",
+	Short: "Show the geofences of an application",
+	Long: `ttnctl applications geofences shows the geofences of an application. The Handler
+emits geofence/enter and geofence/exit events when devices enter or exit them.`,
+	Example: `$ ttnctl applications geofences
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+[
+  {
+    "name": "warehouse",
+    "center": {
+      "latitude": 52.3736,
+      "longitude": 4.8865
+    },
+    "radius": 100
+  }
+]
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 0, 0)
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		var md metadata.MD
+		_, err := handler.NewApplicationManagerClient(conn).GetApplication(manager.GetContext(), &handler.ApplicationIdentifier{AppID: appID}, grpc.Header(&md))
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get application.")
+		}
+
+		values := md.Get(types.GeofencesKey)
+		if len(values) == 0 || values[0] == "" {
+			ctx.Info("No geofences")
+			return
+		}
+
+		if util.IsStructuredOutput() {
+			var geofences []interface{}
+			if err := json.Unmarshal([]byte(values[0]), &geofences); err != nil {
+				ctx.WithError(err).Fatal("Could not read geofences")
+			}
+			util.PrintOutput(ctx, geofences)
+			return
+		}
+
+		var out bytes.Buffer
+		if err := json.Indent(&out, []byte(values[0]), "", "  "); err != nil {
+			ctx.WithError(err).Fatal("Could not read geofences")
+		}
+		fmt.Println(out.String())
+	},
+}
+
+func init() {
+	applicationsCmd.AddCommand(applicationsGeofencesCmd)
+}
@@ -0,0 +1,81 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+var applicationsGeofencesSetCmd = &cobra.Command{
+	Use:   "set [file]",
+	Short: "Set the geofences of an application",
+	Long: `ttnctl applications geofences set sets the geofences of an application from a file or stdin.
+Geofences are a JSON array of named polygons ("polygon": [{"latitude": 52.37, "longitude": 4.88}, ...])
+and circles ("center": {"latitude": 52.37, "longitude": 4.88}, "radius": 100). Devices exit a geofence
+when they are more than "hysteresis" meters (default 20) outside it. Set an empty file to remove the
+geofences.`,
+	Example: `$ ttnctl applications geofences set geofences.json
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Updated geofences                        AppID=test
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 0, 1)
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		app, err := manager.GetApplication(appID)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get application.")
+		}
+
+		var geofences string
+		if len(args) == 1 {
+			content, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				ctx.WithError(err).Fatal("Could not read geofences file")
+			}
+			geofences = strings.TrimSpace(string(content))
+		} else {
+			fmt.Println(`[
+  {
+    "name": "warehouse",
+    "center": { "latitude": 52.3736, "longitude": 4.8865 },
+    "radius": 100
+  }
+]
+########## Write your geofences here and end with Ctrl+D (EOF):`)
+			geofences = readFunction(ctx)
+		}
+		if geofences != "" && !json.Valid([]byte(geofences)) {
+			ctx.Fatal("Invalid geofences: not valid JSON")
+		}
+
+		_, err = handler.NewApplicationManagerClient(conn).SetApplication(metadata.AppendToOutgoingContext(manager.GetContext(), types.GeofencesKey, geofences), app)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not update geofences")
+		}
+
+		ctx.WithFields(log.Fields{
+			"AppID": appID,
+		}).Infof("Updated geofences")
+	},
+}
+
+func init() {
+	applicationsGeofencesCmd.AddCommand(applicationsGeofencesSetCmd)
+}
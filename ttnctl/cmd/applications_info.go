@@ -48,6 +48,11 @@ Collaborators:
 			ctx.WithError(err).Fatal("Could not find application")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, app)
+			return
+		}
+
 		ctx.Info("Found application")
 
 		fmt.Println()
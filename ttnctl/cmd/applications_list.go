@@ -32,6 +32,11 @@ var applicationsListCmd = &cobra.Command{
 			ctx.WithError(err).Fatal("Could not list applications")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, apps)
+			return
+		}
+
 		switch len(apps) {
 		case 0:
 			ctx.Info("You don't have any applications")
@@ -0,0 +1,64 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var applicationsLoRaWANMetadataCmd = &cobra.Command{
+	Use:   "lorawan-metadata",
+	Short: "Show if uplink messages contain the LoRaWAN frame header",
+	Long: `ttnctl applications lorawan-metadata shows if the uplink messages of an application
+contain the LoRaWAN frame header in the lorawan field.`,
+	Example: `$ ttnctl applications lorawan-metadata
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Found application                        AppID=test LoRaWANMetadata=false
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 0, 0)
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		var md metadata.MD
+		_, err := handler.NewApplicationManagerClient(conn).GetApplication(manager.GetContext(), &handler.ApplicationIdentifier{AppID: appID}, grpc.Header(&md))
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get application.")
+		}
+
+		var lorawanMetadata bool
+		if values := md.Get(types.LoRaWANMetadataKey); len(values) > 0 {
+			lorawanMetadata, _ = strconv.ParseBool(values[0])
+		}
+
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, struct {
+				AppID           string `json:"app_id"`
+				LoRaWANMetadata bool   `json:"lorawan_metadata"`
+			}{appID, lorawanMetadata})
+			return
+		}
+
+		ctx.WithFields(log.Fields{
+			"AppID":           appID,
+			"LoRaWANMetadata": lorawanMetadata,
+		}).Info("Found application")
+	},
+}
+
+func init() {
+	applicationsCmd.AddCommand(applicationsLoRaWANMetadataCmd)
+}
@@ -0,0 +1,60 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+var applicationsLoRaWANMetadataSetCmd = &cobra.Command{
+	Use:   "set [true/false]",
+	Short: "Set if uplink messages contain the LoRaWAN frame header",
+	Long: `ttnctl applications lorawan-metadata set sets if the uplink messages of an application
+contain the LoRaWAN frame header in the lorawan field. Uplink messages that acknowledge a
+confirmed downlink always contain it, with the acknowledged downlink.`,
+	Example: `$ ttnctl applications lorawan-metadata set true
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Updated LoRaWAN metadata                 AppID=test LoRaWANMetadata=true
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 1, 1)
+
+		lorawanMetadata, err := strconv.ParseBool(args[0])
+		if err != nil {
+			ctx.WithError(err).Fatal("Invalid value, expected true or false")
+		}
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		app, err := manager.GetApplication(appID)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get application.")
+		}
+
+		_, err = handler.NewApplicationManagerClient(conn).SetApplication(metadata.AppendToOutgoingContext(manager.GetContext(), types.LoRaWANMetadataKey, strconv.FormatBool(lorawanMetadata)), app)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not update LoRaWAN metadata")
+		}
+
+		ctx.WithFields(log.Fields{
+			"AppID":           appID,
+			"LoRaWANMetadata": lorawanMetadata,
+		}).Infof("Updated LoRaWAN metadata")
+	},
+}
+
+func init() {
+	applicationsLoRaWANMetadataCmd.AddCommand(applicationsLoRaWANMetadataSetCmd)
+}
@@ -44,6 +44,11 @@ function Decoder(bytes, port) {
 			ctx.WithError(err).Fatal("Could not get application.")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, app)
+			return
+		}
+
 		ctx.Info("Found application")
 
 		switch app.PayloadFormat {
@@ -4,22 +4,37 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/TheThingsNetwork/api/handler"
 	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/handler/schema"
+	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/ttnctl/util"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
 )
 
 var applicationsPayloadFormatSetCmd = &cobra.Command{
-	Use:   "set [decoder/converter/validator/encoder/cayennelpp] [file.js]",
+	Use:   "set [decoder/converter/validator/encoder/cayennelpp/uplink-schema/downlink-schema/location] [file]",
 	Short: "Set payload format of an application",
 	Long: `ttnctl pf set can be used to get or set the payload format and functions of an application.
-When using payload functions, you can load a file or provide them through stdin.`,
+When using payload functions, you can load a file or provide them through stdin.
+Payload functions can use the helpers in the global ttn object, such as ttn.int16LE(bytes, offset),
+ttn.bits(value, start, length), ttn.bcd(bytes, offset, length), ttn.float32BE(bytes, offset) and
+ttn.base64Encode(bytes). Add a "// @ttn-library 1" comment to pin the version of the helpers.
+The uplink-schema and downlink-schema are JSON Schemas (draft 7) that the payload fields should match.
+Uplink messages with fields that do not match are dropped, unless --forward-raw is set. Schemas can
+not reference other documents. Set an empty schema to remove it.
+The location maps payload fields to the location of devices, for example {"gps": "gps_1"} for
+CayenneLPP GPS channel 1, or {"latitude": "lat", "longitude": "lng", "accuracy": "acc"}. The
+location is updated when the device moved at least min_distance meters, and locations that are
+less accurate than max_accuracy meters are ignored. Set an empty location to remove it.`,
 	Example: `$ ttnctl applications pf set decoder
   INFO Discovering Handler...
   INFO Connecting with Handler...
@@ -72,7 +87,63 @@ Port: 1
 
 		format := args[0]
 
+		var md []string
+
 		switch format {
+		case "uplink-schema", "downlink-schema":
+			var fieldsSchema string
+			if len(args) == 2 {
+				content, err := ioutil.ReadFile(args[1])
+				if err != nil {
+					ctx.WithError(err).Fatal("Could not read schema file")
+				}
+				fieldsSchema = strings.TrimSpace(string(content))
+			} else {
+				fmt.Println(`{
+  "type": "object",
+  "properties": {
+    "temperature": { "type": "number", "minimum": -40, "maximum": 85 }
+  }
+}
+########## Write your JSON Schema here and end with Ctrl+D (EOF):`)
+				fieldsSchema = readFunction(ctx)
+			}
+			if fieldsSchema != "" {
+				if _, err := schema.Parse([]byte(fieldsSchema)); err != nil {
+					ctx.WithError(err).Fatal("Invalid JSON Schema")
+				}
+			}
+			if format == "uplink-schema" {
+				md = append(md, types.UplinkFieldsSchemaKey, fieldsSchema)
+				if cmd.Flags().Changed("forward-raw") {
+					forwardRaw, _ := cmd.Flags().GetBool("forward-raw")
+					md = append(md, types.ForwardRawOnInvalidFieldsKey, strconv.FormatBool(forwardRaw))
+				}
+			} else {
+				md = append(md, types.DownlinkFieldsSchemaKey, fieldsSchema)
+			}
+		case "location":
+			var locationFields string
+			if len(args) == 2 {
+				content, err := ioutil.ReadFile(args[1])
+				if err != nil {
+					ctx.WithError(err).Fatal("Could not read location file")
+				}
+				locationFields = strings.TrimSpace(string(content))
+			} else {
+				fmt.Println(`{
+  "latitude": "latitude",
+  "longitude": "longitude",
+  "altitude": "altitude",
+  "min_distance": 10
+}
+########## Write your location fields here and end with Ctrl+D (EOF):`)
+				locationFields = readFunction(ctx)
+			}
+			if locationFields != "" && !json.Valid([]byte(locationFields)) {
+				ctx.Fatal("Invalid location fields: not valid JSON")
+			}
+			md = append(md, types.LocationFieldsKey, locationFields)
 		case "decoder", "converter", "validator", "encoder":
 			app.PayloadFormat = "custom"
 			if len(args) == 2 {
@@ -211,7 +282,11 @@ Function read from %s:
 			app.PayloadFormat = format
 		}
 
-		err = manager.SetApplication(app)
+		if len(md) > 0 {
+			_, err = handler.NewApplicationManagerClient(conn).SetApplication(metadata.AppendToOutgoingContext(manager.GetContext(), md...), app)
+		} else {
+			err = manager.SetApplication(app)
+		}
 		if err != nil {
 			ctx.WithError(err).Fatal("Could not update application")
 		}
@@ -224,6 +299,7 @@ Function read from %s:
 
 func init() {
 	applicationsPayloadFormatSetCmd.Flags().Bool("skip-test", false, "skip payload format test")
+	applicationsPayloadFormatSetCmd.Flags().Bool("forward-raw", false, "forward uplink messages with invalid fields without the fields")
 	applicationsPayloadFormatCmd.AddCommand(applicationsPayloadFormatSetCmd)
 }
 
@@ -31,6 +31,11 @@ my-gateway-client       https://myttnclient.org/gateways/oauth/callback
 			ctx.WithError(err).Fatal("Failed to fetch the OAuth clients")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, clients)
+			return
+		}
+
 		switch len(clients) {
 		case 0:
 			ctx.Info("You don't have any OAuth client")
@@ -31,6 +31,11 @@ Created:        2016-10-06 09:52:28.766 +0000 UTC
 			ctx.WithError(err).WithField("type", args[0]).WithField("id", args[1]).Fatal("Could not find component")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, component)
+			return
+		}
+
 		ctx.Info("Found network component")
 
 		fmt.Println()
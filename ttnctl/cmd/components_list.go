@@ -61,6 +61,21 @@ var componentsListCmd = &cobra.Command{
 			}
 		}
 
+		if util.IsStructuredOutput() {
+			filtered := make([]account.Component, 0, len(components))
+			if typ == "" || typ == "routers" || typ == "router" {
+				filtered = append(filtered, routers...)
+			}
+			if typ == "" || typ == "brokers" || typ == "broker" {
+				filtered = append(filtered, brokers...)
+			}
+			if typ == "" || typ == "handlers" || typ == "handler" {
+				filtered = append(filtered, handlers...)
+			}
+			util.PrintOutput(ctx, filtered)
+			return
+		}
+
 		if typ == "" || typ == "routers" || typ == "router" {
 			ctx.Info(fmt.Sprintf("Found %v %s", len(routers), plural(len(routers), "router")))
 
@@ -0,0 +1,23 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var contextCmd = &cobra.Command{
+	Use:     "context",
+	Aliases: []string{"contexts"},
+	Short:   "Manage configuration contexts",
+	Long: `ttnctl context can be used to manage configuration contexts.
+
+A context bundles the Account server, Discovery server, Router and Handler IDs
+and MQTT broker to use, and keeps separate credentials and CA certificates.
+The active context can be overridden with the --context flag.`,
+}
+
+func init() {
+	RootCmd.AddCommand(contextCmd)
+}
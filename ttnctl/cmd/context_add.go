@@ -0,0 +1,55 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+)
+
+var contextAddCmd = &cobra.Command{
+	Use:   "add [Name]",
+	Short: "Add a configuration context",
+	Long: `ttnctl context add can be used to add a configuration context.
+
+The context is created from the configuration that is currently used, so the
+settings of the context can be passed with the global flags (such as
+--auth-server, --discovery-address, --router-id, --handler-id and --mqtt-address).
+If a context with the same name already exists, it is replaced.`,
+	Example: `$ ttnctl context add staging --auth-server https://account.staging.example.com --discovery-address discovery.staging.example.com:1900 --handler-id staging-handler --ca-cert ca.cert --use
+  INFO Added context                            Context=staging DiscoveryAddress=discovery.staging.example.com:1900 HandlerID=staging-handler
+  INFO Selected context                         Context=staging
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 1, 1)
+
+		name := args[0]
+		caCert, _ := cmd.Flags().GetString("ca-cert")
+
+		context := util.ContextFromConfig()
+		if err := util.AddContext(name, context, caCert); err != nil {
+			ctx.WithError(err).Fatal("Could not add context")
+		}
+
+		ctx.WithFields(ttnlog.Fields{
+			"Context":          name,
+			"DiscoveryAddress": context.DiscoveryAddress,
+			"HandlerID":        context.HandlerID,
+		}).Info("Added context")
+
+		if use, _ := cmd.Flags().GetBool("use"); use {
+			if err := util.UseContext(name); err != nil {
+				ctx.WithError(err).Fatal("Could not select context")
+			}
+			ctx.WithField("Context", name).Info("Selected context")
+		}
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextAddCmd)
+	contextAddCmd.Flags().String("ca-cert", "", "Location of the CA certificate to use for this context")
+	contextAddCmd.Flags().Bool("use", false, "Select the context after adding it")
+}
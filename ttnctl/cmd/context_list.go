@@ -0,0 +1,73 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+)
+
+var contextListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List configuration contexts",
+	Long:    `ttnctl context list can be used to list configuration contexts.`,
+	Example: `$ ttnctl context list
+  INFO Found 2 contexts:
+
+ 	Name      	Auth Server                        	Discovery Address          	Handler ID
+ 	default   	                                   	                           	
+*	staging   	https://account.staging.example.com	discovery.staging.example.com:1900	staging-handler
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 0, 0)
+
+		names, contexts, err := util.GetContexts()
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not list contexts")
+		}
+
+		current := util.GetContextName()
+
+		if util.IsStructuredOutput() {
+			currentName := current
+			if currentName == "" {
+				currentName = util.DefaultContext
+			}
+			util.PrintOutput(ctx, struct {
+				Current  string                   `json:"current"`
+				Contexts map[string]*util.Context `json:"contexts"`
+			}{currentName, contexts})
+			return
+		}
+
+		ctx.Infof("Found %d %s:", len(names)+1, plural(len(names)+1, "context"))
+
+		table := uitable.New()
+		table.MaxColWidth = 70
+		table.AddRow("", "Name", "Auth Server", "Discovery Address", "Handler ID")
+		marker := func(name string) string {
+			if name == current {
+				return "*"
+			}
+			return ""
+		}
+		table.AddRow(marker(""), util.DefaultContext)
+		for _, name := range names {
+			context := contexts[name]
+			table.AddRow(marker(name), name, context.AuthServer, context.DiscoveryAddress, context.HandlerID)
+		}
+
+		fmt.Println()
+		fmt.Println(table)
+		fmt.Println()
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextListCmd)
+}
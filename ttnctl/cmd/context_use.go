@@ -0,0 +1,34 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+)
+
+var contextUseCmd = &cobra.Command{
+	Use:   "use [Name]",
+	Short: "Select the configuration context to use",
+	Long: `ttnctl context use can be used to select the configuration context to use in next commands.
+
+Use the name "default" to go back to the plain configuration.`,
+	Example: `$ ttnctl context use staging
+  INFO Selected context                         Context=staging
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 1, 1)
+
+		name := args[0]
+		if err := util.UseContext(name); err != nil {
+			ctx.WithError(err).Fatal("Could not select context")
+		}
+
+		ctx.WithField("Context", name).Info("Selected context")
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextUseCmd)
+}
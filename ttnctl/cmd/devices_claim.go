@@ -0,0 +1,83 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/TheThingsNetwork/api"
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/api/protocol/lorawan"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+var devicesClaimCmd = &cobra.Command{
+	Use:   "claim [DevEUI] [Claim Code]",
+	Short: "Claim a device",
+	Long: `ttnctl devices claim registers a claimable device in the selected application, using the
+AppKey from the manifest that the device was imported with (see "ttnctl devices import-manifest").
+The device is then no longer claimable. The Device ID is the DevEUI, unless --dev-id is set.`,
+	Example: `$ ttnctl devices claim 0001D544B2936FCE C1A1MC0DE
+  INFO Using Application                        AppEUI=70B3D57EF0000024 AppID=test
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Claimed device                           AppEUI=70B3D57EF0000024 AppID=test DevEUI=0001D544B2936FCE DevID=0001d544b2936fce
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 2, 2)
+
+		devEUI, err := types.ParseDevEUI(args[0])
+		if err != nil {
+			ctx.Fatalf("Invalid DevEUI: %s", err)
+		}
+
+		devID, _ := cmd.Flags().GetString("dev-id")
+		if devID == "" {
+			devID = devEUI.String()
+		}
+		devID = strings.ToLower(devID)
+		if err := api.NotEmptyAndValidID(devID, "Device ID"); err != nil {
+			ctx.Fatal(err.Error())
+		}
+
+		appID := util.GetAppID(ctx)
+		appEUI := util.GetAppEUI(ctx)
+
+		device := &handler.Device{
+			AppID: appID,
+			DevID: devID,
+			Device: &handler.Device_LoRaWANDevice{LoRaWANDevice: &lorawan.Device{
+				AppID:         appID,
+				DevID:         devID,
+				AppEUI:        appEUI,
+				DevEUI:        devEUI,
+				Uses32BitFCnt: true,
+			}},
+		}
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		_, err = handler.NewApplicationManagerClient(conn).SetDevice(metadata.AppendToOutgoingContext(manager.GetContext(), types.ClaimCodeKey, args[1]), device)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not claim device")
+		}
+
+		ctx.WithFields(ttnlog.Fields{
+			"AppID":  appID,
+			"DevID":  devID,
+			"AppEUI": appEUI,
+			"DevEUI": devEUI,
+		}).Info("Claimed device")
+	},
+}
+
+func init() {
+	devicesCmd.AddCommand(devicesClaimCmd)
+	devicesClaimCmd.Flags().String("dev-id", "", "The Device ID of the claimed device")
+}
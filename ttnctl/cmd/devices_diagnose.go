@@ -0,0 +1,190 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TheThingsNetwork/api"
+	"github.com/TheThingsNetwork/api/discovery"
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/api/protocol/lorawan"
+	"github.com/TheThingsNetwork/go-account-lib/scope"
+	"github.com/TheThingsNetwork/go-utils/grpc/ttnctx"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/mqtt"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context" // See https://github.com/grpc/grpc-go/issues/711"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var devicesDiagnoseCmd = &cobra.Command{
+	Use:   "diagnose [Device ID]",
+	Short: "Diagnose a device",
+	Long: `ttnctl devices diagnose can be used to find out why a device does not work.
+
+It checks the device on the Handler, its queued downlinks, its session and
+recent frames on the NetworkServer and the ownership of its DevAddr prefix in
+Discovery. With --watch, it also subscribes
+to the uplink messages and events of the device for the given duration, to
+inspect the frame counters, data rates and errors.`,
+	Example: `$ ttnctl devices diagnose test --watch 2m
+  INFO Using Application                        AppEUI=70B3D57EF0000024 AppID=test
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Discovering Brokers...
+  INFO Connecting with Broker...                Broker=localhost:1902
+  INFO Watching device for 2m0s...
+
+	Check        	Severity	Finding
+	activation   	ok      	Device joined with DevAddr 26001ADA
+	session      	ok      	Handler and NetworkServer have the same session
+	prefix       	ok      	DevAddr 26001ADA is handled by Broker dev (26000000/7)
+	last seen    	ok      	Device was last seen at 2017-05-02T11:04:15+02:00
+	frames       	ok      	Received 20 of the last 20 frames (FCnt 3 to 22)
+	uplinks      	ok      	Received 2 uplink messages, data rates: SF7BW125 (2)
+	uplink errors	error   	Uplink error: Decoder: TypeError: Cannot read property "0" of undefined
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 1, 1)
+
+		devID := strings.ToLower(args[0])
+		if err := api.NotEmptyAndValidID(devID, "Device ID"); err != nil {
+			ctx.Fatal(err.Error())
+		}
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		var md metadata.MD
+		dev, err := handler.NewApplicationManagerClient(conn).GetDevice(manager.GetContext(), &handler.DeviceIdentifier{AppID: appID, DevID: devID}, grpc.Header(&md))
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get existing device.")
+		}
+
+		threshold, _ := cmd.Flags().GetDuration("last-seen")
+		diagnosis := &util.DeviceDiagnosis{
+			Device:            dev,
+			Brokers:           util.GetBrokers(ctx),
+			LastSeenThreshold: threshold,
+		}
+
+		if values := md.Get(types.DownlinkQueueKey); len(values) > 0 {
+			diagnosis.QueuedDownlinks, _ = strconv.Atoi(values[0])
+		}
+
+		if lorawanDev := dev.GetLoRaWANDevice(); lorawanDev != nil && lorawanDev.DevAddr != nil && !lorawanDev.DevAddr.IsEmpty() {
+			if broker := brokerForDevAddr(diagnosis.Brokers, *lorawanDev.DevAddr); broker != nil {
+				brkConn, deviceManager := util.GetBrokerDeviceManager(ctx, broker)
+				defer brkConn.Close()
+				token := util.TokenForScope(ctx, scope.App(appID))
+				var nsMD metadata.MD
+				nsDev, err := deviceManager.GetDevice(ttnctx.OutgoingContextWithToken(context.Background(), token), &lorawan.DeviceIdentifier{
+					AppEUI: lorawanDev.AppEUI,
+					DevEUI: lorawanDev.DevEUI,
+				}, grpc.Header(&nsMD))
+				if err == nil {
+					diagnosis.NetworkServerDevice = nsDev
+					if values := nsMD.Get(types.FrameHistoryKey); len(values) > 0 {
+						json.Unmarshal([]byte(values[0]), &diagnosis.Frames)
+					}
+				} else if errors.GetErrType(errors.FromGRPCError(err)) != errors.NotFound {
+					ctx.WithError(errors.FromGRPCError(err)).Warn("Could not get device from NetworkServer")
+				}
+			}
+		}
+
+		if watch, _ := cmd.Flags().GetDuration("watch"); watch > 0 {
+			accessKey, _ := cmd.Flags().GetString("access-key")
+			watchDevice(diagnosis, util.GetMQTT(ctx, accessKey), appID, devID, watch)
+		}
+
+		findings := diagnosis.Diagnose()
+
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, findings)
+			return
+		}
+
+		table := uitable.New()
+		table.MaxColWidth = 100
+		table.AddRow("", "Check", "Severity", "Finding")
+		for _, finding := range findings {
+			table.AddRow("", finding.Check, finding.Severity, finding.Message)
+		}
+
+		fmt.Println()
+		fmt.Println(table)
+		fmt.Println()
+	},
+}
+
+func brokerForDevAddr(brokers []*discovery.Announcement, devAddr types.DevAddr) *discovery.Announcement {
+	for _, broker := range brokers {
+		for _, prefix := range broker.DevAddrPrefixes() {
+			if devAddr.HasPrefix(prefix) {
+				return broker
+			}
+		}
+	}
+	return nil
+}
+
+func watchDevice(diagnosis *util.DeviceDiagnosis, client mqtt.Client, appID, devID string, duration time.Duration) {
+	var mu sync.Mutex
+
+	token := client.SubscribeDeviceUplink(appID, devID, func(_ mqtt.Client, _ string, _ string, msg types.UplinkMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		diagnosis.Uplinks = append(diagnosis.Uplinks, msg)
+	})
+	token.Wait()
+	if err := token.Error(); err != nil {
+		ctx.WithError(err).Fatal("Could not subscribe to uplink")
+	}
+
+	token = client.SubscribeDeviceEvents(appID, devID, "#", func(_ mqtt.Client, _ string, _ string, eventType types.EventType, payload []byte) {
+		event := types.DeviceEvent{AppID: appID, DevID: devID, Event: eventType}
+		switch eventType {
+		case types.UplinkErrorEvent, types.DownlinkErrorEvent, types.ActivationErrorEvent, types.ActivationThrottledEvent:
+			data := new(types.ErrorEventData)
+			if err := json.Unmarshal(payload, data); err == nil {
+				event.Data = data
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		diagnosis.Events = append(diagnosis.Events, event)
+	})
+	token.Wait()
+	if err := token.Error(); err != nil {
+		ctx.WithError(err).Fatal("Could not subscribe to events")
+	}
+
+	ctx.Infof("Watching device for %s...", duration)
+	time.Sleep(duration)
+	client.Disconnect()
+
+	mu.Lock()
+	defer mu.Unlock()
+	diagnosis.Watched = true
+}
+
+func init() {
+	devicesCmd.AddCommand(devicesDiagnoseCmd)
+	devicesDiagnoseCmd.Flags().Duration("last-seen", time.Hour, "Report devices that were not seen for longer than this duration")
+	devicesDiagnoseCmd.Flags().Duration("watch", 0, "Watch the uplink messages and events of the device for this duration")
+	devicesDiagnoseCmd.Flags().String("access-key", "", "The access key to use for watching the device")
+}
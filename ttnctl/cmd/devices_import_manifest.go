@@ -0,0 +1,122 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+// manifestBatchSize is the number of devices that are imported per request, to keep the request metadata small
+const manifestBatchSize = 50
+
+type claimableDevice struct {
+	DevEUI    types.DevEUI `json:"dev_eui"`
+	AppKey    types.AppKey `json:"app_key"`
+	ClaimCode string       `json:"claim_code"`
+}
+
+var devicesImportManifestCmd = &cobra.Command{
+	Use:   "import-manifest [file]",
+	Short: "Import a manifest of claimable devices",
+	Long: `ttnctl devices import-manifest imports a manifest of devices into the claimable devices
+of the application. The manifest is a CSV file (or stdin) with a DevEUI, AppKey and claim code on
+each line; lines that start with # are ignored.
+
+Claimable devices are not registered. They can be claimed into any application with their claim code
+using "ttnctl devices claim", or register on join in this application (see "ttnctl devices register
+on-join"). A device is no longer claimable once it is registered.`,
+	Example: `$ ttnctl devices import-manifest manifest.csv
+  INFO Using Application                        AppEUI=70B3D57EF0000024 AppID=test
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Imported manifest                        AppID=test Devices=2
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 0, 1)
+
+		var in io.Reader = os.Stdin
+		if len(args) == 1 {
+			file, err := os.Open(args[0])
+			if err != nil {
+				ctx.WithError(err).Fatal("Could not open manifest")
+			}
+			defer file.Close()
+			in = file
+		}
+
+		reader := csv.NewReader(in)
+		reader.Comment = '#'
+		reader.FieldsPerRecord = 3
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not read manifest")
+		}
+
+		devices := make([]claimableDevice, 0, len(records))
+		for _, record := range records {
+			devEUI, err := types.ParseDevEUI(record[0])
+			if err != nil {
+				ctx.WithError(err).Fatalf("Invalid DevEUI %s", record[0])
+			}
+			appKey, err := types.ParseAppKey(record[1])
+			if err != nil {
+				ctx.WithError(err).Fatalf("Invalid AppKey for %s", devEUI)
+			}
+			claimCode := strings.TrimSpace(record[2])
+			if claimCode == "" {
+				ctx.Fatalf("Missing claim code for %s", devEUI)
+			}
+			devices = append(devices, claimableDevice{DevEUI: devEUI, AppKey: appKey, ClaimCode: claimCode})
+		}
+		if len(devices) == 0 {
+			ctx.Fatal("Manifest does not contain devices")
+		}
+
+		appID := util.GetAppID(ctx)
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		app, err := manager.GetApplication(appID)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not get application.")
+		}
+
+		for start := 0; start < len(devices); start += manifestBatchSize {
+			end := start + manifestBatchSize
+			if end > len(devices) {
+				end = len(devices)
+			}
+			manifest, err := json.Marshal(devices[start:end])
+			if err != nil {
+				ctx.WithError(err).Fatal("Could not encode manifest")
+			}
+			_, err = handler.NewApplicationManagerClient(conn).SetApplication(metadata.AppendToOutgoingContext(manager.GetContext(), types.ClaimableDevicesKey, string(manifest)), app)
+			if err != nil {
+				ctx.WithError(err).WithField("Imported", start).Fatal("Could not import manifest")
+			}
+		}
+
+		ctx.WithFields(log.Fields{
+			"AppID":   appID,
+			"Devices": len(devices),
+		}).Info("Imported manifest")
+	},
+}
+
+func init() {
+	devicesCmd.AddCommand(devicesImportManifestCmd)
+}
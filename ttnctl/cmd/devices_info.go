@@ -4,13 +4,18 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/TheThingsNetwork/api"
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/ttn/core/types"
 	"github.com/TheThingsNetwork/ttn/ttnctl/util"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 var devicesInfoCmd = &cobra.Command{
@@ -52,11 +57,22 @@ var devicesInfoCmd = &cobra.Command{
 		conn, manager := util.GetHandlerManager(ctx, appID)
 		defer conn.Close()
 
-		dev, err := manager.GetDevice(appID, devID)
+		var md metadata.MD
+		dev, err := handler.NewApplicationManagerClient(conn).GetDevice(manager.GetContext(), &handler.DeviceIdentifier{AppID: appID, DevID: devID}, grpc.Header(&md))
 		if err != nil {
 			ctx.WithError(err).Fatal("Could not get existing device.")
 		}
 
+		var suspension types.Suspension
+		if values := md.Get(types.SuspensionKey); len(values) > 0 {
+			json.Unmarshal([]byte(values[0]), &suspension)
+		}
+
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, dev)
+			return
+		}
+
 		byteFormat, _ := cmd.Flags().GetString("format")
 
 		ctx.Info("Found device")
@@ -74,6 +90,14 @@ var devicesInfoCmd = &cobra.Command{
 			fmt.Printf("        Location: %f,%f\n", dev.Latitude, dev.Longitude)
 		}
 
+		if suspension.Suspended {
+			fmt.Printf("       Suspended: since %s", time.Time(suspension.Time))
+			if suspension.Reason != "" {
+				fmt.Printf(" (%s)", suspension.Reason)
+			}
+			fmt.Println()
+		}
+
 		if lorawan := dev.GetLoRaWANDevice(); lorawan != nil {
 			lastSeen := "never"
 			if lorawan.LastSeen > 0 {
@@ -40,6 +40,11 @@ test 	70B3D57EF0000024	0001D544B2936FCE	26001ADA
 			ctx.WithError(err).Fatal("Could not get devices.")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, devices)
+			return
+		}
+
 		table := uitable.New()
 		table.MaxColWidth = 70
 		table.AddRow("DevID", "AppEUI", "DevEUI", "DevAddr", "Description")
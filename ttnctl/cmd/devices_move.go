@@ -0,0 +1,97 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/TheThingsNetwork/api"
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/api/protocol/lorawan"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+var devicesMoveCmd = &cobra.Command{
+	Use:   "move [Device ID] [Application ID]",
+	Short: "Move a device to another application",
+	Long: `ttnctl devices move moves a device to another application on the same Handler. The device keeps
+its keys, session, frame counters, attributes and queued downlinks, so it does not have to rejoin.
+You need the devices right on both applications.
+
+The Handler API has no MoveDevice RPC. The move is a SetDevice request for the device in the other
+application, with the move-from-app-id and move-from-dev-id request metadata. A Handler that does not
+support this metadata creates a new device instead of moving it.`,
+	Example: `$ ttnctl devices move test other-app --to-dev-id test-2
+  INFO Using Application                        AppEUI=70B3D57EF0000024 AppID=test
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Moved device                             AppID=test DevID=test ToAppID=other-app ToDevID=test-2
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 2, 2)
+
+		devID := strings.ToLower(args[0])
+		if err := api.NotEmptyAndValidID(devID, "Device ID"); err != nil {
+			ctx.Fatal(err.Error())
+		}
+		toAppID := strings.ToLower(args[1])
+		if err := api.NotEmptyAndValidID(toAppID, "Application ID"); err != nil {
+			ctx.Fatal(err.Error())
+		}
+		toDevID, _ := cmd.Flags().GetString("to-dev-id")
+		if toDevID == "" {
+			toDevID = devID
+		}
+		toDevID = strings.ToLower(toDevID)
+		if err := api.NotEmptyAndValidID(toDevID, "Device ID"); err != nil {
+			ctx.Fatal(err.Error())
+		}
+
+		var toAppEUI types.AppEUI
+		if appEUI, _ := cmd.Flags().GetString("to-app-eui"); appEUI != "" {
+			var err error
+			toAppEUI, err = types.ParseAppEUI(appEUI)
+			if err != nil {
+				ctx.Fatalf("Invalid AppEUI: %s", err)
+			}
+		}
+
+		appID := util.GetAppID(ctx)
+
+		device := &handler.Device{
+			AppID: toAppID,
+			DevID: toDevID,
+			Device: &handler.Device_LoRaWANDevice{LoRaWANDevice: &lorawan.Device{
+				AppID:  toAppID,
+				DevID:  toDevID,
+				AppEUI: toAppEUI,
+			}},
+		}
+
+		conn, manager := util.GetHandlerManager(ctx, appID)
+		defer conn.Close()
+
+		_, err := handler.NewApplicationManagerClient(conn).SetDevice(metadata.AppendToOutgoingContext(manager.GetContext(), types.MoveFromAppIDKey, appID, types.MoveFromDevIDKey, devID), device)
+		if err != nil {
+			ctx.WithError(err).Fatal("Could not move device")
+		}
+
+		ctx.WithFields(ttnlog.Fields{
+			"AppID":   appID,
+			"DevID":   devID,
+			"ToAppID": toAppID,
+			"ToDevID": toDevID,
+		}).Info("Moved device")
+	},
+}
+
+func init() {
+	devicesCmd.AddCommand(devicesMoveCmd)
+	devicesMoveCmd.Flags().String("to-dev-id", "", "The Device ID in the other application (default is the current Device ID)")
+	devicesMoveCmd.Flags().String("to-app-eui", "", "The AppEUI in the other application (default is the current AppEUI)")
+}
@@ -23,7 +23,9 @@ const registerOnJoinAccessKeyName = "register-on-join"
 var devicesRegisterOnJoinCmd = &cobra.Command{
 	Use:   "on-join [Device ID Prefix] [AppKey]",
 	Short: "Register a new device on join",
-	Long:  `ttnctl devices register on-join can be used to register a device template for on-join registrations.`,
+	Long: `ttnctl devices register on-join can be used to register a device template for on-join registrations.
+Only devices in the manifest of the application can register on join, using the AppKey from the
+manifest (see "ttnctl devices import-manifest").`,
 	Run: func(cmd *cobra.Command, args []string) {
 		assertArgsLength(cmd, args, 1, 2)
 
@@ -0,0 +1,51 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/TheThingsNetwork/api"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+)
+
+var devicesResumeCmd = &cobra.Command{
+	Use:   "resume [Device ID]",
+	Short: "Resume a suspended device",
+	Long: `ttnctl devices resume resumes a device that was suspended with "ttnctl devices suspend".
+The device continues with its keys, frame counters and session, so it does not have to rejoin.
+
+Resuming a device requires the settings right of the application, or the settings right of
+the Handler.`,
+	Example: `$ ttnctl devices resume test
+  INFO Using Application                        AppEUI=70B3D57EF0000024 AppID=test
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Resumed device                           AppID=test DevID=test
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 1, 1)
+
+		devID := strings.ToLower(args[0])
+		if err := api.NotEmptyAndValidID(devID, "Device ID"); err != nil {
+			ctx.Fatal(err.Error())
+		}
+
+		appID := util.GetAppID(ctx)
+
+		setDeviceSuspension(appID, devID, types.Suspension{Suspended: false})
+
+		ctx.WithFields(ttnlog.Fields{
+			"AppID": appID,
+			"DevID": devID,
+		}).Info("Resumed device")
+	},
+}
+
+func init() {
+	devicesCmd.AddCommand(devicesResumeCmd)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/TheThingsNetwork/api"
+	"github.com/TheThingsNetwork/api/handler"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	"github.com/TheThingsNetwork/ttn/ttnctl/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/metadata"
+)
+
+var devicesSuspendCmd = &cobra.Command{
+	Use:   "suspend [Device ID] [Reason]",
+	Short: "Suspend a device",
+	Long: `ttnctl devices suspend suspends a device. The uplinks of a suspended device are dropped,
+its joins are rejected and its downlinks are refused, but it keeps its keys, frame counters and
+session. Use "ttnctl devices resume" to resume the device.
+
+Suspending a device requires the settings right of the application, or the settings right of
+the Handler.`,
+	Example: `$ ttnctl devices suspend test "Unpaid invoice"
+  INFO Using Application                        AppEUI=70B3D57EF0000024 AppID=test
+  INFO Discovering Handler...
+  INFO Connecting with Handler...
+  INFO Suspended device                         AppID=test DevID=test Reason=Unpaid invoice
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertArgsLength(cmd, args, 1, 2)
+
+		devID := strings.ToLower(args[0])
+		if err := api.NotEmptyAndValidID(devID, "Device ID"); err != nil {
+			ctx.Fatal(err.Error())
+		}
+
+		suspension := types.Suspension{Suspended: true}
+		if len(args) == 2 {
+			suspension.Reason = args[1]
+		}
+
+		appID := util.GetAppID(ctx)
+
+		setDeviceSuspension(appID, devID, suspension)
+
+		ctx.WithFields(ttnlog.Fields{
+			"AppID":  appID,
+			"DevID":  devID,
+			"Reason": suspension.Reason,
+		}).Info("Suspended device")
+	},
+}
+
+// setDeviceSuspension sets the device again with the suspension in the request metadata, see core/handler/suspension.go
+func setDeviceSuspension(appID, devID string, suspension types.Suspension) {
+	conn, manager := util.GetHandlerManager(ctx, appID)
+	defer conn.Close()
+
+	dev, err := manager.GetDevice(appID, devID)
+	if err != nil {
+		ctx.WithError(err).Fatal("Could not get existing device.")
+	}
+
+	data, err := json.Marshal(suspension)
+	if err != nil {
+		ctx.WithError(err).Fatal("Could not encode suspension")
+	}
+
+	_, err = handler.NewApplicationManagerClient(conn).SetDevice(metadata.AppendToOutgoingContext(manager.GetContext(), types.SuspensionKey, string(data)), dev)
+	if err != nil {
+		ctx.WithError(err).Fatal("Could not update device")
+	}
+}
+
+func init() {
+	devicesCmd.AddCommand(devicesSuspendCmd)
+}
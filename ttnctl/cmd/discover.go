@@ -49,6 +49,11 @@ var discoverCmd = &cobra.Command{
 
 		ctx.Infof("Discovered %d %ss", len(res.Services), serviceType)
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, res.Services)
+			return
+		}
+
 		fmt.Printf(serviceFmt, "ID", "ADDRESS", "VERSION", "PUBLIC")
 		fmt.Printf(serviceFmt, "==", "=======", "=======", "======")
 		fmt.Println()
@@ -31,6 +31,11 @@ var gatewaysInfoCmd = &cobra.Command{
 			ctx.WithError(err).WithField("id", gatewayID).Fatal("Could not find gateway")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, gateway)
+			return
+		}
+
 		ctx.Info("Found gateway")
 
 		fmt.Println()
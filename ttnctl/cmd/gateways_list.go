@@ -29,6 +29,11 @@ var gatewaysListCmd = &cobra.Command{
 			ctx.WithError(err).Fatal("Could not list gateways")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, gateways)
+			return
+		}
+
 		table := uitable.New()
 		table.MaxColWidth = 70
 		table.AddRow("", "ID", "Activated", "Frequency Plan", "Coordinates")
@@ -40,6 +40,11 @@ var gatewaysStatusCmd = &cobra.Command{
 			ctx.WithError(errors.FromGRPCError(err)).Fatal("Could not get status of gateway.")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, resp)
+			return
+		}
+
 		ctx.Infof("Received status")
 		fmt.Println()
 		printKV("Last seen", time.Unix(0, resp.LastSeen))
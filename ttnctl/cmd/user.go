@@ -35,6 +35,11 @@ var userCmd = &cobra.Command{
 			ctx.WithError(err).Fatal("Could not get user profile")
 		}
 
+		if util.IsStructuredOutput() {
+			util.PrintOutput(ctx, profile)
+			return
+		}
+
 		ctx.Info("Found user profile:")
 		fmt.Println()
 		printKV("Username", profile.Username)
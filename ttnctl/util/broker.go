@@ -0,0 +1,36 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"github.com/TheThingsNetwork/api/discovery"
+	"github.com/TheThingsNetwork/api/protocol/lorawan"
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/TheThingsNetwork/ttn/utils/errors"
+	"google.golang.org/grpc"
+)
+
+// GetBrokers gets the Broker announcements from the Discovery server
+func GetBrokers(ctx ttnlog.Interface) []*discovery.Announcement {
+	ctx.Info("Discovering Brokers...")
+	dscConn, client := GetDiscovery(ctx)
+	defer dscConn.Close()
+	res, err := client.GetAll(GetContext(ctx), &discovery.GetServiceRequest{
+		ServiceName: "broker",
+	})
+	if err != nil {
+		ctx.WithError(errors.FromGRPCError(err)).Fatal("Could not get Brokers from Discovery")
+	}
+	return res.Services
+}
+
+// GetBrokerDeviceManager starts a device management connection with the broker
+func GetBrokerDeviceManager(ctx ttnlog.Interface, brokerAnnouncement *discovery.Announcement) (*grpc.ClientConn, lorawan.DeviceManagerClient) {
+	ctx.WithField("Broker", brokerAnnouncement.NetAddress).Info("Connecting with Broker...")
+	brkConn, err := brokerAnnouncement.Dial(nil)
+	if err != nil {
+		ctx.WithError(err).Fatal("Could not connect to Broker")
+	}
+	return brkConn, lorawan.NewDeviceManagerClient(brkConn)
+}
@@ -69,13 +69,23 @@ func GetConfigFile() string {
 }
 
 // GetDataDir returns the location of the data directory used for
+// storing data of the active context (see GetContextName).
+// If no context is active, this is the base data directory.
+func GetDataDir() string {
+	if name := GetContextName(); name != "" {
+		return contextDataDir(name)
+	}
+	return GetBaseDataDir()
+}
+
+// GetBaseDataDir returns the location of the data directory used for
 // sotring data.
 // It checks the following (in this order):
 // the --data flag
 // $XDG_DATA_HOME/ttnctl (if $XDG_DATA_HOME is set)
 // $XDG_CACHE_HOME/ttnctl (if $XDG_CACHE_HOME is set)
 // $HOME/.ttnctl
-func GetDataDir() string {
+func GetBaseDataDir() string {
 	file := viper.GetString("data")
 	if file != "" {
 		return file
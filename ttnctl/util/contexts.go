@@ -0,0 +1,216 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/TheThingsNetwork/api"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	contextsFilename = "contexts.yml"
+	contextsDir      = "contexts"
+
+	// DefaultContext is the name of the context that uses the plain configuration
+	DefaultContext = "default"
+)
+
+// Context is a named set of configuration options. Each context also has its
+// own data directory, so that credentials, the CA certificate and the selected
+// application are kept separately per context.
+type Context struct {
+	AuthServer       string `yaml:"auth-server,omitempty" json:"auth_server,omitempty"`
+	DiscoveryAddress string `yaml:"discovery-address,omitempty" json:"discovery_address,omitempty"`
+	RouterID         string `yaml:"router-id,omitempty" json:"router_id,omitempty"`
+	HandlerID        string `yaml:"handler-id,omitempty" json:"handler_id,omitempty"`
+	MQTTAddress      string `yaml:"mqtt-address,omitempty" json:"mqtt_address,omitempty"`
+	MQTTUsername     string `yaml:"mqtt-username,omitempty" json:"mqtt_username,omitempty"`
+	MQTTPassword     string `yaml:"mqtt-password,omitempty" json:"-"`
+}
+
+// settings returns the configuration keys and values of the context
+func (c *Context) settings() map[string]string {
+	return map[string]string{
+		"auth-server":       c.AuthServer,
+		"discovery-address": c.DiscoveryAddress,
+		"router-id":         c.RouterID,
+		"handler-id":        c.HandlerID,
+		"mqtt-address":      c.MQTTAddress,
+		"mqtt-username":     c.MQTTUsername,
+		"mqtt-password":     c.MQTTPassword,
+	}
+}
+
+// ContextFromConfig builds a Context from the configuration that is currently used
+func ContextFromConfig() *Context {
+	return &Context{
+		AuthServer:       viper.GetString("auth-server"),
+		DiscoveryAddress: viper.GetString("discovery-address"),
+		RouterID:         viper.GetString("router-id"),
+		HandlerID:        viper.GetString("handler-id"),
+		MQTTAddress:      viper.GetString("mqtt-address"),
+		MQTTUsername:     viper.GetString("mqtt-username"),
+		MQTTPassword:     viper.GetString("mqtt-password"),
+	}
+}
+
+type contextsConfig struct {
+	Current  string              `yaml:"current,omitempty"`
+	Contexts map[string]*Context `yaml:"contexts,omitempty"`
+}
+
+func readContexts() (*contextsConfig, error) {
+	config := &contextsConfig{Contexts: make(map[string]*Context)}
+	data, err := ioutil.ReadFile(path.Join(GetBaseDataDir(), contextsFilename))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("Could not read contexts file: %s", err.Error())
+	}
+	if config.Contexts == nil {
+		config.Contexts = make(map[string]*Context)
+	}
+	return config, nil
+}
+
+func writeContexts(config *contextsConfig) error {
+	dir := GetBaseDataDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Could not create data directory: %s", err.Error())
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("Could not generate contexts file contents: %s", err.Error())
+	}
+	if err := ioutil.WriteFile(path.Join(dir, contextsFilename), data, 0600); err != nil {
+		return fmt.Errorf("Could not write contexts file: %s", err.Error())
+	}
+	return nil
+}
+
+func contextDataDir(name string) string {
+	return path.Join(GetBaseDataDir(), contextsDir, name)
+}
+
+// GetContextName returns the name of the active context.
+// It checks the following (in this order):
+// the --context flag (or $TTNCTL_CONTEXT)
+// the context selected with "ttnctl context use"
+// If the default context is active, it returns an empty string.
+func GetContextName() string {
+	name := viper.GetString("context")
+	if name == "" {
+		if config, err := readContexts(); err == nil {
+			name = config.Current
+		}
+	}
+	if name == DefaultContext {
+		return ""
+	}
+	return name
+}
+
+// GetContexts returns the names of all configured contexts and the contexts themselves
+func GetContexts() ([]string, map[string]*Context, error) {
+	config, err := readContexts()
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, config.Contexts, nil
+}
+
+// AddContext stores the context with the given name, replacing any existing context with that name.
+// If caCert is not empty, the file at that location is used as CA certificate for the context.
+func AddContext(name string, context *Context, caCert string) error {
+	if name == DefaultContext {
+		return fmt.Errorf("Context name %s is reserved", DefaultContext)
+	}
+	if err := api.NotEmptyAndValidID(name, "Context name"); err != nil {
+		return err
+	}
+
+	dir := contextDataDir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Could not create context data directory: %s", err.Error())
+	}
+	if caCert != "" {
+		cert, err := ioutil.ReadFile(caCert)
+		if err != nil {
+			return fmt.Errorf("Could not read CA certificate: %s", err.Error())
+		}
+		if err := ioutil.WriteFile(path.Join(dir, "ca.cert"), cert, 0644); err != nil {
+			return fmt.Errorf("Could not write CA certificate: %s", err.Error())
+		}
+	}
+
+	config, err := readContexts()
+	if err != nil {
+		return err
+	}
+	config.Contexts[name] = context
+	return writeContexts(config)
+}
+
+// UseContext selects the context with the given name for the next commands
+func UseContext(name string) error {
+	config, err := readContexts()
+	if err != nil {
+		return err
+	}
+	if name != DefaultContext {
+		if _, ok := config.Contexts[name]; !ok {
+			return fmt.Errorf("Context %s does not exist", name)
+		}
+	}
+	config.Current = name
+	return writeContexts(config)
+}
+
+// ApplyContext overrides the configuration with the settings of the active context.
+// Settings that were explicitly set with flags or environment variables are not overridden.
+func ApplyContext(flags *pflag.FlagSet) error {
+	name := GetContextName()
+	if name == "" {
+		return nil
+	}
+	config, err := readContexts()
+	if err != nil {
+		return err
+	}
+	context, ok := config.Contexts[name]
+	if !ok {
+		return fmt.Errorf("Context %s does not exist", name)
+	}
+	for key, value := range context.settings() {
+		if value == "" {
+			continue
+		}
+		if flag := flags.Lookup(key); flag != nil && flag.Changed {
+			continue
+		}
+		if os.Getenv("TTNCTL_"+strings.ToUpper(strings.Replace(key, "-", "_", -1))) != "" {
+			continue
+		}
+		viper.Set(key, value)
+	}
+	return nil
+}
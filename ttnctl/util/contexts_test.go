@@ -0,0 +1,61 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	. "github.com/smartystreets/assertions"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestContexts(t *testing.T) {
+	a := New(t)
+
+	dir, err := ioutil.TempDir("", "ttnctl")
+	a.So(err, ShouldBeNil)
+	defer os.RemoveAll(dir)
+
+	viper.Set("data", dir)
+	defer viper.Set("data", "")
+
+	a.So(GetContextName(), ShouldEqual, "")
+	a.So(GetDataDir(), ShouldEqual, dir)
+
+	a.So(AddContext(DefaultContext, &Context{}, ""), ShouldNotBeNil)
+	a.So(UseContext("staging"), ShouldNotBeNil)
+
+	err = AddContext("staging", &Context{
+		AuthServer: "https://account.staging",
+		HandlerID:  "staging-handler",
+	}, "")
+	a.So(err, ShouldBeNil)
+	a.So(UseContext("staging"), ShouldBeNil)
+
+	a.So(GetContextName(), ShouldEqual, "staging")
+	a.So(GetDataDir(), ShouldEqual, path.Join(dir, "contexts", "staging"))
+
+	names, contexts, err := GetContexts()
+	a.So(err, ShouldBeNil)
+	a.So(names, ShouldResemble, []string{"staging"})
+	a.So(contexts["staging"].HandlerID, ShouldEqual, "staging-handler")
+
+	flags := &pflag.FlagSet{}
+	flags.String("auth-server", "", "")
+	flags.String("handler-id", "", "")
+	flags.Set("auth-server", "https://account.local")
+	viper.BindPFlags(flags)
+
+	a.So(ApplyContext(flags), ShouldBeNil)
+	a.So(viper.GetString("auth-server"), ShouldEqual, "https://account.local")
+	a.So(viper.GetString("handler-id"), ShouldEqual, "staging-handler")
+
+	a.So(UseContext(DefaultContext), ShouldBeNil)
+	a.So(GetContextName(), ShouldEqual, "")
+	a.So(GetDataDir(), ShouldEqual, dir)
+}
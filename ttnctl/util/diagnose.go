@@ -0,0 +1,305 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TheThingsNetwork/api/discovery"
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/api/protocol/lorawan"
+	"github.com/TheThingsNetwork/ttn/core/types"
+)
+
+// Finding severities
+const (
+	SeverityOK      = "ok"
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Finding is the result of a single check of a device diagnosis
+type Finding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// DeviceDiagnosis contains the information that is used to diagnose a device
+type DeviceDiagnosis struct {
+	// The device as returned by the Handler
+	Device *handler.Device
+	// The device as returned by the NetworkServer, nil if the NetworkServer does not know the device
+	NetworkServerDevice *lorawan.Device
+	// The Brokers that are announced in Discovery
+	Brokers []*discovery.Announcement
+	// The number of downlinks that are queued on the Handler, including the downlink for the next uplink
+	QueuedDownlinks int
+	// The recent frames of the device that the NetworkServer keeps for ADR, newest first
+	Frames []*types.Frame
+	// The uplink messages that were received while watching the device
+	Uplinks []types.UplinkMessage
+	// The events that were received while watching the device
+	Events []types.DeviceEvent
+	// Whether the device was watched
+	Watched bool
+
+	// The maximum time since the device was last seen
+	LastSeenThreshold time.Duration
+	Now               time.Time
+}
+
+const maxFCnt16 = 1 << 16
+
+// Diagnose runs all checks and returns the findings
+func (d *DeviceDiagnosis) Diagnose() []Finding {
+	if d.Now.IsZero() {
+		d.Now = time.Now()
+	}
+	checks := []func() []Finding{
+		d.checkActivation,
+		d.checkSession,
+		d.checkPrefix,
+		d.checkLastSeen,
+		d.checkFCnt,
+		d.checkFrames,
+		d.checkDownlinks,
+		d.checkUplinks,
+		d.checkEvents,
+	}
+	var findings []Finding
+	for _, check := range checks {
+		findings = append(findings, check()...)
+	}
+	return findings
+}
+
+func (d *DeviceDiagnosis) lorawan() *lorawan.Device {
+	if d.Device == nil {
+		return nil
+	}
+	return d.Device.GetLoRaWANDevice()
+}
+
+func (d *DeviceDiagnosis) devAddr() types.DevAddr {
+	if dev := d.lorawan(); dev != nil && dev.DevAddr != nil {
+		return *dev.DevAddr
+	}
+	return types.DevAddr{}
+}
+
+func (d *DeviceDiagnosis) checkActivation() []Finding {
+	dev := d.lorawan()
+	if dev == nil {
+		return []Finding{{"activation", SeverityError, "Device is not a LoRaWAN device"}}
+	}
+	otaa := dev.AppKey != nil && !dev.AppKey.IsEmpty()
+	if d.devAddr().IsEmpty() {
+		if otaa {
+			return []Finding{{"activation", SeverityError, "Device never joined: there is no session on the Handler"}}
+		}
+		return []Finding{{"activation", SeverityError, "Device has no session and no AppKey: it can not be activated"}}
+	}
+	if otaa {
+		return []Finding{{"activation", SeverityOK, fmt.Sprintf("Device joined with DevAddr %s", d.devAddr())}}
+	}
+	return []Finding{{"activation", SeverityOK, fmt.Sprintf("Device is personalized with DevAddr %s", d.devAddr())}}
+}
+
+func (d *DeviceDiagnosis) checkSession() []Finding {
+	dev := d.lorawan()
+	if dev == nil || d.devAddr().IsEmpty() {
+		return nil
+	}
+	ns := d.NetworkServerDevice
+	if ns == nil {
+		return []Finding{{"session", SeverityError, "NetworkServer does not have a session for the device"}}
+	}
+	var nsDevAddr types.DevAddr
+	if ns.DevAddr != nil {
+		nsDevAddr = *ns.DevAddr
+	}
+	if nsDevAddr != d.devAddr() {
+		return []Finding{{"session", SeverityError, fmt.Sprintf("Session mismatch: DevAddr is %s on the Handler, but %s on the NetworkServer", d.devAddr(), nsDevAddr)}}
+	}
+	if dev.NwkSKey != nil && ns.NwkSKey != nil && !dev.NwkSKey.IsEmpty() && !ns.NwkSKey.IsEmpty() && *dev.NwkSKey != *ns.NwkSKey {
+		return []Finding{{"session", SeverityError, "Session mismatch: NwkSKey on the Handler differs from the NwkSKey on the NetworkServer"}}
+	}
+	if dev.AppEUI != ns.AppEUI || dev.DevEUI != ns.DevEUI {
+		return []Finding{{"session", SeverityError, fmt.Sprintf("Session mismatch: device is registered as %s/%s on the NetworkServer", ns.AppEUI, ns.DevEUI)}}
+	}
+	return []Finding{{"session", SeverityOK, "Handler and NetworkServer have the same session"}}
+}
+
+func (d *DeviceDiagnosis) checkPrefix() []Finding {
+	devAddr := d.devAddr()
+	if devAddr.IsEmpty() || len(d.Brokers) == 0 {
+		return nil
+	}
+	var owners []string
+	for _, broker := range d.Brokers {
+		for _, prefix := range broker.DevAddrPrefixes() {
+			if devAddr.HasPrefix(prefix) {
+				owners = append(owners, fmt.Sprintf("%s (%s)", broker.ID, prefix))
+				break
+			}
+		}
+	}
+	switch len(owners) {
+	case 0:
+		return []Finding{{"prefix", SeverityError, fmt.Sprintf("DevAddr %s is not in a prefix announced in Discovery: uplinks will not be routed", devAddr)}}
+	case 1:
+		return []Finding{{"prefix", SeverityOK, fmt.Sprintf("DevAddr %s is handled by Broker %s", devAddr, owners[0])}}
+	default:
+		return []Finding{{"prefix", SeverityInfo, fmt.Sprintf("DevAddr %s is handled by multiple Brokers: %s", devAddr, strings.Join(owners, ", "))}}
+	}
+}
+
+func (d *DeviceDiagnosis) lastSeen() time.Time {
+	if dev := d.lorawan(); dev != nil && dev.LastSeen > 0 {
+		return time.Unix(0, dev.LastSeen)
+	}
+	return time.Time{}
+}
+
+func (d *DeviceDiagnosis) checkLastSeen() []Finding {
+	if d.devAddr().IsEmpty() {
+		return nil
+	}
+	lastSeen := d.lastSeen()
+	if lastSeen.IsZero() {
+		return []Finding{{"last seen", SeverityError, "No gateway ever heard the device"}}
+	}
+	if ago := d.Now.Sub(lastSeen); d.LastSeenThreshold > 0 && ago > d.LastSeenThreshold {
+		return []Finding{{"last seen", SeverityWarning, fmt.Sprintf("No gateway heard the device recently: last seen %s ago", ago.Truncate(time.Second))}}
+	}
+	return []Finding{{"last seen", SeverityOK, fmt.Sprintf("Device was last seen at %s", lastSeen.Format(time.RFC3339))}}
+}
+
+func (d *DeviceDiagnosis) checkFCnt() (findings []Finding) {
+	dev := d.lorawan()
+	if dev == nil || d.devAddr().IsEmpty() {
+		return nil
+	}
+	if !dev.Uses32BitFCnt && dev.FCntUp >= maxFCnt16-100 {
+		findings = append(findings, Finding{"fcnt", SeverityWarning, fmt.Sprintf("FCntUp %d is about to roll over, but the device does not use 32 bit frame counters", dev.FCntUp)})
+	}
+	if len(d.Uplinks) > 1 {
+		repeated := 0
+		for i := 1; i < len(d.Uplinks); i++ {
+			if d.Uplinks[i].FCnt == d.Uplinks[i-1].FCnt && !d.Uplinks[i].IsRetry {
+				repeated++
+			}
+		}
+		if repeated > 0 {
+			findings = append(findings, Finding{"fcnt", SeverityWarning, fmt.Sprintf("FCnt is stuck: %d uplinks were received with a repeated frame counter", repeated)})
+		}
+	}
+	return
+}
+
+// maxFrameLoss is the fraction of lost frames in the frame history above which a warning is reported
+const maxFrameLoss = 0.1
+
+func (d *DeviceDiagnosis) checkFrames() (findings []Finding) {
+	if len(d.Frames) == 0 {
+		return nil
+	}
+	newest, oldest := d.Frames[0].FCnt, d.Frames[len(d.Frames)-1].FCnt
+	if newest >= oldest {
+		expected := int(newest-oldest) + 1
+		lost := expected - len(d.Frames)
+		if lost > 0 && float64(lost)/float64(expected) > maxFrameLoss {
+			findings = append(findings, Finding{"frames", SeverityWarning, fmt.Sprintf("Lost %d of the last %d frames (FCnt %d to %d)", lost, expected, oldest, newest)})
+		} else {
+			findings = append(findings, Finding{"frames", SeverityOK, fmt.Sprintf("Received %d of the last %d frames (FCnt %d to %d)", len(d.Frames), expected, oldest, newest)})
+		}
+	}
+	singleGateway := true
+	for _, frame := range d.Frames {
+		if frame.GatewayCount > 1 {
+			singleGateway = false
+			break
+		}
+	}
+	if singleGateway {
+		findings = append(findings, Finding{"frames", SeverityInfo, fmt.Sprintf("The last %d frames were all received by only one gateway", len(d.Frames))})
+	}
+	return
+}
+
+func (d *DeviceDiagnosis) checkDownlinks() []Finding {
+	if d.QueuedDownlinks == 0 {
+		return nil
+	}
+	if lastSeen := d.lastSeen(); d.LastSeenThreshold > 0 && (lastSeen.IsZero() || d.Now.Sub(lastSeen) > d.LastSeenThreshold) {
+		return []Finding{{"downlinks", SeverityWarning, fmt.Sprintf("%d downlinks are queued, but the device did not send uplinks recently: downlinks are only sent in response to uplinks", d.QueuedDownlinks)}}
+	}
+	return []Finding{{"downlinks", SeverityInfo, fmt.Sprintf("%d downlinks are queued: they are sent in response to the next uplinks", d.QueuedDownlinks)}}
+}
+
+func (d *DeviceDiagnosis) checkUplinks() []Finding {
+	if !d.Watched {
+		return nil
+	}
+	if len(d.Uplinks) == 0 {
+		return []Finding{{"uplinks", SeverityInfo, "No uplink messages were received while watching the device"}}
+	}
+	dataRates := make(map[string]int)
+	minGateways := -1
+	for _, uplink := range d.Uplinks {
+		dataRates[uplink.Metadata.DataRate]++
+		if gateways := len(uplink.Metadata.Gateways); minGateways < 0 || gateways < minGateways {
+			minGateways = gateways
+		}
+	}
+	rates := make([]string, 0, len(dataRates))
+	for dataRate, count := range dataRates {
+		rates = append(rates, fmt.Sprintf("%s (%d)", dataRate, count))
+	}
+	sort.Strings(rates)
+	findings := []Finding{{"uplinks", SeverityOK, fmt.Sprintf("Received %d uplink messages, data rates: %s", len(d.Uplinks), strings.Join(rates, ", "))}}
+	if minGateways == 1 {
+		findings = append(findings, Finding{"adr", SeverityInfo, "Some uplinks were received by only one gateway"})
+	}
+	if len(dataRates) == 1 && len(d.Uplinks) > 1 && strings.HasPrefix(d.Uplinks[0].Metadata.DataRate, "SF12") {
+		findings = append(findings, Finding{"adr", SeverityWarning, "Device keeps using the slowest data rate: ADR may be disabled on the device"})
+	}
+	return findings
+}
+
+func (d *DeviceDiagnosis) checkEvents() (findings []Finding) {
+	if !d.Watched {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, event := range d.Events {
+		var check, message string
+		switch event.Event {
+		case types.UplinkErrorEvent:
+			check, message = "uplink errors", "Uplink error"
+		case types.DownlinkErrorEvent:
+			check, message = "downlink errors", "Downlink error"
+		case types.ActivationErrorEvent:
+			check, message = "activation errors", "Activation error"
+		case types.ActivationThrottledEvent:
+			check, message = "activation errors", "Activation throttled"
+		default:
+			continue
+		}
+		if data, ok := event.Data.(*types.ErrorEventData); ok && data.Error != "" {
+			message = fmt.Sprintf("%s: %s", message, data.Error)
+		}
+		if seen[message] {
+			continue
+		}
+		seen[message] = true
+		findings = append(findings, Finding{check, SeverityError, message})
+	}
+	return
+}
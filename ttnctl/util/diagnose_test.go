@@ -0,0 +1,189 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheThingsNetwork/api/discovery"
+	"github.com/TheThingsNetwork/api/handler"
+	"github.com/TheThingsNetwork/api/protocol/lorawan"
+	"github.com/TheThingsNetwork/ttn/core/types"
+	. "github.com/smartystreets/assertions"
+)
+
+func findingFor(findings []Finding, check string) *Finding {
+	for _, finding := range findings {
+		if finding.Check == check {
+			return &finding
+		}
+	}
+	return nil
+}
+
+func TestDiagnoseNeverJoined(t *testing.T) {
+	a := New(t)
+
+	appKey := types.AppKey{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+	diagnosis := &DeviceDiagnosis{
+		Device: &handler.Device{
+			AppID: "test",
+			DevID: "test",
+			Device: &handler.Device_LoRaWANDevice{LoRaWANDevice: &lorawan.Device{
+				AppKey: &appKey,
+			}},
+		},
+	}
+	findings := diagnosis.Diagnose()
+	a.So(findings, ShouldHaveLength, 1)
+	a.So(findings[0].Check, ShouldEqual, "activation")
+	a.So(findings[0].Severity, ShouldEqual, SeverityError)
+}
+
+func TestDiagnoseSession(t *testing.T) {
+	a := New(t)
+
+	now := time.Now()
+	devAddr := types.DevAddr{0x26, 0x00, 0x1A, 0xDA}
+	otherDevAddr := types.DevAddr{0x26, 0x00, 0x1A, 0xDB}
+	prefix, _ := types.ParseDevAddrPrefix("26000000/7")
+	prefixBytes, _ := prefix.Marshal()
+
+	diagnosis := &DeviceDiagnosis{
+		Device: &handler.Device{
+			AppID: "test",
+			DevID: "test",
+			Device: &handler.Device_LoRaWANDevice{LoRaWANDevice: &lorawan.Device{
+				DevAddr:  &devAddr,
+				FCntUp:   42,
+				LastSeen: now.Add(-2 * time.Hour).UnixNano(),
+			}},
+		},
+		Brokers: []*discovery.Announcement{
+			{ID: "dev", Metadata: []*discovery.Metadata{
+				{Metadata: &discovery.Metadata_DevAddrPrefix{DevAddrPrefix: prefixBytes}},
+			}},
+		},
+		LastSeenThreshold: time.Hour,
+		Now:               now,
+	}
+
+	findings := diagnosis.Diagnose()
+	a.So(findingFor(findings, "session").Severity, ShouldEqual, SeverityError)
+	a.So(findingFor(findings, "prefix").Severity, ShouldEqual, SeverityOK)
+	a.So(findingFor(findings, "last seen").Severity, ShouldEqual, SeverityWarning)
+	a.So(findingFor(findings, "fcnt"), ShouldBeNil)
+
+	diagnosis.NetworkServerDevice = &lorawan.Device{DevAddr: &otherDevAddr}
+	findings = diagnosis.Diagnose()
+	a.So(findingFor(findings, "session").Message, ShouldContainSubstring, "mismatch")
+
+	diagnosis.NetworkServerDevice = &lorawan.Device{DevAddr: &devAddr}
+	findings = diagnosis.Diagnose()
+	a.So(findingFor(findings, "session").Severity, ShouldEqual, SeverityOK)
+}
+
+func TestDiagnoseWatched(t *testing.T) {
+	a := New(t)
+
+	devAddr := types.DevAddr{0x26, 0x00, 0x1A, 0xDA}
+	diagnosis := &DeviceDiagnosis{
+		Device: &handler.Device{
+			AppID: "test",
+			DevID: "test",
+			Device: &handler.Device_LoRaWANDevice{LoRaWANDevice: &lorawan.Device{
+				DevAddr:  &devAddr,
+				LastSeen: time.Now().UnixNano(),
+			}},
+		},
+		Watched: true,
+		Uplinks: []types.UplinkMessage{
+			{FCnt: 1, Metadata: types.Metadata{DataRate: "SF12BW125"}},
+			{FCnt: 1, Metadata: types.Metadata{DataRate: "SF12BW125"}},
+		},
+		Events: []types.DeviceEvent{
+			{Event: types.UplinkErrorEvent, Data: &types.ErrorEventData{Error: "Decoder failed"}},
+			{Event: types.UplinkErrorEvent, Data: &types.ErrorEventData{Error: "Decoder failed"}},
+			{Event: types.DownlinkScheduledEvent},
+		},
+	}
+
+	findings := diagnosis.Diagnose()
+	a.So(findingFor(findings, "fcnt").Message, ShouldContainSubstring, "stuck")
+	a.So(findingFor(findings, "adr").Severity, ShouldEqual, SeverityWarning)
+	a.So(findingFor(findings, "uplink errors").Message, ShouldEqual, "Uplink error: Decoder failed")
+
+	errors := 0
+	for _, finding := range findings {
+		if finding.Check == "uplink errors" {
+			errors++
+		}
+	}
+	a.So(errors, ShouldEqual, 1)
+}
+
+func TestDiagnoseDownlinks(t *testing.T) {
+	a := New(t)
+
+	now := time.Now()
+	devAddr := types.DevAddr{0x26, 0x00, 0x1A, 0xDA}
+	lorawanDevice := &lorawan.Device{
+		DevAddr:  &devAddr,
+		LastSeen: now.Add(-time.Minute).UnixNano(),
+	}
+	diagnosis := &DeviceDiagnosis{
+		Device: &handler.Device{
+			AppID:  "test",
+			DevID:  "test",
+			Device: &handler.Device_LoRaWANDevice{LoRaWANDevice: lorawanDevice},
+		},
+		LastSeenThreshold: time.Hour,
+		Now:               now,
+	}
+
+	a.So(findingFor(diagnosis.Diagnose(), "downlinks"), ShouldBeNil)
+
+	diagnosis.QueuedDownlinks = 2
+	a.So(findingFor(diagnosis.Diagnose(), "downlinks").Severity, ShouldEqual, SeverityInfo)
+
+	lorawanDevice.LastSeen = now.Add(-2 * time.Hour).UnixNano()
+	a.So(findingFor(diagnosis.Diagnose(), "downlinks").Severity, ShouldEqual, SeverityWarning)
+}
+
+func TestDiagnoseFrames(t *testing.T) {
+	a := New(t)
+
+	devAddr := types.DevAddr{0x26, 0x00, 0x1A, 0xDA}
+	diagnosis := &DeviceDiagnosis{
+		Device: &handler.Device{
+			AppID: "test",
+			DevID: "test",
+			Device: &handler.Device_LoRaWANDevice{LoRaWANDevice: &lorawan.Device{
+				DevAddr:  &devAddr,
+				LastSeen: time.Now().UnixNano(),
+			}},
+		},
+	}
+
+	a.So(findingFor(diagnosis.Diagnose(), "frames"), ShouldBeNil)
+
+	diagnosis.Frames = []*types.Frame{
+		{FCnt: 10, GatewayCount: 2},
+		{FCnt: 9, GatewayCount: 1},
+		{FCnt: 8, GatewayCount: 3},
+	}
+	findings := diagnosis.Diagnose()
+	a.So(findings, ShouldContain, Finding{"frames", SeverityOK, "Received 3 of the last 3 frames (FCnt 8 to 10)"})
+	a.So(findings, ShouldNotContain, Finding{"frames", SeverityInfo, "The last 3 frames were all received by only one gateway"})
+
+	diagnosis.Frames = []*types.Frame{
+		{FCnt: 10, GatewayCount: 1},
+		{FCnt: 5, GatewayCount: 1},
+		{FCnt: 1, GatewayCount: 1},
+	}
+	findings = diagnosis.Diagnose()
+	a.So(findings, ShouldContain, Finding{"frames", SeverityWarning, "Lost 7 of the last 10 frames (FCnt 1 to 10)"})
+	a.So(findings, ShouldContain, Finding{"frames", SeverityInfo, "The last 3 frames were all received by only one gateway"})
+}
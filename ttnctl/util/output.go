@@ -0,0 +1,136 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	ttnlog "github.com/TheThingsNetwork/go-utils/log"
+	"github.com/apex/log"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Output formats that can be selected with the --output flag
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// GetOutputFormat returns the output format selected with the --output flag
+func GetOutputFormat() string {
+	format := viper.GetString("output")
+	if format == "" {
+		return OutputTable
+	}
+	return format
+}
+
+// ValidateOutputFormat returns an error if the selected output format is not supported
+func ValidateOutputFormat() error {
+	switch GetOutputFormat() {
+	case OutputTable, OutputJSON, OutputYAML:
+		return nil
+	default:
+		return fmt.Errorf("Invalid output format %s (should be %s, %s or %s)", GetOutputFormat(), OutputTable, OutputJSON, OutputYAML)
+	}
+}
+
+// IsStructuredOutput returns true if JSON or YAML output is selected
+func IsStructuredOutput() bool {
+	format := GetOutputFormat()
+	return format == OutputJSON || format == OutputYAML
+}
+
+// MarshalOutput marshals v in the given structured output format.
+// Both JSON and YAML output use the JSON field names of v, so that scripts can rely on the same field names.
+func MarshalOutput(format string, v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case OutputJSON:
+		return append(data, '\n'), nil
+	case OutputYAML:
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(generic)
+	default:
+		return nil, fmt.Errorf("Output format %s is not a structured format", format)
+	}
+}
+
+// PrintOutput prints v to stdout in the selected structured output format
+func PrintOutput(ctx ttnlog.Interface, v interface{}) {
+	data, err := MarshalOutput(GetOutputFormat(), v)
+	if err != nil {
+		ctx.WithError(err).Fatal("Could not marshal output")
+	}
+	os.Stdout.Write(data)
+}
+
+// OutputError is the structured representation of an error
+type OutputError struct {
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Error   string                 `json:"error,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// PrintOutputError prints the error to w in the selected structured output format
+func PrintOutputError(w io.Writer, outputErr OutputError) {
+	data, err := MarshalOutput(GetOutputFormat(), map[string]OutputError{"error": outputErr})
+	if err != nil {
+		fmt.Fprintln(w, outputErr.Message)
+		return
+	}
+	w.Write(data)
+}
+
+type structuredLogHandler struct {
+	mu     sync.Mutex
+	writer io.Writer
+	next   log.Handler
+}
+
+// NewStructuredLogHandler returns a log handler that writes errors to w in the selected structured
+// output format. Log entries with a lower level are passed to the next handler.
+func NewStructuredLogHandler(w io.Writer, next log.Handler) log.Handler {
+	return &structuredLogHandler{
+		writer: w,
+		next:   next,
+	}
+}
+
+func (h *structuredLogHandler) HandleLog(e *log.Entry) error {
+	if e.Level < log.ErrorLevel {
+		return h.next.HandleLog(e)
+	}
+
+	outputErr := OutputError{
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Fields:  make(map[string]interface{}),
+	}
+	for k, v := range e.Fields {
+		if k == "error" {
+			outputErr.Error = fmt.Sprint(v)
+			continue
+		}
+		outputErr.Fields[k] = fmt.Sprint(v)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	PrintOutputError(h.writer, outputErr)
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright © 2017 The Things Network
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apex/log"
+	. "github.com/smartystreets/assertions"
+	"github.com/spf13/viper"
+)
+
+type testOutput struct {
+	AppID string `json:"app_id"`
+	DevID string `json:"dev_id,omitempty"`
+}
+
+func TestMarshalOutput(t *testing.T) {
+	a := New(t)
+
+	out := testOutput{AppID: "test"}
+
+	data, err := MarshalOutput(OutputJSON, out)
+	a.So(err, ShouldBeNil)
+	a.So(string(data), ShouldEqual, "{\n  \"app_id\": \"test\"\n}\n")
+
+	data, err = MarshalOutput(OutputYAML, out)
+	a.So(err, ShouldBeNil)
+	a.So(string(data), ShouldEqual, "app_id: test\n")
+
+	_, err = MarshalOutput(OutputTable, out)
+	a.So(err, ShouldNotBeNil)
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	a := New(t)
+	defer viper.Set("output", "")
+
+	viper.Set("output", "")
+	a.So(ValidateOutputFormat(), ShouldBeNil)
+	a.So(IsStructuredOutput(), ShouldBeFalse)
+
+	viper.Set("output", OutputYAML)
+	a.So(ValidateOutputFormat(), ShouldBeNil)
+	a.So(IsStructuredOutput(), ShouldBeTrue)
+
+	viper.Set("output", "xml")
+	a.So(ValidateOutputFormat(), ShouldNotBeNil)
+}
+
+type testHandler struct {
+	entries []*log.Entry
+}
+
+func (h *testHandler) HandleLog(e *log.Entry) error {
+	h.entries = append(h.entries, e)
+	return nil
+}
+
+func TestStructuredLogHandler(t *testing.T) {
+	a := New(t)
+	defer viper.Set("output", "")
+	viper.Set("output", OutputJSON)
+
+	buf := new(bytes.Buffer)
+	next := &testHandler{}
+	logger := &log.Logger{
+		Level:   log.DebugLevel,
+		Handler: NewStructuredLogHandler(buf, next),
+	}
+
+	logger.Info("Found device")
+	a.So(next.entries, ShouldHaveLength, 1)
+	a.So(buf.Len(), ShouldEqual, 0)
+
+	logger.WithField("AppID", "test").Error("Could not get device")
+	a.So(next.entries, ShouldHaveLength, 1)
+	a.So(buf.String(), ShouldContainSubstring, `"message": "Could not get device"`)
+	a.So(buf.String(), ShouldContainSubstring, `"AppID": "test"`)
+}
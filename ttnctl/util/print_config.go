@@ -19,7 +19,10 @@ func PrintConfig(ctx ttnlog.Interface, debug bool) {
 	prt("Using config:")
 	fmt.Println()
 	printKV("config file", viper.ConfigFileUsed())
-	printKV("data dir", viper.GetString("data"))
+	printKV("data dir", GetDataDir())
+	if context := GetContextName(); context != "" {
+		printKV("context", context)
+	}
 	fmt.Println()
 
 	for key, val := range viper.AllSettings() {